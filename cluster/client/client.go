@@ -23,6 +23,9 @@ type RouteHandler func(ctx *Context)
 
 type EventHandler func(conn *Conn)
 
+// LoginHook 登录钩子，传入的连接将跳过断线期间的消息缓存直接发送消息
+type LoginHook func(conn *Conn) error
+
 type Client struct {
 	component.Base
 	opts                *options
@@ -81,6 +84,10 @@ func (c *Client) Start() {
 	c.opts.client.OnDisconnect(c.handleDisconnect)
 	c.opts.client.OnReceive(c.handleReceive)
 
+	if client, ok := c.opts.client.(network.ReconnectClient); ok {
+		client.OnReconnect(c.handleReconnect)
+	}
+
 	c.printInfo()
 
 	c.runHookFunc(cluster.Start)
@@ -119,6 +126,40 @@ func (c *Client) handleDisconnect(conn network.Conn) {
 	}
 }
 
+// 处理断线重连
+// 先执行登录钩子，再按序补发断线期间缓存的消息，最后触发断线重连事件
+func (c *Client) handleReconnect(conn network.Conn) {
+	val, ok := c.conns.Load(conn)
+	if !ok {
+		return
+	}
+
+	cc := val.(*Conn)
+
+	if c.opts.loginHook != nil {
+		if err := c.opts.loginHook(&Conn{conn: conn, client: c}); err != nil {
+			log.Errorf("replay login hook failed, cid: %d, err: %v", conn.ID(), err)
+			_ = cc.Close()
+			return
+		}
+	}
+
+	if err := cc.outbox.flush(conn); err != nil {
+		log.Warnf("flush buffered messages failed, cid: %d, err: %v", conn.ID(), err)
+	}
+
+	handlers, ok := c.events[cluster.Reconnect]
+	if !ok {
+		return
+	}
+
+	for _, handler := range handlers {
+		xcall.Call(func() {
+			handler(cc)
+		})
+	}
+}
+
 // 处理接收到的消息
 func (c *Client) handleReceive(conn network.Conn, buf buffer.Buffer) {
 	defer buf.Release()
@@ -172,7 +213,7 @@ func (c *Client) dial(opts ...DialOption) (*Conn, error) {
 		return nil, err
 	}
 
-	cc := &Conn{conn: conn, client: c}
+	cc := &Conn{conn: conn, client: c, outbox: &outbox{capacity: c.opts.buffer}}
 
 	for key, value := range o.attrs {
 		cc.SetAttr(key, value)
@@ -12,6 +12,7 @@ import (
 type Conn struct {
 	conn   network.Conn
 	client *Client
+	outbox *outbox // 断线重连期间的消息缓存，为nil时直接发送消息
 }
 
 // ID 获取连接ID
@@ -73,6 +74,20 @@ func (c *Conn) RemoteAddr() (net.Addr, error) {
 	return c.conn.RemoteAddr()
 }
 
+// Stats 获取连接流量统计
+func (c *Conn) Stats() *network.ConnStat {
+	return c.conn.Stats()
+}
+
+// CloseCode 获取服务端下发的关闭原因码及说明信息，可在连接断开事件中获取，未收到关闭包时返回false
+func (c *Conn) CloseCode() (network.CloseCode, string, bool) {
+	if cc, ok := c.conn.(network.CodeConn); ok {
+		return cc.CloseCode()
+	}
+
+	return network.CloseNone, "", false
+}
+
 // Push 推送消息
 func (c *Conn) Push(message *cluster.Message) error {
 	var (
@@ -107,6 +122,10 @@ func (c *Conn) Push(message *cluster.Message) error {
 		return err
 	}
 
+	if c.outbox != nil {
+		return c.outbox.push(c.conn, msg)
+	}
+
 	return c.conn.Push(msg)
 }
 
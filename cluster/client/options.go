@@ -11,17 +11,19 @@ import (
 )
 
 const (
-	defaultName    = "client"        // 默认客户端名称
-	defaultCodec   = "proto"         // 默认编解码器名称
-	defaultTimeout = 3 * time.Second // 默认超时时间
+	defaultName            = "client"        // 默认客户端名称
+	defaultCodec           = "proto"         // 默认编解码器名称
+	defaultTimeout         = 3 * time.Second // 默认超时时间
+	defaultReconnectBuffer = 1024            // 默认断线重连期间的最大缓存消息数
 )
 
 const (
-	defaultIDKey       = "etc.cluster.client.id"
-	defaultNameKey     = "etc.cluster.client.name"
-	defaultCodecKey    = "etc.cluster.client.codec"
-	defaultTimeoutKey  = "etc.cluster.client.timeout"
-	defaultAutoDialKey = "etc.cluster.client.autoDial"
+	defaultIDKey              = "etc.cluster.client.id"
+	defaultNameKey            = "etc.cluster.client.name"
+	defaultCodecKey           = "etc.cluster.client.codec"
+	defaultTimeoutKey         = "etc.cluster.client.timeout"
+	defaultAutoDialKey        = "etc.cluster.client.autoDial"
+	defaultReconnectBufferKey = "etc.cluster.client.reconnectBuffer"
 )
 
 type Option func(o *options)
@@ -34,6 +36,8 @@ type options struct {
 	client    network.Client   // 网络客户端
 	timeout   time.Duration    // RPC调用超时时间
 	encryptor crypto.Encryptor // 消息加密器
+	loginHook LoginHook        // 断线重连后的登录钩子
+	buffer    int              // 断线重连期间的最大缓存消息数
 }
 
 func defaultOptions() *options {
@@ -42,6 +46,7 @@ func defaultOptions() *options {
 		name:    defaultName,
 		codec:   encoding.Invoke(defaultCodec),
 		timeout: defaultTimeout,
+		buffer:  etc.Get(defaultReconnectBufferKey, defaultReconnectBuffer).Int(),
 	}
 
 	if id := etc.Get(defaultIDKey).String(); id != "" {
@@ -100,6 +105,16 @@ func WithEncryptor(encryptor crypto.Encryptor) Option {
 	return func(o *options) { o.encryptor = encryptor }
 }
 
+// WithLoginHook 设置断线重连后的登录钩子，用于重放登录、绑定及订阅等操作，钩子返回错误时将关闭连接
+func WithLoginHook(hook LoginHook) Option {
+	return func(o *options) { o.loginHook = hook }
+}
+
+// WithReconnectBuffer 设置断线重连期间的最大缓存消息数，为0时不缓存
+func WithReconnectBuffer(buffer int) Option {
+	return func(o *options) { o.buffer = buffer }
+}
+
 type DialOption func(o *dialOptions)
 
 type dialOptions struct {
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+)
+
+type outbox struct {
+	mu        sync.Mutex
+	capacity  int      // 最大缓存消息数
+	buffering bool     // 是否处于缓存状态
+	messages  [][]byte // 缓存的消息
+}
+
+// 推送消息，连接重连期间缓存消息，缓存已满时返回ErrWriteQueueFull
+func (o *outbox) push(conn network.Conn, msg []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.buffering {
+		err := conn.Push(msg)
+		if o.capacity <= 0 || !errors.Is(err, errors.ErrConnectionReconnecting) {
+			return err
+		}
+
+		o.buffering = true
+	}
+
+	if len(o.messages) >= o.capacity {
+		return errors.ErrWriteQueueFull
+	}
+
+	o.messages = append(o.messages, msg)
+
+	return nil
+}
+
+// 按序补发缓存的消息，再次断线时保留未发送的消息
+func (o *outbox) flush(conn network.Conn) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, msg := range o.messages {
+		if err := conn.Push(msg); err != nil {
+			o.messages = append(o.messages[:0], o.messages[i:]...)
+			return err
+		}
+	}
+
+	o.messages = nil
+	o.buffering = false
+
+	return nil
+}
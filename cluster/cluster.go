@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/session"
 )
 
@@ -160,17 +161,25 @@ type TriggerArgs struct {
 	UID   int64 // 用户ID
 }
 
-type IsOnlineArgs struct {
+type GetConnStatsArgs struct {
 	GID    string       // 网关ID，会话类型为用户时可忽略此参数
 	Kind   session.Kind // 会话类型，session.Conn 或 session.User
 	Target int64        // 会话目标，CID 或 UID
 }
 
-type DisconnectArgs struct {
+type IsOnlineArgs struct {
 	GID    string       // 网关ID，会话类型为用户时可忽略此参数
 	Kind   session.Kind // 会话类型，session.Conn 或 session.User
 	Target int64        // 会话目标，CID 或 UID
-	Force  bool         // 是否强制断开
+}
+
+type DisconnectArgs struct {
+	GID     string            // 网关ID，会话类型为用户时可忽略此参数
+	Kind    session.Kind      // 会话类型，session.Conn 或 session.User
+	Target  int64             // 会话目标，CID 或 UID
+	Force   bool              // 是否强制断开
+	Code    network.CloseCode // 关闭原因码，不为network.CloseNone时向客户端下发关闭包
+	Message string            // 关闭说明信息，随关闭原因码一并下发
 }
 
 type DeliverArgs struct {
@@ -178,3 +187,29 @@ type DeliverArgs struct {
 	UID     int64    // 用户ID
 	Message *Message // 消息
 }
+
+// BatchStat 内部链接合并写入统计
+type BatchStat struct {
+	Batches   uint64 `json:"batches"`   // 写入次数
+	Frames    uint64 `json:"frames"`    // 写入消息数
+	Bytes     uint64 `json:"bytes"`     // 写入字节数
+	MaxFrames uint64 `json:"maxFrames"` // 单次写入的最大消息数
+}
+
+// AvgFrames 平均每次写入的消息数
+func (s *BatchStat) AvgFrames() float64 {
+	if s.Batches == 0 {
+		return 0
+	}
+
+	return float64(s.Frames) / float64(s.Batches)
+}
+
+// AvgBytes 平均每次写入的字节数
+func (s *BatchStat) AvgBytes() float64 {
+	if s.Batches == 0 {
+		return 0
+	}
+
+	return float64(s.Bytes) / float64(s.Batches)
+}
@@ -12,13 +12,17 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/component"
 	"github.com/devagame/due/v2/core/buffer"
 	"github.com/devagame/due/v2/core/info"
 	"github.com/devagame/due/v2/core/net"
+	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/internal/transporter/gate"
+	"github.com/devagame/due/v2/internal/transporter/security"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/registry"
@@ -36,6 +40,8 @@ type Gate struct {
 	session  *session.Session
 	linker   *gate.Server
 	wg       *sync.WaitGroup
+	timeout  atomic.Int64 // 超时时间，可在运行时调整
+	unwatch  func()
 }
 
 func NewGate(opts ...Option) *Gate {
@@ -51,6 +57,7 @@ func NewGate(opts ...Option) *Gate {
 	g.session = session.NewSession()
 	g.state.Store(int32(cluster.Shut))
 	g.wg = &sync.WaitGroup{}
+	g.timeout.Store(int64(o.timeout))
 
 	return g
 }
@@ -66,10 +73,24 @@ func (g *Gate) Init() {
 		log.Fatal("instance id can not be empty")
 	}
 
-	if g.opts.server == nil {
+	if len(g.opts.servers) == 0 {
 		log.Fatal("server component is not injected")
 	}
 
+	if len(g.opts.servers) > maxServers {
+		log.Fatalf("too many server components, at most %d", maxServers)
+	}
+
+	labels := make(map[string]struct{}, len(g.opts.servers))
+	for i, s := range g.opts.servers {
+		if _, ok := labels[s.label]; ok {
+			log.Fatalf("duplicate server label: %s", s.label)
+		}
+
+		labels[s.label] = struct{}{}
+		s.index = int64(i)
+	}
+
 	if g.opts.locator == nil {
 		log.Fatal("locator component is not injected")
 	}
@@ -93,6 +114,8 @@ func (g *Gate) Start() {
 
 	g.proxy.watch()
 
+	g.watch()
+
 	g.printInfo()
 }
 
@@ -121,24 +144,102 @@ func (g *Gate) Destroy() {
 
 	g.stopLinkerServer()
 
+	if g.unwatch != nil {
+		g.unwatch()
+	}
+
 	g.cancel()
 }
 
+// 监听etc配置变化
+// 超时时间来自etc配置时可在运行时生效，其他配置需重启后生效
+func (g *Gate) watch() {
+	if g.opts.watchTimeout {
+		g.unwatch = etc.OnChange(defaultTimeoutKey, func(val value.Value) {
+			if timeout := val.Duration(); timeout > 0 {
+				g.timeout.Store(int64(timeout))
+			}
+		}, defaultTimeout)
+	}
+
+	etc.RestartOnly(defaultIDKey, defaultNameKey, defaultAddrKey, defaultExposeKey, defaultDispatchKey, defaultMetadataKey)
+}
+
+// Stats 获取各网络服务器的连接统计
+func (g *Gate) Stats() []*ServerStat {
+	stats := make([]*ServerStat, 0, len(g.opts.servers))
+	for _, s := range g.opts.servers {
+		stats = append(stats, s.stat())
+	}
+
+	return stats
+}
+
+// LinkStats 获取与各节点连接的合并写入统计，键为节点地址
+func (g *Gate) LinkStats() map[string]*cluster.BatchStat {
+	return g.proxy.nodeLinker.Stats()
+}
+
+// Deny 将IP或网段加入各网络服务器的动态黑名单，拒绝其后续连接
+func (g *Gate) Deny(cidrs ...string) error {
+	return g.guard(func(guard *network.Guard) error { return guard.Deny(cidrs...) })
+}
+
+// Undeny 将IP或网段移出各网络服务器的动态黑名单
+func (g *Gate) Undeny(cidrs ...string) {
+	_ = g.guard(func(guard *network.Guard) error { guard.Undeny(cidrs...); return nil })
+}
+
+// Allow 将IP或网段加入各网络服务器的动态白名单
+func (g *Gate) Allow(cidrs ...string) error {
+	return g.guard(func(guard *network.Guard) error { return guard.Allow(cidrs...) })
+}
+
+// Disallow 将IP或网段移出各网络服务器的动态白名单
+func (g *Gate) Disallow(cidrs ...string) {
+	_ = g.guard(func(guard *network.Guard) error { guard.Disallow(cidrs...); return nil })
+}
+
+// 对支持连接准入控制的网络服务器执行操作
+func (g *Gate) guard(fn func(guard *network.Guard) error) error {
+	for _, s := range g.opts.servers {
+		if gs, ok := s.Server.(network.GuardServer); ok {
+			if err := fn(gs.Guard()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // 启动网络服务器
 func (g *Gate) startNetworkServer() {
-	g.opts.server.OnConnect(g.handleConnect)
-	g.opts.server.OnDisconnect(g.handleDisconnect)
-	g.opts.server.OnReceive(g.handleReceive)
-
-	if err := g.opts.server.Start(); err != nil {
-		log.Fatalf("network server start failed: %v", err)
+	for _, s := range g.opts.servers {
+		s.OnConnect(func(conn network.Conn) {
+			s.connect(conn)
+			g.handleConnect(s.wrap(conn))
+		})
+		s.OnDisconnect(func(conn network.Conn) {
+			g.handleDisconnect(s.wrap(conn))
+			s.disconnect(conn)
+		})
+		s.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+			g.handleReceive(s.wrap(conn), buf)
+		})
+
+		if err := s.Start(); err != nil {
+			log.Fatalf("%s server start failed: %v", s.label, err)
+		}
 	}
 }
 
 // 停止网关服务器
 func (g *Gate) stopNetworkServer() {
-	if err := g.opts.server.Stop(); err != nil {
-		log.Errorf("network server stop failed: %v", err)
+	for _, s := range g.opts.servers {
+		if err := s.Stop(); err != nil {
+			log.Errorf("%s server stop failed: %v", s.label, err)
+		}
 	}
 }
 
@@ -150,7 +251,7 @@ func (g *Gate) handleConnect(conn network.Conn) {
 
 	cid, uid := conn.ID(), conn.UID()
 
-	ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
+	ctx, cancel := context.WithTimeout(g.ctx, time.Duration(g.timeout.Load()))
 	g.proxy.trigger(ctx, cluster.Connect, cid, uid)
 	cancel()
 }
@@ -160,12 +261,12 @@ func (g *Gate) handleDisconnect(conn network.Conn) {
 	g.session.RemConn(conn)
 
 	if cid, uid := conn.ID(), conn.UID(); uid != 0 {
-		ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
+		ctx, cancel := context.WithTimeout(g.ctx, time.Duration(g.timeout.Load()))
 		_ = g.proxy.unbindGate(ctx, cid, uid)
 		g.proxy.trigger(ctx, cluster.Disconnect, cid, uid)
 		cancel()
 	} else {
-		ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
+		ctx, cancel := context.WithTimeout(g.ctx, time.Duration(g.timeout.Load()))
 		g.proxy.trigger(ctx, cluster.Disconnect, cid, uid)
 		cancel()
 	}
@@ -176,16 +277,23 @@ func (g *Gate) handleDisconnect(conn network.Conn) {
 // 处理接收到的消息
 func (g *Gate) handleReceive(conn network.Conn, buf buffer.Buffer) {
 	cid, uid := conn.ID(), conn.UID()
-	ctx, cancel := context.WithTimeout(g.ctx, g.opts.timeout)
+	ctx, cancel := context.WithTimeout(g.ctx, time.Duration(g.timeout.Load()))
 	g.proxy.deliver(ctx, cid, uid, buf)
 	cancel()
 }
 
 // 启动传输服务器
 func (g *Gate) startLinkerServer() {
+	sec, err := security.Load()
+	if err != nil {
+		log.Fatalf("link security load failed: %v", err)
+	}
+
 	transporter, err := gate.NewServer(&provider{gate: g}, &gate.ServerOptions{
-		Addr:   g.opts.addr,
-		Expose: g.opts.expose,
+		Addr:      g.opts.addr,
+		Expose:    g.opts.expose,
+		TLSConfig: sec.ServerTLS,
+		Secret:    sec.Secret,
 	})
 	if err != nil {
 		log.Fatalf("link server create failed: %v", err)
@@ -260,11 +368,13 @@ func (g *Gate) getState() cluster.State {
 
 // 打印组件信息
 func (g *Gate) printInfo() {
-	infos := make([]string, 0, 6)
+	infos := make([]string, 0, 5+len(g.opts.servers))
 	infos = append(infos, fmt.Sprintf("ID: %s", g.opts.id))
 	infos = append(infos, fmt.Sprintf("Name: %s", g.Name()))
 	infos = append(infos, fmt.Sprintf("Link: %s", g.linker.ExposeAddr()))
-	infos = append(infos, fmt.Sprintf("Server: [%s] %s", g.opts.server.Protocol(), net.FulfillAddr(g.opts.server.Addr())))
+	for _, s := range g.opts.servers {
+		infos = append(infos, fmt.Sprintf("Server: [%s] %s", s.label, net.FulfillAddr(s.Addr())))
+	}
 	infos = append(infos, fmt.Sprintf("Locator: %s", g.opts.locator.Name()))
 	infos = append(infos, fmt.Sprintf("Registry: %s", g.opts.registry.Name()))
 
@@ -41,28 +41,30 @@ const (
 type Option func(o *options)
 
 type options struct {
-	ctx      context.Context   // 上下文
-	id       string            // 实例ID
-	name     string            // 实例名称
-	addr     string            // 监听地址
-	expose   bool              // 是否将内部通信地址暴露到公网
-	timeout  time.Duration     // RPC调用超时时间
-	server   network.Server    // 网关服务器
-	locator  locate.Locator    // 用户定位器
-	registry registry.Registry // 服务注册器
-	dispatch cluster.Dispatch  // 无状态路由消息分发策略
-	metadata map[string]string // 元数据
+	ctx          context.Context   // 上下文
+	id           string            // 实例ID
+	name         string            // 实例名称
+	addr         string            // 监听地址
+	expose       bool              // 是否将内部通信地址暴露到公网
+	timeout      time.Duration     // RPC调用超时时间
+	watchTimeout bool              // 是否监听etc中超时时间的变化，显式设置超时时间时不监听
+	servers      []*server         // 网关服务器
+	locator      locate.Locator    // 用户定位器
+	registry     registry.Registry // 服务注册器
+	dispatch     cluster.Dispatch  // 无状态路由消息分发策略
+	metadata     map[string]string // 元数据
 }
 
 func defaultOptions() *options {
 	opts := &options{
-		ctx:      context.Background(),
-		name:     defaultName,
-		addr:     defaultAddr,
-		timeout:  defaultTimeout,
-		dispatch: defaultDispatch,
-		metadata: make(map[string]string),
-		expose:   etc.Get(defaultExposeKey).Bool(),
+		ctx:          context.Background(),
+		name:         defaultName,
+		addr:         defaultAddr,
+		timeout:      defaultTimeout,
+		watchTimeout: true,
+		dispatch:     defaultDispatch,
+		metadata:     make(map[string]string),
+		expose:       etc.Get(defaultExposeKey).Bool(),
 	}
 
 	if id := etc.Get(defaultIDKey).String(); id != "" {
@@ -119,14 +121,23 @@ func WithContext(ctx context.Context) Option {
 	return func(o *options) { o.ctx = ctx }
 }
 
-// WithServer 设置服务器
-func WithServer(server network.Server) Option {
-	return func(o *options) { o.server = server }
+// WithServer 设置服务器，可同时设置多个不同协议的服务器，协议标签默认为服务器协议
+func WithServer(servers ...network.Server) Option {
+	return func(o *options) {
+		for _, s := range servers {
+			o.servers = append(o.servers, &server{Server: s, label: s.Protocol()})
+		}
+	}
+}
+
+// WithLabeledServer 设置服务器并指定协议标签，用于区分同一协议的多个服务器
+func WithLabeledServer(label string, s network.Server) Option {
+	return func(o *options) { o.servers = append(o.servers, &server{Server: s, label: label}) }
 }
 
 // WithTimeout 设置RPC调用超时时间
 func WithTimeout(timeout time.Duration) Option {
-	return func(o *options) { o.timeout = timeout }
+	return func(o *options) { o.timeout, o.watchTimeout = timeout, false }
 }
 
 // WithLocator 设置用户定位器
@@ -6,6 +6,7 @@ import (
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/session"
 	"github.com/devagame/due/v2/utils/xcall"
 )
@@ -52,6 +53,11 @@ func (p *provider) GetIP(ctx context.Context, kind session.Kind, target int64) (
 	return p.gate.session.RemoteIP(kind, target)
 }
 
+// GetConnStats 获取连接流量统计
+func (p *provider) GetConnStats(ctx context.Context, kind session.Kind, target int64) (*network.ConnStat, error) {
+	return p.gate.session.Stats(kind, target)
+}
+
 // IsOnline 检测是否在线
 func (p *provider) IsOnline(ctx context.Context, kind session.Kind, target int64) (bool, error) {
 	return p.gate.session.Has(kind, target)
@@ -63,8 +69,8 @@ func (p *provider) Stat(ctx context.Context, kind session.Kind) (int64, error) {
 }
 
 // Disconnect 断开连接
-func (p *provider) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool) error {
-	return p.gate.session.Close(kind, target, force)
+func (p *provider) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool, code network.CloseCode, message string) error {
+	return p.gate.session.CloseWithCode(kind, target, code, message, force)
 }
 
 // Push 发送消息
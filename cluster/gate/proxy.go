@@ -7,6 +7,7 @@ import (
 	"github.com/devagame/due/v2/core/buffer"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/internal/link"
+	"github.com/devagame/due/v2/internal/transporter/security"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/mode"
 	"github.com/devagame/due/v2/packet"
@@ -18,12 +19,19 @@ type proxy struct {
 }
 
 func newProxy(gate *Gate) *proxy {
+	sec, err := security.Load()
+	if err != nil {
+		log.Fatalf("link security load failed: %v", err)
+	}
+
 	return &proxy{gate: gate, nodeLinker: link.NewNodeLinker(gate.ctx, &link.Options{
-		InsID:    gate.opts.id,
-		InsKind:  cluster.Gate,
-		Locator:  gate.opts.locator,
-		Registry: gate.opts.registry,
-		Dispatch: gate.opts.dispatch,
+		InsID:     gate.opts.id,
+		InsKind:   cluster.Gate,
+		Locator:   gate.opts.locator,
+		Registry:  gate.opts.registry,
+		Dispatch:  gate.opts.dispatch,
+		TLSConfig: sec.ClientTLS,
+		Secret:    sec.Secret,
 	})}
 }
 
@@ -0,0 +1,194 @@
+package gate
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/network"
+)
+
+// 连接ID中服务器索引的偏移位数
+// 各网络服务器独立生成连接ID，网关以服务器索引作为连接ID的高位以保证连接ID在网关内唯一，首个服务器的连接ID保持不变
+const serverIndexShift = 48
+
+// 网关最多支持的网络服务器数量
+const maxServers = 1 << (63 - serverIndexShift)
+
+type server struct {
+	network.Server
+	index  int64        // 服务器索引
+	label  string       // 协议标签
+	online atomic.Int64 // 在线连接数
+	total  atomic.Int64 // 累计连接数
+	conns  sync.Map     // 在线连接
+	closed traffic      // 已关闭连接的累计流量
+}
+
+// 累计流量
+type traffic struct {
+	bytesIn     atomic.Int64
+	bytesOut    atomic.Int64
+	messagesIn  atomic.Int64
+	messagesOut atomic.Int64
+}
+
+// 累加连接流量
+func (t *traffic) add(stat *network.ConnStat) {
+	t.bytesIn.Add(stat.BytesIn)
+	t.bytesOut.Add(stat.BytesOut)
+	t.messagesIn.Add(stat.MessagesIn)
+	t.messagesOut.Add(stat.MessagesOut)
+}
+
+// ServerStat 网络服务器连接统计
+type ServerStat struct {
+	Label    string // 协议标签
+	Protocol string // 协议
+	Addr     string // 监听地址
+	Online   int64  // 在线连接数
+	Total    int64  // 累计连接数
+	Dropped  int64  // 因写入队列溢出丢弃的消息数
+	Slow     int64  // 因慢消费断开的连接数
+	// 流量统计，含已关闭的连接
+	BytesIn     int64         // 累计接收字节数
+	BytesOut    int64         // 累计发送字节数
+	MessagesIn  int64         // 累计接收消息数
+	MessagesOut int64         // 累计发送消息数
+	RTT         time.Duration // 在线连接的平均往返时延，未测得时为0
+}
+
+// 记录连接打开
+func (s *server) connect(c network.Conn) {
+	s.online.Add(1)
+	s.total.Add(1)
+	s.conns.Store(c.ID(), c)
+}
+
+// 记录连接关闭，累加其流量
+func (s *server) disconnect(c network.Conn) {
+	if _, ok := s.conns.LoadAndDelete(c.ID()); ok {
+		s.closed.add(c.Stats())
+		s.online.Add(-1)
+	}
+}
+
+// 包装网络连接，使其ID在网关内唯一
+func (s *server) wrap(c network.Conn) network.Conn {
+	if s.index == 0 {
+		return c
+	}
+
+	return conn{Conn: c, id: s.index<<serverIndexShift | c.ID()}
+}
+
+// 获取统计信息
+func (s *server) stat() *ServerStat {
+	stat := &ServerStat{
+		Label:    s.label,
+		Protocol: s.Protocol(),
+		Addr:     s.Addr(),
+		Online:   s.online.Load(),
+		Total:    s.total.Load(),
+	}
+
+	if qs, ok := s.Server.(network.QueueStater); ok {
+		queue := qs.QueueStat()
+		stat.Dropped, stat.Slow = queue.Dropped, queue.Disconnected
+	}
+
+	stat.BytesIn = s.closed.bytesIn.Load()
+	stat.BytesOut = s.closed.bytesOut.Load()
+	stat.MessagesIn = s.closed.messagesIn.Load()
+	stat.MessagesOut = s.closed.messagesOut.Load()
+
+	var (
+		rtt     time.Duration
+		samples int64
+	)
+
+	s.conns.Range(func(_, value any) bool {
+		cs := value.(network.Conn).Stats()
+		stat.BytesIn += cs.BytesIn
+		stat.BytesOut += cs.BytesOut
+		stat.MessagesIn += cs.MessagesIn
+		stat.MessagesOut += cs.MessagesOut
+
+		if cs.RTT > 0 {
+			rtt += cs.RTT
+			samples++
+		}
+
+		return true
+	})
+
+	if samples > 0 {
+		stat.RTT = rtt / time.Duration(samples)
+	}
+
+	return stat
+}
+
+// 网关内的网络连接
+// 使用值类型以保证同一底层连接多次包装后仍可作为相同的map键
+type conn struct {
+	network.Conn
+	id int64
+}
+
+var (
+	_ network.WrappedConn  = conn{}
+	_ network.ProxyConn    = conn{}
+	_ network.ReasonConn   = conn{}
+	_ network.CodeConn     = conn{}
+	_ network.CriticalConn = conn{}
+	_ network.ForceCloser  = conn{}
+)
+
+// ID 获取连接ID
+func (c conn) ID() int64 {
+	return c.id
+}
+
+// Unwrap 获取被包装的连接
+func (c conn) Unwrap() network.Conn {
+	return c.Conn
+}
+
+// ProxyAddr 获取代理地址
+func (c conn) ProxyAddr() (net.Addr, error) {
+	if pc, ok := c.Conn.(network.ProxyConn); ok {
+		return pc.ProxyAddr()
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// CloseReason 获取连接关闭原因
+func (c conn) CloseReason() network.CloseReason {
+	if rc, ok := c.Conn.(network.ReasonConn); ok {
+		return rc.CloseReason()
+	}
+
+	return network.ReasonNone
+}
+
+// CloseCode 获取关闭原因码及说明信息
+func (c conn) CloseCode() (network.CloseCode, string, bool) {
+	if cc, ok := c.Conn.(network.CodeConn); ok {
+		return cc.CloseCode()
+	}
+
+	return network.CloseNone, "", false
+}
+
+// PushCritical 推送关键消息（异步）
+func (c conn) PushCritical(msg []byte) error {
+	return network.PushCritical(c.Conn, msg)
+}
+
+// ForceClose 在超时时间内尝试直接写入一次消息后强制关闭连接
+func (c conn) ForceClose(msg []byte, timeout time.Duration) error {
+	return network.ForceClose(c.Conn, msg, timeout)
+}
@@ -0,0 +1,171 @@
+package gate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/network"
+)
+
+type testConn struct {
+	network.Conn
+	id       int64
+	stat     network.ConnStat
+	critical [][]byte
+}
+
+func (c *testConn) ID() int64 {
+	return c.id
+}
+
+func (c *testConn) Stats() *network.ConnStat {
+	stat := c.stat
+	return &stat
+}
+
+func (c *testConn) CloseReason() network.CloseReason {
+	return network.ReasonSlowConsumer
+}
+
+func (c *testConn) PushCritical(msg []byte) error {
+	c.critical = append(c.critical, msg)
+	return nil
+}
+
+func (c *testConn) Subprotocol() string {
+	return "json"
+}
+
+type testServer struct {
+	network.Server
+	stat network.QueueStat
+}
+
+func (s *testServer) Protocol() string {
+	return "tcp"
+}
+
+func (s *testServer) Addr() string {
+	return "127.0.0.1:3553"
+}
+
+func (s *testServer) QueueStat() *network.QueueStat {
+	stat := s.stat
+	return &stat
+}
+
+func TestServer_Wrap(t *testing.T) {
+	servers := make([]*server, 3)
+	for i := range servers {
+		servers[i] = &server{Server: &testServer{}, index: int64(i)}
+	}
+
+	c := &testConn{id: 1}
+
+	if wrapped := servers[0].wrap(c); wrapped != network.Conn(c) {
+		t.Fatal("the connection of the first server should not be wrapped")
+	}
+
+	ids := make(map[int64]int)
+	for i, s := range servers {
+		id := s.wrap(c).ID()
+
+		if j, ok := ids[id]; ok {
+			t.Fatalf("connection id %d of server %d collides with server %d", id, i, j)
+		}
+
+		ids[id] = i
+	}
+
+	if id := servers[2].wrap(c).ID(); id != 2<<serverIndexShift|1 {
+		t.Fatalf("id = %d, want %d", id, int64(2<<serverIndexShift|1))
+	}
+
+	// 底层连接ID达到偏移位数上限时仍不与其他服务器冲突
+	last := &testConn{id: 1<<serverIndexShift - 1}
+	if servers[1].wrap(last).ID() == servers[2].wrap(&testConn{id: 0}).ID() {
+		t.Fatal("connection id collides at the boundary of server index")
+	}
+
+	if servers[1].wrap(c) != servers[1].wrap(c) {
+		t.Fatal("the same connection wrapped twice should be equal")
+	}
+}
+
+func TestServer_WrapInterfaces(t *testing.T) {
+	s := &server{Server: &testServer{}, index: 1}
+	c := &testConn{id: 1}
+	wrapped := s.wrap(c)
+
+	if network.Unwrap(wrapped) != network.Conn(c) {
+		t.Fatal("unwrap should return the original connection")
+	}
+
+	if sc, ok := network.Unwrap(wrapped).(interface{ Subprotocol() string }); !ok || sc.Subprotocol() != "json" {
+		t.Fatal("the extended interface of the original connection is not accessible")
+	}
+
+	if reason := wrapped.(network.ReasonConn).CloseReason(); reason != network.ReasonSlowConsumer {
+		t.Fatalf("close reason = %v, want %v", reason, network.ReasonSlowConsumer)
+	}
+
+	if err := network.PushCritical(wrapped, []byte("close")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.critical) != 1 {
+		t.Fatal("critical message is not forwarded to the original connection")
+	}
+
+	if _, _, ok := wrapped.(network.CodeConn).CloseCode(); ok {
+		t.Fatal("close code should not be available")
+	}
+}
+
+func TestServer_Stat(t *testing.T) {
+	s := &server{
+		Server: &testServer{stat: network.QueueStat{Dropped: 3, Disconnected: 1}},
+		index:  1,
+		label:  "tcp-inner",
+	}
+
+	c1 := &testConn{id: 1, stat: network.ConnStat{BytesIn: 10, BytesOut: 20, MessagesIn: 1, MessagesOut: 2, RTT: 10 * time.Millisecond}}
+	c2 := &testConn{id: 2, stat: network.ConnStat{BytesIn: 100, BytesOut: 200, MessagesIn: 10, MessagesOut: 20, RTT: 30 * time.Millisecond}}
+	c3 := &testConn{id: 3, stat: network.ConnStat{BytesIn: 1000, BytesOut: 2000, MessagesIn: 100, MessagesOut: 200}}
+
+	s.connect(c1)
+	s.connect(c2)
+	s.connect(c3)
+	s.disconnect(c3)
+
+	stat := s.stat()
+
+	if stat.Label != "tcp-inner" || stat.Protocol != "tcp" || stat.Addr != "127.0.0.1:3553" {
+		t.Fatalf("label = %s, protocol = %s, addr = %s", stat.Label, stat.Protocol, stat.Addr)
+	}
+
+	if stat.Online != 2 || stat.Total != 3 {
+		t.Fatalf("online = %d, total = %d, want 2 and 3", stat.Online, stat.Total)
+	}
+
+	if stat.Dropped != 3 || stat.Slow != 1 {
+		t.Fatalf("dropped = %d, slow = %d, want 3 and 1", stat.Dropped, stat.Slow)
+	}
+
+	// 流量统计包含已关闭的连接
+	if stat.BytesIn != 1110 || stat.BytesOut != 2220 || stat.MessagesIn != 111 || stat.MessagesOut != 222 {
+		t.Fatalf("bytes = %d/%d, messages = %d/%d", stat.BytesIn, stat.BytesOut, stat.MessagesIn, stat.MessagesOut)
+	}
+
+	// 平均往返时延仅统计在线且已测得时延的连接
+	if stat.RTT != 20*time.Millisecond {
+		t.Fatalf("rtt = %v, want %v", stat.RTT, 20*time.Millisecond)
+	}
+
+	// 重复关闭不应重复累加流量及扣减在线连接数
+	s.disconnect(c3)
+
+	if stat = s.stat(); stat.BytesIn != 1110 || stat.Online != 2 {
+		t.Fatalf("bytes in = %d, online = %d, want 1110 and 2", stat.BytesIn, stat.Online)
+	}
+}
@@ -129,6 +129,8 @@ func (m *Mesh) Proxy() *Proxy {
 // 启动传输服务器
 func (m *Mesh) startTransportServer() {
 	m.opts.transporter.SetDefaultDiscovery(m.opts.registry)
+	m.opts.transporter.UseClientInterceptors(m.opts.clientInterceptors...)
+	m.opts.transporter.UseServerInterceptors(m.opts.serverInterceptors...)
 
 	transporter, err := m.opts.transporter.NewServer()
 	if err != nil {
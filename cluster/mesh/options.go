@@ -32,16 +32,18 @@ const (
 type Option func(o *options)
 
 type options struct {
-	id          string                // 实例ID
-	name        string                // 实例名称
-	ctx         context.Context       // 上下文
-	codec       encoding.Codec        // 编解码器
-	timeout     time.Duration         // RPC调用超时时间
-	locator     locate.Locator        // 用户定位器
-	registry    registry.Registry     // 服务注册器
-	encryptor   crypto.Encryptor      // 消息加密器
-	transporter transport.Transporter // 消息传输器
-	metadata    map[string]string     // 元数据
+	id                 string                        // 实例ID
+	name               string                        // 实例名称
+	ctx                context.Context               // 上下文
+	codec              encoding.Codec                // 编解码器
+	timeout            time.Duration                 // RPC调用超时时间
+	locator            locate.Locator                // 用户定位器
+	registry           registry.Registry             // 服务注册器
+	encryptor          crypto.Encryptor              // 消息加密器
+	transporter        transport.Transporter         // 消息传输器
+	clientInterceptors []transport.ClientInterceptor // 传输客户端拦截器
+	serverInterceptors []transport.ServerInterceptor // 传输服务端拦截器
+	metadata           map[string]string             // 元数据
 }
 
 func defaultOptions() *options {
@@ -118,6 +120,16 @@ func WithTransporter(transporter transport.Transporter) Option {
 	return func(o *options) { o.transporter = transporter }
 }
 
+// WithClientInterceptors 设置传输客户端拦截器，作用于通过代理发起的所有微服务调用
+func WithClientInterceptors(interceptors ...transport.ClientInterceptor) Option {
+	return func(o *options) { o.clientInterceptors = interceptors }
+}
+
+// WithServerInterceptors 设置传输服务端拦截器，作用于当前实例提供的所有微服务
+func WithServerInterceptors(interceptors ...transport.ServerInterceptor) Option {
+	return func(o *options) { o.serverInterceptors = interceptors }
+}
+
 // WithMetadata 设置元数据
 func WithMetadata(metadata map[string]string) Option {
 	return func(o *options) { maps.Copy(o.metadata, metadata) }
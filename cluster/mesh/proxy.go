@@ -5,6 +5,9 @@ import (
 
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/internal/link"
+	"github.com/devagame/due/v2/internal/transporter/security"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/registry"
 	"github.com/devagame/due/v2/session"
 	"github.com/devagame/due/v2/transport"
@@ -17,6 +20,11 @@ type Proxy struct {
 }
 
 func newProxy(mesh *Mesh) *Proxy {
+	sec, err := security.Load()
+	if err != nil {
+		log.Fatalf("link security load failed: %v", err)
+	}
+
 	opts := &link.Options{
 		InsID:     mesh.opts.id,
 		InsKind:   cluster.Mesh,
@@ -24,6 +32,8 @@ func newProxy(mesh *Mesh) *Proxy {
 		Locator:   mesh.opts.locator,
 		Registry:  mesh.opts.registry,
 		Encryptor: mesh.opts.encryptor,
+		TLSConfig: sec.ClientTLS,
+		Secret:    sec.Secret,
 	}
 
 	return &Proxy{
@@ -141,6 +151,11 @@ func (p *Proxy) Stat(ctx context.Context, kind session.Kind) (int64, error) {
 	return p.gateLinker.Stat(ctx, kind)
 }
 
+// GetConnStats 获取连接流量统计，包含收发字节数、收发消息数、最后活跃时间及往返时延
+func (p *Proxy) GetConnStats(ctx context.Context, args *cluster.GetConnStatsArgs) (*network.ConnStat, error) {
+	return p.gateLinker.GetConnStats(ctx, args)
+}
+
 // IsOnline 检测是否在线
 func (p *Proxy) IsOnline(ctx context.Context, args *cluster.IsOnlineArgs) (bool, error) {
 	return p.gateLinker.IsOnline(ctx, args)
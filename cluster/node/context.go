@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/transport"
 )
 
@@ -59,6 +60,8 @@ type Context interface {
 	Response(message any) error
 	// Disconnect 关闭来自网关的连接
 	Disconnect(force ...bool) error
+	// DisconnectWithCode 向客户端下发关闭原因后关闭来自网关的连接
+	DisconnectWithCode(code network.CloseCode, message string, force ...bool) error
 	// BindGate 绑定网关
 	BindGate(uid ...int64) error
 	// UnbindGate 解绑网关
@@ -8,6 +8,7 @@ import (
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/core/chains"
 	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/session"
 	"github.com/devagame/due/v2/task"
 	"github.com/devagame/due/v2/transport"
@@ -361,11 +362,18 @@ func (e *event) Response(message any) error {
 
 // Disconnect 关闭来自网关的连接
 func (e *event) Disconnect(force ...bool) error {
+	return e.DisconnectWithCode(network.CloseNone, "", force...)
+}
+
+// DisconnectWithCode 向客户端下发关闭原因后关闭来自网关的连接
+func (e *event) DisconnectWithCode(code network.CloseCode, message string, force ...bool) error {
 	return e.node.proxy.Disconnect(e.ctx, &cluster.DisconnectArgs{
-		GID:    e.gid,
-		Kind:   session.Conn,
-		Target: e.cid,
-		Force:  len(force) > 0 && force[0],
+		GID:     e.gid,
+		Kind:    session.Conn,
+		Target:  e.cid,
+		Force:   len(force) > 0 && force[0],
+		Code:    code,
+		Message: message,
 	})
 }
 
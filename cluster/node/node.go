@@ -10,6 +10,7 @@ import (
 	"github.com/devagame/due/v2/component"
 	"github.com/devagame/due/v2/core/info"
 	"github.com/devagame/due/v2/internal/transporter/node"
+	"github.com/devagame/due/v2/internal/transporter/security"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/registry"
 	"github.com/devagame/due/v2/transport"
@@ -209,9 +210,16 @@ func (n *Node) dispatch() {
 
 // 启动连接服务器
 func (n *Node) startLinkServer() {
+	sec, err := security.Load()
+	if err != nil {
+		log.Fatalf("link security load failed: %v", err)
+	}
+
 	linker, err := node.NewServer(&provider{node: n}, &node.ServerOptions{
-		Addr:   n.opts.addr,
-		Expose: n.opts.expose,
+		Addr:      n.opts.addr,
+		Expose:    n.opts.expose,
+		TLSConfig: sec.ServerTLS,
+		Secret:    sec.Secret,
 	})
 	if err != nil {
 		log.Fatalf("link server create failed: %v", err)
@@ -240,6 +248,8 @@ func (n *Node) startTransportServer() {
 	}
 
 	n.opts.transporter.SetDefaultDiscovery(n.opts.registry)
+	n.opts.transporter.UseClientInterceptors(n.opts.clientInterceptors...)
+	n.opts.transporter.UseServerInterceptors(n.opts.serverInterceptors...)
 
 	if len(n.services) == 0 {
 		return
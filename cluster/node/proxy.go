@@ -7,6 +7,9 @@ import (
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/internal/link"
+	"github.com/devagame/due/v2/internal/transporter/security"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/registry"
 	"github.com/devagame/due/v2/session"
 	"github.com/devagame/due/v2/transport"
@@ -20,6 +23,11 @@ type Proxy struct {
 }
 
 func newProxy(node *Node) *Proxy {
+	sec, err := security.Load()
+	if err != nil {
+		log.Fatalf("link security load failed: %v", err)
+	}
+
 	opts := &link.Options{
 		InsID:     node.opts.id,
 		InsKind:   cluster.Node,
@@ -27,6 +35,8 @@ func newProxy(node *Node) *Proxy {
 		Locator:   node.opts.locator,
 		Registry:  node.opts.registry,
 		Encryptor: node.opts.encryptor,
+		TLSConfig: sec.ClientTLS,
+		Secret:    sec.Secret,
 	}
 
 	return &Proxy{
@@ -226,6 +236,17 @@ func (p *Proxy) PackBuffer(message any) ([]byte, error) {
 	return p.gateLinker.PackBuffer(message, true)
 }
 
+// LinkStats 获取与各网关及节点连接的合并写入统计，键为网关或节点地址
+func (p *Proxy) LinkStats() map[string]*cluster.BatchStat {
+	stats := p.gateLinker.Stats()
+
+	for addr, stat := range p.nodeLinker.Stats() {
+		stats[addr] = stat
+	}
+
+	return stats
+}
+
 // GetIP 获取客户端IP
 func (p *Proxy) GetIP(ctx context.Context, args *cluster.GetIPArgs) (string, error) {
 	return p.gateLinker.GetIP(ctx, args)
@@ -236,6 +257,11 @@ func (p *Proxy) Stat(ctx context.Context, kind session.Kind) (int64, error) {
 	return p.gateLinker.Stat(ctx, kind)
 }
 
+// GetConnStats 获取连接流量统计，包含收发字节数、收发消息数、最后活跃时间及往返时延
+func (p *Proxy) GetConnStats(ctx context.Context, args *cluster.GetConnStatsArgs) (*network.ConnStat, error) {
+	return p.gateLinker.GetConnStats(ctx, args)
+}
+
 // IsOnline 检测是否在线
 func (p *Proxy) IsOnline(ctx context.Context, args *cluster.IsOnlineArgs) (bool, error) {
 	return p.gateLinker.IsOnline(ctx, args)
@@ -15,6 +15,7 @@ import (
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/core/chains"
 	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/session"
 	"github.com/devagame/due/v2/task"
 	"github.com/devagame/due/v2/transport"
@@ -419,15 +420,22 @@ func (r *request) Response(message any) error {
 
 // Disconnect 关闭来自网关的连接
 func (r *request) Disconnect(force ...bool) error {
+	return r.DisconnectWithCode(network.CloseNone, "", force...)
+}
+
+// DisconnectWithCode 向客户端下发关闭原因后关闭来自网关的连接
+func (r *request) DisconnectWithCode(code network.CloseCode, message string, force ...bool) error {
 	if r.gid == "" {
 		return errors.ErrIllegalOperation
 	}
 
 	return r.node.proxy.Disconnect(r.ctx, &cluster.DisconnectArgs{
-		GID:    r.gid,
-		Kind:   session.Conn,
-		Target: r.cid,
-		Force:  len(force) > 0 && force[0],
+		GID:     r.gid,
+		Kind:    session.Conn,
+		Target:  r.cid,
+		Force:   len(force) > 0 && force[0],
+		Code:    code,
+		Message: message,
 	})
 }
 
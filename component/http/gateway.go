@@ -0,0 +1,341 @@
+package http
+
+import (
+	ctx "context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devagame/due/v2/codes"
+	"github.com/devagame/due/v2/encoding/json"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/transport"
+	"github.com/gofiber/fiber/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	gatewayTimeoutHeader  = "X-Mesh-Timeout"   // 调用超时时间请求头，如500ms、3s
+	gatewayMetadataPrefix = "X-Mesh-Metadata-" // 调用元数据请求头前缀，去除前缀后作为元数据键名透传至微服务
+)
+
+// StatusClientClosedRequest 客户端关闭请求（非标准状态码）
+const StatusClientClosedRequest = 499
+
+var (
+	typeOfContext = reflect.TypeOf((*ctx.Context)(nil)).Elem()
+	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+var (
+	protoUnmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+	protoMarshalOptions   = protojson.MarshalOptions{UseProtoNames: true, UseEnumNumbers: true, EmitUnpopulated: true}
+)
+
+// MeshService 网关暴露的微服务
+type MeshService struct {
+	Name     string // 服务名称，与微服务注册时的名称保持一致
+	Path     string // 服务调用路径，GRPC为服务全名（如pb.Greeter），RPCX、DRPC为服务名，默认与Name相同
+	Target   string // 调用目标，默认为discovery://{Name}
+	Provider any    // 服务提供者，可传入服务实现或服务接口指针，如(*pb.GreeterServer)(nil)
+}
+
+type gatewayMethod struct {
+	name      string
+	argType   reflect.Type // 请求参数类型
+	replyType reflect.Type // 响应结果类型（非指针）
+}
+
+type gatewayService struct {
+	MeshService
+	methods map[string]*gatewayMethod
+	mu      sync.Mutex
+	client  transport.Client
+}
+
+type gateway struct {
+	server   *Server
+	rw       sync.RWMutex
+	services map[string]*gatewayService
+}
+
+func newGateway(s *Server) *gateway {
+	return &gateway{server: s, services: make(map[string]*gatewayService)}
+}
+
+// 注册微服务
+func (g *gateway) register(service MeshService) error {
+	if service.Name == "" || service.Provider == nil {
+		return errors.ErrInvalidServiceDesc
+	}
+
+	if service.Path == "" {
+		service.Path = service.Name
+	}
+
+	if service.Target == "" {
+		service.Target = "discovery://" + service.Name
+	}
+
+	methods := parseMethods(service.Provider)
+	if len(methods) == 0 {
+		return errors.ErrInvalidServiceDesc
+	}
+
+	g.rw.Lock()
+	g.services[service.Name] = &gatewayService{MeshService: service, methods: methods}
+	g.rw.Unlock()
+
+	return nil
+}
+
+// 查找微服务方法
+func (g *gateway) lookup(service, method string) (*gatewayService, *gatewayMethod, bool) {
+	g.rw.RLock()
+	defer g.rw.RUnlock()
+
+	s, ok := g.services[service]
+	if !ok {
+		return nil, nil, false
+	}
+
+	m, ok := s.methods[method]
+	if !ok {
+		return nil, nil, false
+	}
+
+	return s, m, true
+}
+
+// 获取已注册的微服务列表
+func (g *gateway) list() []*gatewayService {
+	g.rw.RLock()
+	defer g.rw.RUnlock()
+
+	services := make([]*gatewayService, 0, len(g.services))
+	for _, s := range g.services {
+		services = append(services, s)
+	}
+
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	return services
+}
+
+// 处理网关请求
+func (g *gateway) handle(c fiber.Ctx) error {
+	s, m, ok := g.lookup(c.Params("service"), c.Params("method"))
+	if !ok {
+		return g.failure(c, codes.NotFound)
+	}
+
+	client, err := s.getClient(g.server.opts.transporter)
+	if err != nil {
+		return g.failure(c, codes.InternalError.WithMessage(err.Error()))
+	}
+
+	args := m.newArgs()
+	if body := c.Body(); len(body) > 0 {
+		if err = unmarshal(body, args); err != nil {
+			return g.failure(c, codes.InvalidArgument.WithMessage(err.Error()))
+		}
+	}
+
+	timeout := g.server.opts.gatewayOpts.Timeout
+	if v := c.Get(gatewayTimeoutHeader); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			timeout = d
+		} else {
+			return g.failure(c, codes.InvalidArgument.WithMessage("invalid "+gatewayTimeoutHeader+" header"))
+		}
+	}
+
+	// 继承请求上下文，中间件写入的上下文信息可传递至微服务调用
+	callCtx, cancel := c.Context(), ctx.CancelFunc(func() {})
+	if timeout > 0 {
+		callCtx, cancel = ctx.WithTimeout(callCtx, timeout)
+	}
+	defer cancel()
+
+	if md := gatewayMetadata(c); len(md) > 0 {
+		callCtx = transport.NewOutgoingContext(callCtx, md)
+	}
+
+	reply := reflect.New(m.replyType).Interface()
+
+	if m.argType.Kind() == reflect.Ptr {
+		err = client.Call(callCtx, s.Path, m.name, args, reply)
+	} else {
+		err = client.Call(callCtx, s.Path, m.name, reflect.ValueOf(args).Elem().Interface(), reply)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(callCtx.Err(), ctx.DeadlineExceeded):
+			return g.failure(c, codes.DeadlineExceeded)
+		case errors.Is(err, ctx.Canceled):
+			return g.failure(c, codes.Canceled)
+		default:
+			return g.failure(c, codes.Convert(err))
+		}
+	}
+
+	data, err := marshal(reply)
+	if err != nil {
+		return g.failure(c, codes.InternalError.WithMessage(err.Error()))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+
+	return c.Status(StatusOK).Send(data)
+}
+
+// 失败响应
+func (g *gateway) failure(c fiber.Ctx, code *codes.Code) error {
+	return c.Status(StatusFromCode(code)).JSON(&Resp{Code: code.Code(), Message: code.Message()})
+}
+
+// 获取微服务客户端，首次调用时创建
+func (s *gatewayService) getClient(transporter transport.Transporter) (transport.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	if transporter == nil {
+		return nil, errors.ErrMissingTransporter
+	}
+
+	client, err := transporter.NewClient(s.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	s.client = client
+
+	return client, nil
+}
+
+// 新建请求参数
+func (m *gatewayMethod) newArgs() any {
+	if m.argType.Kind() == reflect.Ptr {
+		return reflect.New(m.argType.Elem()).Interface()
+	}
+
+	return reflect.New(m.argType).Interface()
+}
+
+// StatusFromCode 将错误码映射为HTTP状态码
+func StatusFromCode(code *codes.Code) int {
+	switch code.Code() {
+	case codes.OK.Code():
+		return StatusOK
+	case codes.Canceled.Code():
+		return StatusClientClosedRequest
+	case codes.InvalidArgument.Code(), codes.IllegalRequest.Code():
+		return StatusBadRequest
+	case codes.DeadlineExceeded.Code():
+		return StatusGatewayTimeout
+	case codes.NotFound.Code():
+		return StatusNotFound
+	case codes.Unauthorized.Code():
+		return StatusUnauthorized
+	case codes.IllegalInvoke.Code():
+		return StatusForbidden
+	case codes.TooManyRequests.Code():
+		return StatusTooManyRequests
+	default:
+		return StatusInternalServerError
+	}
+}
+
+// 解析服务方法
+// 支持func(ctx context.Context, args *T) (*R, error)及func(ctx context.Context, args T, reply *R) error两种签名
+func parseMethods(provider any) map[string]*gatewayMethod {
+	rt := reflect.TypeOf(provider)
+
+	offset := 1
+	if rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Interface {
+		rt, offset = rt.Elem(), 0
+	}
+
+	methods := make(map[string]*gatewayMethod)
+
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+		if !m.IsExported() {
+			continue
+		}
+
+		if method := parseMethod(m.Name, m.Type, offset); method != nil {
+			methods[m.Name] = method
+		}
+	}
+
+	return methods
+}
+
+// 解析单个服务方法，offset为接收者参数偏移
+func parseMethod(name string, t reflect.Type, offset int) *gatewayMethod {
+	switch {
+	case t.NumIn() == offset+2 && t.NumOut() == 2:
+		if !t.In(offset).Implements(typeOfContext) || t.In(offset+1).Kind() != reflect.Ptr {
+			return nil
+		}
+
+		if t.Out(0).Kind() != reflect.Ptr || t.Out(1) != typeOfError {
+			return nil
+		}
+
+		return &gatewayMethod{name: name, argType: t.In(offset + 1), replyType: t.Out(0).Elem()}
+	case t.NumIn() == offset+3 && t.NumOut() == 1:
+		if !t.In(offset).Implements(typeOfContext) || t.In(offset+2).Kind() != reflect.Ptr || t.Out(0) != typeOfError {
+			return nil
+		}
+
+		return &gatewayMethod{name: name, argType: t.In(offset + 1), replyType: t.In(offset + 2).Elem()}
+	default:
+		return nil
+	}
+}
+
+// 提取调用元数据
+func gatewayMetadata(c fiber.Ctx) transport.Metadata {
+	var md transport.Metadata
+
+	for key, values := range c.GetReqHeaders() {
+		if len(values) == 0 || len(key) <= len(gatewayMetadataPrefix) || !strings.EqualFold(key[:len(gatewayMetadataPrefix)], gatewayMetadataPrefix) {
+			continue
+		}
+
+		if md == nil {
+			md = make(transport.Metadata)
+		}
+
+		md.Set(key[len(gatewayMetadataPrefix):], values[0])
+	}
+
+	return md
+}
+
+// 解码请求参数，protobuf消息使用protojson解码
+func unmarshal(data []byte, v any) error {
+	if msg, ok := v.(proto.Message); ok {
+		return protoUnmarshalOptions.Unmarshal(data, msg)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// 编码响应结果，protobuf消息使用protojson编码
+func marshal(v any) ([]byte, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return protoMarshalOptions.Marshal(msg)
+	}
+
+	return json.Marshal(v)
+}
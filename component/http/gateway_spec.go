@@ -0,0 +1,197 @@
+package http
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/devagame/due/v2/encoding/json"
+	"github.com/go-openapi/spec"
+	"github.com/gofiber/fiber/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	typeOfTime         = reflect.TypeOf(time.Time{})
+	typeOfProtoMessage = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	invalidDefinition  = regexp.MustCompile(`[^A-Za-z0-9_.]+`)
+)
+
+// 生成网关接口文档，base为已有的swagger文档，生成的接口将合并至已有文档中
+func (g *gateway) document(base []byte, title string) ([]byte, error) {
+	doc := &spec.Swagger{}
+	if len(base) > 0 {
+		if err := json.Unmarshal(base, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if doc.Swagger == "" {
+		doc.Swagger = "2.0"
+	}
+
+	if doc.Info == nil {
+		doc.Info = &spec.Info{InfoProps: spec.InfoProps{Title: title, Version: "1.0"}}
+	}
+
+	if doc.Paths == nil {
+		doc.Paths = &spec.Paths{}
+	}
+
+	if doc.Paths.Paths == nil {
+		doc.Paths.Paths = make(map[string]spec.PathItem)
+	}
+
+	if doc.Definitions == nil {
+		doc.Definitions = make(spec.Definitions)
+	}
+
+	b := &schemaBuilder{definitions: doc.Definitions}
+	failure := spec.RefSchema(b.define(reflect.TypeOf(Resp{}), false))
+
+	for _, s := range g.list() {
+		for _, m := range s.methods {
+			argType, replyType := m.argType, m.replyType
+			if argType.Kind() == reflect.Ptr {
+				argType = argType.Elem()
+			}
+
+			args, reply := b.schema(argType, false), b.schema(replyType, false)
+
+			op := spec.NewOperation(s.Name+"."+m.name).
+				WithTags(s.Name).
+				WithSummary(s.Path+"/"+m.name).
+				WithConsumes(fiber.MIMEApplicationJSON).
+				WithProduces(fiber.MIMEApplicationJSON).
+				AddParam(spec.BodyParam("body", &args)).
+				AddParam(spec.HeaderParam(gatewayTimeoutHeader).Typed("string", "").WithDescription("调用超时时间，如500ms、3s")).
+				RespondsWith(StatusOK, spec.NewResponse().WithDescription("OK").WithSchema(&reply)).
+				WithDefaultResponse(spec.NewResponse().WithDescription("Failure").WithSchema(failure))
+
+			doc.Paths.Paths[path.Join(g.server.opts.gatewayOpts.Prefix, s.Name, m.name)] = spec.PathItem{
+				PathItemProps: spec.PathItemProps{Post: op},
+			}
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+type schemaBuilder struct {
+	definitions spec.Definitions
+}
+
+// 构建类型的文档结构，isProto标识是否处于protobuf消息中
+func (b *schemaBuilder) schema(t reflect.Type, isProto bool) spec.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == typeOfTime {
+		return *spec.DateTimeProperty()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return *spec.BoolProperty()
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return *spec.Int32Property()
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		if isProto {
+			// protojson将64位整数编码为字符串
+			return *spec.StrFmtProperty("int64")
+		}
+		return *spec.Int64Property()
+	case reflect.Float32:
+		return *spec.Float32Property()
+	case reflect.Float64:
+		return *spec.Float64Property()
+	case reflect.String:
+		return *spec.StringProperty()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return *spec.StrFmtProperty("byte")
+		}
+		return *spec.ArrayProperty(ptrSchema(b.schema(t.Elem(), isProto)))
+	case reflect.Map:
+		return *spec.MapProperty(ptrSchema(b.schema(t.Elem(), isProto)))
+	case reflect.Struct:
+		return *spec.RefSchema(b.define(t, isProto || reflect.PointerTo(t).Implements(typeOfProtoMessage)))
+	default:
+		return *new(spec.Schema).Typed("object", "")
+	}
+}
+
+// 定义结构体类型，返回引用路径
+func (b *schemaBuilder) define(t reflect.Type, isProto bool) string {
+	name := invalidDefinition.ReplaceAllString(t.String(), "_")
+	ref := "#/definitions/" + name
+
+	if _, ok := b.definitions[name]; ok {
+		return ref
+	}
+
+	// 先占位，避免递归类型无限展开
+	b.definitions[name] = spec.Schema{}
+
+	schema := new(spec.Schema).Typed("object", "")
+	b.properties(schema, t, isProto)
+	b.definitions[name] = *schema
+
+	return ref
+}
+
+// 填充结构体字段，匿名结构体字段将被展开
+func (b *schemaBuilder) properties(schema *spec.Schema, t reflect.Type, isProto bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, omit := parseJSONTag(field)
+		if omit {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				b.properties(schema, ft, isProto)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.SetProperty(name, b.schema(field.Type, isProto))
+	}
+}
+
+// 解析json标签，返回字段名及是否忽略该字段
+func parseJSONTag(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			return tag[:i], false
+		}
+	}
+
+	return tag, false
+}
+
+func ptrSchema(s spec.Schema) *spec.Schema {
+	return &s
+}
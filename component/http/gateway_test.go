@@ -0,0 +1,272 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/component/http/v2"
+	"github.com/devagame/due/v2/codes"
+	"github.com/devagame/due/v2/registry"
+	"github.com/devagame/due/v2/transport"
+	"github.com/gofiber/fiber/v3"
+)
+
+type greetArgs struct {
+	Name string `json:"name"`
+}
+
+type greetReply struct {
+	Message string `json:"message"`
+}
+
+type greeter struct{}
+
+type tenantKey struct{}
+
+func (greeter) Greet(ctx context.Context, args *greetArgs, reply *greetReply) error {
+	switch args.Name {
+	case "":
+		return codes.InvalidArgument.Err()
+	case "slow":
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if md, ok := transport.FromOutgoingContext(ctx); ok && md.Get("lang") == "zh" {
+		reply.Message = "你好, " + args.Name
+	} else {
+		reply.Message = "hello, " + args.Name
+	}
+
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+		reply.Message += "@" + tenant
+	}
+
+	return nil
+}
+
+type transporter struct{}
+
+func (transporter) Name() string                                         { return "mock" }
+func (transporter) NewServer() (transport.Server, error)                 { return nil, nil }
+func (transporter) SetDefaultDiscovery(registry.Discovery)               {}
+func (transporter) UseClientInterceptors(...transport.ClientInterceptor) {}
+func (transporter) UseServerInterceptors(...transport.ServerInterceptor) {}
+func (transporter) NewClient(string) (transport.Client, error)           { return client{}, nil }
+
+type client struct{}
+
+func (client) Client() any { return nil }
+
+func (client) Call(ctx context.Context, service, method string, args any, reply any, _ ...any) error {
+	if service != "greeter" || method != "Greet" {
+		return codes.NotFound.Err()
+	}
+
+	return greeter{}.Greet(ctx, args.(*greetArgs), reply.(*greetReply))
+}
+
+func TestGateway(t *testing.T) {
+	server := http.NewServer(
+		http.WithTransporter(transporter{}),
+		http.WithGatewayOptions(http.GatewayOptions{Enable: true, Prefix: "/mesh", Timeout: time.Second}),
+		http.WithMeshServices(http.MeshService{Name: "greeter", Provider: greeter{}}),
+	)
+
+	tests := []struct {
+		path    string
+		body    string
+		headers map[string]string
+		status  int
+		expect  string
+	}{
+		{path: "/mesh/greeter/Greet", body: `{"name":"due"}`, status: http.StatusOK, expect: "hello, due"},
+		{path: "/mesh/greeter/Greet", body: `{"name":"due"}`, headers: map[string]string{"X-Mesh-Metadata-Lang": "zh"}, status: http.StatusOK, expect: "你好, due"},
+		{path: "/mesh/greeter/Greet", body: `{}`, status: http.StatusBadRequest},
+		{path: "/mesh/greeter/Greet", body: `{"name":`, status: http.StatusBadRequest},
+		{path: "/mesh/greeter/Greet", body: `{"name":"slow"}`, headers: map[string]string{"X-Mesh-Timeout": "50ms"}, status: http.StatusGatewayTimeout},
+		{path: "/mesh/greeter/Hello", body: `{}`, status: http.StatusNotFound},
+		{path: "/mesh/unknown/Greet", body: `{}`, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req, _ := nethttp.NewRequest(nethttp.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := server.Proxy().App().Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Fatalf("%s %s: status = %d, want %d, body = %s", tt.path, tt.body, resp.StatusCode, tt.status, data)
+		}
+
+		if tt.expect != "" {
+			reply := &greetReply{}
+			if err = json.Unmarshal(data, reply); err != nil {
+				t.Fatal(err)
+			}
+
+			if reply.Message != tt.expect {
+				t.Fatalf("message = %q, want %q", reply.Message, tt.expect)
+			}
+		}
+	}
+}
+
+func TestGateway_Context(t *testing.T) {
+	server := http.NewServer(
+		http.WithTransporter(transporter{}),
+		http.WithGatewayOptions(http.GatewayOptions{Enable: true, Prefix: "/mesh", Timeout: time.Second}),
+		http.WithMeshServices(http.MeshService{Name: "greeter", Provider: greeter{}}),
+		http.WithMiddlewares(fiber.Handler(func(c fiber.Ctx) error {
+			c.SetContext(context.WithValue(c.Context(), tenantKey{}, "due"))
+			return c.Next()
+		})),
+	)
+
+	req, _ := nethttp.NewRequest(nethttp.MethodPost, "/mesh/greeter/Greet", strings.NewReader(`{"name":"due"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := server.Proxy().App().Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	reply := &greetReply{}
+	if err = json.NewDecoder(resp.Body).Decode(reply); err != nil {
+		t.Fatal(err)
+	}
+
+	if reply.Message != "hello, due@due" {
+		t.Fatalf("message = %q, want %q", reply.Message, "hello, due@due")
+	}
+}
+
+func TestStatusFromCode(t *testing.T) {
+	tests := map[*codes.Code]int{
+		codes.OK:               http.StatusOK,
+		codes.InvalidArgument:  http.StatusBadRequest,
+		codes.DeadlineExceeded: http.StatusGatewayTimeout,
+		codes.Unauthorized:     http.StatusUnauthorized,
+		codes.TooManyRequests:  http.StatusTooManyRequests,
+		codes.NewCode(1000):    http.StatusInternalServerError,
+	}
+
+	for code, status := range tests {
+		if s := http.StatusFromCode(code); s != status {
+			t.Errorf("StatusFromCode(%v) = %d, want %d", code, s, status)
+		}
+	}
+}
+
+func TestGatewaySwagger(t *testing.T) {
+	server := http.NewServer(
+		http.WithTransporter(transporter{}),
+		http.WithSwagOptions(http.SwagOptions{Enable: true, Title: "API", BasePath: "/swagger", FilePath: "swagger.json"}),
+		http.WithGatewayOptions(http.GatewayOptions{Enable: true, Prefix: "/mesh", Swagger: true}),
+		http.WithMeshServices(http.MeshService{Name: "greeter", Provider: greeter{}}),
+	)
+
+	req, _ := nethttp.NewRequest(nethttp.MethodGet, "/swagger/swagger.json", nil)
+
+	resp, err := server.Proxy().App().Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	doc := struct {
+		Paths       map[string]any `json:"paths"`
+		Definitions map[string]any `json:"definitions"`
+	}{}
+
+	if err = json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := doc.Paths["/mesh/greeter/Greet"]; !ok {
+		t.Fatalf("missing gateway path, paths = %v", doc.Paths)
+	}
+
+	if _, ok := doc.Definitions["http_test.greetArgs"]; !ok {
+		t.Fatalf("missing args definition, definitions = %v", doc.Definitions)
+	}
+}
+
+func TestGatewaySwagger_YAML(t *testing.T) {
+	dir := t.TempDir()
+
+	base := `swagger: "2.0"
+info:
+  title: API
+  version: "1.0"
+paths:
+  /users:
+    get:
+      responses:
+        200:
+          description: ok
+`
+
+	for _, file := range []string{"swagger.yaml", "swagger.txt"} {
+		filePath := filepath.Join(dir, file)
+		if err := os.WriteFile(filePath, []byte(base), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		server := http.NewServer(
+			http.WithTransporter(transporter{}),
+			http.WithSwagOptions(http.SwagOptions{Enable: true, Title: "API", BasePath: "/swagger", FilePath: filePath}),
+			http.WithGatewayOptions(http.GatewayOptions{Enable: true, Prefix: "/mesh", Swagger: true}),
+			http.WithMeshServices(http.MeshService{Name: "greeter", Provider: greeter{}}),
+		)
+
+		req, _ := nethttp.NewRequest(nethttp.MethodGet, "/swagger/swagger.json", nil)
+
+		resp, err := server.Proxy().App().Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if file == "swagger.txt" {
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("%s: status = %d, want %d", file, resp.StatusCode, http.StatusInternalServerError)
+			}
+			continue
+		}
+
+		doc := struct {
+			Paths map[string]any `json:"paths"`
+		}{}
+
+		err = json.NewDecoder(resp.Body).Decode(&doc)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, p := range []string{"/users", "/mesh/greeter/Greet"} {
+			if _, ok := doc.Paths[p]; !ok {
+				t.Fatalf("%s: missing path %s, paths = %v", file, p, doc.Paths)
+			}
+		}
+	}
+}
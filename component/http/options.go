@@ -1,6 +1,8 @@
 package http
 
 import (
+	"time"
+
 	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/registry"
 	"github.com/devagame/due/v2/transport"
@@ -13,6 +15,11 @@ const (
 	defaultConcurrency = 256 * 1024      // 默认最大并发连接数
 )
 
+const (
+	defaultGatewayPrefix  = "/mesh"         // 默认微服务网关路由前缀
+	defaultGatewayTimeout = 3 * time.Second // 默认微服务网关调用超时时间
+)
+
 const (
 	defaultNameKey          = "etc.http.name"
 	defaultAddrKey          = "etc.http.addr"
@@ -27,6 +34,13 @@ const (
 	defaultSwaggerKey       = "etc.http.swagger"
 )
 
+const (
+	defaultGatewayEnableKey  = "etc.http.gateway.enable"
+	defaultGatewayPrefixKey  = "etc.http.gateway.prefix"
+	defaultGatewayTimeoutKey = "etc.http.gateway.timeout"
+	defaultGatewaySwaggerKey = "etc.http.gateway.swagger"
+)
+
 type Option func(o *options)
 
 type options struct {
@@ -43,6 +57,8 @@ type options struct {
 	transporter   transport.Transporter // 消息传输器
 	corsOpts      CorsOptions           // 跨域配置
 	swagOpts      SwagOptions           // swagger配置
+	gatewayOpts   GatewayOptions        // 微服务网关配置
+	meshServices  []MeshService         // 微服务网关暴露的微服务
 	middlewares   []any                 // 中间件
 }
 
@@ -67,6 +83,13 @@ type SwagOptions struct {
 	SwaggerStylesUrl string `json:"swaggerStylesUrl"` // swagger-ui.css地址
 }
 
+type GatewayOptions struct {
+	Enable  bool          // 是否启用，启用后微服务方法将以POST {Prefix}/{service}/{method}的形式对外暴露
+	Prefix  string        // 路由前缀，默认为/mesh
+	Timeout time.Duration // 调用超时时间，可通过X-Mesh-Timeout请求头覆盖，默认为3s
+	Swagger bool          // 是否将生成的接口文档合并至swagger文档中
+}
+
 func defaultOptions() *options {
 	opts := &options{
 		name:          etc.Get(defaultNameKey, defaultName).String(),
@@ -80,6 +103,12 @@ func defaultOptions() *options {
 		certFile:      etc.Get(defaultCertFileKey).String(),
 		corsOpts:      CorsOptions{},
 		swagOpts:      SwagOptions{},
+		gatewayOpts: GatewayOptions{
+			Enable:  etc.Get(defaultGatewayEnableKey).Bool(),
+			Prefix:  etc.Get(defaultGatewayPrefixKey, defaultGatewayPrefix).String(),
+			Timeout: etc.Get(defaultGatewayTimeoutKey, defaultGatewayTimeout).Duration(),
+			Swagger: etc.Get(defaultGatewaySwaggerKey).Bool(),
+		},
 	}
 
 	if err := etc.Get(defaultCorsKey).Scan(&opts.corsOpts); err != nil {
@@ -153,6 +182,16 @@ func WithSwagOptions(swagOpts SwagOptions) Option {
 	return func(o *options) { o.swagOpts = swagOpts }
 }
 
+// WithGatewayOptions 设置微服务网关配置
+func WithGatewayOptions(gatewayOpts GatewayOptions) Option {
+	return func(o *options) { o.gatewayOpts = gatewayOpts }
+}
+
+// WithMeshServices 设置微服务网关暴露的微服务
+func WithMeshServices(services ...MeshService) Option {
+	return func(o *options) { o.meshServices = append(o.meshServices, services...) }
+}
+
 // WithMiddlewares 设置中间件
 func WithMiddlewares(middlewares ...any) Option {
 	return func(o *options) { o.middlewares = middlewares }
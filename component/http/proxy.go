@@ -36,3 +36,12 @@ func (p *Proxy) NewMeshClient(target string) (transport.Client, error) {
 
 	return p.server.opts.transporter.NewClient(target)
 }
+
+// RegisterMeshService 注册微服务至网关，注册后可通过POST {Prefix}/{service}/{method}调用微服务方法
+func (p *Proxy) RegisterMeshService(service MeshService) error {
+	if !p.server.opts.gatewayOpts.Enable {
+		return errors.ErrGatewayDisabled
+	}
+
+	return p.server.gateway.register(service)
+}
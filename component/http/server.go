@@ -2,6 +2,9 @@ package http
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/devagame/due/component/http/v2/swagger"
@@ -10,6 +13,7 @@ import (
 	xnet "github.com/devagame/due/v2/core/net"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/log"
+	"github.com/go-openapi/swag"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/cors"
 	"github.com/gofiber/fiber/v3/middleware/logger"
@@ -18,9 +22,10 @@ import (
 
 type Server struct {
 	component.Base
-	opts  *options
-	app   *fiber.App
-	proxy *Proxy
+	opts    *options
+	app     *fiber.App
+	proxy   *Proxy
+	gateway *gateway
 }
 
 func NewServer(opts ...Option) *Server {
@@ -32,6 +37,7 @@ func NewServer(opts ...Option) *Server {
 	s := &Server{}
 	s.opts = o
 	s.proxy = newProxy(s)
+	s.gateway = newGateway(s)
 	s.app = fiber.New(fiber.Config{
 		ServerHeader:  o.name,
 		BodyLimit:     o.bodyLimit,
@@ -60,14 +66,20 @@ func NewServer(opts ...Option) *Server {
 	}
 
 	if s.opts.swagOpts.Enable {
-		s.app.Use(swagger.New(swagger.Config{
+		cfg := swagger.Config{
 			Title:            s.opts.swagOpts.Title,
 			BasePath:         s.opts.swagOpts.BasePath,
 			FilePath:         s.opts.swagOpts.FilePath,
 			SwaggerBundleUrl: s.opts.swagOpts.SwaggerBundleUrl,
 			SwaggerPresetUrl: s.opts.swagOpts.SwaggerPresetUrl,
 			SwaggerStylesUrl: s.opts.swagOpts.SwaggerStylesUrl,
-		}))
+		}
+
+		if s.opts.gatewayOpts.Enable && s.opts.gatewayOpts.Swagger {
+			cfg.Spec = s.gatewaySpec
+		}
+
+		s.app.Use(swagger.New(cfg))
 	}
 
 	for i := range o.middlewares {
@@ -81,6 +93,16 @@ func NewServer(opts ...Option) *Server {
 		}
 	}
 
+	if s.opts.gatewayOpts.Enable {
+		for _, service := range o.meshServices {
+			if err := s.gateway.register(service); err != nil {
+				log.Fatalf("register mesh service failed: %v", err)
+			}
+		}
+
+		s.app.Post(path.Join("/", s.opts.gatewayOpts.Prefix, ":service", ":method"), s.gateway.handle)
+	}
+
 	return s
 }
 
@@ -121,6 +143,35 @@ func (s *Server) Start() {
 	}()
 }
 
+// 生成微服务网关接口文档，已有的json及yaml格式swagger文档将被合并，不支持合并的格式返回错误
+func (s *Server) gatewaySpec() ([]byte, error) {
+	if s.opts.swagOpts.FilePath == "" {
+		return s.gateway.document(nil, s.opts.swagOpts.Title)
+	}
+
+	data, err := os.ReadFile(s.opts.swagOpts.FilePath)
+	if err != nil {
+		return s.gateway.document(nil, s.opts.swagOpts.Title)
+	}
+
+	switch filepath.Ext(s.opts.swagOpts.FilePath) {
+	case ".json":
+	case ".yaml", ".yml":
+		doc, err := swag.BytesToYAMLDoc(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if data, err = swag.YAMLToJSON(doc); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.ErrInvalidFormat
+	}
+
+	return s.gateway.document(data, s.opts.swagOpts.Title)
+}
+
 func (s *Server) printInfo(addr string) {
 	infos := make([]string, 0, 3)
 	infos = append(infos, fmt.Sprintf("Name: %s", s.Name()))
@@ -138,6 +189,10 @@ func (s *Server) printInfo(addr string) {
 		infos = append(infos, fmt.Sprintf("Swagger: %s/%s", baseUrl, strings.TrimPrefix(s.opts.swagOpts.BasePath, "/")))
 	}
 
+	if s.opts.gatewayOpts.Enable {
+		infos = append(infos, fmt.Sprintf("Gateway: %s/%s", baseUrl, strings.TrimPrefix(s.opts.gatewayOpts.Prefix, "/")))
+	}
+
 	if s.opts.registry != nil {
 		infos = append(infos, fmt.Sprintf("Registry: %s", s.opts.registry.Name()))
 	} else {
@@ -13,12 +13,13 @@ import (
 )
 
 type Config struct {
-	Title            string // 文档标题
-	FilePath         string // 文档路径
-	BasePath         string // 访问路径
-	SwaggerBundleUrl string // swagger-ui-bundle.js地址
-	SwaggerPresetUrl string // swagger-ui-preset.js地址
-	SwaggerStylesUrl string // swagger-ui.css地址
+	Title            string                 // 文档标题
+	FilePath         string                 // 文档路径
+	BasePath         string                 // 访问路径
+	SwaggerBundleUrl string                 // swagger-ui-bundle.js地址
+	SwaggerPresetUrl string                 // swagger-ui-preset.js地址
+	SwaggerStylesUrl string                 // swagger-ui.css地址
+	Spec             func() ([]byte, error) // 文档生成函数，设置后将通过该函数获取文档内容，不再读取文档文件
 }
 
 const (
@@ -27,16 +28,27 @@ const (
 	defaultSwaggerStylesUrl = "https://unpkg.com/swagger-ui@5.28.1/dist/swagger-ui.css"
 )
 
+const defaultSpecFilePath = "swagger.json"
+
 func New(cfg Config) fiber.Handler {
-	// Verify Swagger file exists
-	if _, err := os.Stat(cfg.FilePath); os.IsNotExist(err) {
-		log.Fatalf("%s file does not exist", cfg.FilePath)
-	}
+	var rawSpec []byte
+
+	if cfg.Spec == nil {
+		// Verify Swagger file exists
+		if _, err := os.Stat(cfg.FilePath); os.IsNotExist(err) {
+			log.Fatalf("%s file does not exist", cfg.FilePath)
+		}
+
+		// Read Swagger Spec into memory
+		spec, err := os.ReadFile(cfg.FilePath)
+		if err != nil {
+			log.Fatalf("Failed to read provided Swagger file (%s): %v", cfg.FilePath, err)
+		}
 
-	// Read Swagger Spec into memory
-	rawSpec, err := os.ReadFile(cfg.FilePath)
-	if err != nil {
-		log.Fatalf("Failed to read provided Swagger file (%s): %v", cfg.FilePath, err)
+		rawSpec = spec
+	} else if cfg.FilePath == "" || !strings.HasSuffix(cfg.FilePath, ".json") {
+		// Generated Swagger Spec is always served as JSON
+		cfg.FilePath = defaultSpecFilePath
 	}
 
 	// Generate URL path's for the middleware
@@ -45,6 +57,16 @@ func New(cfg Config) fiber.Handler {
 
 	// Serve the Swagger spec from memory
 	swaggerSpecHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawSpec := rawSpec
+		if cfg.Spec != nil {
+			spec, err := cfg.Spec()
+			if err != nil {
+				http.Error(w, "Error generating Swagger Spec", http.StatusInternalServerError)
+				return
+			}
+			rawSpec = spec
+		}
+
 		if strings.HasSuffix(r.URL.Path, ".yaml") || strings.HasSuffix(r.URL.Path, ".yml") {
 			w.Header().Set("Content-Type", "application/yaml")
 			w.Header().Set("Cache-Control", "public, max-age=3600")
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/utils/xconv"
+	"github.com/devagame/due/v2/utils/xvalidate"
+)
+
+const defaultTag = "default"
+
+// 可移除监听器的配置器
+type removableWatcher interface {
+	addWatcher(cb WatchCallbackFunc, names ...string) func()
+}
+
+type Binding[T any] struct {
+	c        Configurator
+	pattern  string
+	value    atomic.Pointer[T]
+	closed   atomic.Bool
+	unwatch  func()
+	rw       sync.RWMutex
+	changes  []func(old, new *T)
+	failures []func(err error)
+}
+
+// Bind 将全局配置器中匹配规则对应的配置绑定到结构体
+func Bind[T any](pattern string) (*Binding[T], error) {
+	if globalConfigurator == nil {
+		return nil, errors.ErrNotFoundConfigSource
+	}
+
+	return NewBinding[T](globalConfigurator, pattern)
+}
+
+// NewBinding 将配置器中匹配规则对应的配置绑定到结构体
+// 配置热更新时会重新解码并校验，校验通过后原子替换结构体；校验失败时保留旧值
+func NewBinding[T any](c Configurator, pattern string) (*Binding[T], error) {
+	b := &Binding[T]{c: c, pattern: pattern}
+
+	v := new(T)
+	if err := doScan(c, pattern, v); err != nil {
+		return nil, err
+	}
+
+	b.value.Store(v)
+
+	if w, ok := c.(removableWatcher); ok {
+		b.unwatch = w.addWatcher(func(names ...string) { b.reload() })
+	} else {
+		c.Watch(func(names ...string) { b.reload() })
+	}
+
+	return b, nil
+}
+
+// Get 获取当前绑定的结构体
+// 返回的结构体不应被修改
+func (b *Binding[T]) Get() *T {
+	return b.value.Load()
+}
+
+// OnChange 设置配置变更回调
+func (b *Binding[T]) OnChange(fn func(old, new *T)) {
+	b.rw.Lock()
+	b.changes = append(b.changes, fn)
+	b.rw.Unlock()
+}
+
+// OnError 设置配置重载失败回调
+func (b *Binding[T]) OnError(fn func(err error)) {
+	b.rw.Lock()
+	b.failures = append(b.failures, fn)
+	b.rw.Unlock()
+}
+
+// Close 关闭绑定，关闭后不再响应配置变更
+// 配置器支持移除监听器时同时移除绑定的监听器
+func (b *Binding[T]) Close() {
+	if !b.closed.CompareAndSwap(false, true) {
+		return
+	}
+
+	if b.unwatch != nil {
+		b.unwatch()
+	}
+}
+
+// 重新加载配置
+func (b *Binding[T]) reload() {
+	if b.closed.Load() {
+		return
+	}
+
+	v := new(T)
+	if err := doScan(b.c, b.pattern, v); err != nil {
+		b.rw.RLock()
+		defer b.rw.RUnlock()
+
+		if len(b.failures) == 0 {
+			log.Printf("reload configure %s failed and the old value is kept: %v", b.pattern, err)
+		}
+
+		for _, fn := range b.failures {
+			fn(err)
+		}
+
+		return
+	}
+
+	old := b.value.Load()
+	if reflect.DeepEqual(old, v) {
+		return
+	}
+
+	b.value.Store(v)
+
+	b.rw.RLock()
+	defer b.rw.RUnlock()
+
+	for _, fn := range b.changes {
+		fn(old, v)
+	}
+}
+
+// 按照default标签设置结构体默认值
+func setDefaults(rv reflect.Value) error {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if tag, ok := field.Tag.Lookup(defaultTag); ok && fv.IsZero() {
+			if err := setDefault(fv, tag); err != nil {
+				return errors.NewError(field.Name, err)
+			}
+		}
+
+		if fv.Kind() == reflect.Struct || (fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct) {
+			if err := setDefaults(fv); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// 设置字段默认值
+func setDefault(fv reflect.Value, tag string) error {
+	if fv.Kind() == reflect.Ptr {
+		fv.Set(reflect.New(fv.Type().Elem()))
+		fv = fv.Elem()
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(tag)
+	case reflect.Bool:
+		fv.SetBool(xconv.Bool(tag))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fv.Type().PkgPath() == "time" && fv.Type().Name() == "Duration" {
+			fv.SetInt(int64(xconv.Duration(tag)))
+		} else {
+			fv.SetInt(xconv.Int64(tag))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(xconv.Uint64(tag))
+	case reflect.Float32, reflect.Float64:
+		fv.SetFloat(xconv.Float64(tag))
+	case reflect.Slice:
+		items := strings.Split(tag, ",")
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setDefault(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	default:
+		return errors.ErrInvalidArgument
+	}
+
+	return nil
+}
+
+// 扫描配置值到结构体，并进行默认值填充和校验
+// 扫描配置器中的配置到结构体，配置器未实现Inspector时按获取的配置值扫描
+func doScan(c Configurator, pattern string, dest any) error {
+	if i, ok := c.(Inspector); ok {
+		return i.Scan(pattern, dest)
+	}
+
+	if !c.Has(pattern) {
+		return scan(nil, dest)
+	}
+
+	return scan(c.Get(pattern), dest)
+}
+
+func scan(val value.Value, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.ErrInvalidPointer
+	}
+
+	if err := setDefaults(rv); err != nil {
+		return err
+	}
+
+	if val != nil {
+		if err := val.Scan(dest); err != nil {
+			return err
+		}
+	}
+
+	if rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	return xvalidate.Struct(dest)
+}
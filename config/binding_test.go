@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+type round struct {
+	MaxRound int `json:"maxRound" default:"30"`
+}
+
+func TestBinding_Close(t *testing.T) {
+	c := NewConfigurator().(*defaultConfigurator)
+	defer c.Close()
+
+	b1, err := NewBinding[round](c, "battle")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b2, err := NewBinding[round](c, "battle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b2.Close()
+
+	// 在变更回调中关闭绑定
+	b1.OnChange(func(old, new *round) { b1.Close() })
+
+	if err = c.Set("battle.maxRound", 60); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		c.notify()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("close binding in change callback deadlocked")
+	}
+
+	if b1.Get().MaxRound != 60 || b2.Get().MaxRound != 60 {
+		t.Fatalf("max round = %d/%d, want 60", b1.Get().MaxRound, b2.Get().MaxRound)
+	}
+
+	if n := len(c.watchers); n != 1 {
+		t.Fatalf("watchers = %d, want 1", n)
+	}
+
+	b1.Close()
+	b2.Close()
+
+	if n := len(c.watchers); n != 0 {
+		t.Fatalf("watchers = %d, want 0", n)
+	}
+}
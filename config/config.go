@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/errors"
 )
 
 var globalConfigurator Configurator
@@ -53,6 +54,19 @@ func Set(pattern string, value any) error {
 	return globalConfigurator.Set(pattern, value)
 }
 
+// Unset 撤销通过Set设置的配置值及其下级配置值，撤销后配置源中的配置值重新生效
+func Unset(pattern string) error {
+	if globalConfigurator == nil {
+		return nil
+	}
+
+	if o, ok := globalConfigurator.(Overrider); ok {
+		return o.Unset(pattern)
+	}
+
+	return errors.ErrNotSupported
+}
+
 // Match 匹配多个规则
 func Match(patterns ...string) Matcher {
 	if globalConfigurator == nil {
@@ -62,6 +76,15 @@ func Match(patterns ...string) Matcher {
 	return globalConfigurator.Match(patterns...)
 }
 
+// Scan 扫描配置到结构体，支持default标签设置默认值及validate标签校验
+func Scan(pattern string, dest any) error {
+	if globalConfigurator == nil {
+		return errors.ErrNotFoundConfigSource
+	}
+
+	return doScan(globalConfigurator, pattern, dest)
+}
+
 // Watch 设置监听回调
 func Watch(cb WatchCallbackFunc, names ...string) {
 	if globalConfigurator == nil {
@@ -89,6 +112,48 @@ func Store(ctx context.Context, source string, file string, content any, overrid
 	return globalConfigurator.Store(ctx, source, file, content, override...)
 }
 
+// Versions 列出配置项的历史版本
+func Versions(ctx context.Context, source string, file string) ([]*Snapshot, error) {
+	if globalConfigurator == nil {
+		return nil, errors.ErrNotFoundConfigSource
+	}
+
+	h, ok := globalConfigurator.(Historian)
+	if !ok {
+		return nil, errors.ErrNotSupported
+	}
+
+	return h.Versions(ctx, source, file)
+}
+
+// Diff 比较配置项的两个历史版本
+func Diff(ctx context.Context, source string, file string, from, to int64) ([]*Change, error) {
+	if globalConfigurator == nil {
+		return nil, errors.ErrNotFoundConfigSource
+	}
+
+	h, ok := globalConfigurator.(Historian)
+	if !ok {
+		return nil, errors.ErrNotSupported
+	}
+
+	return h.Diff(ctx, source, file, from, to)
+}
+
+// Rollback 回滚配置项到指定历史版本
+func Rollback(ctx context.Context, source string, file string, version int64) error {
+	if globalConfigurator == nil {
+		return errors.ErrNotFoundConfigSource
+	}
+
+	h, ok := globalConfigurator.(Historian)
+	if !ok {
+		return errors.ErrNotSupported
+	}
+
+	return h.Rollback(ctx, source, file, version)
+}
+
 // Close 关闭配置监听
 func Close() {
 	if globalConfigurator != nil {
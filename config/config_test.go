@@ -1,11 +1,16 @@
 package config_test
 
 import (
+	"bytes"
 	"context"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/devagame/due/v2/config"
+	"github.com/devagame/due/v2/config/env"
 	"github.com/devagame/due/v2/config/file"
 )
 
@@ -74,3 +79,412 @@ func BenchmarkGet(b *testing.B) {
 		config.Get("config").Value()
 	}
 }
+
+type battle struct {
+	MaxRound  int      `json:"maxRound" default:"30" validate:"min=1,max=100"`
+	Mode      string   `json:"mode" default:"pve" validate:"oneof=pve pvp"`
+	Whitelist []string `json:"whitelist" default:"1001,1002"`
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "battle.json"), []byte(`{"mode":"pvp"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"maxRound":1000}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.NewConfigurator(config.WithSources(file.NewSource(file.WithPath(dir))))
+	defer c.Close()
+
+	b := &battle{}
+
+	if err := c.(config.Inspector).Scan("battle", b); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.MaxRound != 30 || b.Mode != "pvp" || len(b.Whitelist) != 2 {
+		t.Fatalf("unexpected battle config: %+v", b)
+	}
+
+	if err := c.(config.Inspector).Scan("invalid", &battle{}); err == nil {
+		t.Fatal("expect a validation error")
+	}
+
+	binding, err := config.NewBinding[battle](c, "battle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer binding.Close()
+
+	if binding.Get().Mode != "pvp" {
+		t.Fatalf("unexpected binding value: %+v", binding.Get())
+	}
+}
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "battle.json"), []byte(`{"mode":"pvp","maxRound":30}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DUE_CONFIG_battle__maxRound", "50")
+
+	c := config.NewConfigurator(config.WithSources(
+		file.NewSource(file.WithPath(dir)),
+		env.NewSource(),
+	))
+	defer c.Close()
+
+	if maxRound := c.Get("battle.maxRound").Int(); maxRound != 50 {
+		t.Fatalf("expect maxRound overridden by env, got %d", maxRound)
+	}
+
+	if mode := c.Get("battle.mode").String(); mode != "pvp" {
+		t.Fatalf("expect mode kept from file, got %s", mode)
+	}
+
+	inspector := c.(config.Inspector)
+
+	origins := inspector.Trace("battle.maxRound")
+	if len(origins) != 2 || origins[0].Layer != env.Name || origins[1].Layer != file.Name {
+		t.Fatalf("unexpected origins: %+v", origins)
+	}
+
+	if err := c.Set("battle.maxRound", 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if origins = inspector.Trace("battle.maxRound"); origins[0].Layer != config.RuntimeLayer {
+		t.Fatalf("unexpected origins: %+v", origins)
+	}
+
+	// 运行时设置按路径匹配上级及下级配置值
+	if err := c.Set("battle.reward", map[string]any{"gold": 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	if origins = inspector.Trace("battle.reward.gold"); len(origins) != 1 || origins[0].Layer != config.RuntimeLayer || origins[0].Value.Int() != 100 {
+		t.Fatalf("unexpected origins: %+v", origins)
+	}
+
+	if origins = inspector.Trace("battle"); len(origins) != 3 || origins[0].Layer != config.RuntimeLayer {
+		t.Fatalf("unexpected origins: %+v", origins)
+	}
+
+	// 撤销运行时设置后配置源中的配置值重新生效
+	if err := c.(config.Overrider).Unset("battle"); err != nil {
+		t.Fatal(err)
+	}
+
+	if maxRound := c.Get("battle.maxRound").Int(); maxRound != 50 {
+		t.Fatalf("expect maxRound restored from env, got %d", maxRound)
+	}
+
+	if c.Has("battle.reward") {
+		t.Fatal("expect reward unset")
+	}
+
+	if origins = inspector.Trace("battle.maxRound"); len(origins) != 2 || origins[0].Layer != env.Name {
+		t.Fatalf("unexpected origins: %+v", origins)
+	}
+}
+
+type reverseEncryptor struct{}
+
+func (reverseEncryptor) Encrypt(data []byte) ([]byte, error) {
+	dst := make([]byte, len(data))
+	for i, b := range data {
+		dst[len(data)-1-i] = b
+	}
+	return dst, nil
+}
+
+func (e reverseEncryptor) Decrypt(data []byte) ([]byte, error) {
+	return e.Encrypt(data)
+}
+
+func TestPriority(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "battle.json"), []byte(`{"code":"A1","maxRound":30}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DUE_CONFIG_battle__maxRound", "50")
+	t.Setenv("DUE_CONFIG_battle__code", "0123")
+
+	// 显式设置优先级后不再依赖配置源的设置顺序
+	c := config.NewConfigurator(
+		config.WithSources(env.NewSource(), file.NewSource(file.WithPath(dir))),
+		config.WithPriority(env.Name, 1),
+	)
+	defer c.Close()
+
+	if maxRound := c.Get("battle.maxRound").Int(); maxRound != 50 {
+		t.Fatalf("expect maxRound overridden by env, got %d", maxRound)
+	}
+
+	// 覆盖值保留为字符串
+	if code := c.Get("battle.code").Value(); code != "0123" {
+		t.Fatalf("expect code kept as string, got %v", code)
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	dir := t.TempDir()
+
+	secret, err := config.EncryptSecret(reverseEncryptor{}, "123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "db.json"), []byte(`{"user":"root","password":"`+secret+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.NewConfigurator(
+		config.WithSources(file.NewSource(file.WithPath(dir), file.WithMode(config.ReadWrite))),
+		config.WithEncryptor(reverseEncryptor{}),
+	)
+	defer c.Close()
+
+	if password := c.Get("db.password").String(); password != "123456" {
+		t.Fatalf("expect decrypted password, got %s", password)
+	}
+
+	if err = c.Store(context.Background(), file.Name, "db.json", map[string]any{"password": "654321"}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(filepath.Join(dir, "db.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(buf, []byte("654321")) || !bytes.Contains(buf, []byte("ENC(")) {
+		t.Fatalf("expect password stored encrypted, got %s", buf)
+	}
+}
+
+func TestSecrets_RawContent(t *testing.T) {
+	dir := t.TempDir()
+
+	secret, err := config.EncryptSecret(reverseEncryptor{}, "123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "db.json"), []byte(`{"user":"root","password":"`+secret+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.NewConfigurator(
+		config.WithSources(file.NewSource(file.WithPath(dir), file.WithMode(config.ReadWrite))),
+		config.WithEncryptor(reverseEncryptor{}),
+	)
+	defer c.Close()
+
+	for _, content := range []any{
+		`{"user":"root","password":"654321"}`,
+		[]byte(`{"user":"root","password":"654321"}`),
+	} {
+		if err = c.Store(context.Background(), file.Name, "db.json", content, true); err != nil {
+			t.Fatal(err)
+		}
+
+		buf, err := os.ReadFile(filepath.Join(dir, "db.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Contains(buf, []byte("654321")) || !bytes.Contains(buf, []byte("ENC(")) {
+			t.Fatalf("expect %T password stored encrypted, got %s", content, buf)
+		}
+	}
+}
+
+func TestSecrets_KeepContent(t *testing.T) {
+	dir := t.TempDir()
+
+	c := config.NewConfigurator(
+		config.WithSources(file.NewSource(file.WithPath(dir), file.WithMode(config.ReadWrite))),
+		config.WithEncryptor(reverseEncryptor{}),
+	)
+	defer c.Close()
+
+	// 无密文路径时保留原始内容中的注释及字段顺序
+	content := "# game server\nname: due\nport: 3553\n"
+
+	if err := c.Store(context.Background(), file.Name, "app.yaml", content, true); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(filepath.Join(dir, "app.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(buf) != content {
+		t.Fatalf("expect content unchanged, got %s", buf)
+	}
+}
+
+func TestSecrets_Diff(t *testing.T) {
+	var (
+		ctx = context.Background()
+		dir = t.TempDir()
+	)
+
+	c := config.NewConfigurator(
+		config.WithSources(file.NewSource(file.WithPath(dir), file.WithMode(config.ReadWrite))),
+		config.WithEncryptor(reverseEncryptor{}),
+		config.WithSecrets("db.password"),
+		config.WithHistory(config.NewMemoryHistory(10)),
+	)
+	defer c.Close()
+
+	for _, password := range []string{"123456", "654321"} {
+		if err := c.Store(ctx, file.Name, "db.json", `{"user":"root","password":"`+password+`"}`, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := c.(config.Historian).Diff(ctx, file.Name, "db.json", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0].Path != "password" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	if changes[0].Old == "123456" || changes[0].New == "654321" {
+		t.Fatalf("expect secret masked, got %v -> %v", changes[0].Old, changes[0].New)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	var (
+		ctx = config.NewAuthorContext(context.Background(), "tester")
+		dir = t.TempDir()
+	)
+
+	if err := os.WriteFile(filepath.Join(dir, "balance.json"), []byte(`{"gold":100,"exp":10}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.NewConfigurator(
+		config.WithSources(file.NewSource(file.WithPath(dir), file.WithMode(config.ReadWrite))),
+		config.WithHistory(config.NewSourceHistory(file.NewSource(file.WithPath(t.TempDir()), file.WithMode(config.ReadWrite)), 10)),
+	)
+	defer c.Close()
+
+	h := c.(config.Historian)
+
+	if err := c.Store(ctx, file.Name, "balance.json", map[string]any{"gold": 200}); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := h.Versions(ctx, file.Name, "balance.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(snapshots) != 2 || snapshots[1].Author != "tester" || snapshots[1].Checksum == snapshots[0].Checksum {
+		t.Fatalf("unexpected snapshots: %+v", snapshots)
+	}
+
+	changes, err := h.Diff(ctx, file.Name, "balance.json", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0].Path != "gold" || changes[0].Kind != config.ChangeModified {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	if err = h.Rollback(ctx, file.Name, "balance.json", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(filepath.Join(dir, "balance.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(buf) != `{"gold":100,"exp":10}` {
+		t.Fatalf("unexpected content after rollback: %s", buf)
+	}
+
+	if snapshots, _ = h.Versions(ctx, file.Name, "balance.json"); len(snapshots) != 3 {
+		t.Fatalf("expect rollback snapshot, got %d snapshots", len(snapshots))
+	}
+}
+
+type chanLocker chan struct{}
+
+func (l chanLocker) Acquire(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l chanLocker) Release(ctx context.Context) error {
+	<-l
+	return nil
+}
+
+// 写入前延迟的配置源，放大读取与写回之间的并发窗口
+type slowSource struct {
+	config.Source
+}
+
+func (s *slowSource) Store(ctx context.Context, file string, content []byte) error {
+	time.Sleep(time.Millisecond)
+	return s.Source.Store(ctx, file, content)
+}
+
+func TestSourceHistory_Locker(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		source = &slowSource{file.NewSource(file.WithPath(t.TempDir()), file.WithMode(config.ReadWrite))}
+		locker = make(chanLocker, 1)
+		wg     sync.WaitGroup
+	)
+
+	// 两个历史记录共享同一配置源，模拟多个进程并发保存快照
+	histories := []config.History{
+		config.NewSourceHistory(source, 0, locker),
+		config.NewSourceHistory(source, 0, locker),
+	}
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(h config.History) {
+			defer wg.Done()
+
+			if err := h.Save(ctx, &config.Snapshot{Source: file.Name, File: "balance.json", Content: []byte(`{}`)}); err != nil {
+				t.Error(err)
+			}
+		}(histories[i%2])
+	}
+
+	wg.Wait()
+
+	snapshots, err := histories[0].List(ctx, file.Name, "balance.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(snapshots) != 20 {
+		t.Fatalf("snapshots = %d, want 20", len(snapshots))
+	}
+}
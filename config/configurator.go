@@ -6,6 +6,7 @@ import (
 	"math"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,6 +39,30 @@ type Configurator interface {
 	Close()
 }
 
+// Inspector 可扫描及追踪配置值的配置器
+type Inspector interface {
+	// Scan 扫描配置到结构体，支持default标签设置默认值及validate标签校验
+	Scan(pattern string, dest any) error
+	// Trace 追踪配置值来源，按优先级从高到低返回各配置层中的配置值，首个即为生效的配置值
+	Trace(pattern string) []*Origin
+}
+
+// Overrider 可撤销运行时设置的配置器
+type Overrider interface {
+	// Unset 撤销通过Set设置的配置值及其下级配置值，撤销后配置源中的配置值重新生效
+	Unset(pattern string) error
+}
+
+// Historian 可查询及回滚配置历史版本的配置器
+type Historian interface {
+	// Versions 列出配置项的历史版本
+	Versions(ctx context.Context, source string, file string) ([]*Snapshot, error)
+	// Diff 比较配置项的两个历史版本
+	Diff(ctx context.Context, source string, file string, from, to int64) ([]*Change, error)
+	// Rollback 回滚配置项到指定历史版本
+	Rollback(ctx context.Context, source string, file string, version int64) error
+}
+
 type WatchCallbackFunc func(names ...string)
 
 type watcher struct {
@@ -51,13 +76,21 @@ type defaultConfigurator struct {
 	cancel   context.CancelFunc
 	sources  map[string]Source
 	mu       sync.Mutex
+	layers   []*layer
+	settings []*setting
+	secrets  *secrets
 	idx      int64
 	values   [2]map[string]any
 	rw       sync.RWMutex
 	watchers []*watcher
 }
 
-var _ Configurator = &defaultConfigurator{}
+var (
+	_ Configurator = &defaultConfigurator{}
+	_ Inspector    = &defaultConfigurator{}
+	_ Overrider    = &defaultConfigurator{}
+	_ Historian    = &defaultConfigurator{}
+)
 
 func NewConfigurator(opts ...Option) Configurator {
 	o := defaultOptions()
@@ -69,6 +102,7 @@ func NewConfigurator(opts ...Option) Configurator {
 	r.opts = o
 	r.ctx, r.cancel = context.WithCancel(o.ctx)
 	r.watchers = make([]*watcher, 0)
+	r.secrets = newSecrets(o.secrets...)
 	r.init()
 	r.watch()
 
@@ -76,14 +110,23 @@ func NewConfigurator(opts ...Option) Configurator {
 }
 
 // 初始化配置源
+// 配置源按照优先级从低到高分层，相同优先级按照设置顺序分层，同名配置按键深度合并
 func (c *defaultConfigurator) init() {
 	c.sources = make(map[string]Source, len(c.opts.sources))
+	c.layers = make([]*layer, 0, len(c.opts.sources))
 	for _, s := range c.opts.sources {
 		c.sources[s.Name()] = s
 	}
 
-	values := make(map[string]any)
-	for _, s := range c.opts.sources {
+	sources := append([]Source(nil), c.opts.sources...)
+	sort.SliceStable(sources, func(i, j int) bool {
+		return c.opts.priorities[sources[i].Name()] < c.opts.priorities[sources[j].Name()]
+	})
+
+	for _, s := range sources {
+		l := &layer{source: s, values: make(map[string]any)}
+		c.layers = append(c.layers, l)
+
 		cs, err := s.Load(c.ctx)
 		if err != nil {
 			log.Printf("load configure failed: %v", err)
@@ -95,7 +138,7 @@ func (c *defaultConfigurator) init() {
 				continue
 			}
 
-			v, err := c.opts.decoder(cc.Format, cc.Content)
+			v, err := c.decode(cc.Name, cc.Format, cc.Content)
 			if err != nil {
 				if !errors.Is(err, errors.ErrInvalidFormat) {
 					log.Printf("decode configure failed: %v", err)
@@ -103,7 +146,87 @@ func (c *defaultConfigurator) init() {
 				continue
 			}
 
-			values[cc.Name] = v
+			l.values[cc.Name] = v
+		}
+	}
+
+	c.store(mergeLayers(c.layers))
+}
+
+// 解码配置内容，设置了加密器时解密其中的密文
+func (c *defaultConfigurator) decode(name, format string, content []byte) (any, error) {
+	v, err := c.opts.decoder(format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.opts.encryptor == nil {
+		return v, nil
+	}
+
+	return c.secrets.decrypt(c.opts.encryptor, name, v), nil
+}
+
+// 扫描配置内容，设置了加密器时先解密其中的密文
+func (c *defaultConfigurator) scan(name, format string, content []byte, dest any) error {
+	if c.opts.encryptor == nil {
+		return c.opts.scanner(format, content, dest)
+	}
+
+	v, err := c.decode(name, format, content)
+	if err != nil {
+		return err
+	}
+
+	buf, err := c.opts.encoder(format, v)
+	if err != nil {
+		return err
+	}
+
+	return c.opts.scanner(format, buf, dest)
+}
+
+// 是否为原始配置内容，原始配置内容按文件格式直接保存
+func isRawContent(content any) bool {
+	switch content.(type) {
+	case string, *string, []byte, *[]byte:
+		return true
+	default:
+		return false
+	}
+}
+
+// 加密配置内容中位于密文路径上的明文
+func (c *defaultConfigurator) seal(name, format string, content []byte) ([]byte, error) {
+	if c.opts.encryptor == nil {
+		return content, nil
+	}
+
+	v, err := c.opts.decoder(format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	v, sealed, err := c.secrets.encrypt(c.opts.encryptor, name, v)
+	if err != nil {
+		return nil, err
+	}
+
+	// 无明文需要加密时保留原始内容，避免重新编码丢失注释及字段顺序
+	if !sealed {
+		return content, nil
+	}
+
+	return c.opts.encoder(format, v)
+}
+
+// 重新合并配置层并应用运行时设置，调用方需持有锁
+func (c *defaultConfigurator) rebuild() {
+	values := mergeLayers(c.layers)
+
+	for _, st := range c.settings {
+		if err := doSet(values, st.pattern, st.value); err != nil {
+			log.Printf("apply runtime configure %s failed: %v", st.pattern, err)
 		}
 	}
 
@@ -140,14 +263,14 @@ func (c *defaultConfigurator) copy() (map[string]any, error) {
 
 // 监听配置源变化
 func (c *defaultConfigurator) watch() {
-	for _, s := range c.opts.sources {
-		w, err := s.Watch(c.ctx)
+	for _, l := range c.layers {
+		w, err := l.source.Watch(c.ctx)
 		if err != nil {
 			log.Printf("watching configure change failed: %v", err)
 			continue
 		}
 
-		go func() {
+		go func(l *layer) {
 			defer w.Stop()
 
 			for {
@@ -169,7 +292,7 @@ func (c *defaultConfigurator) watch() {
 						continue
 					}
 
-					v, err := c.opts.decoder(cc.Format, cc.Content)
+					v, err := c.decode(cc.Name, cc.Format, cc.Content)
 					if err != nil {
 						continue
 					}
@@ -177,37 +300,41 @@ func (c *defaultConfigurator) watch() {
 					values[cc.Name] = v
 				}
 
+				if len(names) == 0 {
+					continue
+				}
+
 				func() {
 					c.mu.Lock()
 					defer c.mu.Unlock()
 
-					dst, err := c.copy()
-					if err != nil {
-						return
+					dst := make(map[string]any, len(l.values)+len(values))
+					for name, v := range l.values {
+						dst[name] = v
 					}
 
-					err = mergo.Merge(&dst, values, mergo.WithOverride)
-					if err != nil {
-						return
+					for name, v := range values {
+						dst[name] = v
 					}
 
-					c.store(dst)
+					l.values = dst
+
+					c.rebuild()
 				}()
 
-				if len(names) > 0 {
-					go c.notify(names...)
-				}
+				go c.notify(names...)
 			}
-		}()
+		}(l)
 	}
 }
 
 // 通知给监听器
 func (c *defaultConfigurator) notify(names ...string) {
 	c.rw.RLock()
-	defer c.rw.RUnlock()
+	watchers := c.watchers
+	c.rw.RUnlock()
 
-	for _, w := range c.watchers {
+	for _, w := range watchers {
 		if len(w.names) == 0 {
 			w.callback(names...)
 		} else {
@@ -241,42 +368,9 @@ func (c *defaultConfigurator) Has(pattern string) bool {
 
 // 执行检测配置是否存在操作
 func (c *defaultConfigurator) doHas(pattern string) bool {
-	var (
-		keys   = strings.Split(pattern, ".")
-		node   any
-		found  = true
-		values = c.load()
-	)
-
-	keys = reviseKeys(keys, values)
-	node = values
-	for _, key := range keys {
-		switch vs := node.(type) {
-		case map[string]any:
-			if v, ok := vs[key]; ok {
-				node = v
-			} else {
-				found = false
-			}
-		case []any:
-			i, err := strconv.Atoi(key)
-			if err != nil {
-				found = false
-			} else if len(vs) > i {
-				node = vs[i]
-			} else {
-				found = false
-			}
-		default:
-			found = false
-		}
-
-		if !found {
-			break
-		}
-	}
+	_, ok := lookup(c.load(), pattern)
 
-	return found
+	return ok
 }
 
 // Get 获取配置值
@@ -293,62 +387,54 @@ func (c *defaultConfigurator) Match(patterns ...string) Matcher {
 	return &defaultMatcher{c: c, patterns: patterns}
 }
 
+// Scan 扫描配置到结构体，支持default标签设置默认值及validate标签校验
+func (c *defaultConfigurator) Scan(pattern string, dest any) error {
+	val, _ := c.doGet(pattern)
+
+	return scan(val, dest)
+}
+
 // 执行获取配置操作
 func (c *defaultConfigurator) doGet(pattern string) (value.Value, bool) {
-	var (
-		keys   = strings.Split(pattern, ".")
-		node   any
-		found  = true
-		values = c.load()
-	)
-
-	if len(values) == 0 {
-		goto NOTFOUND
+	if node, ok := lookup(c.load(), pattern); ok {
+		return value.NewValue(node), true
 	}
 
-	keys = reviseKeys(keys, values)
-	node = values
-	for _, key := range keys {
-		switch vs := node.(type) {
-		case map[string]any:
-			if v, ok := vs[key]; ok {
-				node = v
-			} else {
-				found = false
-			}
-		case []any:
-			i, err := strconv.Atoi(key)
-			if err != nil {
-				found = false
-			} else if len(vs) > i {
-				node = vs[i]
-			} else {
-				found = false
-			}
-		default:
-			found = false
+	return nil, false
+}
+
+// Trace 追踪配置值来源，按优先级从高到低返回各配置层中的配置值，首个即为生效的配置值
+// 运行时设置按路径匹配，设置了上级或下级配置值时运行时配置层同样包含该配置值
+func (c *defaultConfigurator) Trace(pattern string) []*Origin {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	origins := make([]*Origin, 0, len(c.layers)+1)
+
+	if len(c.settings) > 0 {
+		values := make(map[string]any)
+		for _, st := range c.settings {
+			// 拷贝设置值，避免设置下级配置值时修改上级设置值
+			_ = doSet(values, st.pattern, mergeValue(nil, st.value))
 		}
 
-		if !found {
-			break
+		if node, ok := lookup(values, pattern); ok {
+			origins = append(origins, &Origin{Layer: RuntimeLayer, Value: value.NewValue(node)})
 		}
 	}
 
-	if found {
-		return value.NewValue(node), true
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		if node, ok := lookup(c.layers[i].values, pattern); ok {
+			origins = append(origins, &Origin{Layer: c.layers[i].source.Name(), Value: value.NewValue(node)})
+		}
 	}
 
-NOTFOUND:
-	return nil, false
+	return origins
 }
 
 // Set 设置配置值
+// 设置的配置值位于运行时配置层，优先级高于所有配置源，配置源变更后依然生效，可通过Unset撤销
 func (c *defaultConfigurator) Set(pattern string, value any) error {
-	var (
-		keys = strings.Split(pattern, ".")
-		node any
-	)
-
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -357,6 +443,54 @@ func (c *defaultConfigurator) Set(pattern string, value any) error {
 		return err
 	}
 
+	if err = doSet(values, pattern, value); err != nil {
+		return err
+	}
+
+	c.store(values)
+
+	for _, st := range c.settings {
+		if st.pattern == pattern {
+			st.value = value
+			return nil
+		}
+	}
+
+	c.settings = append(c.settings, &setting{pattern: pattern, value: value})
+
+	return nil
+}
+
+// Unset 撤销通过Set设置的配置值及其下级配置值，撤销后配置源中的配置值重新生效
+func (c *defaultConfigurator) Unset(pattern string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	settings := make([]*setting, 0, len(c.settings))
+	for _, st := range c.settings {
+		if st.pattern != pattern && !strings.HasPrefix(st.pattern, pattern+".") {
+			settings = append(settings, st)
+		}
+	}
+
+	if len(settings) == len(c.settings) {
+		return nil
+	}
+
+	c.settings = settings
+
+	c.rebuild()
+
+	return nil
+}
+
+// 执行设置配置操作
+func doSet(values map[string]any, pattern string, value any) error {
+	var (
+		keys = strings.Split(pattern, ".")
+		node any
+	)
+
 	keys = reviseKeys(keys, values)
 	node = values
 	for i, key := range keys {
@@ -441,13 +575,16 @@ func (c *defaultConfigurator) Set(pattern string, value any) error {
 		}
 	}
 
-	c.store(values)
-
 	return nil
 }
 
 // Watch 设置监听回调
 func (c *defaultConfigurator) Watch(cb WatchCallbackFunc, names ...string) {
+	c.addWatcher(cb, names...)
+}
+
+// 添加监听器，返回移除监听器的函数
+func (c *defaultConfigurator) addWatcher(cb WatchCallbackFunc, names ...string) func() {
 	w := &watcher{}
 	w.names = make(map[string]struct{}, len(names))
 	w.callback = cb
@@ -459,6 +596,18 @@ func (c *defaultConfigurator) Watch(cb WatchCallbackFunc, names ...string) {
 	c.rw.Lock()
 	c.watchers = append(c.watchers, w)
 	c.rw.Unlock()
+
+	return func() {
+		c.rw.Lock()
+		defer c.rw.Unlock()
+
+		for i, item := range c.watchers {
+			if item == w {
+				c.watchers = append(c.watchers[:i:i], c.watchers[i+1:]...)
+				break
+			}
+		}
+	}
 }
 
 // Load 加载配置项
@@ -474,13 +623,20 @@ func (c *defaultConfigurator) Load(ctx context.Context, source string, file ...s
 	}
 
 	for _, cc := range configs {
-		cc.decoder, cc.scanner = c.opts.decoder, c.opts.scanner
+		name := cc.Name
+		cc.decoder = func(format string, content []byte) (any, error) {
+			return c.decode(name, format, content)
+		}
+		cc.scanner = func(format string, content []byte, dest any) error {
+			return c.scan(name, format, content, dest)
+		}
 	}
 
 	return configs, nil
 }
 
 // Store 保存配置项
+// 设置了加密器时，密文路径上的明文配置值会被加密为ENC(base64)格式后保存
 func (c *defaultConfigurator) Store(ctx context.Context, source string, file string, content any, override ...bool) error {
 	if content == nil {
 		return errors.ErrInvalidConfigContent
@@ -495,11 +651,14 @@ func (c *defaultConfigurator) Store(ctx context.Context, source string, file str
 		err    error
 		buf    []byte
 		ext    = filepath.Ext(file)
+		name   = strings.TrimSuffix(filepath.Base(file), ext)
 		format = strings.TrimPrefix(ext, ".")
 	)
 
-	switch rk, _ := xreflect.Value(content); rk {
-	case reflect.Map, reflect.Struct:
+	switch rk, _ := xreflect.Value(content); {
+	case isRawContent(content):
+		buf = xconv.Bytes(xconv.String(content))
+	case rk == reflect.Map || rk == reflect.Struct:
 		if len(override) > 0 && override[0] {
 			buf, err = c.opts.encoder(format, content)
 		} else {
@@ -508,8 +667,6 @@ func (c *defaultConfigurator) Store(ctx context.Context, source string, file str
 				return err
 			}
 
-			name := strings.TrimSuffix(filepath.Base(file), ext)
-
 			val, ok := dest[name]
 			if !ok {
 				buf, err = c.opts.encoder(format, content)
@@ -534,7 +691,7 @@ func (c *defaultConfigurator) Store(ctx context.Context, source string, file str
 				buf, err = c.opts.encoder(format, content)
 			}
 		}
-	case reflect.Array, reflect.Slice:
+	case rk == reflect.Array || rk == reflect.Slice:
 		buf, err = c.opts.encoder(format, content)
 	default:
 		buf = xconv.Bytes(xconv.String(content))
@@ -543,7 +700,125 @@ func (c *defaultConfigurator) Store(ctx context.Context, source string, file str
 		return err
 	}
 
-	return s.Store(ctx, file, buf)
+	if buf, err = c.seal(name, format, buf); err != nil {
+		return err
+	}
+
+	return c.doStore(ctx, s, file, buf, "")
+}
+
+// 执行保存配置操作，并记录配置快照
+func (c *defaultConfigurator) doStore(ctx context.Context, s Source, file string, content []byte, comment string) error {
+	if c.opts.history == nil {
+		return s.Store(ctx, file, content)
+	}
+
+	snapshots, err := c.opts.history.List(ctx, s.Name(), file)
+	if err != nil {
+		return err
+	}
+
+	// 首次保存时记录配置源中的原始内容，以便回滚到保存前的版本
+	if len(snapshots) == 0 {
+		if cs, err := s.Load(ctx, file); err == nil {
+			for _, cc := range cs {
+				if cc.File == filepath.Base(file) && len(cc.Content) > 0 {
+					snapshot := newSnapshot(context.Background(), s.Name(), file, cc.Content, "original")
+					if err = c.opts.history.Save(ctx, snapshot); err != nil {
+						return err
+					}
+					snapshots = append(snapshots, snapshot)
+					break
+				}
+			}
+		}
+	}
+
+	if err = s.Store(ctx, file, content); err != nil {
+		return err
+	}
+
+	snapshot := newSnapshot(ctx, s.Name(), file, content, comment)
+
+	if n := len(snapshots); n > 0 && snapshots[n-1].Checksum == snapshot.Checksum && comment == "" {
+		return nil
+	}
+
+	if err = c.opts.history.Save(ctx, snapshot); err != nil {
+		log.Printf("save configure %s snapshot failed: %v", file, err)
+	}
+
+	return nil
+}
+
+// Versions 列出配置项的历史版本
+func (c *defaultConfigurator) Versions(ctx context.Context, source string, file string) ([]*Snapshot, error) {
+	if _, ok := c.sources[source]; !ok {
+		return nil, errors.ErrNotFoundConfigSource
+	}
+
+	if c.opts.history == nil {
+		return nil, errors.ErrMissingHistory
+	}
+
+	return c.opts.history.List(ctx, source, file)
+}
+
+// Diff 比较配置项的两个历史版本
+// 按配置路径返回从from版本到to版本的变更，密文配置值会先解密再比较，比较结果中的密文配置值以掩码代替
+func (c *defaultConfigurator) Diff(ctx context.Context, source string, file string, from, to int64) ([]*Change, error) {
+	snapshots, err := c.Versions(ctx, source, file)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, err := findSnapshot(snapshots, from)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := findSnapshot(snapshots, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ext    = filepath.Ext(file)
+		name   = strings.TrimSuffix(filepath.Base(file), ext)
+		format = strings.TrimPrefix(ext, ".")
+	)
+
+	ov, err1 := c.decode(name, format, prev.Content)
+	nv, err2 := c.decode(name, format, next.Content)
+	if err1 != nil || err2 != nil {
+		ov, nv = string(prev.Content), string(next.Content)
+	}
+
+	changes := diffValues(ov, nv)
+
+	for _, change := range changes {
+		if change.Path != "" && c.secrets.has(name+"."+change.Path) {
+			change.Old, change.New = maskSecret(change.Old), maskSecret(change.New)
+		}
+	}
+
+	return changes, nil
+}
+
+// Rollback 回滚配置项到指定历史版本
+// 回滚本身也会生成一个新的快照
+func (c *defaultConfigurator) Rollback(ctx context.Context, source string, file string, version int64) error {
+	snapshots, err := c.Versions(ctx, source, file)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := findSnapshot(snapshots, version)
+	if err != nil {
+		return err
+	}
+
+	return c.doStore(ctx, c.sources[source], file, snapshot.Content, "rollback to version "+strconv.FormatInt(version, 10))
 }
 
 func reviseKeys(keys []string, values map[string]any) []string {
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultPrefix    = "DUE_CONFIG_"
+	defaultSeparator = "__"
+)
+
+const (
+	defaultPrefixKey    = "etc.config.env.prefix"
+	defaultSeparatorKey = "etc.config.env.separator"
+)
+
+type Option func(o *options)
+
+type options struct {
+	// 环境变量前缀
+	// 带有该前缀的环境变量会被作为配置覆盖项，默认为DUE_CONFIG_
+	prefix string
+
+	// 键分隔符
+	// 去除前缀后的环境变量名以该分隔符拆分为配置键路径，默认为__
+	// 例如：DUE_CONFIG_battle__maxRound=50 将覆盖配置 battle.maxRound
+	separator string
+
+	// 环境变量与配置键的显式映射
+	// 例如：{"GAME_MAX_ROUND": "battle.maxRound"}，默认为空
+	keys map[string]string
+}
+
+func defaultOptions() *options {
+	return &options{
+		prefix:    etc.Get(defaultPrefixKey, defaultPrefix).String(),
+		separator: etc.Get(defaultSeparatorKey, defaultSeparator).String(),
+		keys:      make(map[string]string),
+	}
+}
+
+// WithPrefix 设置环境变量前缀
+func WithPrefix(prefix string) Option {
+	return func(o *options) { o.prefix = prefix }
+}
+
+// WithSeparator 设置键分隔符
+func WithSeparator(separator string) Option {
+	return func(o *options) { o.separator = separator }
+}
+
+// WithKeys 设置环境变量与配置键的显式映射
+func WithKeys(keys map[string]string) Option {
+	return func(o *options) { o.keys = keys }
+}
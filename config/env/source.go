@@ -0,0 +1,68 @@
+package env
+
+import (
+	"context"
+	"strings"
+
+	"github.com/devagame/due/v2/config"
+	"github.com/devagame/due/v2/config/internal/override"
+	"github.com/devagame/due/v2/env"
+	"github.com/devagame/due/v2/errors"
+)
+
+const Name = "env"
+
+type Source struct {
+	opts *options
+}
+
+var _ config.Source = &Source{}
+
+func NewSource(opts ...Option) config.Source {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Source{opts: o}
+}
+
+// Name 配置源名称
+func (s *Source) Name() string {
+	return Name
+}
+
+// Load 加载配置项
+func (s *Source) Load(ctx context.Context, file ...string) ([]*config.Configuration, error) {
+	pairs := make(map[string]string)
+
+	for key, val := range env.All() {
+		if k, ok := s.opts.keys[key]; ok {
+			pairs[k] = val
+			continue
+		}
+
+		if s.opts.prefix == "" || !strings.HasPrefix(key, s.opts.prefix) {
+			continue
+		}
+
+		pairs[strings.ReplaceAll(strings.TrimPrefix(key, s.opts.prefix), s.opts.separator, ".")] = val
+	}
+
+	return override.Load(pairs, file...)
+}
+
+// Store 保存配置项
+func (s *Source) Store(ctx context.Context, file string, content []byte) error {
+	return errors.ErrNoOperationPermission
+}
+
+// Watch 监听配置项
+func (s *Source) Watch(ctx context.Context) (config.Watcher, error) {
+	return override.NewWatcher(ctx), nil
+}
+
+// Close 关闭配置源
+func (s *Source) Close() error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package flag
+
+import (
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultPrefix = "config."
+)
+
+const (
+	defaultPrefixKey = "etc.config.flag.prefix"
+)
+
+type Option func(o *options)
+
+type options struct {
+	// 运行参数前缀
+	// 带有该前缀的运行参数会被作为配置覆盖项，默认为config.
+	// 例如：--config.battle.maxRound=50 将覆盖配置 battle.maxRound
+	prefix string
+}
+
+func defaultOptions() *options {
+	return &options{
+		prefix: etc.Get(defaultPrefixKey, defaultPrefix).String(),
+	}
+}
+
+// WithPrefix 设置运行参数前缀
+func WithPrefix(prefix string) Option {
+	return func(o *options) { o.prefix = prefix }
+}
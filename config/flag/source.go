@@ -0,0 +1,63 @@
+package flag
+
+import (
+	"context"
+	"strings"
+
+	"github.com/devagame/due/v2/config"
+	"github.com/devagame/due/v2/config/internal/override"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/flag"
+)
+
+const Name = "flag"
+
+type Source struct {
+	opts *options
+}
+
+var _ config.Source = &Source{}
+
+func NewSource(opts ...Option) config.Source {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Source{opts: o}
+}
+
+// Name 配置源名称
+func (s *Source) Name() string {
+	return Name
+}
+
+// Load 加载配置项
+func (s *Source) Load(ctx context.Context, file ...string) ([]*config.Configuration, error) {
+	pairs := make(map[string]string)
+
+	for key, val := range flag.All() {
+		if s.opts.prefix == "" || !strings.HasPrefix(key, s.opts.prefix) {
+			continue
+		}
+
+		pairs[strings.TrimPrefix(key, s.opts.prefix)] = val
+	}
+
+	return override.Load(pairs, file...)
+}
+
+// Store 保存配置项
+func (s *Source) Store(ctx context.Context, file string, content []byte) error {
+	return errors.ErrNoOperationPermission
+}
+
+// Watch 监听配置项
+func (s *Source) Watch(ctx context.Context) (config.Watcher, error) {
+	return override.NewWatcher(ctx), nil
+}
+
+// Close 关闭配置源
+func (s *Source) Close() error {
+	return nil
+}
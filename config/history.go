@@ -0,0 +1,295 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devagame/due/v2/encoding/json"
+	"github.com/devagame/due/v2/errors"
+)
+
+type authorCtxKey struct{}
+
+// Snapshot 配置快照
+type Snapshot struct {
+	Source    string    `json:"source"`            // 配置源名称
+	File      string    `json:"file"`              // 文件全称
+	Version   int64     `json:"version"`           // 版本号，同一配置源的同一文件内单调递增
+	Author    string    `json:"author,omitempty"`  // 操作人
+	Comment   string    `json:"comment,omitempty"` // 备注
+	Timestamp time.Time `json:"timestamp"`         // 快照时间
+	Checksum  string    `json:"checksum"`          // 内容的SHA256校验和
+	Content   []byte    `json:"content"`           // 文件内容
+}
+
+// ChangeKind 变更类型
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"    // 新增
+	ChangeRemoved  ChangeKind = "removed"  // 删除
+	ChangeModified ChangeKind = "modified" // 修改
+)
+
+// Change 配置变更
+type Change struct {
+	Path string     // 配置路径，无法解码的内容路径为空
+	Kind ChangeKind // 变更类型
+	Old  any        // 旧值
+	New  any        // 新值
+}
+
+type History interface {
+	// Save 保存快照，版本号由历史记录分配
+	Save(ctx context.Context, snapshot *Snapshot) error
+	// List 按版本号从小到大列出快照
+	List(ctx context.Context, source string, file string) ([]*Snapshot, error)
+}
+
+// NewAuthorContext 设置操作人，保存或回滚配置时记录到快照中
+func NewAuthorContext(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorCtxKey{}, author)
+}
+
+// AuthorFromContext 获取操作人
+func AuthorFromContext(ctx context.Context) string {
+	author, _ := ctx.Value(authorCtxKey{}).(string)
+	return author
+}
+
+// 新建快照
+func newSnapshot(ctx context.Context, source, file string, content []byte, comment string) *Snapshot {
+	sum := sha256.Sum256(content)
+
+	return &Snapshot{
+		Source:    source,
+		File:      file,
+		Author:    AuthorFromContext(ctx),
+		Comment:   comment,
+		Timestamp: time.Now(),
+		Checksum:  hex.EncodeToString(sum[:]),
+		Content:   content,
+	}
+}
+
+// 查找指定版本的快照
+func findSnapshot(snapshots []*Snapshot, version int64) (*Snapshot, error) {
+	for _, snapshot := range snapshots {
+		if snapshot.Version == version {
+			return snapshot, nil
+		}
+	}
+
+	return nil, errors.ErrNotFoundSnapshot
+}
+
+// 追加快照并分配版本号，超出限制时淘汰最旧的快照
+func appendSnapshot(snapshots []*Snapshot, snapshot *Snapshot, limit int) []*Snapshot {
+	if n := len(snapshots); n > 0 {
+		snapshot.Version = snapshots[n-1].Version + 1
+	} else {
+		snapshot.Version = 1
+	}
+
+	snapshots = append(snapshots, snapshot)
+
+	if limit > 0 && len(snapshots) > limit {
+		snapshots = append([]*Snapshot(nil), snapshots[len(snapshots)-limit:]...)
+	}
+
+	return snapshots
+}
+
+type memoryHistory struct {
+	limit     int
+	rw        sync.RWMutex
+	snapshots map[string][]*Snapshot
+}
+
+// NewMemoryHistory 新建内存历史记录，每个文件最多保留limit个快照，limit小于等于0时不限制
+// 内存历史记录随进程退出而丢失，需要持久化时请使用NewSourceHistory
+func NewMemoryHistory(limit int) History {
+	return &memoryHistory{limit: limit, snapshots: make(map[string][]*Snapshot)}
+}
+
+// Save 保存快照
+func (h *memoryHistory) Save(ctx context.Context, snapshot *Snapshot) error {
+	key := snapshot.Source + ":" + snapshot.File
+
+	h.rw.Lock()
+	h.snapshots[key] = appendSnapshot(h.snapshots[key], snapshot, h.limit)
+	h.rw.Unlock()
+
+	return nil
+}
+
+// List 列出快照
+func (h *memoryHistory) List(ctx context.Context, source string, file string) ([]*Snapshot, error) {
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+
+	return append([]*Snapshot(nil), h.snapshots[source+":"+file]...), nil
+}
+
+// Locker 跨进程锁，lock包中的Locker均实现了该接口
+type Locker interface {
+	// Acquire 获取锁
+	Acquire(ctx context.Context) error
+	// Release 释放锁
+	Release(ctx context.Context) error
+}
+
+type sourceHistory struct {
+	source Source
+	limit  int
+	locker Locker
+	mu     sync.Mutex
+}
+
+// NewSourceHistory 新建基于配置源的历史记录，每个文件的快照以JSON格式保存在配置源的单个文件中
+// 可使用file、etcd、consul、nacos等任意可写配置源，为避免快照被当作配置加载，应使用独立的目录、前缀或分组
+// 每个文件最多保留limit个快照，limit小于等于0时不限制
+// 保存快照需要读取、追加并写回快照文件，进程内由互斥锁保证串行；多个进程共享同一历史记录时须传入跨进程锁，否则并发保存的快照可能相互覆盖
+func NewSourceHistory(source Source, limit int, locker ...Locker) History {
+	h := &sourceHistory{source: source, limit: limit}
+
+	if len(locker) > 0 {
+		h.locker = locker[0]
+	}
+
+	return h
+}
+
+// Save 保存快照
+func (h *sourceHistory) Save(ctx context.Context, snapshot *Snapshot) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.locker != nil {
+		if err := h.locker.Acquire(ctx); err != nil {
+			return err
+		}
+
+		defer h.locker.Release(context.WithoutCancel(ctx))
+	}
+
+	snapshots, err := h.load(ctx, snapshot.Source, snapshot.File)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(appendSnapshot(snapshots, snapshot, h.limit))
+	if err != nil {
+		return err
+	}
+
+	return h.source.Store(ctx, h.makeFile(snapshot.Source, snapshot.File), buf)
+}
+
+// List 列出快照
+func (h *sourceHistory) List(ctx context.Context, source string, file string) ([]*Snapshot, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.load(ctx, source, file)
+}
+
+// 加载快照
+func (h *sourceHistory) load(ctx context.Context, source string, file string) ([]*Snapshot, error) {
+	name := h.makeFile(source, file)
+
+	configs, err := h.source.Load(ctx, name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, cc := range configs {
+		if cc.File != name || len(cc.Content) == 0 {
+			continue
+		}
+
+		snapshots := make([]*Snapshot, 0)
+		if err = json.Unmarshal(cc.Content, &snapshots); err != nil {
+			return nil, err
+		}
+
+		return snapshots, nil
+	}
+
+	return nil, nil
+}
+
+// 生成快照文件名，将路径分隔符替换为下划线以兼容不支持多级路径的配置源
+func (h *sourceHistory) makeFile(source string, file string) string {
+	return source + "_" + strings.ReplaceAll(filepath.ToSlash(strings.TrimPrefix(file, "/")), "/", "_") + ".history.json"
+}
+
+// 比较两个配置值，返回按路径排序的变更列表
+func diffValues(prev, next any) []*Change {
+	olds, news := make(map[string]any), make(map[string]any)
+	flatten("", prev, olds)
+	flatten("", next, news)
+
+	changes := make([]*Change, 0)
+
+	for path, ov := range olds {
+		nv, ok := news[path]
+		switch {
+		case !ok:
+			changes = append(changes, &Change{Path: path, Kind: ChangeRemoved, Old: ov})
+		case !reflect.DeepEqual(ov, nv):
+			changes = append(changes, &Change{Path: path, Kind: ChangeModified, Old: ov, New: nv})
+		}
+	}
+
+	for path, nv := range news {
+		if _, ok := olds[path]; !ok {
+			changes = append(changes, &Change{Path: path, Kind: ChangeAdded, New: nv})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes
+}
+
+// 将配置值展开为路径与叶子值的映射
+func flatten(path string, v any, dst map[string]any) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch vv := v.(type) {
+	case map[string]any:
+		if len(vv) == 0 {
+			dst[path] = vv
+		}
+		for k, item := range vv {
+			flatten(join(k), item, dst)
+		}
+	case []any:
+		if len(vv) == 0 {
+			dst[path] = vv
+		}
+		for i, item := range vv {
+			flatten(join(strconv.Itoa(i)), item, dst)
+		}
+	default:
+		dst[path] = v
+	}
+}
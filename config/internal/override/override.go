@@ -0,0 +1,110 @@
+package override
+
+import (
+	"context"
+	"strings"
+
+	"github.com/devagame/due/v2/config"
+	"github.com/devagame/due/v2/encoding/json"
+)
+
+// Load 将键值对覆盖项转换为配置项
+// 键以.分隔，首段为配置名称，其余为配置名称下的键路径
+func Load(pairs map[string]string, file ...string) ([]*config.Configuration, error) {
+	values := make(map[string]map[string]any)
+
+	for key, val := range pairs {
+		name, path, ok := strings.Cut(key, ".")
+		if !ok || name == "" || path == "" {
+			continue
+		}
+
+		if len(file) > 0 && file[0] != "" && file[0] != name && file[0] != name+"."+json.Name {
+			continue
+		}
+
+		if _, ok = values[name]; !ok {
+			values[name] = make(map[string]any)
+		}
+
+		set(values[name], strings.Split(path, "."), parse(val))
+	}
+
+	configs := make([]*config.Configuration, 0, len(values))
+	for name, value := range values {
+		content, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		configs = append(configs, &config.Configuration{
+			Path:     "/" + name + "." + json.Name,
+			File:     name + "." + json.Name,
+			Name:     name,
+			Format:   json.Name,
+			Content:  content,
+			FullPath: "/" + name + "." + json.Name,
+		})
+	}
+
+	return configs, nil
+}
+
+// 设置键路径对应的值
+func set(node map[string]any, keys []string, val any) {
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			node[key] = val
+			return
+		}
+
+		next, ok := node[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			node[key] = next
+		}
+
+		node = next
+	}
+}
+
+// 解析覆盖值，JSON数组或对象解析为对应结构，否则保留为字符串
+// 标量值不做类型推断，避免"0123"、"1.0"等字符串被转换为数值而丢失原始内容，读取时由配置值按需转换
+func parse(val string) any {
+	if strings.HasPrefix(val, "[") || strings.HasPrefix(val, "{") {
+		var v any
+		if err := json.Unmarshal([]byte(val), &v); err == nil {
+			return v
+		}
+	}
+
+	return val
+}
+
+type watcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewWatcher 新建监听器
+// 覆盖项在进程运行期间不会发生变化，监听器仅阻塞至停止监听
+func NewWatcher(ctx context.Context) config.Watcher {
+	w := &watcher{}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+
+	return w
+}
+
+// Next 返回配置列表
+func (w *watcher) Next() ([]*config.Configuration, error) {
+	<-w.ctx.Done()
+
+	return nil, w.ctx.Err()
+}
+
+// Stop 停止监听
+func (w *watcher) Stop() error {
+	w.cancel()
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/devagame/due/v2/core/value"
+)
+
+// RuntimeLayer 运行时配置层名称，通过Set设置的配置值位于该层，优先级高于所有配置源
+const RuntimeLayer = "runtime"
+
+// Origin 配置值来源
+type Origin struct {
+	Layer string      // 配置层名称，即配置源名称或运行时配置层名称
+	Value value.Value // 该配置层中的配置值
+}
+
+// 配置层
+type layer struct {
+	source Source
+	values map[string]any
+}
+
+// 运行时设置
+type setting struct {
+	pattern string
+	value   any
+}
+
+// 合并配置层，后面的配置层优先级更高
+func mergeLayers(layers []*layer) map[string]any {
+	dst := make(map[string]any)
+
+	for _, l := range layers {
+		for name, v := range l.values {
+			dst[name] = mergeValue(dst[name], v)
+		}
+	}
+
+	return dst
+}
+
+// 合并配置值，字典逐键深度合并，其他类型直接覆盖
+// 合并结果中的字典与切片均为新建，避免修改合并结果时影响配置层
+func mergeValue(dst, src any) any {
+	if ss, ok := src.([]any); ok {
+		merged := make([]any, len(ss))
+		for i, v := range ss {
+			merged[i] = mergeValue(nil, v)
+		}
+		return merged
+	}
+
+	sm, ok := src.(map[string]any)
+	if !ok {
+		return src
+	}
+
+	dm, _ := dst.(map[string]any)
+
+	merged := make(map[string]any, len(dm)+len(sm))
+	for k, v := range dm {
+		merged[k] = v
+	}
+
+	for k, v := range sm {
+		merged[k] = mergeValue(merged[k], v)
+	}
+
+	return merged
+}
+
+// 查找配置值
+func lookup(values map[string]any, pattern string) (any, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	var (
+		keys = reviseKeys(strings.Split(pattern, "."), values)
+		node = any(values)
+	)
+
+	for _, key := range keys {
+		switch vs := node.(type) {
+		case map[string]any:
+			v, ok := vs[key]
+			if !ok {
+				return nil, false
+			}
+			node = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(vs) {
+				return nil, false
+			}
+			node = vs[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return node, true
+}
@@ -23,6 +23,14 @@ type options struct {
 	encoder Encoder
 	decoder Decoder
 	scanner Scanner
+	// 配置加密器，设置后自动解密ENC(base64)格式的配置值，并在保存配置时重新加密
+	encryptor Encryptor
+	// 需要加密保存的配置路径
+	secrets []string
+	// 配置历史记录
+	history History
+	// 配置源优先级
+	priorities map[string]int
 }
 
 func defaultOptions() *options {
@@ -40,10 +48,23 @@ func WithContext(ctx context.Context) Option {
 }
 
 // WithSources 设置配置源
+// 配置源按照优先级从低到高分层，优先级高的配置源覆盖优先级低的配置源，同名配置按键深度合并
+// 未通过WithPriority设置优先级的配置源优先级均为0，相同优先级的配置源按照设置顺序分层，后设置的配置源优先级更高
 func WithSources(sources ...Source) Option {
 	return func(o *options) { o.sources = sources[:] }
 }
 
+// WithPriority 设置配置源优先级，数值越大优先级越高，默认为0
+// 例如将环境变量与命令行参数配置源的优先级设置为1、2，无论WithSources中的顺序如何均可覆盖其他配置源
+func WithPriority(source string, priority int) Option {
+	return func(o *options) {
+		if o.priorities == nil {
+			o.priorities = make(map[string]int)
+		}
+		o.priorities[source] = priority
+	}
+}
+
 // WithEncoder 设置编码器
 func WithEncoder(encoder Encoder) Option {
 	return func(o *options) { o.encoder = encoder }
@@ -54,6 +75,24 @@ func WithDecoder(decoder Decoder) Option {
 	return func(o *options) { o.decoder = decoder }
 }
 
+// WithEncryptor 设置配置加密器，可直接使用已注册的crypto.Encryptor
+// 设置后加载配置时自动解密ENC(base64)格式的配置值，保存配置时重新加密原密文所在路径上的配置值
+func WithEncryptor(encryptor Encryptor) Option {
+	return func(o *options) { o.encryptor = encryptor }
+}
+
+// WithSecrets 设置需要加密保存的配置路径，路径首段为配置文件名，例如：db.mysql.password
+// 加载配置时解密过的配置路径会被自动记录，无需重复设置
+func WithSecrets(patterns ...string) Option {
+	return func(o *options) { o.secrets = append(o.secrets, patterns...) }
+}
+
+// WithHistory 设置配置历史记录，默认不记录历史，可使用NewMemoryHistory或NewSourceHistory开启
+// 通过Store保存或通过Rollback回滚的配置都会生成快照
+func WithHistory(history History) Option {
+	return func(o *options) { o.history = history }
+}
+
 // 默认编码器
 func defaultEncoder(format string, content any) ([]byte, error) {
 	switch strings.ToLower(format) {
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/base64"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/devagame/due/v2/errors"
+)
+
+const (
+	secretPrefix = "ENC("
+	secretSuffix = ")"
+	secretMask   = "******"
+)
+
+// Encryptor 配置加密器，与crypto.Encryptor兼容，可直接使用已注册的加密器
+type Encryptor interface {
+	// Encrypt 加密
+	Encrypt(data []byte) ([]byte, error)
+	// Decrypt 解密
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// IsSecret 检测配置值是否为ENC(base64)格式的密文
+func IsSecret(s string) bool {
+	return len(s) > len(secretPrefix)+len(secretSuffix) && strings.HasPrefix(s, secretPrefix) && strings.HasSuffix(s, secretSuffix)
+}
+
+// EncryptSecret 加密明文为ENC(base64)格式的密文
+func EncryptSecret(encryptor Encryptor, plaintext string) (string, error) {
+	if encryptor == nil {
+		return "", errors.ErrInvalidEncryptor
+	}
+
+	ciphertext, err := encryptor.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return secretPrefix + base64.StdEncoding.EncodeToString(ciphertext) + secretSuffix, nil
+}
+
+// DecryptSecret 解密ENC(base64)格式的密文
+func DecryptSecret(encryptor Encryptor, secret string) (string, error) {
+	if encryptor == nil {
+		return "", errors.ErrInvalidEncryptor
+	}
+
+	if !IsSecret(secret) {
+		return "", errors.ErrInvalidSecret
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(secret[len(secretPrefix) : len(secret)-len(secretSuffix)])
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := encryptor.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// 掩盖密文配置值，避免明文外泄
+func maskSecret(v any) any {
+	if v == nil {
+		return nil
+	}
+
+	return secretMask
+}
+
+// 密文配置路径集合
+type secrets struct {
+	rw    sync.RWMutex
+	paths map[string]struct{}
+}
+
+func newSecrets(patterns ...string) *secrets {
+	s := &secrets{paths: make(map[string]struct{}, len(patterns))}
+
+	for _, pattern := range patterns {
+		s.paths[pattern] = struct{}{}
+	}
+
+	return s
+}
+
+// 检测路径是否为密文配置
+func (s *secrets) has(path string) bool {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	_, ok := s.paths[path]
+
+	return ok
+}
+
+// 解密配置中所有ENC(base64)格式的密文，并记录密文所在路径
+// 解密失败时保留原始密文
+func (s *secrets) decrypt(encryptor Encryptor, path string, v any) any {
+	switch vv := v.(type) {
+	case string:
+		if !IsSecret(vv) {
+			return vv
+		}
+
+		plaintext, err := DecryptSecret(encryptor, vv)
+		if err != nil {
+			log.Printf("decrypt configure %s failed: %v", path, err)
+			return vv
+		}
+
+		s.rw.Lock()
+		s.paths[path] = struct{}{}
+		s.rw.Unlock()
+
+		return plaintext
+	case map[string]any:
+		for k, item := range vv {
+			vv[k] = s.decrypt(encryptor, path+"."+k, item)
+		}
+	case []any:
+		for i, item := range vv {
+			vv[i] = s.decrypt(encryptor, path+"."+strconv.Itoa(i), item)
+		}
+	}
+
+	return v
+}
+
+// 加密配置中位于密文路径上的明文，返回是否有明文被加密
+func (s *secrets) encrypt(encryptor Encryptor, path string, v any) (any, bool, error) {
+	switch vv := v.(type) {
+	case string:
+		if IsSecret(vv) || !s.has(path) {
+			return vv, false, nil
+		}
+
+		ciphertext, err := EncryptSecret(encryptor, vv)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return ciphertext, true, nil
+	case map[string]any:
+		sealed := false
+		for k, item := range vv {
+			ev, ok, err := s.encrypt(encryptor, path+"."+k, item)
+			if err != nil {
+				return nil, false, err
+			}
+			vv[k], sealed = ev, sealed || ok
+		}
+		return vv, sealed, nil
+	case []any:
+		sealed := false
+		for i, item := range vv {
+			ev, ok, err := s.encrypt(encryptor, path+"."+strconv.Itoa(i), item)
+			if err != nil {
+				return nil, false, err
+			}
+			vv[i], sealed = ev, sealed || ok
+		}
+		return vv, sealed, nil
+	}
+
+	return v, false, nil
+}
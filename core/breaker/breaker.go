@@ -0,0 +1,307 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+const numBuckets = 10
+
+// State 熔断器状态
+type State int
+
+const (
+	StateClosed   State = iota // 关闭状态，正常放行请求
+	StateOpen                  // 打开状态，拒绝请求，端点被摘除
+	StateHalfOpen              // 半开状态，放行请求以探测端点是否恢复
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText 以文本形式输出状态
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Stat 熔断器统计信息
+type Stat struct {
+	Addr      string    `json:"addr"`               // 端点地址
+	State     State     `json:"state"`              // 当前状态
+	Requests  int64     `json:"requests"`           // 统计窗口内的请求数
+	Failures  int64     `json:"failures"`           // 统计窗口内的失败数
+	SlowCalls int64     `json:"slowCalls"`          // 统计窗口内的慢调用数
+	Ejections int       `json:"ejections"`          // 连续熔断次数
+	OpenedAt  time.Time `json:"openedAt,omitempty"` // 最近一次熔断时间
+}
+
+type bucket struct {
+	index     int64
+	requests  int64
+	failures  int64
+	slowCalls int64
+}
+
+// Breaker 端点熔断器
+type Breaker struct {
+	addr      string
+	opts      *options
+	notify    func(addr string, state State)
+	mu        sync.Mutex
+	state     State
+	buckets   [numBuckets]bucket
+	failures  int // 连续失败次数
+	successes int // 半开状态下的连续成功次数
+	probes    int // 半开状态下已放行且尚未完成的探测请求数
+	ejections int // 连续熔断次数
+	openedAt  time.Time
+	timer     *time.Timer
+}
+
+func newBreaker(addr string, opts *options, notify func(addr string, state State)) *Breaker {
+	return &Breaker{addr: addr, opts: opts, notify: notify}
+}
+
+// Addr 获取端点地址
+func (b *Breaker) Addr() string {
+	return b.addr
+}
+
+// State 获取熔断器状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// Allow 检测是否允许请求
+// 半开状态下最多同时放行配置数量的探测请求，放行的请求须调用Done记录结果以释放探测名额
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if b.probes >= b.probeLimit() {
+			return false
+		}
+
+		b.probes++
+	}
+
+	return true
+}
+
+// 检测是否可以接收请求，不占用探测名额
+func (b *Breaker) ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		return b.probes < b.probeLimit()
+	default:
+		return true
+	}
+}
+
+// Done 记录请求结果
+func (b *Breaker) Done(err error, latency time.Duration) {
+	failed := b.opts.isFailure(err)
+	slow := b.opts.slowCallDuration > 0 && latency >= b.opts.slowCallDuration
+
+	b.mu.Lock()
+
+	var changed bool
+
+	switch b.state {
+	case StateClosed:
+		bk := b.bucket(time.Now())
+		bk.requests++
+
+		if failed {
+			bk.failures++
+			b.failures++
+		} else {
+			b.failures = 0
+		}
+
+		if slow {
+			bk.slowCalls++
+		}
+
+		if b.shouldTrip() {
+			b.open()
+			changed = true
+		}
+	case StateHalfOpen:
+		if b.probes > 0 {
+			b.probes--
+		}
+
+		if failed || slow {
+			b.open()
+			changed = true
+		} else if b.successes++; b.successes >= b.opts.halfOpenRequests {
+			b.close()
+			changed = true
+		}
+	}
+
+	state := b.state
+
+	b.mu.Unlock()
+
+	if changed && b.notify != nil {
+		b.notify(b.addr, state)
+	}
+}
+
+// Stat 获取统计信息
+func (b *Breaker) Stat() *Stat {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stat := &Stat{Addr: b.addr, State: b.state, Ejections: b.ejections, OpenedAt: b.openedAt}
+	stat.Requests, stat.Failures, stat.SlowCalls = b.sum(time.Now())
+
+	return stat
+}
+
+// 检测是否需要熔断
+func (b *Breaker) shouldTrip() bool {
+	if b.opts.consecutiveFailures > 0 && b.failures >= b.opts.consecutiveFailures {
+		return true
+	}
+
+	requests, failures, slowCalls := b.sum(time.Now())
+	if requests == 0 || requests < int64(b.opts.minRequests) {
+		return false
+	}
+
+	if b.opts.errorRate > 0 && float64(failures)/float64(requests) >= b.opts.errorRate {
+		return true
+	}
+
+	return b.opts.slowCallRate > 0 && float64(slowCalls)/float64(requests) >= b.opts.slowCallRate
+}
+
+// 打开熔断器，恢复等待时间随连续熔断次数倍增
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.openedAt = time.Now()
+	b.successes = 0
+	b.probes = 0
+	b.ejections++
+
+	timeout := b.opts.openTimeout
+	for i := 1; i < b.ejections && (b.opts.maxOpenTimeout <= 0 || timeout < b.opts.maxOpenTimeout); i++ {
+		timeout *= 2
+	}
+
+	if b.opts.maxOpenTimeout > 0 && timeout > b.opts.maxOpenTimeout {
+		timeout = b.opts.maxOpenTimeout
+	}
+
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+
+	b.timer = time.AfterFunc(timeout, b.halfOpen)
+}
+
+// 半开熔断器
+func (b *Breaker) halfOpen() {
+	b.mu.Lock()
+
+	if b.state != StateOpen {
+		b.mu.Unlock()
+		return
+	}
+
+	b.state = StateHalfOpen
+	b.successes = 0
+	b.probes = 0
+
+	b.mu.Unlock()
+
+	if b.notify != nil {
+		b.notify(b.addr, StateHalfOpen)
+	}
+}
+
+// 关闭熔断器
+func (b *Breaker) close() {
+	b.state = StateClosed
+	b.failures = 0
+	b.successes = 0
+	b.probes = 0
+	b.ejections = 0
+	b.buckets = [numBuckets]bucket{}
+}
+
+// 停止熔断器
+func (b *Breaker) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+}
+
+// 获取半开状态下同时放行的探测请求数，至少放行一个
+func (b *Breaker) probeLimit() int {
+	return max(b.opts.halfOpenRequests, 1)
+}
+
+// 获取当前时间所在的统计桶
+func (b *Breaker) bucket(now time.Time) *bucket {
+	index := now.UnixNano() / int64(b.bucketDuration())
+	bk := &b.buckets[index%numBuckets]
+
+	if bk.index != index {
+		*bk = bucket{index: index}
+	}
+
+	return bk
+}
+
+// 汇总统计窗口内的数据
+func (b *Breaker) sum(now time.Time) (requests, failures, slowCalls int64) {
+	index := now.UnixNano() / int64(b.bucketDuration())
+
+	for i := range b.buckets {
+		if bk := &b.buckets[i]; bk.index > index-numBuckets {
+			requests += bk.requests
+			failures += bk.failures
+			slowCalls += bk.slowCalls
+		}
+	}
+
+	return
+}
+
+// 获取统计桶时长
+func (b *Breaker) bucketDuration() time.Duration {
+	if d := b.opts.window / numBuckets; d > 0 {
+		return d
+	}
+
+	return defaultWindow / numBuckets
+}
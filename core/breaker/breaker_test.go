@@ -0,0 +1,154 @@
+package breaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/core/breaker"
+)
+
+func TestGroup(t *testing.T) {
+	g := breaker.NewGroup("test",
+		breaker.WithConsecutiveFailures(3),
+		breaker.WithOpenTimeout(50*time.Millisecond, time.Second),
+		breaker.WithHalfOpenRequests(2),
+		breaker.WithMaxEjectionPercent(50),
+	)
+	defer g.Close()
+
+	states := make(chan breaker.State, 10)
+	g.OnStateChange(func(addr string, state breaker.State) { states <- state })
+
+	addrs := []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"}
+
+	for i := 0; i < 3; i++ {
+		g.Done(addrs[0], errors.New("timeout"), time.Millisecond)
+		g.Done(addrs[1], errors.New("timeout"), time.Millisecond)
+	}
+
+	if g.Allow(addrs[0]) || g.Allow(addrs[1]) {
+		t.Fatal("breaker should be open")
+	}
+
+	if available := g.Available(addrs); len(available) != 2 || available[0] != addrs[1] {
+		t.Fatalf("unexpected available endpoints: %v", available)
+	}
+
+	<-states
+	<-states
+
+	if state := <-states; state != breaker.StateHalfOpen {
+		t.Fatalf("unexpected state: %v", state)
+	}
+
+	<-states
+
+	g.Done(addrs[0], nil, time.Millisecond)
+	g.Done(addrs[0], nil, time.Millisecond)
+
+	if state := <-states; state != breaker.StateClosed {
+		t.Fatalf("unexpected state: %v", state)
+	}
+
+	for _, stat := range g.Stats() {
+		t.Logf("%s %s %d", stat.Addr, stat.State, stat.Ejections)
+	}
+}
+
+func TestGroup_HalfOpenProbes(t *testing.T) {
+	g := breaker.NewGroup("test",
+		breaker.WithConsecutiveFailures(1),
+		breaker.WithOpenTimeout(20*time.Millisecond, time.Second),
+		breaker.WithHalfOpenRequests(2),
+	)
+	defer g.Close()
+
+	states := make(chan breaker.State, 10)
+	g.OnStateChange(func(addr string, state breaker.State) { states <- state })
+
+	addr := "127.0.0.1:1"
+
+	g.Done(addr, errors.New("timeout"), time.Millisecond)
+
+	<-states
+
+	if state := <-states; state != breaker.StateHalfOpen {
+		t.Fatalf("unexpected state: %v", state)
+	}
+
+	// 半开状态下最多同时放行两个探测请求
+	if !g.Allow(addr) || !g.Allow(addr) || g.Allow(addr) {
+		t.Fatal("half-open breaker should only allow 2 probes")
+	}
+
+	if available := g.Available([]string{addr, "127.0.0.1:2"}); len(available) != 1 {
+		t.Fatalf("unexpected available endpoints: %v", available)
+	}
+
+	// 探测请求完成后释放名额
+	g.Done(addr, nil, time.Millisecond)
+
+	if !g.Allow(addr) || g.Allow(addr) {
+		t.Fatal("completed probe should release its slot")
+	}
+
+	g.Done(addr, nil, time.Millisecond)
+
+	if state := <-states; state != breaker.StateClosed {
+		t.Fatalf("unexpected state: %v", state)
+	}
+
+	for i := 0; i < 10; i++ {
+		if !g.Allow(addr) {
+			t.Fatal("closed breaker should allow all requests")
+		}
+	}
+}
+
+func TestGroup_AvailableKeepsOne(t *testing.T) {
+	g := breaker.NewGroup("test", breaker.WithConsecutiveFailures(1), breaker.WithMaxEjectionPercent(100))
+	defer g.Close()
+
+	addrs := []string{"127.0.0.1:1", "127.0.0.1:2"}
+
+	g.Done(addrs[0], errors.New("timeout"), time.Millisecond)
+
+	if available := g.Available(addrs[:1]); len(available) != 1 || available[0] != addrs[0] {
+		t.Fatalf("the only endpoint should not be ejected: %v", available)
+	}
+
+	g.Done(addrs[1], errors.New("timeout"), time.Millisecond)
+
+	if available := g.Available(addrs); len(available) != 1 {
+		t.Fatalf("at least one endpoint should be kept: %v", available)
+	}
+}
+
+func TestNewGroup_SameName(t *testing.T) {
+	g1 := breaker.NewGroup("scoped")
+	g2 := breaker.NewGroup("scoped")
+
+	if g1.Name() != "scoped" || g2.Name() != "scoped#2" {
+		t.Fatalf("unexpected names: %s, %s", g1.Name(), g2.Name())
+	}
+
+	names := func() map[string]bool {
+		names := make(map[string]bool)
+		for _, g := range breaker.Groups() {
+			names[g.Name()] = true
+		}
+		return names
+	}
+
+	if n := names(); !n["scoped"] || !n["scoped#2"] {
+		t.Fatalf("both groups should be registered: %v", n)
+	}
+
+	g1.Close()
+	g2.Close()
+
+	if n := names(); n["scoped"] || n["scoped#2"] {
+		t.Fatalf("closed groups should be unregistered: %v", n)
+	}
+}
@@ -0,0 +1,198 @@
+package breaker
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	grw    sync.RWMutex
+	groups = make(map[string]*Group)
+)
+
+// Group 熔断器组，为每个端点维护独立的熔断器
+type Group struct {
+	name      string
+	opts      *options
+	rw        sync.RWMutex
+	breakers  map[string]*Breaker
+	lrw       sync.RWMutex
+	listeners []func(addr string, state State)
+}
+
+// NewGroup 新建熔断器组
+// 每个熔断器组独立注册，同名的熔断器组已存在时以追加序号的名称注册，如grpc、grpc#2
+func NewGroup(name string, opts ...Option) *Group {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	g := &Group{opts: o, breakers: make(map[string]*Breaker)}
+
+	grw.Lock()
+	g.name = name
+	for i := 2; groups[g.name] != nil; i++ {
+		g.name = name + "#" + strconv.Itoa(i)
+	}
+	groups[g.name] = g
+	grw.Unlock()
+
+	return g
+}
+
+// Groups 获取所有熔断器组，供管理接口查询熔断状态
+func Groups() []*Group {
+	grw.RLock()
+	defer grw.RUnlock()
+
+	list := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, g)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+
+	return list
+}
+
+// Name 获取熔断器组名称
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Get 获取端点熔断器，不存在时自动创建
+func (g *Group) Get(addr string) *Breaker {
+	g.rw.RLock()
+	b, ok := g.breakers[addr]
+	g.rw.RUnlock()
+
+	if ok {
+		return b
+	}
+
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	if b, ok = g.breakers[addr]; !ok {
+		b = newBreaker(addr, g.opts, g.dispatch)
+		g.breakers[addr] = b
+	}
+
+	return b
+}
+
+// Close 关闭熔断器组，停止所有熔断器并取消注册
+func (g *Group) Close() {
+	grw.Lock()
+	if groups[g.name] == g {
+		delete(groups, g.name)
+	}
+	grw.Unlock()
+
+	g.Retain(nil)
+}
+
+// Allow 检测端点是否允许请求，半开状态下放行的请求将占用探测名额，须调用Done记录结果
+func (g *Group) Allow(addr string) bool {
+	g.rw.RLock()
+	b, ok := g.breakers[addr]
+	g.rw.RUnlock()
+
+	return !ok || b.Allow()
+}
+
+// Done 记录端点请求结果
+func (g *Group) Done(addr string, err error, latency time.Duration) {
+	g.Get(addr).Done(err, latency)
+}
+
+// Available 过滤出可用端点，实现离群摘除
+// 被熔断及半开状态下探测名额已满的端点将被摘除，但摘除数量不超过最大摘除百分比，至少允许摘除一个端点且至少保留一个端点
+func (g *Group) Available(addrs []string) []string {
+	ejected := make([]*Breaker, 0)
+
+	g.rw.RLock()
+	for _, addr := range addrs {
+		if b, ok := g.breakers[addr]; ok && !b.ready() {
+			ejected = append(ejected, b)
+		}
+	}
+	g.rw.RUnlock()
+
+	limit := min(max(len(addrs)*g.opts.maxEjectionPercent/100, 1), len(addrs)-1)
+	if len(ejected) == 0 || limit <= 0 {
+		return addrs
+	}
+
+	if len(ejected) > limit {
+		sort.Slice(ejected, func(i, j int) bool { return ejected[i].Stat().OpenedAt.Before(ejected[j].Stat().OpenedAt) })
+		ejected = ejected[:limit]
+	}
+
+	excludes := make(map[string]struct{}, len(ejected))
+	for _, b := range ejected {
+		excludes[b.addr] = struct{}{}
+	}
+
+	list := make([]string, 0, len(addrs)-len(ejected))
+	for _, addr := range addrs {
+		if _, ok := excludes[addr]; !ok {
+			list = append(list, addr)
+		}
+	}
+
+	return list
+}
+
+// Retain 仅保留指定端点的熔断器，用于清理已下线的端点
+func (g *Group) Retain(addrs []string) {
+	keeps := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		keeps[addr] = struct{}{}
+	}
+
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	for addr, b := range g.breakers {
+		if _, ok := keeps[addr]; !ok {
+			b.stop()
+			delete(g.breakers, addr)
+		}
+	}
+}
+
+// OnStateChange 监听熔断器状态变化
+func (g *Group) OnStateChange(fn func(addr string, state State)) {
+	g.lrw.Lock()
+	g.listeners = append(g.listeners, fn)
+	g.lrw.Unlock()
+}
+
+// Stats 获取所有端点的熔断统计信息
+func (g *Group) Stats() []*Stat {
+	g.rw.RLock()
+	stats := make([]*Stat, 0, len(g.breakers))
+	for _, b := range g.breakers {
+		stats = append(stats, b.Stat())
+	}
+	g.rw.RUnlock()
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Addr < stats[j].Addr })
+
+	return stats
+}
+
+// 分发状态变化
+func (g *Group) dispatch(addr string, state State) {
+	g.lrw.RLock()
+	listeners := g.listeners
+	g.lrw.RUnlock()
+
+	for _, fn := range listeners {
+		fn(addr, state)
+	}
+}
@@ -0,0 +1,110 @@
+package breaker
+
+import (
+	"time"
+
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultWindow              = 10 * time.Second // 默认统计窗口
+	defaultMinRequests         = 20               // 默认触发熔断的最小请求数
+	defaultErrorRate           = 0.5              // 默认触发熔断的错误率
+	defaultSlowCallDuration    = time.Second      // 默认慢调用耗时
+	defaultSlowCallRate        = 0.8              // 默认触发熔断的慢调用率
+	defaultConsecutiveFailures = 5                // 默认触发熔断的连续失败次数
+	defaultOpenTimeout         = 5 * time.Second  // 默认熔断恢复等待时间
+	defaultMaxOpenTimeout      = time.Minute      // 默认最大熔断恢复等待时间
+	defaultHalfOpenRequests    = 3                // 默认半开状态下放行的探测请求数及恢复所需的连续成功次数
+	defaultMaxEjectionPercent  = 50               // 默认最大摘除百分比
+)
+
+const (
+	defaultWindowKey              = "etc.transport.breaker.window"
+	defaultMinRequestsKey         = "etc.transport.breaker.minRequests"
+	defaultErrorRateKey           = "etc.transport.breaker.errorRate"
+	defaultSlowCallDurationKey    = "etc.transport.breaker.slowCallDuration"
+	defaultSlowCallRateKey        = "etc.transport.breaker.slowCallRate"
+	defaultConsecutiveFailuresKey = "etc.transport.breaker.consecutiveFailures"
+	defaultOpenTimeoutKey         = "etc.transport.breaker.openTimeout"
+	defaultMaxOpenTimeoutKey      = "etc.transport.breaker.maxOpenTimeout"
+	defaultHalfOpenRequestsKey    = "etc.transport.breaker.halfOpenRequests"
+	defaultMaxEjectionPercentKey  = "etc.transport.breaker.maxEjectionPercent"
+)
+
+type Option func(o *options)
+
+type options struct {
+	window              time.Duration    // 统计窗口
+	minRequests         int              // 统计窗口内触发熔断的最小请求数
+	errorRate           float64          // 触发熔断的错误率
+	slowCallDuration    time.Duration    // 慢调用耗时，小于等于0时不统计慢调用
+	slowCallRate        float64          // 触发熔断的慢调用率
+	consecutiveFailures int              // 触发熔断的连续失败次数，小于等于0时不检测
+	openTimeout         time.Duration    // 熔断恢复等待时间，连续熔断时按倍数增长
+	maxOpenTimeout      time.Duration    // 最大熔断恢复等待时间
+	halfOpenRequests    int              // 半开状态下同时放行的探测请求数及恢复所需的连续成功次数
+	maxEjectionPercent  int              // 同一服务下最大摘除百分比
+	isFailure           func(error) bool // 失败判定函数
+}
+
+func defaultOptions() *options {
+	return &options{
+		window:              etc.Get(defaultWindowKey, defaultWindow).Duration(),
+		minRequests:         etc.Get(defaultMinRequestsKey, defaultMinRequests).Int(),
+		errorRate:           etc.Get(defaultErrorRateKey, defaultErrorRate).Float64(),
+		slowCallDuration:    etc.Get(defaultSlowCallDurationKey, defaultSlowCallDuration).Duration(),
+		slowCallRate:        etc.Get(defaultSlowCallRateKey, defaultSlowCallRate).Float64(),
+		consecutiveFailures: etc.Get(defaultConsecutiveFailuresKey, defaultConsecutiveFailures).Int(),
+		openTimeout:         etc.Get(defaultOpenTimeoutKey, defaultOpenTimeout).Duration(),
+		maxOpenTimeout:      etc.Get(defaultMaxOpenTimeoutKey, defaultMaxOpenTimeout).Duration(),
+		halfOpenRequests:    etc.Get(defaultHalfOpenRequestsKey, defaultHalfOpenRequests).Int(),
+		maxEjectionPercent:  etc.Get(defaultMaxEjectionPercentKey, defaultMaxEjectionPercent).Int(),
+		isFailure:           func(err error) bool { return err != nil },
+	}
+}
+
+// WithWindow 设置统计窗口
+func WithWindow(window time.Duration) Option {
+	return func(o *options) { o.window = window }
+}
+
+// WithMinRequests 设置统计窗口内触发熔断的最小请求数
+func WithMinRequests(minRequests int) Option {
+	return func(o *options) { o.minRequests = minRequests }
+}
+
+// WithErrorRate 设置触发熔断的错误率
+func WithErrorRate(rate float64) Option {
+	return func(o *options) { o.errorRate = rate }
+}
+
+// WithSlowCall 设置慢调用耗时及触发熔断的慢调用率
+func WithSlowCall(duration time.Duration, rate float64) Option {
+	return func(o *options) { o.slowCallDuration, o.slowCallRate = duration, rate }
+}
+
+// WithConsecutiveFailures 设置触发熔断的连续失败次数
+func WithConsecutiveFailures(n int) Option {
+	return func(o *options) { o.consecutiveFailures = n }
+}
+
+// WithOpenTimeout 设置熔断恢复等待时间及最大熔断恢复等待时间
+func WithOpenTimeout(timeout, maxTimeout time.Duration) Option {
+	return func(o *options) { o.openTimeout, o.maxOpenTimeout = timeout, maxTimeout }
+}
+
+// WithHalfOpenRequests 设置半开状态下同时放行的探测请求数及恢复所需的连续成功次数
+func WithHalfOpenRequests(n int) Option {
+	return func(o *options) { o.halfOpenRequests = n }
+}
+
+// WithMaxEjectionPercent 设置同一服务下最大摘除百分比
+func WithMaxEjectionPercent(percent int) Option {
+	return func(o *options) { o.maxEjectionPercent = percent }
+}
+
+// WithFailurePredicate 设置失败判定函数，默认所有错误均视为失败
+func WithFailurePredicate(fn func(err error) bool) Option {
+	return func(o *options) { o.isFailure = fn }
+}
@@ -0,0 +1,298 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devagame/due/v2/errors"
+)
+
+const defaultHeaderTimeout = 5 * time.Second // 默认读取代理头超时时间
+
+const (
+	v1MaxLength = 107 // v1版本代理头最大长度
+	v2HeadSize  = 16  // v2版本代理头固定部分长度
+)
+
+var (
+	v1Signature = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// Listener 支持PROXY protocol的监听器
+// 接收到的连接在首次读取数据或获取地址时解析代理头，不阻塞Accept
+type Listener struct {
+	net.Listener
+	trusted Trusted
+	timeout time.Duration
+}
+
+// NewListener 新建监听器
+// trusted为可信代理网段，仅解析来自可信代理的代理头，为空时不信任任何来源；timeout为读取代理头超时时间
+func NewListener(ln net.Listener, trusted Trusted, timeout ...time.Duration) *Listener {
+	l := &Listener{Listener: ln, trusted: trusted, timeout: defaultHeaderTimeout}
+
+	if len(timeout) > 0 && timeout[0] > 0 {
+		l.timeout = timeout[0]
+	}
+
+	return l
+}
+
+// Accept 接收连接
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{
+		Conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: l.timeout,
+		trusted: l.trusted.Contains(addrIP(conn.RemoteAddr())),
+	}, nil
+}
+
+// Conn 支持PROXY protocol的连接
+type Conn struct {
+	net.Conn
+	reader  *bufio.Reader
+	once    sync.Once
+	timeout time.Duration
+	trusted bool     // 是否来自可信代理
+	srcAddr net.Addr // 客户端地址
+	dstAddr net.Addr // 代理接收连接的地址
+	err     error
+}
+
+// Read 读取数据
+func (c *Conn) Read(b []byte) (int, error) {
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+
+	return c.reader.Read(b)
+}
+
+// RemoteAddr 获取客户端地址，未携带代理头时为对端地址
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.init() == nil && c.srcAddr != nil {
+		return c.srcAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr 获取本地地址，携带代理头时为代理接收连接的地址
+func (c *Conn) LocalAddr() net.Addr {
+	if c.init() == nil && c.dstAddr != nil {
+		return c.dstAddr
+	}
+
+	return c.Conn.LocalAddr()
+}
+
+// ProxyAddr 获取代理地址，即连接的实际对端地址
+func (c *Conn) ProxyAddr() net.Addr {
+	return c.Conn.RemoteAddr()
+}
+
+// 解析代理头
+func (c *Conn) init() error {
+	c.once.Do(func() {
+		if !c.trusted {
+			return
+		}
+
+		if c.timeout > 0 {
+			_ = c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+			defer c.Conn.SetReadDeadline(time.Time{})
+		}
+
+		c.srcAddr, c.dstAddr, c.err = readHeader(c.reader)
+	})
+
+	return c.err
+}
+
+// ProxyAddr 获取连接的代理地址，支持解包TLS、Websocket等封装的连接，未经代理时返回对端地址
+func ProxyAddr(conn net.Conn) net.Addr {
+	for c := conn; c != nil; {
+		if pc, ok := c.(*Conn); ok {
+			return pc.ProxyAddr()
+		}
+
+		nc, ok := c.(interface{ NetConn() net.Conn })
+		if !ok {
+			break
+		}
+
+		c = nc.NetConn()
+	}
+
+	return conn.RemoteAddr()
+}
+
+// 读取代理头，未携带代理头时返回空地址
+func readHeader(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	for n := 1; n <= len(v2Signature); n++ {
+		buf, err := r.Peek(n)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		isV1 := n <= len(v1Signature) && bytes.Equal(buf, v1Signature[:n])
+		isV2 := bytes.Equal(buf, v2Signature[:n])
+
+		switch {
+		case isV1 && n == len(v1Signature):
+			return readV1Header(r)
+		case isV2 && n == len(v2Signature):
+			return readV2Header(r)
+		case !isV1 && !isV2:
+			return nil, nil, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// 读取v1版本代理头，格式为PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n
+func readV1Header(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	line := make([]byte, 0, v1MaxLength)
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line = append(line, b)
+
+		if b == '\n' {
+			break
+		}
+
+		if len(line) >= v1MaxLength {
+			return nil, nil, errors.ErrInvalidProxyHeader
+		}
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, nil, errors.ErrInvalidProxyHeader
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) < 2 {
+		return nil, nil, errors.ErrInvalidProxyHeader
+	}
+
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil, nil
+	case "TCP4", "TCP6":
+		if len(fields) != 6 {
+			return nil, nil, errors.ErrInvalidProxyHeader
+		}
+	default:
+		return nil, nil, errors.ErrInvalidProxyHeader
+	}
+
+	src, err := parseV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dst, err := parseV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return src, dst, nil
+}
+
+// 解析v1版本地址
+func parseV1Addr(host, port string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, errors.ErrInvalidProxyHeader
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, errors.ErrInvalidProxyHeader
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(p)}, nil
+}
+
+// 读取v2版本代理头
+// 格式为：签名(12) + 版本及命令(1) + 地址族及协议(1) + 地址长度(2) + 地址(n)
+func readV2Header(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	head := make([]byte, v2HeadSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, nil, err
+	}
+
+	if head[12]>>4 != 2 {
+		return nil, nil, errors.ErrInvalidProxyHeader
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(head[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+
+	switch head[12] & 0x0f {
+	case 0x00: // LOCAL命令，如代理的健康检查，保留原始地址
+		return nil, nil, nil
+	case 0x01: // PROXY命令
+	default:
+		return nil, nil, errors.ErrInvalidProxyHeader
+	}
+
+	var size int
+
+	switch head[13] >> 4 {
+	case 0x01: // AF_INET
+		size = net.IPv4len
+	case 0x02: // AF_INET6
+		size = net.IPv6len
+	default: // AF_UNSPEC、AF_UNIX，保留原始地址
+		return nil, nil, nil
+	}
+
+	if len(payload) < 2*size+4 {
+		return nil, nil, errors.ErrInvalidProxyHeader
+	}
+
+	src := &net.TCPAddr{IP: net.IP(payload[:size]), Port: int(binary.BigEndian.Uint16(payload[2*size:]))}
+	dst := &net.TCPAddr{IP: net.IP(payload[size : 2*size]), Port: int(binary.BigEndian.Uint16(payload[2*size+2:]))}
+
+	return src, dst, nil
+}
+
+// 提取地址中的IP
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+
+	return net.ParseIP(host)
+}
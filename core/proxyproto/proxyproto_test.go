@@ -0,0 +1,175 @@
+package proxyproto_test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/devagame/due/v2/core/proxyproto"
+)
+
+func dial(t *testing.T, trusted proxyproto.Trusted, header []byte) (net.Conn, []byte) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = conn.Write(append(header, "hello"...))
+	}()
+
+	conn, err := proxyproto.NewListener(ln, trusted).Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn, data
+}
+
+func v2Header(cmd byte, src, dst net.IP, sport, dport uint16) []byte {
+	header := []byte("\r\n\r\n\x00\r\nQUIT\n")
+	header = append(header, 0x20|cmd, 0x11, 0, 12)
+	header = append(header, src.To4()...)
+	header = append(header, dst.To4()...)
+	header = binary.BigEndian.AppendUint16(header, sport)
+	header = binary.BigEndian.AppendUint16(header, dport)
+	return header
+}
+
+func TestConn(t *testing.T) {
+	local := []string{"127.0.0.1"}
+	header := []byte("PROXY TCP4 1.2.3.4 10.0.0.1 5678 3553\r\n")
+
+	tests := []struct {
+		name    string
+		trusted []string
+		header  []byte
+		data    string
+		remote  string
+	}{
+		{name: "v1", trusted: local, header: header, remote: "1.2.3.4:5678"},
+		{name: "v1-ipv6", trusted: local, header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 5678 3553\r\n"), remote: "[2001:db8::1]:5678"},
+		{name: "v1-unknown", trusted: local, header: []byte("PROXY UNKNOWN\r\n")},
+		{name: "v2", trusted: local, header: v2Header(1, net.ParseIP("1.2.3.4"), net.ParseIP("10.0.0.1"), 5678, 3553), remote: "1.2.3.4:5678"},
+		{name: "v2-local", trusted: local, header: v2Header(0, net.ParseIP("1.2.3.4"), net.ParseIP("10.0.0.1"), 5678, 3553)},
+		{name: "none", trusted: local, header: nil},
+		{name: "untrusted", trusted: []string{"10.0.0.0/8"}, header: header, data: string(header) + "hello"},
+		{name: "empty-trusted", header: header, data: string(header) + "hello"},
+	}
+
+	for _, tt := range tests {
+		trusted, err := proxyproto.ParseTrusted(tt.trusted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, data := dial(t, trusted, tt.header)
+
+		if tt.data == "" {
+			tt.data = "hello"
+		}
+
+		if string(data) != tt.data {
+			t.Fatalf("%s: data = %q, want %q", tt.name, data, tt.data)
+		}
+
+		remote := tt.remote
+		if remote == "" {
+			remote = proxyproto.ProxyAddr(conn).String()
+		}
+
+		if conn.RemoteAddr().String() != remote {
+			t.Fatalf("%s: remote addr = %s, want %s", tt.name, conn.RemoteAddr(), remote)
+		}
+
+		if host, _, _ := net.SplitHostPort(proxyproto.ProxyAddr(conn).String()); host != "127.0.0.1" {
+			t.Fatalf("%s: proxy addr = %s", tt.name, proxyproto.ProxyAddr(conn))
+		}
+
+		_ = conn.Close()
+	}
+}
+
+func TestConn_InvalidHeader(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = conn.Write([]byte("PROXY TCP4 invalid\r\n"))
+	}()
+
+	trusted, err := proxyproto.ParseTrusted([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := proxyproto.NewListener(ln, trusted).Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Read(make([]byte, 8)); err == nil {
+		t.Fatal("read with invalid header should fail")
+	}
+}
+
+func TestForwardedAddr(t *testing.T) {
+	trusted, err := proxyproto.ParseTrusted([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remote string
+		header http.Header
+		expect string
+	}{
+		{remote: "10.0.0.1:80", header: http.Header{"X-Forwarded-For": {"1.1.1.1, 2.2.2.2, 10.0.0.2"}}, expect: "2.2.2.2"},
+		{remote: "192.168.1.1:80", header: http.Header{"X-Forwarded-For": {"1.1.1.1"}}, expect: "1.1.1.1"},
+		{remote: "10.0.0.1:80", header: http.Header{"X-Real-Ip": {"3.3.3.3"}}, expect: "3.3.3.3"},
+		{remote: "8.8.8.8:80", header: http.Header{"X-Forwarded-For": {"1.1.1.1"}}, expect: "8.8.8.8"},
+		{remote: "10.0.0.1:80", header: http.Header{}, expect: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		remote, _ := net.ResolveTCPAddr("tcp", tt.remote)
+		addr := proxyproto.ForwardedAddr(tt.header, remote, trusted)
+
+		if ip := addr.(*net.TCPAddr).IP.String(); ip != tt.expect {
+			t.Fatalf("%s %v: ip = %s, want %s", tt.remote, tt.header, ip, tt.expect)
+		}
+	}
+
+	remote, _ := net.ResolveTCPAddr("tcp", "10.0.0.1:80")
+	if addr := proxyproto.ForwardedAddr(http.Header{"X-Forwarded-For": {"1.1.1.1"}}, remote, nil); addr != remote {
+		t.Fatalf("empty trusted: addr = %s, want %s", addr, remote)
+	}
+
+	if _, err = proxyproto.ParseTrusted([]string{"invalid"}); err == nil {
+		t.Fatal("parse invalid cidr should fail")
+	}
+}
@@ -0,0 +1,92 @@
+package proxyproto
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/devagame/due/v2/errors"
+)
+
+// Trusted 可信代理网段，为空时不信任任何来源
+type Trusted []*net.IPNet
+
+// ParseTrusted 解析可信代理网段，支持CIDR（如10.0.0.0/8）及单个IP地址
+func ParseTrusted(cidrs []string) (Trusted, error) {
+	trusted := make(Trusted, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, errors.ErrInvalidProxyCIDR
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.ErrInvalidProxyCIDR
+		}
+
+		trusted = append(trusted, ipNet)
+	}
+
+	return trusted, nil
+}
+
+// Contains 检测IP是否可信
+func (t Trusted) Contains(ip net.IP) bool {
+	if len(t) == 0 || ip == nil {
+		return false
+	}
+
+	for _, ipNet := range t {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ForwardedAddr 根据X-Forwarded-For、X-Real-IP请求头获取客户端地址
+// 仅在配置了可信代理且对端为可信代理时解析请求头，X-Forwarded-For自右向左跳过可信代理，取第一个不可信的地址作为客户端地址
+func ForwardedAddr(header http.Header, remote net.Addr, trusted Trusted) net.Addr {
+	if !trusted.Contains(addrIP(remote)) {
+		return remote
+	}
+
+	if values := header.Values("X-Forwarded-For"); len(values) > 0 {
+		ips := strings.Split(strings.Join(values, ","), ",")
+
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(ips[i]))
+			if ip == nil {
+				break
+			}
+
+			if i == 0 || !trusted.Contains(ip) {
+				return &net.TCPAddr{IP: ip}
+			}
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(header.Get("X-Real-IP"))); ip != nil {
+		return &net.TCPAddr{IP: ip}
+	}
+
+	return remote
+}
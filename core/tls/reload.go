@@ -0,0 +1,178 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/devagame/due/v2/errors"
+)
+
+const defaultReloadInterval = 10 * time.Second // 默认证书文件检测间隔
+
+// Reloader 可热更新的证书加载器
+// 建立TLS连接时按检测间隔检查证书文件的修改时间，文件发生变化时自动重新加载，无需重启服务即可完成证书轮换
+type Reloader struct {
+	certFile  string
+	keyFile   string
+	caFile    string
+	interval  time.Duration
+	rw        sync.RWMutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewReloader 新建证书加载器
+// certFile与keyFile为本端证书及秘钥，caFile为用于校验对端证书的CA证书，interval为证书文件检测间隔
+func NewReloader(certFile, keyFile, caFile string, interval ...time.Duration) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile, interval: defaultReloadInterval}
+
+	if len(interval) > 0 && interval[0] > 0 {
+		r.interval = interval[0]
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload 重新加载证书
+func (r *Reloader) Reload() error {
+	modTime, err := r.lastModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	var pool *x509.CertPool
+
+	if r.caFile != "" {
+		caCert, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+
+		pool = x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(caCert) {
+			return errors.ErrInvalidCertFile
+		}
+	}
+
+	r.rw.Lock()
+	r.cert, r.pool, r.modTime, r.checkedAt = &cert, pool, modTime, time.Now()
+	r.rw.Unlock()
+
+	return nil
+}
+
+// ServerConfig 生成服务端TLS配置，设置了CA证书时将强制校验客户端证书（mTLS）
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.load()
+
+			cfg := &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{*cert}}
+
+			if pool != nil {
+				cfg.ClientCAs = pool
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+
+			return cfg, nil
+		},
+	}
+}
+
+// ClientConfig 生成客户端TLS配置
+// serverName为空时仅校验服务端证书链，不校验服务端主机名，适用于以IP地址互联的内部服务
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: true, // 由VerifyConnection以最新的CA证书校验服务端证书
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.load()
+			return cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			_, pool := r.load()
+
+			if len(cs.PeerCertificates) == 0 {
+				return errors.ErrInvalidCertFile
+			}
+
+			opts := x509.VerifyOptions{
+				Roots:         pool,
+				DNSName:       serverName,
+				Intermediates: x509.NewCertPool(),
+			}
+
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+
+			_, err := cs.PeerCertificates[0].Verify(opts)
+
+			return err
+		},
+	}
+}
+
+// 获取证书，到达检测间隔时检测证书文件是否发生变化
+func (r *Reloader) load() (*tls.Certificate, *x509.CertPool) {
+	r.rw.RLock()
+	cert, pool, modTime, checkedAt := r.cert, r.pool, r.modTime, r.checkedAt
+	r.rw.RUnlock()
+
+	if time.Since(checkedAt) < r.interval {
+		return cert, pool
+	}
+
+	r.rw.Lock()
+	r.checkedAt = time.Now()
+	r.rw.Unlock()
+
+	if t, err := r.lastModTime(); err == nil && t.After(modTime) {
+		if err = r.Reload(); err == nil {
+			r.rw.RLock()
+			cert, pool = r.cert, r.pool
+			r.rw.RUnlock()
+		}
+	}
+
+	return cert, pool
+}
+
+// 获取证书文件的最后修改时间
+func (r *Reloader) lastModTime() (time.Time, error) {
+	var modTime time.Time
+
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTime, err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+
+	return modTime, nil
+}
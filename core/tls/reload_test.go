@@ -0,0 +1,172 @@
+package tls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	xtls "github.com/devagame/due/v2/core/tls"
+)
+
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newAuthority(t *testing.T, dir string) *authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "due-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", der)
+
+	return &authority{cert: cert, key: key}
+}
+
+func (a *authority) issue(t *testing.T, dir, name string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+	writePEM(t, filepath.Join(dir, name+".key"), "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func handshake(server, client *tls.Config) (*x509.Certificate, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	defer ln.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer conn.Close()
+
+		errs <- tls.Server(conn, server).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), client)
+	if err != nil {
+		<-errs
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = <-errs; err != nil {
+		return nil, err
+	}
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, dir)
+	ca.issue(t, dir, "server", 2)
+	ca.issue(t, dir, "client", 3)
+
+	caFile := filepath.Join(dir, "ca.pem")
+
+	server, err := xtls.NewReloader(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), caFile, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := xtls.NewReloader(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key"), caFile, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peer, err := handshake(server.ServerConfig(), client.ClientConfig("server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if peer.SerialNumber.Int64() != 2 {
+		t.Fatalf("unexpected server certificate serial: %v", peer.SerialNumber)
+	}
+
+	// 未携带客户端证书时拒绝连接
+	if _, err = handshake(server.ServerConfig(), &tls.Config{InsecureSkipVerify: true}); err == nil {
+		t.Fatal("handshake without client certificate should fail")
+	}
+
+	// 服务端主机名不匹配时拒绝连接
+	if _, err = handshake(server.ServerConfig(), client.ClientConfig("other")); err == nil {
+		t.Fatal("handshake with mismatched server name should fail")
+	}
+
+	// 轮换服务端证书
+	time.Sleep(10 * time.Millisecond)
+	ca.issue(t, dir, "server", 4)
+	future := time.Now().Add(time.Second)
+	_ = os.Chtimes(filepath.Join(dir, "server.pem"), future, future)
+
+	if peer, err = handshake(server.ServerConfig(), client.ClientConfig("server")); err != nil {
+		t.Fatal(err)
+	}
+
+	if peer.SerialNumber.Int64() != 4 {
+		t.Fatalf("certificate not reloaded, serial: %v", peer.SerialNumber)
+	}
+}
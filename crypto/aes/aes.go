@@ -0,0 +1,3 @@
+package aes
+
+const Name = "aes"
@@ -0,0 +1,32 @@
+package aes_test
+
+import (
+	"testing"
+
+	"github.com/devagame/due/crypto/aes/v2"
+	"github.com/devagame/due/v2/utils/xrand"
+)
+
+func Test_Encrypt_Decrypt(t *testing.T) {
+	encryptor := aes.NewEncryptor(aes.WithEncryptorKey("0123456789abcdef0123456789abcdef"))
+
+	str := xrand.Letters(2000)
+
+	ciphertext, err := encryptor.Encrypt([]byte(str))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := encryptor.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != str {
+		t.Fatal("decrypt data is not equal to the original data")
+	}
+
+	if _, err = aes.NewEncryptor(aes.WithEncryptorKey("short")).Encrypt(data); err == nil {
+		t.Fatal("expect invalid key error")
+	}
+}
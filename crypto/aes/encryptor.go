@@ -0,0 +1,80 @@
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"github.com/devagame/due/v2/errors"
+)
+
+type Encryptor struct {
+	err  error
+	opts *encryptorOptions
+	aead cipher.AEAD
+}
+
+func NewEncryptor(opts ...EncryptorOption) *Encryptor {
+	o := defaultEncryptorOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	e := &Encryptor{opts: o}
+	e.init()
+
+	return e
+}
+
+// Name 名称
+func (e *Encryptor) Name() string {
+	return Name
+}
+
+// Encrypt 加密
+// 采用AES-GCM模式，密文由随机nonce与加密数据拼接而成
+func (e *Encryptor) Encrypt(data []byte) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(data)+e.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return e.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt 解密
+func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	if len(ciphertext) < e.aead.NonceSize()+e.aead.Overhead() {
+		return nil, errors.ErrInvalidCiphertext
+	}
+
+	nonce, data := ciphertext[:e.aead.NonceSize()], ciphertext[e.aead.NonceSize():]
+
+	return e.aead.Open(nil, nonce, data, nil)
+}
+
+func (e *Encryptor) init() {
+	switch len(e.opts.key) {
+	case 16, 24, 32:
+	default:
+		e.err = errors.ErrInvalidCipherKey
+		return
+	}
+
+	block, err := aes.NewCipher([]byte(e.opts.key))
+	if err != nil {
+		e.err = err
+		return
+	}
+
+	e.aead, e.err = cipher.NewGCM(block)
+}
@@ -0,0 +1,27 @@
+package aes
+
+import (
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultEncryptorKeyKey = "etc.crypto.aes.encryptor.key"
+)
+
+type EncryptorOption func(o *encryptorOptions)
+
+type encryptorOptions struct {
+	// 密钥。长度必须为16、24或32字节，分别对应AES-128、AES-192、AES-256
+	key string
+}
+
+func defaultEncryptorOptions() *encryptorOptions {
+	return &encryptorOptions{
+		key: etc.Get(defaultEncryptorKeyKey).String(),
+	}
+}
+
+// WithEncryptorKey 设置加解密密钥
+func WithEncryptorKey(key string) EncryptorOption {
+	return func(o *encryptorOptions) { o.key = key }
+}
@@ -65,3 +65,9 @@ func Invoke(name string) Codec {
 
 	return codec
 }
+
+// Lookup 查找编解码器
+func Lookup(name string) (Codec, bool) {
+	codec, ok := codecs[name]
+	return codec, ok
+}
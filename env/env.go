@@ -2,6 +2,7 @@ package env
 
 import (
 	"os"
+	"strings"
 
 	"github.com/devagame/due/v2/core/value"
 )
@@ -30,3 +31,17 @@ func Has(key string) bool {
 	_, ok := os.LookupEnv(key)
 	return ok
 }
+
+// All 获取所有环境变量
+func All() map[string]string {
+	environ := os.Environ()
+	values := make(map[string]string, len(environ))
+
+	for _, kv := range environ {
+		if key, val, ok := strings.Cut(kv, "="); ok {
+			values[key] = val
+		}
+	}
+
+	return values
+}
@@ -67,6 +67,32 @@ var (
 	ErrInvalidCertFile         = New("invalid cert file")
 	ErrMissingCacheInstance    = New("missing cache instance")
 	ErrMissingEventbusInstance = New("missing eventbus instance")
+	ErrInvalidTopicPattern     = New("invalid topic pattern")
+	ErrEventPayloadMismatch    = New("event payload mismatch")
+	ErrInvalidEncryptor        = New("invalid encryptor")
+	ErrInvalidSecret           = New("invalid secret")
+	ErrInvalidCipherKey        = New("invalid cipher key")
+	ErrInvalidCiphertext       = New("invalid ciphertext")
+	ErrNotFoundSnapshot        = New("not found snapshot")
+	ErrMissingHistory          = New("missing history")
+	ErrNotFoundService         = New("not found service")
+	ErrNotFoundMethod          = New("not found method")
+	ErrInvalidMetadata         = New("invalid metadata")
+	ErrGatewayDisabled         = New("gateway disabled")
+	ErrUnauthorized            = New("unauthorized")
+	ErrInvalidProxyHeader      = New("invalid proxy protocol header")
+	ErrInvalidProxyCIDR        = New("invalid proxy cidr")
+	ErrWriteQueueFull          = New("write queue full")
+	ErrSlowConsumer            = New("slow consumer")
+	ErrInvalidCIDR             = New("invalid cidr")
+	ErrIPDenied                = New("ip denied")
+	ErrIPNotAllowed            = New("ip not allowed")
+	ErrTooManyConnPerIP        = New("too many connection per ip")
+	ErrAcceptRateLimited       = New("accept rate limited")
+	ErrAddressInUse            = New("address already in use")
+	ErrConnectionRefused       = New("connection refused")
+	ErrConnectionReconnecting  = New("connection is reconnecting")
+	ErrNotSupported            = New("not supported")
 )
 
 // NewError 新建一个错误
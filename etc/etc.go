@@ -10,6 +10,7 @@ import (
 
 // etc主要被当做项目启动配置存在；常用于集群配置、服务组件配置等。
 // etc只能通过配置文件进行配置；并且无法通过master管理服进行修改。
+// 配置文件变更后，组件通过OnChange监听的配置会在运行时生效；通过RestartOnly标记的配置需重启后生效。
 // 如想在业务使用配置，推荐使用config配置中心进行实现。
 // config配置中心的配置信息可通过master管理服进行动态修改。
 
@@ -25,6 +26,7 @@ func init() {
 	path := env.Get(dueEtcEnvName, defaultEtcPath).String()
 	path = flag.String(dueEtcArgName, path)
 	globalConfigurator = config.NewConfigurator(config.WithSources(core.NewSource(path, config.ReadOnly)))
+	watch(globalConfigurator)
 }
 
 // SetConfigurator 设置配置器
@@ -34,6 +36,11 @@ func SetConfigurator(configurator config.Configurator) {
 	}
 
 	globalConfigurator = configurator
+
+	if configurator != nil {
+		watch(configurator)
+		dispatch()
+	}
 }
 
 // GetConfigurator 获取配置器
@@ -52,8 +59,15 @@ func Get(pattern string, def ...any) value.Value {
 }
 
 // Set 设置配置值
+// 设置成功后通过OnChange监听该配置的组件会立即生效
 func Set(pattern string, value any) error {
-	return globalConfigurator.Set(pattern, value)
+	if err := globalConfigurator.Set(pattern, value); err != nil {
+		return err
+	}
+
+	dispatch()
+
+	return nil
 }
 
 // Match 匹配多个规则
@@ -1,6 +1,7 @@
 package etc_test
 
 import (
+	"github.com/devagame/due/v2/core/value"
 	"github.com/devagame/due/v2/etc"
 	"testing"
 )
@@ -9,3 +10,29 @@ func Test_Get(t *testing.T) {
 	v := etc.Get("c.redis.addrs.1A", "192.168.0.1:3308").String()
 	t.Log(v)
 }
+
+func Test_OnChange(t *testing.T) {
+	var changed int
+
+	cancel := etc.OnChange("etc.test.maxConnNum", func(val value.Value) {
+		changed = val.Int()
+	}, 5000)
+
+	if err := etc.Set("etc.test.maxConnNum", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed != 100 {
+		t.Fatalf("expect changed to 100, got %d", changed)
+	}
+
+	cancel()
+
+	if err := etc.Set("etc.test.maxConnNum", 200); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed != 100 {
+		t.Fatalf("expect no change after cancel, got %d", changed)
+	}
+}
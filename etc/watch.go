@@ -0,0 +1,100 @@
+package etc
+
+import (
+	"log"
+	"reflect"
+	"sync"
+
+	"github.com/devagame/due/v2/config"
+	"github.com/devagame/due/v2/core/value"
+)
+
+type subscriber struct {
+	pattern string
+	def     []any
+	last    any
+	fn      func(val value.Value)
+}
+
+var (
+	mu          sync.Mutex
+	seq         int64
+	subscribers = make(map[int64]*subscriber)
+	restarts    = make(map[string]any)
+)
+
+// OnChange 监听配置值变化，配置值发生变化时以最新值回调，配置值被删除时以默认值回调
+// 适用于可在运行时安全生效的配置，如连接上限、心跳间隔、超时时间、日志级别等
+// 返回的函数用于取消监听，组件停止时应取消监听
+func OnChange(pattern string, fn func(val value.Value), def ...any) (cancel func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	seq++
+	id := seq
+	subscribers[id] = &subscriber{
+		pattern: pattern,
+		def:     def,
+		last:    globalConfigurator.Get(pattern, def...).Value(),
+		fn:      fn,
+	}
+
+	return func() {
+		mu.Lock()
+		delete(subscribers, id)
+		mu.Unlock()
+	}
+}
+
+// RestartOnly 标记仅在重启后生效的配置
+// 被标记的配置发生变化时仅输出警告，提示需要重启服务
+func RestartOnly(patterns ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, pattern := range patterns {
+		if _, ok := restarts[pattern]; !ok {
+			restarts[pattern] = globalConfigurator.Get(pattern).Value()
+		}
+	}
+}
+
+// 监听配置器变化
+func watch(c config.Configurator) {
+	c.Watch(func(names ...string) {
+		if c == globalConfigurator {
+			dispatch()
+		}
+	})
+}
+
+// 分发配置变化
+func dispatch() {
+	mu.Lock()
+
+	changes := make([]func(), 0)
+
+	for _, s := range subscribers {
+		val := globalConfigurator.Get(s.pattern, s.def...)
+		if reflect.DeepEqual(val.Value(), s.last) {
+			continue
+		}
+
+		s.last = val.Value()
+		fn := s.fn
+		changes = append(changes, func() { fn(val) })
+	}
+
+	for pattern, last := range restarts {
+		if v := globalConfigurator.Get(pattern).Value(); !reflect.DeepEqual(v, last) {
+			restarts[pattern] = v
+			log.Printf("etc %s has changed and will take effect after restart", pattern)
+		}
+	}
+
+	mu.Unlock()
+
+	for _, change := range changes {
+		change()
+	}
+}
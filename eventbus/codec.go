@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/devagame/due/v2/encoding"
+	"github.com/devagame/due/v2/encoding/json"
+	"github.com/devagame/due/v2/log"
+)
+
+var (
+	codecRW       sync.RWMutex
+	defaultCodec  encoding.Codec = json.DefaultCodec
+	topicCodecs                  = make(map[string]encoding.Codec)
+	patternCodecs []*patternCodec
+)
+
+type patternCodec struct {
+	pattern string
+	codec   encoding.Codec
+}
+
+// SetDefaultCodec 设置默认的载荷编解码器
+// 未单独设置编解码器的主题将使用默认编解码器，默认为json
+func SetDefaultCodec(codec encoding.Codec) {
+	if codec == nil {
+		log.Warn("cannot set a nil codec")
+		return
+	}
+
+	codecRW.Lock()
+	defer codecRW.Unlock()
+
+	defaultCodec = codec
+}
+
+// SetTopicCodec 设置主题的载荷编解码器
+// 主题可为通配模式，精确主题的设置优先于通配模式的设置；多个通配模式均匹配时，先设置的通配模式优先，重复设置同一通配模式不改变其优先级
+func SetTopicCodec(topic string, codec encoding.Codec) {
+	if codec == nil {
+		log.Warn("cannot set a nil codec")
+		return
+	}
+
+	codecRW.Lock()
+	defer codecRW.Unlock()
+
+	if !IsPattern(topic) {
+		topicCodecs[topic] = codec
+		return
+	}
+
+	for _, pc := range patternCodecs {
+		if pc.pattern == topic {
+			pc.codec = codec
+			return
+		}
+	}
+
+	patternCodecs = append(patternCodecs, &patternCodec{pattern: topic, codec: codec})
+}
+
+// GetTopicCodec 获取主题的载荷编解码器
+func GetTopicCodec(topic string) encoding.Codec {
+	codecRW.RLock()
+	defer codecRW.RUnlock()
+
+	if codec, ok := topicCodecs[topic]; ok {
+		return codec
+	}
+
+	for _, pc := range patternCodecs {
+		if MatchTopic(pc.pattern, topic) {
+			return pc.codec
+		}
+	}
+
+	return defaultCodec
+}
@@ -0,0 +1,116 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// 类型化处理器均由同一泛型函数生成，事件总线按处理器函数地址区分处理器时无法区分不同的类型化处理器
+// 因此每个主题或通配模式仅向事件总线订阅一个分发器，再由分发器按用户处理器的函数地址管理类型化处理器
+var (
+	dispatchersMu sync.Mutex
+	dispatchers   = make(map[dispatcherKey]*dispatcher)
+)
+
+type dispatcherKey struct {
+	topic   string // 主题或通配模式
+	pattern bool   // 是否为通配模式
+}
+
+type dispatcher struct {
+	rw       sync.RWMutex
+	handlers map[uintptr][]EventHandler
+}
+
+// 分发事件
+func (d *dispatcher) dispatch(event *Event) {
+	d.rw.RLock()
+	handlers := make([]EventHandler, 0, len(d.handlers))
+	for _, list := range d.handlers {
+		handlers = append(handlers, list...)
+	}
+	d.rw.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// 添加处理器
+func (d *dispatcher) addHandler(pointer uintptr, handler EventHandler) {
+	d.rw.Lock()
+	d.handlers[pointer] = append(d.handlers[pointer], handler)
+	d.rw.Unlock()
+}
+
+// 移除处理器
+func (d *dispatcher) delHandler(pointer uintptr) int {
+	d.rw.Lock()
+	defer d.rw.Unlock()
+
+	delete(d.handlers, pointer)
+
+	return len(d.handlers)
+}
+
+// 添加类型化订阅，首个处理器加入时向事件总线订阅分发器
+func typedSubscribe(ctx context.Context, topic string, pattern bool, handler any, fn EventHandler) error {
+	key := dispatcherKey{topic: topic, pattern: pattern}
+
+	dispatchersMu.Lock()
+	defer dispatchersMu.Unlock()
+
+	d, ok := dispatchers[key]
+	if !ok {
+		d = &dispatcher{handlers: make(map[uintptr][]EventHandler)}
+
+		var err error
+		if pattern {
+			err = PSubscribe(ctx, topic, d.dispatch)
+		} else {
+			err = Subscribe(ctx, topic, d.dispatch)
+		}
+		if err != nil {
+			return err
+		}
+
+		dispatchers[key] = d
+	}
+
+	d.addHandler(reflect.ValueOf(handler).Pointer(), fn)
+
+	return nil
+}
+
+// 移除类型化订阅，最后一个处理器移除时向事件总线取消订阅分发器
+func typedUnsubscribe(ctx context.Context, topic string, pattern bool, handler any) error {
+	key := dispatcherKey{topic: topic, pattern: pattern}
+
+	dispatchersMu.Lock()
+	defer dispatchersMu.Unlock()
+
+	d, ok := dispatchers[key]
+	if !ok {
+		return nil
+	}
+
+	if d.delHandler(reflect.ValueOf(handler).Pointer()) > 0 {
+		return nil
+	}
+
+	delete(dispatchers, key)
+
+	if pattern {
+		return PUnsubscribe(ctx, topic, d.dispatch)
+	}
+
+	return Unsubscribe(ctx, topic, d.dispatch)
+}
+
+// 重置类型化订阅，更换事件总线时旧事件总线上的订阅随之失效
+func resetDispatchers() {
+	dispatchersMu.Lock()
+	dispatchers = make(map[dispatcherKey]*dispatcher)
+	dispatchersMu.Unlock()
+}
@@ -13,6 +13,13 @@ var globalEventbus Eventbus
 type (
 	Event        = internal.Event
 	EventHandler = internal.EventHandler
+	Encoded      = internal.Encoded
+)
+
+const (
+	TopicSeparator = internal.TopicSeparator // 主题分隔符
+	SingleWildcard = internal.SingleWildcard // 单段通配符
+	MultiWildcard  = internal.MultiWildcard  // 多段通配符
 )
 
 type Eventbus interface {
@@ -24,6 +31,26 @@ type Eventbus interface {
 	Subscribe(ctx context.Context, topic string, handler EventHandler) error
 	// Unsubscribe 取消订阅
 	Unsubscribe(ctx context.Context, topic string, handler EventHandler) error
+	// PSubscribe 按通配模式订阅事件
+	PSubscribe(ctx context.Context, pattern string, handler EventHandler) error
+	// PUnsubscribe 按通配模式取消订阅
+	PUnsubscribe(ctx context.Context, pattern string, handler EventHandler) error
+}
+
+// IsPattern 检测主题是否为通配模式
+// 主题以.分段，*匹配任意一段，>匹配剩余的一段或多段
+func IsPattern(topic string) bool {
+	return internal.IsPattern(topic)
+}
+
+// ValidPattern 检测通配模式是否合法
+func ValidPattern(pattern string) bool {
+	return internal.ValidPattern(pattern)
+}
+
+// MatchTopic 检测具体主题是否匹配通配模式
+func MatchTopic(pattern, topic string) bool {
+	return internal.MatchTopic(pattern, topic)
 }
 
 // SetEventbus 设置事件总线
@@ -40,6 +67,8 @@ func SetEventbus(eb Eventbus) {
 	}
 
 	globalEventbus = eb
+
+	resetDispatchers()
 }
 
 // GetEventbus 获取事件总线
@@ -74,6 +103,24 @@ func Unsubscribe(ctx context.Context, topic string, handler EventHandler) error
 	return globalEventbus.Unsubscribe(ctx, topic, handler)
 }
 
+// PSubscribe 按通配模式订阅事件
+func PSubscribe(ctx context.Context, pattern string, handler EventHandler) error {
+	if globalEventbus == nil {
+		return errors.ErrMissingEventbusInstance
+	}
+
+	return globalEventbus.PSubscribe(ctx, pattern, handler)
+}
+
+// PUnsubscribe 按通配模式取消订阅
+func PUnsubscribe(ctx context.Context, pattern string, handler EventHandler) error {
+	if globalEventbus == nil {
+		return errors.ErrMissingEventbusInstance
+	}
+
+	return globalEventbus.PUnsubscribe(ctx, pattern, handler)
+}
+
 // Close 关闭事件总线
 func Close() error {
 	if globalEventbus == nil {
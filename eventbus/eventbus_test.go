@@ -6,6 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/devagame/due/v2/encoding/json"
+	"github.com/devagame/due/v2/encoding/msgpack"
+	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/eventbus"
 	"github.com/devagame/due/v2/eventbus/process"
 )
@@ -57,3 +60,202 @@ func TestEventbus_Subscribe(t *testing.T) {
 
 	time.Sleep(30 * time.Second)
 }
+
+func TestMatchTopic(t *testing.T) {
+	cases := []struct {
+		pattern string
+		topic   string
+		matched bool
+	}{
+		{"guild.*", "guild.create", true},
+		{"guild.*", "guild.member.join", false},
+		{"guild.>", "guild.member.join", true},
+		{"guild.>", "guild", false},
+		{"player.*.levelup", "player.1001.levelup", true},
+		{"player.*.levelup", "player.1001.logout", false},
+		{"*.levelup", "player.levelup", true},
+		{"login", "login", true},
+	}
+
+	for _, c := range cases {
+		if matched := eventbus.MatchTopic(c.pattern, c.topic); matched != c.matched {
+			t.Fatalf("pattern: %s topic: %s expect: %v got: %v", c.pattern, c.topic, c.matched, matched)
+		}
+	}
+}
+
+func TestEventbus_PSubscribe(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		events = make(chan *eventbus.Event, 1)
+	)
+
+	err := eb.PSubscribe(ctx, "player.*.levelup", func(event *eventbus.Event) {
+		events <- event
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = eb.Publish(ctx, "player.1001.levelup", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Topic != "player.1001.levelup" || event.Pattern != "player.*.levelup" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+}
+
+type levelUp struct {
+	UID   int64
+	Level int
+}
+
+func TestSubscribeTyped(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		messages = make(chan *levelUp, 1)
+		errs     = make(chan error, 1)
+	)
+
+	eventbus.SetEventbus(process.NewEventbus())
+	eventbus.SetTopicCodec("player.>", msgpack.DefaultCodec)
+	eventbus.SetErrorHandler(func(event *eventbus.Event, err error) {
+		errs <- err
+	})
+
+	err := eventbus.SubscribeTyped(ctx, "player.levelup", func(ctx context.Context, message *levelUp) {
+		messages <- message
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = eventbus.PublishTyped(ctx, "player.levelup", &levelUp{UID: 1001, Level: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case message := <-messages:
+		if message.UID != 1001 || message.Level != 10 {
+			t.Fatalf("unexpected message: %+v", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+
+	err = eventbus.PublishTyped(ctx, "player.levelup", "levelup")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err = <-errs:
+		if !errors.Is(err, errors.ErrEventPayloadMismatch) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("mismatch error not reported")
+	}
+}
+
+type ctxKey struct{}
+
+func TestUnsubscribeTyped(t *testing.T) {
+	var (
+		ctx    = context.WithValue(context.Background(), ctxKey{}, "player")
+		first  = make(chan int, 2)
+		second = make(chan int, 2)
+	)
+
+	eventbus.SetEventbus(process.NewEventbus())
+
+	handler1 := func(ctx context.Context, message int) {
+		if ctx.Value(ctxKey{}) != "player" {
+			t.Error("handler context is not derived from the subscribe context")
+		}
+		first <- message
+	}
+	handler2 := func(ctx context.Context, message int) { second <- message }
+
+	if err := eventbus.SubscribeTyped(ctx, "player.exp", handler1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := eventbus.SubscribeTyped(ctx, "player.exp", handler2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := eventbus.UnsubscribeTyped(ctx, "player.exp", handler1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := eventbus.PublishTyped(ctx, "player.exp", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case message := <-second:
+		if message != 100 {
+			t.Fatalf("unexpected message: %d", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not received by the remaining handler")
+	}
+
+	select {
+	case <-first:
+		t.Fatal("message received by the unsubscribed handler")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := eventbus.SubscribeTyped(ctx, "player.exp", handler1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := eventbus.PublishTyped(ctx, "player.exp", 200); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range []chan int{first, second} {
+		select {
+		case message := <-ch:
+			if message != 200 {
+				t.Fatalf("unexpected message: %d", message)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	}
+}
+
+func TestGetTopicCodec(t *testing.T) {
+	eventbus.SetTopicCodec("guild.>", msgpack.DefaultCodec)
+	eventbus.SetTopicCodec("guild.*", json.DefaultCodec)
+	eventbus.SetTopicCodec("guild.war", json.DefaultCodec)
+
+	// 重复设置不改变通配模式的优先级
+	eventbus.SetTopicCodec("guild.>", msgpack.DefaultCodec)
+
+	// 多个通配模式均匹配时先设置的通配模式优先
+	for i := 0; i < 100; i++ {
+		if codec := eventbus.GetTopicCodec("guild.join"); codec.Name() != msgpack.Name {
+			t.Fatalf("codec = %s, want %s", codec.Name(), msgpack.Name)
+		}
+	}
+
+	if codec := eventbus.GetTopicCodec("guild.war"); codec.Name() != json.Name {
+		t.Fatalf("codec = %s, want %s", codec.Name(), json.Name)
+	}
+
+	if codec := eventbus.GetTopicCodec("team.join"); codec.Name() != json.Name {
+		t.Fatalf("codec = %s, want %s", codec.Name(), json.Name)
+	}
+}
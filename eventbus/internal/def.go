@@ -10,7 +10,15 @@ type EventHandler func(event *Event)
 
 type Event struct {
 	ID        string      // 事件ID
-	Topic     string      // 事件主题
+	Topic     string      // 事件主题（发布时的具体主题）
+	Pattern   string      // 订阅模式（通过通配模式订阅时为匹配到的订阅模式，否则为空）
 	Payload   value.Value // 事件载荷
+	Codec     string      // 载荷编解码器（通过编解码器发布时为编解码器名称，否则为空）
 	Timestamp time.Time   // 事件时间
 }
+
+// Encoded 经编解码器编码的事件载荷
+type Encoded struct {
+	Codec string // 编解码器名称
+	Data  []byte // 编码后的数据
+}
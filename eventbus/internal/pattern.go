@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"strings"
+)
+
+const (
+	TopicSeparator = "." // 主题分隔符
+	SingleWildcard = "*" // 单段通配符，匹配任意一段
+	MultiWildcard  = ">" // 多段通配符，匹配剩余的一段或多段，只能位于模式末尾
+)
+
+// IsPattern 检测主题是否为通配模式
+func IsPattern(topic string) bool {
+	for _, segment := range strings.Split(topic, TopicSeparator) {
+		if segment == SingleWildcard || segment == MultiWildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidPattern 检测通配模式是否合法
+func ValidPattern(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+
+	segments := strings.Split(pattern, TopicSeparator)
+
+	for i, segment := range segments {
+		if segment == "" {
+			return false
+		}
+
+		if segment == MultiWildcard && i != len(segments)-1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchTopic 检测具体主题是否匹配通配模式
+func MatchTopic(pattern, topic string) bool {
+	if pattern == topic {
+		return true
+	}
+
+	patterns := strings.Split(pattern, TopicSeparator)
+	segments := strings.Split(topic, TopicSeparator)
+
+	for i, p := range patterns {
+		if p == MultiWildcard {
+			return i == len(patterns)-1 && len(segments) > i
+		}
+
+		if i >= len(segments) {
+			return false
+		}
+
+		if p != SingleWildcard && p != segments[i] {
+			return false
+		}
+	}
+
+	return len(patterns) == len(segments)
+}
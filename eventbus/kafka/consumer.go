@@ -11,6 +11,8 @@ import (
 )
 
 type consumer struct {
+	pattern  string              // 订阅模式
+	topics   map[string]struct{} // 订阅模式已匹配的主题
 	ctx      context.Context
 	cancel   context.CancelFunc
 	rw       sync.RWMutex
@@ -53,6 +55,14 @@ func (c *consumer) dispatch(data []byte) {
 		return
 	}
 
+	if c.pattern != "" {
+		if !eventbus.MatchTopic(c.pattern, event.Topic) {
+			return
+		}
+
+		event.Pattern = c.pattern
+	}
+
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
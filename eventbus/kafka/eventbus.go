@@ -2,11 +2,15 @@ package kafka
 
 import (
 	"context"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/eventbus"
+	"github.com/devagame/due/v2/log"
 )
 
 type Eventbus struct {
@@ -23,6 +27,7 @@ type Eventbus struct {
 	builtin      bool
 	rw           sync.RWMutex
 	consumers    map[string]*consumer
+	patterns     map[string]*consumer
 }
 
 func NewEventbus(opts ...Option) *Eventbus {
@@ -34,6 +39,7 @@ func NewEventbus(opts ...Option) *Eventbus {
 	eb := &Eventbus{}
 	eb.opts = o
 	eb.consumers = make(map[string]*consumer)
+	eb.patterns = make(map[string]*consumer)
 	eb.ctx, eb.cancel = context.WithCancel(o.ctx)
 
 	if o.client == nil {
@@ -174,6 +180,93 @@ func (eb *Eventbus) Unsubscribe(_ context.Context, topic string, handler eventbu
 	return nil
 }
 
+// PSubscribe 按通配模式订阅事件
+// kafka不支持原生的通配订阅，此处通过正则匹配现有主题进行订阅，并定时刷新以发现新创建的匹配主题
+func (eb *Eventbus) PSubscribe(_ context.Context, pattern string, handler eventbus.EventHandler) error {
+	if eb.err != nil {
+		return eb.err
+	}
+
+	if eb.err1 != nil {
+		return eb.err1
+	}
+
+	if !eventbus.ValidPattern(pattern) {
+		return errors.ErrInvalidTopicPattern
+	}
+
+	eb.rw.Lock()
+	c, ok := eb.patterns[pattern]
+	if !ok {
+		c = &consumer{pattern: pattern, topics: make(map[string]struct{}), handlers: make(map[uintptr][]eventbus.EventHandler)}
+		c.ctx, c.cancel = context.WithCancel(eb.ctx)
+		eb.patterns[pattern] = c
+	}
+	c.addHandler(handler)
+	eb.rw.Unlock()
+
+	if ok {
+		return nil
+	}
+
+	re := eb.doMakeRegexp(pattern)
+
+	if err := eb.refresh(c, re); err != nil {
+		// 首次刷新失败时移除通配订阅，避免失效的消费者残留导致后续订阅无法重建
+		eb.rw.Lock()
+		if eb.patterns[pattern] == c {
+			delete(eb.patterns, pattern)
+		}
+		eb.rw.Unlock()
+
+		c.cancel()
+
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(eb.opts.patternRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := eb.refresh(c, re); err != nil {
+					log.Warnf("refresh topics of pattern %s failed: %v", pattern, err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// PUnsubscribe 按通配模式取消订阅
+func (eb *Eventbus) PUnsubscribe(_ context.Context, pattern string, handler eventbus.EventHandler) error {
+	if eb.err != nil {
+		return eb.err
+	}
+
+	if eb.err1 != nil {
+		return eb.err1
+	}
+
+	eb.rw.Lock()
+	defer eb.rw.Unlock()
+
+	if c, ok := eb.patterns[pattern]; ok {
+		if c.delHandler(handler) != 0 {
+			return nil
+		}
+		c.cancel()
+		delete(eb.patterns, pattern)
+	}
+
+	return nil
+}
+
 // Close 停止监听
 func (eb *Eventbus) Close() error {
 	if eb.err != nil {
@@ -232,6 +325,50 @@ func (eb *Eventbus) watch(c *consumer, topic string) error {
 	return nil
 }
 
+// 刷新通配订阅匹配的主题
+func (eb *Eventbus) refresh(c *consumer, re *regexp.Regexp) error {
+	if err := eb.opts.client.RefreshMetadata(); err != nil {
+		return err
+	}
+
+	topics, err := eb.opts.client.Topics()
+	if err != nil {
+		return err
+	}
+
+	for _, topic := range topics {
+		if _, ok := c.topics[topic]; ok || !re.MatchString(topic) {
+			continue
+		}
+
+		if err = eb.watch(c, topic); err != nil {
+			return err
+		}
+
+		c.topics[topic] = struct{}{}
+	}
+
+	return nil
+}
+
+// 将订阅模式转换为kafka主题正则
+func (eb *Eventbus) doMakeRegexp(pattern string) *regexp.Regexp {
+	segments := strings.Split(pattern, eventbus.TopicSeparator)
+
+	for i, segment := range segments {
+		switch segment {
+		case eventbus.SingleWildcard:
+			segments[i] = `[^.]+`
+		case eventbus.MultiWildcard:
+			segments[i] = `.+`
+		default:
+			segments[i] = regexp.QuoteMeta(segment)
+		}
+	}
+
+	return regexp.MustCompile("^" + regexp.QuoteMeta(eb.doMakeChannel("")) + strings.Join(segments, `\.`) + "$")
+}
+
 func (eb *Eventbus) doMakeChannel(topic string) string {
 	if eb.opts.prefix == "" {
 		return topic
@@ -2,21 +2,24 @@ package kafka
 
 import (
 	"context"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/devagame/due/v2/etc"
 )
 
 const (
-	defaultAddr   = "127.0.0.1:9092"
-	defaultPrefix = "due:eventbus"
+	defaultAddr                   = "127.0.0.1:9092"
+	defaultPrefix                 = "due:eventbus"
+	defaultPatternRefreshInterval = 30 * time.Second
 )
 
 const (
-	defaultAddrsKey           = "etc.eventbus.kafka.addrs"
-	defaultPrefixKey          = "etc.eventbus.kafka.prefix"
-	defaultVersionKey         = "etc.eventbus.kafka.version"
-	defaultAutoCreateTopicKey = "etc.eventbus.kafka.autoCreateTopic"
+	defaultAddrsKey                  = "etc.eventbus.kafka.addrs"
+	defaultPrefixKey                 = "etc.eventbus.kafka.prefix"
+	defaultVersionKey                = "etc.eventbus.kafka.version"
+	defaultAutoCreateTopicKey        = "etc.eventbus.kafka.autoCreateTopic"
+	defaultPatternRefreshIntervalKey = "etc.eventbus.kafka.patternRefreshInterval"
 )
 
 type Option func(o *options)
@@ -42,15 +45,20 @@ type options struct {
 	// 自动创建topic
 	// 当为true时，若不存在该主题，会自动创建，默认为false
 	autoCreateTopic bool
+
+	// 通配订阅的主题刷新间隔
+	// 通配订阅时会定时拉取元数据以发现新创建的匹配主题，默认为30s
+	patternRefreshInterval time.Duration
 }
 
 func defaultOptions() *options {
 	return &options{
-		ctx:             context.Background(),
-		addrs:           etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
-		prefix:          etc.Get(defaultPrefixKey, defaultPrefix).String(),
-		version:         etc.Get(defaultVersionKey).String(),
-		autoCreateTopic: etc.Get(defaultAutoCreateTopicKey).Bool(),
+		ctx:                    context.Background(),
+		addrs:                  etc.Get(defaultAddrsKey, []string{defaultAddr}).Strings(),
+		prefix:                 etc.Get(defaultPrefixKey, defaultPrefix).String(),
+		version:                etc.Get(defaultVersionKey).String(),
+		autoCreateTopic:        etc.Get(defaultAutoCreateTopicKey).Bool(),
+		patternRefreshInterval: etc.Get(defaultPatternRefreshIntervalKey, defaultPatternRefreshInterval).Duration(),
 	}
 }
 
@@ -83,3 +91,8 @@ func WithClient(client sarama.Client) Option {
 func WithAutoCreateTopic(autoCreateTopic bool) Option {
 	return func(o *options) { o.autoCreateTopic = autoCreateTopic }
 }
+
+// WithPatternRefreshInterval 设置通配订阅的主题刷新间隔
+func WithPatternRefreshInterval(interval time.Duration) Option {
+	return func(o *options) { o.patternRefreshInterval = interval }
+}
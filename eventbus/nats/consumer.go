@@ -11,6 +11,7 @@ import (
 )
 
 type consumer struct {
+	pattern  string // 订阅模式
 	sub      *nats.Subscription
 	rw       sync.RWMutex
 	handlers map[uintptr][]eventbus.EventHandler
@@ -52,6 +53,14 @@ func (c *consumer) dispatch(data []byte) {
 		return
 	}
 
+	if c.pattern != "" {
+		if !eventbus.MatchTopic(c.pattern, event.Topic) {
+			return
+		}
+
+		event.Pattern = c.pattern
+	}
+
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
@@ -2,8 +2,10 @@ package nats
 
 import (
 	"context"
+	"strings"
 	"sync"
 
+	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/eventbus"
 	"github.com/nats-io/nats.go"
 )
@@ -14,6 +16,7 @@ type Eventbus struct {
 	builtin   bool
 	rw        sync.RWMutex
 	consumers map[string]*consumer
+	patterns  map[string]*consumer
 }
 
 func NewEventbus(opts ...Option) *Eventbus {
@@ -25,6 +28,7 @@ func NewEventbus(opts ...Option) *Eventbus {
 	eb := &Eventbus{opts: o}
 	eb.opts = o
 	eb.consumers = make(map[string]*consumer)
+	eb.patterns = make(map[string]*consumer)
 
 	if o.conn == nil {
 		o.conn, eb.err = nats.Connect(o.url, nats.Timeout(o.timeout))
@@ -103,6 +107,65 @@ func (eb *Eventbus) Unsubscribe(ctx context.Context, topic string, handler event
 	return nil
 }
 
+// PSubscribe 按通配模式订阅事件
+func (eb *Eventbus) PSubscribe(ctx context.Context, pattern string, handler eventbus.EventHandler) error {
+	if eb.err != nil {
+		return eb.err
+	}
+
+	if !eventbus.ValidPattern(pattern) {
+		return errors.ErrInvalidTopicPattern
+	}
+
+	eb.rw.Lock()
+	defer eb.rw.Unlock()
+
+	c, ok := eb.patterns[pattern]
+	if !ok {
+		c = &consumer{pattern: pattern, handlers: make(map[uintptr][]eventbus.EventHandler)}
+		sub, err := eb.opts.conn.Subscribe(eb.doMakeSubject(pattern), func(msg *nats.Msg) {
+			if eb.opts.prefix != "" && !strings.HasPrefix(msg.Subject, eb.opts.prefix+":") {
+				return
+			}
+
+			c.dispatch(msg.Data)
+		})
+		if err != nil {
+			return err
+		}
+		c.sub = sub
+		eb.patterns[pattern] = c
+	}
+
+	c.addHandler(handler)
+
+	return nil
+}
+
+// PUnsubscribe 按通配模式取消订阅
+func (eb *Eventbus) PUnsubscribe(ctx context.Context, pattern string, handler eventbus.EventHandler) error {
+	if eb.err != nil {
+		return eb.err
+	}
+
+	eb.rw.Lock()
+	defer eb.rw.Unlock()
+
+	if c, ok := eb.patterns[pattern]; ok {
+		if c.delHandler(handler) != 0 {
+			return nil
+		}
+
+		if err := c.sub.Unsubscribe(); err != nil {
+			return err
+		}
+
+		delete(eb.patterns, pattern)
+	}
+
+	return nil
+}
+
 // Close 停止监听
 func (eb *Eventbus) Close() error {
 	if eb.err != nil {
@@ -123,3 +186,20 @@ func (eb *Eventbus) doMakeChannel(topic string) string {
 		return eb.opts.prefix + ":" + topic
 	}
 }
+
+// 将订阅模式转换为nats主题
+// 前缀与主题首段以:拼接为同一段，当首段为通配符时需由通配符同时覆盖前缀，分发时再进行前缀与精确匹配
+func (eb *Eventbus) doMakeSubject(pattern string) string {
+	if eb.opts.prefix == "" {
+		return pattern
+	}
+
+	segment, _, _ := strings.Cut(pattern, eventbus.TopicSeparator)
+
+	switch segment {
+	case eventbus.SingleWildcard, eventbus.MultiWildcard:
+		return pattern
+	default:
+		return eb.doMakeChannel(pattern)
+	}
+}
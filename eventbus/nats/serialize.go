@@ -1,6 +1,8 @@
 package nats
 
 import (
+	"encoding/base64"
+
 	"github.com/devagame/due/v2/core/value"
 	"github.com/devagame/due/v2/encoding/json"
 	"github.com/devagame/due/v2/eventbus"
@@ -10,20 +12,29 @@ import (
 )
 
 type data struct {
-	ID        string `json:"id"`        // 事件ID
-	Topic     string `json:"topic"`     // 事件主题
-	Payload   string `json:"payload"`   // 事件载荷
-	Timestamp int64  `json:"timestamp"` // 事件时间
+	ID        string `json:"id"`              // 事件ID
+	Topic     string `json:"topic"`           // 事件主题
+	Payload   string `json:"payload"`         // 事件载荷
+	Codec     string `json:"codec,omitempty"` // 载荷编解码器
+	Timestamp int64  `json:"timestamp"`       // 事件时间
 }
 
 // 序列化
 func serialize(topic string, payload any) ([]byte, error) {
-	return json.Marshal(&data{
+	d := &data{
 		ID:        xuuid.UUID(),
 		Topic:     topic,
-		Payload:   xconv.String(payload),
 		Timestamp: xtime.Now().UnixNano(),
-	})
+	}
+
+	if encoded, ok := payload.(*eventbus.Encoded); ok {
+		d.Payload = base64.StdEncoding.EncodeToString(encoded.Data)
+		d.Codec = encoded.Codec
+	} else {
+		d.Payload = xconv.String(payload)
+	}
+
+	return json.Marshal(d)
 }
 
 // 反序列化
@@ -35,10 +46,23 @@ func deserialize(v []byte) (*eventbus.Event, error) {
 		return nil, err
 	}
 
-	return &eventbus.Event{
+	event := &eventbus.Event{
 		ID:        d.ID,
 		Topic:     d.Topic,
-		Payload:   value.NewValue(d.Payload),
 		Timestamp: xtime.UnixNano(d.Timestamp),
-	}, nil
+	}
+
+	if d.Codec != "" {
+		buf, err := base64.StdEncoding.DecodeString(d.Payload)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Payload = value.NewValue(buf)
+		event.Codec = d.Codec
+	} else {
+		event.Payload = value.NewValue(d.Payload)
+	}
+
+	return event, nil
 }
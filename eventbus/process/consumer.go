@@ -5,17 +5,22 @@ import (
 	"sync"
 
 	"github.com/devagame/due/v2/eventbus/internal"
-	"github.com/devagame/due/v2/task"
 )
 
 type consumer struct {
+	opts     *options
 	rw       sync.RWMutex
-	handlers map[uintptr][]internal.EventHandler
+	handlers map[uintptr][]*subscriber
 }
 
 func NewConsumer() *consumer {
+	return newConsumer(defaultOptions())
+}
+
+func newConsumer(opts *options) *consumer {
 	return &consumer{
-		handlers: make(map[uintptr][]internal.EventHandler),
+		opts:     opts,
+		handlers: make(map[uintptr][]*subscriber),
 	}
 }
 
@@ -27,10 +32,10 @@ func (c *consumer) addHandler(handler internal.EventHandler) int {
 	defer c.rw.Unlock()
 
 	if _, ok := c.handlers[pointer]; !ok {
-		c.handlers[pointer] = make([]internal.EventHandler, 0, 1)
+		c.handlers[pointer] = make([]*subscriber, 0, 1)
 	}
 
-	c.handlers[pointer] = append(c.handlers[pointer], handler)
+	c.handlers[pointer] = append(c.handlers[pointer], newSubscriber(c.opts, handler))
 
 	return len(c.handlers[pointer])
 }
@@ -42,20 +47,44 @@ func (c *consumer) delHandler(handler internal.EventHandler) int {
 	c.rw.Lock()
 	defer c.rw.Unlock()
 
+	for _, s := range c.handlers[pointer] {
+		s.stop()
+	}
+
 	delete(c.handlers, pointer)
 
 	return len(c.handlers)
 }
 
-// 分发数据
-func (c *consumer) dispatch(event *internal.Event) {
+// 获取订阅者快照，投递事件时无需持有锁
+func (c *consumer) subscribers() []*subscriber {
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
-	for _, handlers := range c.handlers {
-		for i := range handlers {
-			handler := handlers[i]
-			task.AddTask(func() { handler(event) })
+	subscribers := make([]*subscriber, 0, len(c.handlers))
+
+	for _, list := range c.handlers {
+		subscribers = append(subscribers, list...)
+	}
+
+	return subscribers
+}
+
+// 关闭消费者
+func (c *consumer) close() []*subscriber {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	subscribers := make([]*subscriber, 0, len(c.handlers))
+
+	for _, list := range c.handlers {
+		for _, s := range list {
+			s.stop()
+			subscribers = append(subscribers, s)
 		}
 	}
+
+	c.handlers = make(map[uintptr][]*subscriber)
+
+	return subscribers
 }
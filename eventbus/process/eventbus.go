@@ -5,41 +5,99 @@ import (
 	"sync"
 
 	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/eventbus/internal"
 	"github.com/devagame/due/v2/utils/xtime"
 	"github.com/devagame/due/v2/utils/xuuid"
 )
 
+type keyCtxKey struct{}
+
 type Eventbus struct {
+	opts      *options
 	rw        sync.RWMutex
 	consumers map[string]*consumer
+	patterns  map[string]*consumer
 }
 
-func NewEventbus() *Eventbus {
+func NewEventbus(opts ...Option) *Eventbus {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	eb := &Eventbus{}
+	eb.opts = o
 	eb.consumers = make(map[string]*consumer)
+	eb.patterns = make(map[string]*consumer)
 
 	return eb
 }
 
 // Publish 发布事件
+// 在锁内复制订阅者后再投递事件，避免阻塞投递期间持有锁导致处理器内再次发布或取消订阅时死锁
 func (eb *Eventbus) Publish(ctx context.Context, topic string, payload any) error {
+	event := &internal.Event{
+		ID:        xuuid.UUID(),
+		Topic:     topic,
+		Timestamp: xtime.UnixNano(xtime.Now().UnixNano()),
+	}
+
+	if encoded, ok := payload.(*internal.Encoded); ok {
+		event.Payload = value.NewValue(encoded.Data)
+		event.Codec = encoded.Codec
+	} else {
+		event.Payload = value.NewValue(payload)
+	}
+
+	key, ok := ctx.Value(keyCtxKey{}).(string)
+	if !ok && eb.opts.keyFunc != nil {
+		key = eb.opts.keyFunc(event)
+	}
+
+	deliveries := eb.deliveries(event)
+
+	var err error
+
+	for _, d := range deliveries {
+		for _, s := range d.subscribers {
+			if e := s.deliver(ctx, d.event, key); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+
+	return err
+}
+
+type delivery struct {
+	event       *internal.Event
+	subscribers []*subscriber
+}
+
+// 收集事件的所有订阅者
+func (eb *Eventbus) deliveries(event *internal.Event) []delivery {
 	eb.rw.RLock()
 	defer eb.rw.RUnlock()
 
-	c, ok := eb.consumers[topic]
-	if !ok {
-		return nil
+	deliveries := make([]delivery, 0, 1)
+
+	if c, ok := eb.consumers[event.Topic]; ok {
+		deliveries = append(deliveries, delivery{event: event, subscribers: c.subscribers()})
 	}
 
-	c.dispatch(&internal.Event{
-		ID:        xuuid.UUID(),
-		Topic:     topic,
-		Payload:   value.NewValue(payload),
-		Timestamp: xtime.UnixNano(xtime.Now().UnixNano()),
-	})
+	for pattern, c := range eb.patterns {
+		if !internal.MatchTopic(pattern, event.Topic) {
+			continue
+		}
 
-	return nil
+		clone := *event
+		clone.Pattern = pattern
+
+		deliveries = append(deliveries, delivery{event: &clone, subscribers: c.subscribers()})
+	}
+
+	return deliveries
 }
 
 // Subscribe 订阅事件
@@ -49,7 +107,7 @@ func (eb *Eventbus) Subscribe(ctx context.Context, topic string, handler interna
 
 	c, ok := eb.consumers[topic]
 	if !ok {
-		c = &consumer{handlers: make(map[uintptr][]internal.EventHandler, 1)}
+		c = newConsumer(eb.opts)
 		eb.consumers[topic] = c
 	}
 
@@ -74,7 +132,66 @@ func (eb *Eventbus) Unsubscribe(ctx context.Context, topic string, handler inter
 	return nil
 }
 
+// PSubscribe 按通配模式订阅事件
+func (eb *Eventbus) PSubscribe(ctx context.Context, pattern string, handler internal.EventHandler) error {
+	if !internal.ValidPattern(pattern) {
+		return errors.ErrInvalidTopicPattern
+	}
+
+	eb.rw.Lock()
+	defer eb.rw.Unlock()
+
+	c, ok := eb.patterns[pattern]
+	if !ok {
+		c = newConsumer(eb.opts)
+		eb.patterns[pattern] = c
+	}
+
+	c.addHandler(handler)
+
+	return nil
+}
+
+// PUnsubscribe 按通配模式取消订阅
+func (eb *Eventbus) PUnsubscribe(ctx context.Context, pattern string, handler internal.EventHandler) error {
+	eb.rw.Lock()
+	defer eb.rw.Unlock()
+
+	if c, ok := eb.patterns[pattern]; ok {
+		if c.delHandler(handler) != 0 {
+			return nil
+		}
+
+		delete(eb.patterns, pattern)
+	}
+
+	return nil
+}
+
 // Close 停止监听
+// 队列分发时会等待所有订阅者处理完队列中剩余的事件
 func (eb *Eventbus) Close() error {
+	eb.rw.Lock()
+	subscribers := make([]*subscriber, 0)
+	for _, c := range eb.consumers {
+		subscribers = append(subscribers, c.close()...)
+	}
+	for _, c := range eb.patterns {
+		subscribers = append(subscribers, c.close()...)
+	}
+	eb.consumers = make(map[string]*consumer)
+	eb.patterns = make(map[string]*consumer)
+	eb.rw.Unlock()
+
+	for _, s := range subscribers {
+		s.wait()
+	}
+
 	return nil
 }
+
+// WithKey 设置事件排序键
+// 队列分发时，同一排序键的事件将由每个订阅者按发布顺序依次处理
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyCtxKey{}, key)
+}
@@ -0,0 +1,162 @@
+package process_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/eventbus"
+	"github.com/devagame/due/v2/eventbus/process"
+)
+
+func TestEventbus_OrderedDispatch(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		received = make(map[string][]int)
+		eb       = process.NewEventbus(process.WithQueueSize(16), process.WithWorkers(4))
+	)
+
+	err := eb.Subscribe(ctx, "damage", func(event *eventbus.Event) {
+		defer wg.Done()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strconv.Itoa(event.Payload.Int() % 3)
+		received[key] = append(received[key], event.Payload.Int())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+
+		if err = eb.Publish(process.WithKey(ctx, strconv.Itoa(i%3)), "damage", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wg.Wait()
+
+	for key, values := range received {
+		for i := 1; i < len(values); i++ {
+			if values[i] < values[i-1] {
+				t.Fatalf("events of key %s are out of order: %v", key, values)
+			}
+		}
+	}
+
+	if err = eb.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEventbus_OverflowDropNewest(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		block   = make(chan struct{})
+		mu      sync.Mutex
+		dropped int
+		eb      = process.NewEventbus(
+			process.WithQueueSize(1),
+			process.WithOverflowPolicy(process.OverflowDropNewest),
+			process.WithOverflowHandler(func(event *eventbus.Event) {
+				mu.Lock()
+				dropped++
+				mu.Unlock()
+			}),
+		)
+	)
+
+	err := eb.Subscribe(ctx, "damage", func(event *eventbus.Event) {
+		<-block
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err = eb.Publish(ctx, "damage", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(block)
+
+	if err = eb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if dropped < 8 {
+		t.Fatalf("expect at least 8 dropped events, got %d", dropped)
+	}
+}
+
+func TestEventbus_RepublishWithUnsubscribe(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		done = make(chan struct{})
+		stop = make(chan struct{})
+		wg   sync.WaitGroup
+		eb   = process.NewEventbus(process.WithQueueSize(1), process.WithOverflowPolicy(process.OverflowBlock))
+	)
+
+	err := eb.Subscribe(ctx, "damage", func(event *eventbus.Event) {
+		defer wg.Done()
+
+		time.Sleep(time.Millisecond)
+
+		if err := eb.Publish(ctx, "hurt", event.Payload.Int()); err != nil {
+			t.Error(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		handler := func(event *eventbus.Event) {}
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			_ = eb.Subscribe(ctx, "hurt", handler)
+			_ = eb.Unsubscribe(ctx, "hurt", handler)
+		}
+	}()
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 200; i++ {
+			wg.Add(1)
+
+			if err := eb.Publish(ctx, "damage", i); err != nil {
+				t.Error(err)
+			}
+		}
+
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("publish deadlocked while republishing with concurrent unsubscribe")
+	}
+
+	close(stop)
+
+	if err = eb.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
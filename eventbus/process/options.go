@@ -0,0 +1,81 @@
+package process
+
+import (
+	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/eventbus/internal"
+)
+
+const (
+	OverflowBlock      = "block"      // 队列已满时阻塞发布者，直至队列空闲或发布上下文结束
+	OverflowDropNewest = "dropNewest" // 队列已满时丢弃新事件
+	OverflowDropOldest = "dropOldest" // 队列已满时丢弃队列中最旧的事件
+)
+
+const (
+	defaultQueueSize      = 0
+	defaultWorkers        = 1
+	defaultOverflowPolicy = OverflowBlock
+)
+
+const (
+	defaultQueueSizeKey      = "etc.eventbus.process.queueSize"
+	defaultWorkersKey        = "etc.eventbus.process.workers"
+	defaultOverflowPolicyKey = "etc.eventbus.process.overflowPolicy"
+)
+
+type Option func(o *options)
+
+type options struct {
+	// 每个订阅者的队列容量
+	// 为0时沿用任务池进行分发，不保证事件顺序；大于0时每个订阅者拥有独立的有界队列，默认为0
+	queueSize int
+
+	// 每个订阅者的工作协程数
+	// 仅在队列分发时生效，同一个排序键的事件始终由同一个工作协程按序处理，默认为1
+	workers int
+
+	// 队列溢出策略
+	// 仅在队列分发时生效，可选值：block、dropNewest、dropOldest，默认为block
+	overflowPolicy string
+
+	// 排序键生成函数
+	// 发布上下文中未携带排序键时，使用该函数生成排序键，默认为nil
+	keyFunc func(event *internal.Event) string
+
+	// 溢出处理器
+	// 事件因队列溢出被丢弃时调用，默认打印告警日志
+	overflowHandler func(event *internal.Event)
+}
+
+func defaultOptions() *options {
+	return &options{
+		queueSize:      etc.Get(defaultQueueSizeKey, defaultQueueSize).Int(),
+		workers:        etc.Get(defaultWorkersKey, defaultWorkers).Int(),
+		overflowPolicy: etc.Get(defaultOverflowPolicyKey, defaultOverflowPolicy).String(),
+	}
+}
+
+// WithQueueSize 设置每个订阅者的队列容量
+func WithQueueSize(queueSize int) Option {
+	return func(o *options) { o.queueSize = queueSize }
+}
+
+// WithWorkers 设置每个订阅者的工作协程数
+func WithWorkers(workers int) Option {
+	return func(o *options) { o.workers = workers }
+}
+
+// WithOverflowPolicy 设置队列溢出策略
+func WithOverflowPolicy(policy string) Option {
+	return func(o *options) { o.overflowPolicy = policy }
+}
+
+// WithKeyFunc 设置排序键生成函数
+func WithKeyFunc(fn func(event *internal.Event) string) Option {
+	return func(o *options) { o.keyFunc = fn }
+}
+
+// WithOverflowHandler 设置溢出处理器
+func WithOverflowHandler(handler func(event *internal.Event)) Option {
+	return func(o *options) { o.overflowHandler = handler }
+}
@@ -0,0 +1,153 @@
+package process
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/eventbus/internal"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/task"
+	"github.com/devagame/due/v2/utils/xcall"
+)
+
+type subscriber struct {
+	opts    *options
+	handler internal.EventHandler
+	queues  []chan *internal.Event
+	index   atomic.Uint64
+	wg      sync.WaitGroup
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newSubscriber(opts *options, handler internal.EventHandler) *subscriber {
+	s := &subscriber{opts: opts, handler: handler, done: make(chan struct{})}
+
+	if opts.queueSize <= 0 {
+		return s
+	}
+
+	s.queues = make([]chan *internal.Event, max(opts.workers, 1))
+
+	for i := range s.queues {
+		s.queues[i] = make(chan *internal.Event, opts.queueSize)
+		s.wg.Add(1)
+		go s.work(s.queues[i])
+	}
+
+	return s
+}
+
+// 投递事件，订阅者停止后不再投递
+func (s *subscriber) deliver(ctx context.Context, event *internal.Event, key string) error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+
+	if s.queues == nil {
+		task.AddTask(func() { s.handler(event) })
+		return nil
+	}
+
+	queue := s.queues[s.route(key)]
+
+	switch s.opts.overflowPolicy {
+	case OverflowDropNewest:
+		select {
+		case queue <- event:
+		default:
+			s.overflow(event)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case queue <- event:
+				return nil
+			case <-s.done:
+				return nil
+			default:
+			}
+
+			select {
+			case oldest := <-queue:
+				s.overflow(oldest)
+			default:
+			}
+		}
+	default:
+		select {
+		case queue <- event:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// 选择队列，同一排序键始终路由到同一队列
+func (s *subscriber) route(key string) int {
+	if len(s.queues) == 1 {
+		return 0
+	}
+
+	if key == "" {
+		return int(s.index.Add(1) % uint64(len(s.queues)))
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(len(s.queues)))
+}
+
+// 处理溢出事件
+func (s *subscriber) overflow(event *internal.Event) {
+	if s.opts.overflowHandler != nil {
+		s.opts.overflowHandler(event)
+	} else {
+		log.Warnf("event queue is full and the event is dropped, id: %s topic: %s", event.ID, event.Topic)
+	}
+}
+
+// 工作协程
+func (s *subscriber) work(queue chan *internal.Event) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case event := <-queue:
+			s.handle(event)
+		case <-s.done:
+			for {
+				select {
+				case event := <-queue:
+					s.handle(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// 处理事件
+func (s *subscriber) handle(event *internal.Event) {
+	xcall.Call(func() { s.handler(event) })
+}
+
+// 停止投递，工作协程处理完队列中剩余的事件后退出
+// 不关闭队列，避免与未持有锁的投递并发时向已关闭的队列写入
+func (s *subscriber) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// 等待工作协程退出
+func (s *subscriber) wait() {
+	s.wg.Wait()
+}
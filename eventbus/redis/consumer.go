@@ -10,6 +10,8 @@ import (
 )
 
 type consumer struct {
+	pattern  string // 订阅模式
+	glob     string // 订阅模式对应的redis通配符
 	rw       sync.RWMutex
 	handlers map[uintptr][]eventbus.EventHandler
 }
@@ -50,6 +52,14 @@ func (c *consumer) dispatch(data []byte) {
 		return
 	}
 
+	if c.pattern != "" {
+		if !eventbus.MatchTopic(c.pattern, event.Topic) {
+			return
+		}
+
+		event.Pattern = c.pattern
+	}
+
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
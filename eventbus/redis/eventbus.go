@@ -2,14 +2,18 @@ package redis
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"github.com/devagame/due/v2/core/tls"
+	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/eventbus"
 	"github.com/devagame/due/v2/utils/xconv"
 	"github.com/go-redis/redis/v8"
 )
 
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
 type Eventbus struct {
 	err       error
 	ctx       context.Context
@@ -19,6 +23,7 @@ type Eventbus struct {
 	sub       *redis.PubSub
 	rw        sync.RWMutex
 	consumers map[string]*consumer
+	patterns  map[string]*consumer
 }
 
 func NewEventbus(opts ...Option) *Eventbus {
@@ -35,6 +40,7 @@ func NewEventbus(opts ...Option) *Eventbus {
 			eb.ctx, eb.cancel = context.WithCancel(o.ctx)
 			eb.sub = eb.opts.client.Subscribe(eb.ctx)
 			eb.consumers = make(map[string]*consumer)
+			eb.patterns = make(map[string]*consumer)
 
 			go eb.watch()
 		}
@@ -127,6 +133,66 @@ func (eb *Eventbus) Unsubscribe(ctx context.Context, topic string, handler event
 	return nil
 }
 
+// PSubscribe 按通配模式订阅事件
+func (eb *Eventbus) PSubscribe(ctx context.Context, pattern string, handler eventbus.EventHandler) error {
+	if eb.err != nil {
+		return eb.err
+	}
+
+	if !eventbus.ValidPattern(pattern) {
+		return errors.ErrInvalidTopicPattern
+	}
+
+	glob := eb.doMakeGlob(pattern)
+
+	if err := eb.sub.PSubscribe(ctx, glob); err != nil {
+		return err
+	}
+
+	eb.rw.Lock()
+	defer eb.rw.Unlock()
+
+	c, ok := eb.patterns[pattern]
+	if !ok {
+		c = &consumer{pattern: pattern, glob: glob, handlers: make(map[uintptr][]eventbus.EventHandler, 1)}
+		eb.patterns[pattern] = c
+	}
+
+	c.addHandler(handler)
+
+	return nil
+}
+
+// PUnsubscribe 按通配模式取消订阅
+func (eb *Eventbus) PUnsubscribe(ctx context.Context, pattern string, handler eventbus.EventHandler) error {
+	if eb.err != nil {
+		return eb.err
+	}
+
+	eb.rw.Lock()
+	defer eb.rw.Unlock()
+
+	c, ok := eb.patterns[pattern]
+	if !ok {
+		return nil
+	}
+
+	if c.delHandler(handler) != 0 {
+		return nil
+	}
+
+	delete(eb.patterns, pattern)
+
+	// 不同的订阅模式可能对应同一个redis通配符
+	for _, other := range eb.patterns {
+		if other.glob == c.glob {
+			return nil
+		}
+	}
+
+	return eb.sub.PUnsubscribe(ctx, c.glob)
+}
+
 // watch 监听事件
 func (eb *Eventbus) watch() {
 	for {
@@ -137,11 +203,21 @@ func (eb *Eventbus) watch() {
 
 		switch v := iface.(type) {
 		case *redis.Message:
-			eb.rw.RLock()
-			c, ok := eb.consumers[v.Channel]
-			eb.rw.RUnlock()
-			if ok {
-				c.dispatch(xconv.Bytes(v.Payload))
+			if v.Pattern == "" {
+				eb.rw.RLock()
+				c, ok := eb.consumers[v.Channel]
+				eb.rw.RUnlock()
+				if ok {
+					c.dispatch(xconv.Bytes(v.Payload))
+				}
+			} else {
+				eb.rw.RLock()
+				for _, c := range eb.patterns {
+					if c.glob == v.Pattern {
+						c.dispatch(xconv.Bytes(v.Payload))
+					}
+				}
+				eb.rw.RUnlock()
 			}
 		}
 	}
@@ -171,3 +247,26 @@ func (eb *Eventbus) doMakeChannel(topic string) string {
 		return eb.opts.prefix + ":" + topic
 	}
 }
+
+// 将订阅模式转换为redis通配符
+// redis通配符无法约束单段匹配，因而*与>均转换为*，分发时再进行精确匹配
+func (eb *Eventbus) doMakeGlob(pattern string) string {
+	segments := strings.Split(pattern, eventbus.TopicSeparator)
+
+	for i, segment := range segments {
+		switch segment {
+		case eventbus.SingleWildcard, eventbus.MultiWildcard:
+			segments[i] = "*"
+		default:
+			segments[i] = globEscaper.Replace(segment)
+		}
+	}
+
+	glob := strings.Join(segments, eventbus.TopicSeparator)
+
+	if eb.opts.prefix == "" {
+		return glob
+	} else {
+		return globEscaper.Replace(eb.opts.prefix) + ":" + glob
+	}
+}
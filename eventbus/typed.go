@@ -0,0 +1,128 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/encoding"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+)
+
+type eventCtxKey struct{}
+
+// ErrorHandler 事件处理错误处理器
+type ErrorHandler func(event *Event, err error)
+
+var errorHandler atomic.Value
+
+func init() {
+	errorHandler.Store(ErrorHandler(func(event *Event, err error) {
+		log.Errorf("handle event failed, id: %s topic: %s err: %v", event.ID, event.Topic, err)
+	}))
+}
+
+// SetErrorHandler 设置事件处理错误处理器
+// 类型化订阅解码载荷失败时，会将错误交由该处理器处理，默认仅打印错误日志
+func SetErrorHandler(handler ErrorHandler) {
+	if handler == nil {
+		log.Warn("cannot set a nil error handler")
+		return
+	}
+
+	errorHandler.Store(handler)
+}
+
+// EventFromContext 从类型化订阅处理器的上下文中获取原始事件
+func EventFromContext(ctx context.Context) (*Event, bool) {
+	event, ok := ctx.Value(eventCtxKey{}).(*Event)
+	return event, ok
+}
+
+// PublishTyped 使用主题的编解码器编码并发布事件
+func PublishTyped[T any](ctx context.Context, topic string, message T) error {
+	codec := GetTopicCodec(topic)
+
+	data, err := codec.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return Publish(ctx, topic, &Encoded{Codec: codec.Name(), Data: data})
+}
+
+// SubscribeTyped 订阅事件，并将载荷解码为指定类型后交由处理器处理
+// 处理器的上下文派生自订阅时的上下文，可通过EventFromContext获取原始事件
+func SubscribeTyped[T any](ctx context.Context, topic string, handler func(ctx context.Context, message T)) error {
+	return typedSubscribe(ctx, topic, false, handler, makeTypedHandler(ctx, handler))
+}
+
+// UnsubscribeTyped 取消类型化订阅
+func UnsubscribeTyped[T any](ctx context.Context, topic string, handler func(ctx context.Context, message T)) error {
+	return typedUnsubscribe(ctx, topic, false, handler)
+}
+
+// PSubscribeTyped 按通配模式订阅事件，并将载荷解码为指定类型后交由处理器处理
+// 处理器的上下文派生自订阅时的上下文，可通过EventFromContext获取原始事件
+func PSubscribeTyped[T any](ctx context.Context, pattern string, handler func(ctx context.Context, message T)) error {
+	return typedSubscribe(ctx, pattern, true, handler, makeTypedHandler(ctx, handler))
+}
+
+// PUnsubscribeTyped 按通配模式取消类型化订阅
+func PUnsubscribeTyped[T any](ctx context.Context, pattern string, handler func(ctx context.Context, message T)) error {
+	return typedUnsubscribe(ctx, pattern, true, handler)
+}
+
+// 生成类型化事件处理器
+func makeTypedHandler[T any](ctx context.Context, handler func(ctx context.Context, message T)) EventHandler {
+	return func(event *Event) {
+		message, err := decodePayload[T](event)
+		if err != nil {
+			errorHandler.Load().(ErrorHandler)(event, err)
+			return
+		}
+
+		handler(context.WithValue(ctx, eventCtxKey{}, event), message)
+	}
+}
+
+// 解码事件载荷
+func decodePayload[T any](event *Event) (message T, err error) {
+	if event.Payload == nil {
+		err = errors.NewError("empty payload", errors.ErrEventPayloadMismatch)
+		return
+	}
+
+	if event.Codec == "" {
+		if v, ok := event.Payload.Value().(T); ok {
+			return v, nil
+		}
+
+		if err = event.Payload.Scan(&message); err != nil {
+			err = errors.NewError(err.Error(), errors.ErrEventPayloadMismatch)
+		}
+
+		return
+	}
+
+	codec, ok := encoding.Lookup(event.Codec)
+	if !ok {
+		err = errors.NewError(fmt.Sprintf("%s codec is not registered", event.Codec), errors.ErrEventPayloadMismatch)
+		return
+	}
+
+	var target any = &message
+
+	if rt := reflect.TypeFor[T](); rt.Kind() == reflect.Ptr {
+		message = reflect.New(rt.Elem()).Interface().(T)
+		target = message
+	}
+
+	if err = codec.Unmarshal(event.Payload.Bytes(), target); err != nil {
+		err = errors.NewError(fmt.Sprintf("%s codec decode failed: %v", event.Codec, err), errors.ErrEventPayloadMismatch)
+	}
+
+	return
+}
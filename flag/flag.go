@@ -19,6 +19,11 @@ func Has(key string) bool {
 	return commandLine.has(key)
 }
 
+// All 获取所有运行参数
+func All() map[string]string {
+	return commandLine.all()
+}
+
 func String(key string, def ...string) string {
 	return commandLine.string(key, def...)
 }
@@ -158,6 +163,15 @@ func (f *flagSet) parseOne() (bool, error) {
 	return true, nil
 }
 
+func (f *flagSet) all() map[string]string {
+	values := make(map[string]string, len(f.values))
+	for key, val := range f.values {
+		values[key] = val
+	}
+
+	return values
+}
+
 func (f *flagSet) has(key string) bool {
 	_, ok := f.values[key]
 	return ok
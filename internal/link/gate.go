@@ -14,6 +14,7 @@ import (
 	"github.com/devagame/due/v2/internal/transporter/gate"
 	"github.com/devagame/due/v2/locate"
 	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/packet"
 	"github.com/devagame/due/v2/registry"
 	"github.com/devagame/due/v2/session"
@@ -30,9 +31,14 @@ type GateLinker struct {
 
 func NewGateLinker(ctx context.Context, opts *Options) *GateLinker {
 	l := &GateLinker{
-		ctx:        ctx,
-		opts:       opts,
-		builder:    gate.NewBuilder(&gate.Options{InsID: opts.InsID, InsKind: opts.InsKind}),
+		ctx:  ctx,
+		opts: opts,
+		builder: gate.NewBuilder(&gate.Options{
+			InsID:     opts.InsID,
+			InsKind:   opts.InsKind,
+			TLSConfig: opts.TLSConfig,
+			Secret:    opts.Secret,
+		}),
 		dispatcher: dispatcher.NewDispatcher(opts.Dispatch),
 	}
 
@@ -198,6 +204,46 @@ func (l *GateLinker) doIndirectGetIP(ctx context.Context, uid int64) (string, er
 	return v.(string), nil
 }
 
+// GetConnStats 获取连接流量统计
+func (l *GateLinker) GetConnStats(ctx context.Context, args *GetConnStatsArgs) (*network.ConnStat, error) {
+	switch args.Kind {
+	case session.Conn:
+		return l.doDirectGetConnStats(ctx, args.GID, args.Kind, args.Target)
+	case session.User:
+		if args.GID == "" {
+			return l.doIndirectGetConnStats(ctx, args.Target)
+		} else {
+			return l.doDirectGetConnStats(ctx, args.GID, args.Kind, args.Target)
+		}
+	default:
+		return nil, errors.ErrInvalidSessionKind
+	}
+}
+
+// 直接获取连接流量统计
+func (l *GateLinker) doDirectGetConnStats(ctx context.Context, gid string, kind session.Kind, target int64) (*network.ConnStat, error) {
+	client, err := l.doBuildClient(gid)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, _, err := client.GetConnStats(ctx, kind, target)
+	return stat, err
+}
+
+// 间接获取连接流量统计
+func (l *GateLinker) doIndirectGetConnStats(ctx context.Context, uid int64) (*network.ConnStat, error) {
+	v, err := l.doRPC(ctx, uid, func(client *gate.Client) (bool, any, error) {
+		stat, miss, err := client.GetConnStats(ctx, session.User, uid)
+		return miss, stat, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*network.ConnStat), nil
+}
+
 // Stat 统计会话总数
 func (l *GateLinker) Stat(ctx context.Context, kind session.Kind) (int64, error) {
 	total := int64(0)
@@ -278,7 +324,7 @@ func (l *GateLinker) Disconnect(ctx context.Context, args *DisconnectArgs) error
 		return l.doDirectDisconnect(ctx, args)
 	case session.User:
 		if args.GID == "" {
-			return l.doIndirectDisconnect(ctx, args.Target, args.Force)
+			return l.doIndirectDisconnect(ctx, args)
 		} else {
 			return l.doDirectDisconnect(ctx, args)
 		}
@@ -294,13 +340,13 @@ func (l *GateLinker) doDirectDisconnect(ctx context.Context, args *DisconnectArg
 		return err
 	}
 
-	return client.Disconnect(ctx, args.Kind, args.Target, args.Force)
+	return client.Disconnect(ctx, args.Kind, args.Target, args.Force, args.Code, args.Message)
 }
 
 // 间接断开连接
-func (l *GateLinker) doIndirectDisconnect(ctx context.Context, uid int64, force bool) error {
-	_, err := l.doRPC(ctx, uid, func(client *gate.Client) (bool, any, error) {
-		return false, nil, client.Disconnect(ctx, session.User, uid, force)
+func (l *GateLinker) doIndirectDisconnect(ctx context.Context, args *DisconnectArgs) error {
+	_, err := l.doRPC(ctx, args.Target, func(client *gate.Client) (bool, any, error) {
+		return false, nil, client.Disconnect(ctx, session.User, args.Target, args.Force, args.Code, args.Message)
 	})
 
 	return err
@@ -778,6 +824,11 @@ func (l *GateLinker) PackBuffer(message any, encrypt bool) ([]byte, error) {
 	return data, nil
 }
 
+// Stats 获取与各网关连接的合并写入统计
+func (l *GateLinker) Stats() map[string]*cluster.BatchStat {
+	return l.builder.Stats()
+}
+
 // WatchUserLocate 监听用户定位
 func (l *GateLinker) WatchUserLocate() {
 	if l.opts.Locator == nil {
@@ -29,9 +29,14 @@ type NodeLinker struct {
 
 func NewNodeLinker(ctx context.Context, opts *Options) *NodeLinker {
 	l := &NodeLinker{
-		ctx:        ctx,
-		opts:       opts,
-		builder:    node.NewBuilder(&node.Options{InsID: opts.InsID, InsKind: opts.InsKind}),
+		ctx:  ctx,
+		opts: opts,
+		builder: node.NewBuilder(&node.Options{
+			InsID:     opts.InsID,
+			InsKind:   opts.InsKind,
+			TLSConfig: opts.TLSConfig,
+			Secret:    opts.Secret,
+		}),
 		dispatcher: dispatcher.NewDispatcher(opts.Dispatch),
 		sources:    make(map[int64]map[string]string),
 	}
@@ -347,6 +352,11 @@ func (l *NodeLinker) PackBuffer(message any, encrypt bool) ([]byte, error) {
 	return data, nil
 }
 
+// Stats 获取与各节点连接的合并写入统计
+func (l *NodeLinker) Stats() map[string]*cluster.BatchStat {
+	return l.builder.Stats()
+}
+
 // 保存用户节点来源
 func (l *NodeLinker) doSaveSource(uid int64, name, nid string) {
 	l.rw.Lock()
@@ -1,6 +1,8 @@
 package link
 
 import (
+	"crypto/tls"
+
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/crypto"
 	"github.com/devagame/due/v2/encoding"
@@ -16,4 +18,6 @@ type Options struct {
 	Registry  registry.Registry // 注册器
 	Encryptor crypto.Encryptor  // 加密器
 	Dispatch  cluster.Dispatch  // 无状态路由消息分发策略
+	TLSConfig *tls.Config       // 内部通信TLS配置
+	Secret    string            // 内部通信握手鉴权秘钥
 }
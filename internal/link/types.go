@@ -5,16 +5,17 @@ import (
 )
 
 type (
-	Message         = cluster.Message
-	GetIPArgs       = cluster.GetIPArgs
-	IsOnlineArgs    = cluster.IsOnlineArgs
-	DisconnectArgs  = cluster.DisconnectArgs
-	PushArgs        = cluster.PushArgs
-	MulticastArgs   = cluster.MulticastArgs
-	BroadcastArgs   = cluster.BroadcastArgs
-	PublishArgs     = cluster.PublishArgs
-	SubscribeArgs   = cluster.SubscribeArgs
-	UnsubscribeArgs = cluster.UnsubscribeArgs
+	Message          = cluster.Message
+	GetIPArgs        = cluster.GetIPArgs
+	GetConnStatsArgs = cluster.GetConnStatsArgs
+	IsOnlineArgs     = cluster.IsOnlineArgs
+	DisconnectArgs   = cluster.DisconnectArgs
+	PushArgs         = cluster.PushArgs
+	MulticastArgs    = cluster.MulticastArgs
+	BroadcastArgs    = cluster.BroadcastArgs
+	PublishArgs      = cluster.PublishArgs
+	SubscribeArgs    = cluster.SubscribeArgs
+	UnsubscribeArgs  = cluster.UnsubscribeArgs
 )
 
 type DeliverArgs struct {
@@ -10,6 +10,7 @@ import (
 	"github.com/devagame/due/v2/internal/transporter/internal/client"
 	"github.com/devagame/due/v2/internal/transporter/internal/codes"
 	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/session"
 )
 
@@ -81,6 +82,25 @@ func (c *Client) GetIP(ctx context.Context, kind session.Kind, target int64) (st
 	return ip, code == codes.NotFoundSession, nil
 }
 
+// GetConnStats 获取连接流量统计
+func (c *Client) GetConnStats(ctx context.Context, kind session.Kind, target int64) (*network.ConnStat, bool, error) {
+	seq := c.doGenSequence()
+
+	buf := protocol.EncodeGetConnStatsReq(seq, kind, target)
+
+	res, err := c.cli.Call(ctx, seq, buf)
+	if err != nil {
+		return nil, false, err
+	}
+
+	code, stat, err := protocol.DecodeGetConnStatsRes(res)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return stat, code == codes.NotFoundSession, codes.CodeToError(code)
+}
+
 // Stat 推送广播消息
 func (c *Client) Stat(ctx context.Context, kind session.Kind) (int64, error) {
 	seq := c.doGenSequence()
@@ -117,11 +137,11 @@ func (c *Client) IsOnline(ctx context.Context, kind session.Kind, target int64)
 }
 
 // Disconnect 断开连接
-func (c *Client) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool) error {
+func (c *Client) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool, code network.CloseCode, message string) error {
 	if force {
-		return c.cli.Send(ctx, protocol.EncodeDisconnectReq(0, kind, target, force))
+		return c.cli.Send(ctx, protocol.EncodeDisconnectReq(0, kind, target, force, uint16(code), message))
 	} else {
-		return c.cli.Send(ctx, protocol.EncodeDisconnectReq(0, kind, target, force), target)
+		return c.cli.Send(ctx, protocol.EncodeDisconnectReq(0, kind, target, force, uint16(code), message), target)
 	}
 }
 
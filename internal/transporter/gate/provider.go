@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/session"
 )
 
@@ -14,12 +15,14 @@ type Provider interface {
 	Unbind(ctx context.Context, uid int64) error
 	// GetIP 获取客户端IP地址
 	GetIP(ctx context.Context, kind session.Kind, target int64) (ip string, err error)
+	// GetConnStats 获取连接流量统计
+	GetConnStats(ctx context.Context, kind session.Kind, target int64) (stat *network.ConnStat, err error)
 	// IsOnline 检测是否在线
 	IsOnline(ctx context.Context, kind session.Kind, target int64) (isOnline bool, err error)
 	// Stat 统计会话总数
 	Stat(ctx context.Context, kind session.Kind) (total int64, err error)
-	// Disconnect 断开连接
-	Disconnect(ctx context.Context, kind session.Kind, target int64, force bool) error
+	// Disconnect 断开连接，关闭原因码不为CloseNone时向客户端下发关闭包
+	Disconnect(ctx context.Context, kind session.Kind, target int64, force bool, code network.CloseCode, message string) error
 	// Push 发送消息
 	Push(ctx context.Context, kind session.Kind, target int64, message []byte) error
 	// Multicast 推送组播消息
@@ -7,6 +7,7 @@ import (
 	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
 	"github.com/devagame/due/v2/internal/transporter/internal/route"
 	"github.com/devagame/due/v2/internal/transporter/internal/server"
+	"github.com/devagame/due/v2/network"
 )
 
 type Server struct {
@@ -32,6 +33,7 @@ func (s *Server) init() {
 	s.RegisterHandler(route.Bind, s.bind)
 	s.RegisterHandler(route.Unbind, s.unbind)
 	s.RegisterHandler(route.GetIP, s.getIP)
+	s.RegisterHandler(route.GetConnStats, s.getConnStats)
 	s.RegisterHandler(route.Stat, s.stat)
 	s.RegisterHandler(route.IsOnline, s.isOnline)
 	s.RegisterHandler(route.Disconnect, s.disconnect)
@@ -87,6 +89,20 @@ func (s *Server) getIP(conn *server.Conn, data []byte) error {
 	}
 }
 
+// 获取连接流量统计
+func (s *Server) getConnStats(conn *server.Conn, data []byte) error {
+	seq, kind, target, err := protocol.DecodeGetConnStatsReq(data)
+	if err != nil {
+		return err
+	}
+
+	if stat, err := s.provider.GetConnStats(context.Background(), kind, target); seq == 0 {
+		return err
+	} else {
+		return conn.Send(protocol.EncodeGetConnStatsRes(seq, codes.ErrorToCode(err), stat))
+	}
+}
+
 // 统计在线人数
 func (s *Server) stat(conn *server.Conn, data []byte) error {
 	seq, kind, err := protocol.DecodeStatReq(data)
@@ -117,12 +133,12 @@ func (s *Server) isOnline(conn *server.Conn, data []byte) error {
 
 // 断开连接
 func (s *Server) disconnect(conn *server.Conn, data []byte) error {
-	seq, kind, target, force, err := protocol.DecodeDisconnectReq(data)
+	seq, kind, target, force, code, message, err := protocol.DecodeDisconnectReq(data)
 	if err != nil {
 		return err
 	}
 
-	if err = s.provider.Disconnect(context.Background(), kind, target, force); seq == 0 {
+	if err = s.provider.Disconnect(context.Background(), kind, target, force, network.CloseCode(code), message); seq == 0 {
 		return err
 	} else {
 		return conn.Send(protocol.EncodeDisconnectRes(seq, codes.ErrorToCode(err)))
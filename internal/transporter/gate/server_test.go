@@ -8,6 +8,7 @@ import (
 
 	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/internal/transporter/gate"
+	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/session"
 )
 
@@ -90,7 +91,7 @@ func (p *provider) Stat(ctx context.Context, kind session.Kind) (total int64, er
 }
 
 // Disconnect 断开连接
-func (p *provider) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool) error {
+func (p *provider) Disconnect(ctx context.Context, kind session.Kind, target int64, force bool, code network.CloseCode, message string) error {
 	return nil
 }
 
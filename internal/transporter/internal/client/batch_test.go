@@ -0,0 +1,128 @@
+package client_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/internal/transporter/internal/client"
+	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
+	"github.com/devagame/due/v2/internal/transporter/internal/route"
+	"github.com/devagame/due/v2/internal/transporter/internal/server"
+)
+
+func TestClient_Batch(t *testing.T) {
+	testBatch(t, nil, nil)
+}
+
+func TestClient_BatchTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "due"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	testBatch(t,
+		&tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		&tls.Config{RootCAs: pool, ServerName: "127.0.0.1"},
+	)
+}
+
+func testBatch(t *testing.T, serverTLS, clientTLS *tls.Config) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	s, err := server.NewServer(&server.Options{Addr: addr, TLSConfig: serverTLS})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received atomic.Int64
+
+	s.RegisterHandler(route.Bind, func(conn *server.Conn, data []byte) error {
+		received.Add(1)
+		return nil
+	})
+
+	go s.Start()
+	defer s.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	c := client.NewClient(&client.Options{
+		Addr:      addr,
+		InsID:     "node",
+		InsKind:   cluster.Node,
+		TLSConfig: clientTLS,
+		Batch:     &client.BatchOptions{MaxBytes: 64 * 1024, MaxFrames: 64, FlushInterval: time.Millisecond},
+	})
+
+	if err = c.Establish(); err != nil {
+		t.Fatal(err)
+	}
+
+	const total = 10000
+
+	for i := 0; i < total; i++ {
+		if err = c.Send(context.Background(), protocol.EncodeBindReq(0, int64(i), int64(i)), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for received.Load() < total && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := received.Load(); n != total {
+		t.Fatalf("received %d messages, want %d", n, total)
+	}
+
+	stat := c.Stat()
+
+	if stat.Frames != total {
+		t.Fatalf("stat frames = %d, want %d", stat.Frames, total)
+	}
+
+	if stat.MaxFrames > 64 {
+		t.Fatalf("stat max frames = %d, exceeds limit", stat.MaxFrames)
+	}
+
+	t.Logf("batches: %d, avg frames: %.2f, max frames: %d, avg bytes: %.2f", stat.Batches, stat.AvgFrames(), stat.MaxFrames, stat.AvgBytes())
+}
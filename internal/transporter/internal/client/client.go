@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/core/buffer"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/log"
@@ -25,6 +26,8 @@ type chWrite struct {
 
 type Client struct {
 	opts            *Options       // 配置
+	batch           *BatchOptions  // 合并写入配置
+	stat            batchStat      // 合并写入统计
 	connections     []*Conn        // 连接
 	disorderlyQueue chan *chWrite  // 无序队列
 	wg              sync.WaitGroup // 等待组
@@ -35,6 +38,10 @@ type Client struct {
 func NewClient(opts *Options) *Client {
 	c := &Client{}
 	c.opts = opts
+	c.batch = opts.Batch
+	if c.batch == nil {
+		c.batch = defaultBatchOptions()
+	}
 	c.connections = make([]*Conn, 0, defaultConnNum)
 	c.disorderlyQueue = make(chan *chWrite, 10240)
 	c.closed.Store(true)
@@ -86,10 +93,14 @@ func (c *Client) Call(ctx context.Context, seq uint64, buf *buffer.NocopyBuffer,
 		return nil, errors.ErrClientClosed
 	}
 
+	// 回调通道保存在局部变量中，发送成功后消息归写入协程所有，写入完成即被回收复用
+	// 回调通道带有缓冲，避免调用超时后读取协程阻塞在回调写入上
+	call := make(chan []byte, 1)
+
 	ch := c.pool.Get().(*chWrite)
 	ch.seq = seq
 	ch.buf = buf
-	ch.call = make(chan []byte)
+	ch.call = call
 
 	conn := c.load(idx...)
 
@@ -98,7 +109,12 @@ func (c *Client) Call(ctx context.Context, seq uint64, buf *buffer.NocopyBuffer,
 		return nil, err
 	}
 
-	tctx, tcancel := context.WithTimeout(ctx, defaultTimeout)
+	timeout := c.opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	tctx, tcancel := context.WithTimeout(ctx, timeout)
 	defer tcancel()
 
 	select {
@@ -108,7 +124,7 @@ func (c *Client) Call(ctx context.Context, seq uint64, buf *buffer.NocopyBuffer,
 	case <-tctx.Done():
 		conn.delete(seq)
 		return nil, tctx.Err()
-	case data, ok := <-ch.call:
+	case data, ok := <-call:
 		if !ok {
 			return nil, errors.ErrConnectionHanged
 		}
@@ -136,6 +152,11 @@ func (c *Client) Send(ctx context.Context, buf *buffer.NocopyBuffer, idx ...int6
 	return nil
 }
 
+// Stat 获取合并写入统计
+func (c *Client) Stat() *cluster.BatchStat {
+	return c.stat.snapshot()
+}
+
 // 获取连接
 func (c *Client) load(idx ...int64) *Conn {
 	if len(idx) > 0 {
@@ -146,6 +167,7 @@ func (c *Client) load(idx ...int64) *Conn {
 }
 
 // 释放
+// 仅由消息的持有者调用：发送失败时由调用方释放，发送成功后由写入协程在写入完成后释放
 func (c *Client) release(ch *chWrite) {
 	if ch.buf == nil {
 		return
@@ -2,12 +2,14 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"sync/atomic"
 	"time"
 
 	"github.com/devagame/due/v2/core/buffer"
 	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/internal/transporter/internal/codes"
 	"github.com/devagame/due/v2/internal/transporter/internal/def"
 	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
 	"github.com/devagame/due/v2/log"
@@ -28,6 +30,10 @@ type Conn struct {
 	ctx               context.Context    // 上下文
 	cancel            context.CancelFunc // 取消函数
 	lastHeartbeatTime atomic.Int64       // 上次心跳时间
+	busy              bool               // 上次写入是否合并了多条消息，仅在写入协程中访问
+	batch             []*chWrite         // 合并写入的消息，仅在写入协程中访问
+	bufs              net.Buffers        // 合并写入的数据，仅在写入协程中访问
+	buf               []byte             // 合并写入的连续缓冲区，仅在写入协程中访问
 }
 
 func newConn(cli *Client, queue chan *chWrite) *Conn {
@@ -49,7 +55,7 @@ func (c *Conn) dial() error {
 	)
 
 	for {
-		conn, err := net.DialTimeout("tcp", c.cli.opts.Addr, dialTimeout)
+		conn, err := c.doDial()
 		if err != nil {
 			retry++
 
@@ -76,6 +82,15 @@ func (c *Conn) dial() error {
 	}
 }
 
+// 建立连接，配置了TLS时建立TLS连接
+func (c *Conn) doDial() (net.Conn, error) {
+	if c.cli.opts.TLSConfig == nil {
+		return net.DialTimeout("tcp", c.cli.opts.Addr, dialTimeout)
+	}
+
+	return tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", c.cli.opts.Addr, c.cli.opts.TLSConfig)
+}
+
 // 发送
 func (c *Conn) send(ch *chWrite, isOrderly ...bool) error {
 	switch c.state.Load() {
@@ -99,16 +114,20 @@ func (c *Conn) send(ch *chWrite, isOrderly ...bool) error {
 // 处理连接
 func (c *Conn) process(conn net.Conn) error {
 	c.ctx, c.cancel = context.WithCancel(context.Background())
-	c.state.Store(def.ConnOpened)
 	c.lastHeartbeatTime.Store(xtime.Now().Unix())
 
 	go c.read(conn)
 
+	// 握手完成前保持挂起状态，避免握手被拒绝后读取协程触发重连
 	if err := c.handshake(conn); err != nil {
 		c.close()
 
+		_ = conn.Close()
+
 		return err
 	} else {
+		c.state.Store(def.ConnOpened)
+
 		go c.write(conn)
 
 		return nil
@@ -119,10 +138,19 @@ func (c *Conn) process(conn net.Conn) error {
 func (c *Conn) handshake(conn net.Conn) error {
 	var (
 		seq  = uint64(1)
-		call = make(chan []byte)
+		call = make(chan []byte, 1)
 	)
 
-	buf := protocol.EncodeHandshakeReq(seq, c.cli.opts.InsKind, c.cli.opts.InsID)
+	var buf *buffer.NocopyBuffer
+
+	if c.cli.opts.Secret != "" {
+		var err error
+		if buf, err = protocol.EncodeAuthHandshakeReq(seq, c.cli.opts.InsKind, c.cli.opts.InsID, c.cli.opts.Secret); err != nil {
+			return err
+		}
+	} else {
+		buf = protocol.EncodeHandshakeReq(seq, c.cli.opts.InsKind, c.cli.opts.InsID)
+	}
 	defer buf.Release()
 
 	c.pending.store(seq, call)
@@ -141,8 +169,13 @@ func (c *Conn) handshake(conn net.Conn) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-call:
-		return nil
+	case data := <-call:
+		code, err := protocol.DecodeHandshakeRes(data)
+		if err != nil {
+			return err
+		}
+
+		return codes.CodeToError(code)
 	}
 }
 
@@ -222,27 +255,122 @@ func (c *Conn) write(conn net.Conn) {
 	}
 }
 
-// 执行写入数据
+// 执行写入数据，将队列中已就绪的消息合并为一次写入
 func (c *Conn) doWrite(conn net.Conn, ch *chWrite) bool {
-	if ch.seq != 0 {
-		c.pending.store(ch.seq, ch.call)
-	}
+	batch := c.collect(ch)
 
-	ok := ch.buf.Visit(func(node *buffer.NocopyNode) bool {
-		if _, err := conn.Write(node.Bytes()); err != nil {
-			return false
-		} else {
-			return true
+	bufs := c.bufs[:0]
+	size := 0
+
+	for _, ch := range batch {
+		if ch.seq != 0 {
+			c.pending.store(ch.seq, ch.call)
 		}
-	})
 
-	c.cli.release(ch)
+		ch.buf.Visit(func(node *buffer.NocopyNode) bool {
+			bufs = append(bufs, node.Bytes())
+			size += len(node.Bytes())
+			return true
+		})
+	}
+
+	c.bufs = bufs
 
-	if !ok {
+	err := c.flush(conn, bufs)
+
+	for i, ch := range batch {
+		c.cli.release(ch)
+		batch[i] = nil
+	}
+
+	clear(c.bufs[:cap(c.bufs)])
+
+	if err != nil {
 		c.retry(conn)
+		return false
+	}
+
+	c.busy = len(batch) > 1
+	c.cli.stat.record(len(batch), size)
+
+	return true
+}
+
+// 写入合并的数据
+// 仅TCP连接支持writev，其他连接（如TLS连接）使用net.Buffers写入时会逐个缓冲区写入，需先拷贝至连续缓冲区再一次写入
+func (c *Conn) flush(conn net.Conn, bufs net.Buffers) error {
+	if _, ok := conn.(*net.TCPConn); ok || len(bufs) == 1 {
+		_, err := bufs.WriteTo(conn)
+		return err
+	}
+
+	buf := c.buf[:0]
+	for _, b := range bufs {
+		buf = append(buf, b...)
+	}
+
+	c.buf = buf
+
+	_, err := conn.Write(buf)
+
+	return err
+}
+
+// 收集待合并写入的消息
+// 连接繁忙（上次写入合并了多条消息）且配置了延迟预算时，最多等待延迟预算时长以合并更多消息
+func (c *Conn) collect(ch *chWrite) []*chWrite {
+	batch := append(c.batch[:0], ch)
+	opts := c.cli.batch
+
+	if opts.MaxFrames <= 1 {
+		c.batch = batch
+		return batch
+	}
+
+	size := ch.buf.Len()
+
+	var timeout <-chan time.Time
+	if c.busy && opts.FlushInterval > 0 {
+		timer := time.NewTimer(opts.FlushInterval)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for len(batch) < opts.MaxFrames && (opts.MaxBytes <= 0 || size < opts.MaxBytes) {
+		var (
+			next *chWrite
+			ok   bool
+		)
+
+		select {
+		case next, ok = <-c.orderlyQueue:
+		case next, ok = <-c.disorderlyQueue:
+		default:
+			if timeout == nil {
+				c.batch = batch
+				return batch
+			}
+
+			select {
+			case next, ok = <-c.orderlyQueue:
+			case next, ok = <-c.disorderlyQueue:
+			case <-timeout:
+				c.batch = batch
+				return batch
+			}
+		}
+
+		if !ok {
+			break
+		}
+
+		batch = append(batch, next)
+		size += next.buf.Len()
 	}
 
-	return ok
+	c.batch = batch
+
+	return batch
 }
 
 // 重试拨号
@@ -1,10 +1,47 @@
 package client
 
-import "github.com/devagame/due/v2/cluster"
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultBatchMaxBytes      = 64 * 1024 // 默认单次合并写入的最大字节数
+	defaultBatchMaxFrames     = 128       // 默认单次合并写入的最大消息数
+	defaultBatchFlushInterval = 0         // 默认合并写入的延迟预算
+)
+
+const (
+	defaultBatchMaxBytesKey      = "etc.cluster.link.batch.maxBytes"
+	defaultBatchMaxFramesKey     = "etc.cluster.link.batch.maxFrames"
+	defaultBatchFlushIntervalKey = "etc.cluster.link.batch.flushInterval"
+)
 
 type Options struct {
-	Addr         string       // 连接地址
-	InsID        string       // 实例ID
-	InsKind      cluster.Kind // 实例类型
-	CloseHandler func()       // 关闭处理器
+	Addr         string        // 连接地址
+	InsID        string        // 实例ID
+	InsKind      cluster.Kind  // 实例类型
+	Timeout      time.Duration // 调用超时时间，为0时使用默认超时时间
+	TLSConfig    *tls.Config   // TLS配置，为空时使用明文TCP通信
+	Secret       string        // 握手鉴权秘钥，为空时不携带鉴权信息
+	Batch        *BatchOptions // 合并写入配置，为空时使用etc中的配置
+	CloseHandler func()        // 关闭处理器
+}
+
+// BatchOptions 合并写入配置
+type BatchOptions struct {
+	MaxBytes      int           // 单次合并写入的最大字节数
+	MaxFrames     int           // 单次合并写入的最大消息数，小于等于1时不合并写入
+	FlushInterval time.Duration // 合并写入的延迟预算，连接繁忙时最多等待该时长以合并更多消息，为0时仅合并队列中已就绪的消息
+}
+
+func defaultBatchOptions() *BatchOptions {
+	return &BatchOptions{
+		MaxBytes:      int(etc.Get(defaultBatchMaxBytesKey, defaultBatchMaxBytes).B()),
+		MaxFrames:     etc.Get(defaultBatchMaxFramesKey, defaultBatchMaxFrames).Int(),
+		FlushInterval: etc.Get(defaultBatchFlushIntervalKey, defaultBatchFlushInterval).Duration(),
+	}
 }
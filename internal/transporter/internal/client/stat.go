@@ -0,0 +1,38 @@
+package client
+
+import (
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/cluster"
+)
+
+type batchStat struct {
+	batches   atomic.Uint64
+	frames    atomic.Uint64
+	bytes     atomic.Uint64
+	maxFrames atomic.Uint64
+}
+
+// 记录一次写入
+func (s *batchStat) record(frames, bytes int) {
+	s.batches.Add(1)
+	s.frames.Add(uint64(frames))
+	s.bytes.Add(uint64(bytes))
+
+	for {
+		max := s.maxFrames.Load()
+		if uint64(frames) <= max || s.maxFrames.CompareAndSwap(max, uint64(frames)) {
+			return
+		}
+	}
+}
+
+// 获取统计快照
+func (s *batchStat) snapshot() *cluster.BatchStat {
+	return &cluster.BatchStat{
+		Batches:   s.batches.Load(),
+		Frames:    s.frames.Load(),
+		Bytes:     s.bytes.Load(),
+		MaxFrames: s.maxFrames.Load(),
+	}
+}
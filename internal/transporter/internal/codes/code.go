@@ -8,6 +8,10 @@ const (
 	OK              uint16 = iota // 成功
 	NotFoundSession               // 未找到会话连接
 	InternalError                 // 内部错误
+	NotFoundService               // 未找到服务
+	NotFoundMethod                // 未找到服务方法
+	ServiceError                  // 服务方法返回错误
+	Unauthorized                  // 鉴权失败
 )
 
 // ErrorToCode 错误转错误码
@@ -17,6 +21,12 @@ func ErrorToCode(err error) uint16 {
 		return OK
 	case errors.Is(err, errors.ErrNotFoundSession):
 		return NotFoundSession
+	case errors.Is(err, errors.ErrNotFoundService):
+		return NotFoundService
+	case errors.Is(err, errors.ErrNotFoundMethod):
+		return NotFoundMethod
+	case errors.Is(err, errors.ErrUnauthorized):
+		return Unauthorized
 	default:
 		return InternalError
 	}
@@ -28,6 +38,12 @@ func CodeToError(code uint16) error {
 		return nil
 	case NotFoundSession:
 		return errors.ErrNotFoundSession
+	case NotFoundService:
+		return errors.ErrNotFoundService
+	case NotFoundMethod:
+		return errors.ErrNotFoundMethod
+	case Unauthorized:
+		return errors.ErrUnauthorized
 	default:
 		return errors.ErrUnknownError
 	}
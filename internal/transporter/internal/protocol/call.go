@@ -0,0 +1,166 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/internal/transporter/internal/route"
+)
+
+const (
+	callReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b64 + b8 + b8 + b8
+	callResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
+)
+
+// EncodeCallReq 编码调用微服务请求
+// 协议：size + header + route + seq + timeout + service len + service + method len + method + metadata num + [key len + key + value len + value]... + <args>
+func EncodeCallReq(seq uint64, timeout int64, service, method string, metadata map[string]string, args []byte) (*buffer.NocopyBuffer, error) {
+	if len(service) > math.MaxUint8 || len(method) > math.MaxUint8 || len(metadata) > math.MaxUint8 {
+		return nil, errors.ErrInvalidMessage
+	}
+
+	size := callReqBytes + len(service) + len(method)
+	for k, v := range metadata {
+		if len(k) > math.MaxUint8 || len(v) > math.MaxUint16 {
+			return nil, errors.ErrInvalidMetadata
+		}
+
+		size += b8 + len(k) + b16 + len(v)
+	}
+
+	writer := buffer.MallocWriter(size)
+	writer.WriteUint32s(binary.BigEndian, uint32(size-defaultSizeBytes+len(args)))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Call)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteInt64s(binary.BigEndian, timeout)
+	writer.WriteUint8s(uint8(len(service)))
+	writer.WriteString(service)
+	writer.WriteUint8s(uint8(len(method)))
+	writer.WriteString(method)
+	writer.WriteUint8s(uint8(len(metadata)))
+
+	for k, v := range metadata {
+		writer.WriteUint8s(uint8(len(k)))
+		writer.WriteString(k)
+		writer.WriteUint16s(binary.BigEndian, uint16(len(v)))
+		writer.WriteString(v)
+	}
+
+	return buffer.NewNocopyBuffer(writer, args), nil
+}
+
+// DecodeCallReq 解码调用微服务请求
+// 协议：size + header + route + seq + timeout + service len + service + method len + method + metadata num + [key len + key + value len + value]... + <args>
+func DecodeCallReq(data []byte) (seq uint64, timeout int64, service, method string, metadata map[string]string, args []byte, err error) {
+	if len(data) < callReqBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if seq, err = reader.ReadUint64(binary.BigEndian); err != nil {
+		return
+	}
+
+	if timeout, err = reader.ReadInt64(binary.BigEndian); err != nil {
+		return
+	}
+
+	if service, err = readString8(reader); err != nil {
+		return
+	}
+
+	if method, err = readString8(reader); err != nil {
+		return
+	}
+
+	var num uint8
+	if num, err = reader.ReadUint8(); err != nil {
+		return
+	}
+
+	metadata = make(map[string]string, num)
+
+	for range num {
+		var (
+			k string
+			n uint16
+			v string
+		)
+
+		if k, err = readString8(reader); err != nil {
+			return
+		}
+
+		if n, err = reader.ReadUint16(binary.BigEndian); err != nil {
+			return
+		}
+
+		if v, err = reader.ReadString(int(n)); err != nil {
+			return
+		}
+
+		metadata[k] = v
+	}
+
+	offset, _ := reader.Seek(0, io.SeekCurrent)
+
+	args = data[offset:]
+
+	return
+}
+
+// EncodeCallRes 编码调用微服务响应
+// 协议：size + header + route + seq + code + <reply or error message>
+func EncodeCallRes(seq uint64, code uint16, reply []byte) *buffer.NocopyBuffer {
+	writer := buffer.MallocWriter(callResBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(callResBytes-defaultSizeBytes+len(reply)))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Call)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint16s(binary.BigEndian, code)
+
+	return buffer.NewNocopyBuffer(writer, reply)
+}
+
+// DecodeCallRes 解码调用微服务响应
+// 协议：size + header + route + seq + code + <reply or error message>
+func DecodeCallRes(data []byte) (code uint16, reply []byte, err error) {
+	if len(data) < callResBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if code, err = reader.ReadUint16(binary.BigEndian); err != nil {
+		return
+	}
+
+	reply = data[callResBytes:]
+
+	return
+}
+
+// 读取以单字节长度为前缀的字符串
+func readString8(reader *buffer.Reader) (string, error) {
+	n, err := reader.ReadUint8()
+	if err != nil {
+		return "", err
+	}
+
+	return reader.ReadString(int(n))
+}
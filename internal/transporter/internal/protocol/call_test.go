@@ -0,0 +1,51 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/devagame/due/v2/internal/transporter/internal/codes"
+	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
+)
+
+func TestEncodeCallReq(t *testing.T) {
+	buffer, err := protocol.EncodeCallReq(1, 3000, "greeter", "Hello", map[string]string{"user": "1"}, []byte("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log(buffer.Bytes())
+}
+
+func TestDecodeCallReq(t *testing.T) {
+	buffer, err := protocol.EncodeCallReq(1, 3000, "greeter", "Hello", map[string]string{"user": "1"}, []byte("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seq, timeout, service, method, metadata, args, err := protocol.DecodeCallReq(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || timeout != 3000 || service != "greeter" || method != "Hello" || metadata["user"] != "1" || string(args) != "hello world" {
+		t.Fatalf("unexpected request: %v %v %v %v %v %v", seq, timeout, service, method, metadata, string(args))
+	}
+}
+
+func TestEncodeCallRes(t *testing.T) {
+	buffer := protocol.EncodeCallRes(1, codes.OK, []byte("hello world"))
+
+	t.Log(buffer.Bytes())
+}
+
+func TestDecodeCallRes(t *testing.T) {
+	buffer := protocol.EncodeCallRes(1, codes.OK, []byte("hello world"))
+
+	code, reply, err := protocol.DecodeCallRes(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("code: %v", code)
+	t.Logf("reply: %v", string(reply))
+}
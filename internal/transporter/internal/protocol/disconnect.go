@@ -16,10 +16,17 @@ const (
 )
 
 // EncodeDisconnectReq 编码断连请求
-// 协议：size + header + route + seq + session kind + target + force
-func EncodeDisconnectReq(seq uint64, kind session.Kind, target int64, force bool) *buffer.NocopyBuffer {
-	writer := buffer.MallocWriter(disconnectReqBytes)
-	writer.WriteUint32s(binary.BigEndian, uint32(disconnectReqBytes-defaultSizeBytes))
+// 协议：size + header + route + seq + session kind + target + force + [close code + close message]
+// 关闭原因码为0时不编码关闭原因，以兼容旧版本网关
+func EncodeDisconnectReq(seq uint64, kind session.Kind, target int64, force bool, code uint16, message string) *buffer.NocopyBuffer {
+	size := disconnectReqBytes
+
+	if code != 0 {
+		size += b16 + len([]byte(message))
+	}
+
+	writer := buffer.MallocWriter(size)
+	writer.WriteUint32s(binary.BigEndian, uint32(size-defaultSizeBytes))
 	writer.WriteUint8s(dataBit)
 	writer.WriteUint8s(route.Disconnect)
 	writer.WriteUint64s(binary.BigEndian, seq)
@@ -27,13 +34,18 @@ func EncodeDisconnectReq(seq uint64, kind session.Kind, target int64, force bool
 	writer.WriteInt64s(binary.BigEndian, target)
 	writer.WriteBools(force)
 
+	if code != 0 {
+		writer.WriteUint16s(binary.BigEndian, code)
+		writer.WriteString(message)
+	}
+
 	return buffer.NewNocopyBuffer(writer)
 }
 
 // DecodeDisconnectReq 解码端连请求
-// 协议：size + header + route + seq + session kind + target + force
-func DecodeDisconnectReq(data []byte) (seq uint64, kind session.Kind, target int64, force bool, err error) {
-	if len(data) != disconnectReqBytes {
+// 协议：size + header + route + seq + session kind + target + force + [close code + close message]
+func DecodeDisconnectReq(data []byte) (seq uint64, kind session.Kind, target int64, force bool, code uint16, message string, err error) {
+	if len(data) != disconnectReqBytes && len(data) < disconnectReqBytes+b16 {
 		err = errors.ErrInvalidMessage
 		return
 	}
@@ -63,6 +75,16 @@ func DecodeDisconnectReq(data []byte) (seq uint64, kind session.Kind, target int
 		return
 	}
 
+	if len(data) == disconnectReqBytes {
+		return
+	}
+
+	if code, err = reader.ReadUint16(binary.BigEndian); err != nil {
+		return
+	}
+
+	message = string(data[disconnectReqBytes+b16:])
+
 	return
 }
 
@@ -8,15 +8,15 @@ import (
 )
 
 func TestEncodeDisconnectReq(t *testing.T) {
-	buffer := protocol.EncodeDisconnectReq(1, session.User, 3, true)
+	buffer := protocol.EncodeDisconnectReq(1, session.User, 3, true, 0, "")
 
 	t.Log(buffer.Bytes())
 }
 
 func TestDecodeDisconnectReq(t *testing.T) {
-	buffer := protocol.EncodeDisconnectReq(1, session.User, 3, false)
+	buffer := protocol.EncodeDisconnectReq(1, session.User, 3, false, 2, "kicked")
 
-	seq, kind, target, force, err := protocol.DecodeDisconnectReq(buffer.Bytes())
+	seq, kind, target, force, code, message, err := protocol.DecodeDisconnectReq(buffer.Bytes())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -25,6 +25,8 @@ func TestDecodeDisconnectReq(t *testing.T) {
 	t.Logf("kind: %v", kind)
 	t.Logf("target: %v", target)
 	t.Logf("force: %v", force)
+	t.Logf("code: %v", code)
+	t.Logf("message: %v", message)
 }
 
 func TestEncodeDisconnectRes(t *testing.T) {
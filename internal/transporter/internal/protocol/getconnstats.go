@@ -0,0 +1,133 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/internal/transporter/internal/codes"
+	"github.com/devagame/due/v2/internal/transporter/internal/route"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/session"
+)
+
+const (
+	getConnStatsReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b8 + b64
+	getConnStatsResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes + 6*b64
+)
+
+// EncodeGetConnStatsReq 编码获取连接流量统计请求
+// 协议：size + header + route + seq + session kind + target
+func EncodeGetConnStatsReq(seq uint64, kind session.Kind, target int64) *buffer.NocopyBuffer {
+	writer := buffer.MallocWriter(getConnStatsReqBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(getConnStatsReqBytes-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.GetConnStats)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint8s(uint8(kind))
+	writer.WriteInt64s(binary.BigEndian, target)
+
+	return buffer.NewNocopyBuffer(writer)
+}
+
+// DecodeGetConnStatsReq 解码获取连接流量统计请求
+// 协议：size + header + route + seq + session kind + target
+func DecodeGetConnStatsReq(data []byte) (seq uint64, kind session.Kind, target int64, err error) {
+	if len(data) != getConnStatsReqBytes {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if seq, err = reader.ReadUint64(binary.BigEndian); err != nil {
+		return
+	}
+
+	var k uint8
+	if k, err = reader.ReadUint8(); err != nil {
+		return
+	} else {
+		kind = session.Kind(k)
+	}
+
+	if target, err = reader.ReadInt64(binary.BigEndian); err != nil {
+		return
+	}
+
+	return
+}
+
+// EncodeGetConnStatsRes 编码获取连接流量统计响应
+// 协议：size + header + route + seq + code + [bytes in + bytes out + messages in + messages out + last active + rtt]
+func EncodeGetConnStatsRes(seq uint64, code uint16, stat ...*network.ConnStat) *buffer.NocopyBuffer {
+	size := getConnStatsResBytes - defaultSizeBytes
+	if code != codes.OK || len(stat) == 0 || stat[0] == nil {
+		size -= 6 * b64
+	}
+
+	writer := buffer.MallocWriter(getConnStatsResBytes)
+	writer.WriteUint32s(binary.BigEndian, uint32(size))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.GetConnStats)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint16s(binary.BigEndian, code)
+
+	if code == codes.OK && len(stat) > 0 && stat[0] != nil {
+		writer.WriteInt64s(binary.BigEndian,
+			stat[0].BytesIn,
+			stat[0].BytesOut,
+			stat[0].MessagesIn,
+			stat[0].MessagesOut,
+			stat[0].LastActive.UnixNano(),
+			int64(stat[0].RTT),
+		)
+	}
+
+	return buffer.NewNocopyBuffer(writer)
+}
+
+// DecodeGetConnStatsRes 解码获取连接流量统计响应
+// 协议：size + header + route + seq + code + [bytes in + bytes out + messages in + messages out + last active + rtt]
+func DecodeGetConnStatsRes(data []byte) (code uint16, stat *network.ConnStat, err error) {
+	if len(data) != getConnStatsResBytes && len(data) != getConnStatsResBytes-6*b64 {
+		err = errors.ErrInvalidMessage
+		return
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err = reader.Seek(defaultSizeBytes+defaultHeaderBytes+defaultRouteBytes+defaultSeqBytes, io.SeekStart); err != nil {
+		return
+	}
+
+	if code, err = reader.ReadUint16(binary.BigEndian); err != nil {
+		return
+	}
+
+	if code != codes.OK || len(data) != getConnStatsResBytes {
+		return
+	}
+
+	var values []int64
+	if values, err = reader.ReadInt64s(binary.BigEndian, 6); err != nil {
+		return
+	}
+
+	stat = &network.ConnStat{
+		BytesIn:     values[0],
+		BytesOut:    values[1],
+		MessagesIn:  values[2],
+		MessagesOut: values[3],
+		LastActive:  time.Unix(0, values[4]),
+		RTT:         time.Duration(values[5]),
+	}
+
+	return
+}
@@ -0,0 +1,65 @@
+package protocol_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/internal/transporter/internal/codes"
+	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/session"
+)
+
+func TestEncodeGetConnStatsReq(t *testing.T) {
+	buffer := protocol.EncodeGetConnStatsReq(1, session.User, 3)
+
+	t.Log(buffer.Bytes())
+}
+
+func TestDecodeGetConnStatsReq(t *testing.T) {
+	buffer := protocol.EncodeGetConnStatsReq(1, session.User, 3)
+
+	seq, kind, target, err := protocol.DecodeGetConnStatsReq(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("seq: %v", seq)
+	t.Logf("kind: %v", kind)
+	t.Logf("target: %v", target)
+}
+
+func TestEncodeGetConnStatsRes(t *testing.T) {
+	buffer := protocol.EncodeGetConnStatsRes(1, codes.OK, &network.ConnStat{BytesIn: 100, RTT: time.Millisecond})
+
+	t.Log(buffer.Bytes())
+}
+
+func TestDecodeGetConnStatsRes(t *testing.T) {
+	buffer := protocol.EncodeGetConnStatsRes(1, codes.OK, &network.ConnStat{
+		BytesIn:     100,
+		BytesOut:    200,
+		MessagesIn:  3,
+		MessagesOut: 4,
+		LastActive:  time.Now(),
+		RTT:         time.Millisecond,
+	})
+
+	code, stat, err := protocol.DecodeGetConnStatsRes(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stat.BytesOut != 200 || stat.RTT != time.Millisecond {
+		t.Fatalf("unexpected stat: %+v", stat)
+	}
+
+	t.Logf("code: %v", code)
+	t.Logf("stat: %+v", stat)
+
+	buffer = protocol.EncodeGetConnStatsRes(1, codes.NotFoundSession)
+
+	if code, stat, err = protocol.DecodeGetConnStatsRes(buffer.Bytes()); err != nil || code != codes.NotFoundSession || stat != nil {
+		t.Fatalf("code: %v, stat: %v, err: %v", code, stat, err)
+	}
+}
@@ -1,6 +1,9 @@
 package protocol
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/binary"
 	"io"
 
@@ -8,13 +11,31 @@ import (
 	"github.com/devagame/due/v2/core/buffer"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/internal/transporter/internal/route"
+	"github.com/devagame/due/v2/utils/xtime"
 )
 
 const (
-	handshakeReqBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b8
-	handshakeResBytes = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
+	handshakeAuthBit   uint8 = 1 << 7                                 // 握手鉴权标识位，设置在实例类型字节上
+	handshakeNonceSize       = 16                                     // 握手随机数字节数
+	handshakeAuthBytes       = b64 + handshakeNonceSize + sha256.Size // 握手鉴权信息字节数
+	handshakeReqBytes        = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + b8
+	handshakeResBytes        = defaultSizeBytes + defaultHeaderBytes + defaultRouteBytes + defaultSeqBytes + defaultCodeBytes
 )
 
+// HandshakeAuth 握手鉴权信息
+type HandshakeAuth struct {
+	Timestamp int64                    // 签名时间戳（毫秒）
+	Nonce     [handshakeNonceSize]byte // 随机数
+	Signature [sha256.Size]byte        // HMAC-SHA256签名
+}
+
+// Verify 校验握手签名
+func (a *HandshakeAuth) Verify(secret string, insKind cluster.Kind, insID string) bool {
+	signature := signHandshake(secret, insKind, insID, a.Timestamp, a.Nonce[:])
+
+	return hmac.Equal(signature, a.Signature[:])
+}
+
 // EncodeHandshakeReq 编码握手请求
 // 协议：size + header + route + seq + ins kind + ins id
 func EncodeHandshakeReq(seq uint64, insKind cluster.Kind, insID string) *buffer.NocopyBuffer {
@@ -31,6 +52,31 @@ func EncodeHandshakeReq(seq uint64, insKind cluster.Kind, insID string) *buffer.
 	return buffer.NewNocopyBuffer(writer)
 }
 
+// EncodeAuthHandshakeReq 编码携带鉴权信息的握手请求
+// 协议：size + header + route + seq + ins kind(auth bit) + ins id + timestamp + nonce + signature
+func EncodeAuthHandshakeReq(seq uint64, insKind cluster.Kind, insID string, secret string) (*buffer.NocopyBuffer, error) {
+	nonce := make([]byte, handshakeNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	timestamp := xtime.Now().UnixMilli()
+	size := handshakeReqBytes + len(insID) + handshakeAuthBytes
+
+	writer := buffer.MallocWriter(size)
+	writer.WriteUint32s(binary.BigEndian, uint32(size-defaultSizeBytes))
+	writer.WriteUint8s(dataBit)
+	writer.WriteUint8s(route.Handshake)
+	writer.WriteUint64s(binary.BigEndian, seq)
+	writer.WriteUint8s(uint8(insKind) | handshakeAuthBit)
+	writer.WriteString(insID)
+	writer.WriteInt64s(binary.BigEndian, timestamp)
+	writer.WriteBytes(nonce...)
+	writer.WriteBytes(signHandshake(secret, insKind, insID, timestamp, nonce)...)
+
+	return buffer.NewNocopyBuffer(writer), nil
+}
+
 // DecodeHandshakeReq 解码握手请求
 // 协议：size + header + route + seq + ins kind + ins id
 func DecodeHandshakeReq(data []byte) (seq uint64, insKind cluster.Kind, insID string, err error) {
@@ -48,16 +94,81 @@ func DecodeHandshakeReq(data []byte) (seq uint64, insKind cluster.Kind, insID st
 	if k, err = reader.ReadUint8(); err != nil {
 		return
 	} else {
-		insKind = cluster.Kind(k)
+		insKind = cluster.Kind(k &^ handshakeAuthBit)
+	}
+
+	size := len(data) - handshakeReqBytes
+	if k&handshakeAuthBit != 0 {
+		size -= handshakeAuthBytes
+	}
+
+	if size < 0 {
+		err = errors.ErrInvalidMessage
+		return
 	}
 
-	if insID, err = reader.ReadString(len(data) - handshakeReqBytes); err != nil {
+	if insID, err = reader.ReadString(size); err != nil {
 		return
 	}
 
 	return
 }
 
+// DecodeHandshakeAuth 解码握手请求中的鉴权信息，未携带鉴权信息时返回nil
+// 协议：size + header + route + seq + ins kind(auth bit) + ins id + timestamp + nonce + signature
+func DecodeHandshakeAuth(data []byte) (*HandshakeAuth, error) {
+	if len(data) < handshakeReqBytes {
+		return nil, errors.ErrInvalidMessage
+	}
+
+	if data[handshakeReqBytes-b8]&handshakeAuthBit == 0 {
+		return nil, nil
+	}
+
+	if len(data) < handshakeReqBytes+handshakeAuthBytes {
+		return nil, errors.ErrInvalidMessage
+	}
+
+	reader := buffer.NewReader(data)
+
+	if _, err := reader.Seek(-handshakeAuthBytes, io.SeekEnd); err != nil {
+		return nil, err
+	}
+
+	auth := &HandshakeAuth{}
+
+	timestamp, err := reader.ReadInt64(binary.BigEndian)
+	if err != nil {
+		return nil, err
+	}
+	auth.Timestamp = timestamp
+
+	nonce, err := reader.ReadBytes(handshakeNonceSize)
+	if err != nil {
+		return nil, err
+	}
+	copy(auth.Nonce[:], nonce)
+
+	signature, err := reader.ReadBytes(sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	copy(auth.Signature[:], signature)
+
+	return auth, nil
+}
+
+// 计算握手签名
+func signHandshake(secret string, insKind cluster.Kind, insID string, timestamp int64, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte{uint8(insKind)})
+	mac.Write([]byte(insID))
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(timestamp)))
+	mac.Write(nonce)
+
+	return mac.Sum(nil)
+}
+
 // EncodeHandshakeRes 编码握手响应
 // 协议：size + header + route + seq + code
 func EncodeHandshakeRes(seq uint64, code uint16) *buffer.NocopyBuffer {
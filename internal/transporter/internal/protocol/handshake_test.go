@@ -43,3 +43,38 @@ func TestDecodeHandshakeRes(t *testing.T) {
 
 	t.Logf("code: %v", code)
 }
+
+func TestDecodeHandshakeAuth(t *testing.T) {
+	insID := xuuid.UUID()
+
+	buffer, err := protocol.EncodeAuthHandshakeReq(1, cluster.Gate, insID, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seq, insKind, id, err := protocol.DecodeHandshakeReq(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq != 1 || insKind != cluster.Gate || id != insID {
+		t.Fatalf("unexpected handshake: seq = %d kind = %v id = %s", seq, insKind, id)
+	}
+
+	auth, err := protocol.DecodeHandshakeAuth(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if auth == nil || !auth.Verify("secret", insKind, id) {
+		t.Fatal("handshake auth verify failed")
+	}
+
+	if auth.Verify("other", insKind, id) || auth.Verify("secret", cluster.Node, id) {
+		t.Fatal("handshake auth verify should fail")
+	}
+
+	if auth, err = protocol.DecodeHandshakeAuth(protocol.EncodeHandshakeReq(1, cluster.Gate, insID).Bytes()); err != nil || auth != nil {
+		t.Fatalf("unexpected auth: %v %v", auth, err)
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/internal/transporter/internal/def"
 	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
+	xroute "github.com/devagame/due/v2/internal/transporter/internal/route"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/utils/xtime"
 )
 
 type chWrite struct {
 	isHeartbeat bool
+	isClose     bool // 写入后关闭连接
 	buf         *buffer.NocopyBuffer
 }
 
@@ -28,6 +30,7 @@ type Conn struct {
 	state             int32              // 连接状态
 	chWrite           chan chWrite       // 写入通道
 	lastHeartbeatTime int64              // 上次心跳时间
+	authorized        atomic.Bool        // 是否已通过握手
 	InsKind           cluster.Kind       // 集群类型
 	InsID             string             // 集群ID
 }
@@ -59,6 +62,17 @@ func (c *Conn) Send(buf *buffer.NocopyBuffer) error {
 	return nil
 }
 
+// 拒绝连接，发送消息后关闭连接
+func (c *Conn) reject(buf *buffer.NocopyBuffer) error {
+	if atomic.LoadInt32(&c.state) == def.ConnClosed {
+		return errors.ErrConnectionClosed
+	}
+
+	c.chWrite <- chWrite{buf: buf, isClose: true}
+
+	return nil
+}
+
 // 关闭连接
 func (c *Conn) close(isNeedRecycle ...bool) error {
 	if !atomic.CompareAndSwapInt32(&c.state, def.ConnOpened, def.ConnClosed) {
@@ -104,6 +118,12 @@ func (c *Conn) read() {
 			if isHeartbeat {
 				c.chWrite <- chWrite{isHeartbeat: true}
 			} else {
+				if c.server.opts.Secret != "" && !c.authorized.Load() && route != xroute.Handshake {
+					log.Warnf("unauthorized link connection from %s", conn.RemoteAddr())
+					_ = c.close(true)
+					return
+				}
+
 				handler, ok := c.server.handlers[route]
 				if !ok {
 					continue
@@ -157,6 +177,11 @@ func (c *Conn) write() {
 				if !ok {
 					return
 				}
+
+				if ch.isClose {
+					_ = c.close(true)
+					return
+				}
 			}
 		}
 	}
@@ -1,6 +1,10 @@
 package server
 
+import "crypto/tls"
+
 type Options struct {
-	Addr   string // 监听地址
-	Expose bool   // 是否暴露公网IP
+	Addr      string      // 监听地址
+	Expose    bool        // 是否暴露公网IP
+	TLSConfig *tls.Config // TLS配置，为空时使用明文TCP通信
+	Secret    string      // 握手鉴权秘钥，为空时不进行握手鉴权
 }
@@ -1,23 +1,32 @@
 package server
 
 import (
+	"crypto/tls"
 	"net"
 	"sync"
 	"time"
 
+	"github.com/devagame/due/v2/cluster"
 	"github.com/devagame/due/v2/core/endpoint"
 	xnet "github.com/devagame/due/v2/core/net"
+	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/internal/transporter/internal/codes"
 	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
 	"github.com/devagame/due/v2/internal/transporter/internal/route"
 	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/utils/xtime"
 )
 
 const scheme = "drpc"
 
+const (
+	handshakeSkew = 30 * time.Second // 握手签名时间戳允许的最大偏差
+)
+
 type RouteHandler func(conn *Conn, data []byte) error
 
 type Server struct {
+	opts        *Options               // 配置项
 	listener    net.Listener           // 监听器
 	listenAddr  string                 // 监听地址
 	exposeAddr  string                 // 暴露地址
@@ -25,6 +34,8 @@ type Server struct {
 	handlers    map[uint8]RouteHandler // 路由处理器
 	rw          sync.RWMutex           // 锁
 	connections map[net.Conn]*Conn     // 连接
+	nmu         sync.Mutex             // 握手随机数锁
+	nonces      map[[16]byte]int64     // 握手随机数，用于防止握手请求重放
 }
 
 func NewServer(opts *Options) (*Server, error) {
@@ -34,10 +45,12 @@ func NewServer(opts *Options) (*Server, error) {
 	}
 
 	s := &Server{}
+	s.opts = opts
 	s.listenAddr = listenAddr
 	s.exposeAddr = exposeAddr
-	s.endpoint = endpoint.NewEndpoint(scheme, exposeAddr, false)
+	s.endpoint = endpoint.NewEndpoint(scheme, exposeAddr, opts.TLSConfig != nil)
 	s.connections = make(map[net.Conn]*Conn)
+	s.nonces = make(map[[16]byte]int64)
 	s.handlers = make(map[uint8]RouteHandler)
 	s.handlers[route.Handshake] = s.handshake
 
@@ -76,7 +89,11 @@ func (s *Server) Start() error {
 		return err
 	}
 
-	s.listener = ln
+	if s.opts.TLSConfig != nil {
+		s.listener = tls.NewListener(ln, s.opts.TLSConfig)
+	} else {
+		s.listener = ln
+	}
 
 	var tempDelay time.Duration
 
@@ -151,8 +168,53 @@ func (s *Server) handshake(conn *Conn, data []byte) error {
 		return err
 	}
 
+	if err = s.authenticate(data, insKind, insID); err != nil {
+		log.Warnf("link handshake from %s rejected: %v", conn.conn.RemoteAddr(), err)
+
+		return conn.reject(protocol.EncodeHandshakeRes(seq, codes.ErrorToCode(err)))
+	}
+
 	conn.InsID = insID
 	conn.InsKind = insKind
+	conn.authorized.Store(true)
 
 	return conn.Send(protocol.EncodeHandshakeRes(seq, codes.ErrorToCode(err)))
 }
+
+// 校验握手鉴权信息
+func (s *Server) authenticate(data []byte, insKind cluster.Kind, insID string) error {
+	if s.opts.Secret == "" {
+		return nil
+	}
+
+	auth, err := protocol.DecodeHandshakeAuth(data)
+	if err != nil {
+		return err
+	}
+
+	if auth == nil || !auth.Verify(s.opts.Secret, insKind, insID) {
+		return errors.ErrUnauthorized
+	}
+
+	now := xtime.Now().UnixMilli()
+	if skew := now - auth.Timestamp; skew > handshakeSkew.Milliseconds() || skew < -handshakeSkew.Milliseconds() {
+		return errors.ErrUnauthorized
+	}
+
+	s.nmu.Lock()
+	defer s.nmu.Unlock()
+
+	if _, ok := s.nonces[auth.Nonce]; ok {
+		return errors.ErrUnauthorized
+	}
+
+	for nonce, timestamp := range s.nonces {
+		if now-timestamp > 2*handshakeSkew.Milliseconds() {
+			delete(s.nonces, nonce)
+		}
+	}
+
+	s.nonces[auth.Nonce] = auth.Timestamp
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package mesh
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/internal/transporter/internal/client"
+	"github.com/devagame/due/v2/internal/transporter/internal/codes"
+	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
+)
+
+type Client struct {
+	seq uint64
+	cli *client.Client
+}
+
+func NewClient(cli *client.Client) *Client {
+	return &Client{
+		cli: cli,
+	}
+}
+
+// Call 调用微服务方法，上下文的截止时间将传递到服务端
+func (c *Client) Call(ctx context.Context, service, method string, metadata map[string]string, args []byte) ([]byte, error) {
+	var timeout int64
+	if deadline, ok := ctx.Deadline(); ok {
+		if timeout = time.Until(deadline).Milliseconds(); timeout <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+	}
+
+	seq := c.doGenSequence()
+
+	buf, err := protocol.EncodeCallReq(seq, timeout, service, method, metadata, args)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.cli.Call(ctx, seq, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	code, reply, err := protocol.DecodeCallRes(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == codes.ServiceError {
+		return nil, errors.New(string(reply))
+	}
+
+	if err = codes.CodeToError(code); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// 生成序列号，规避生成序列号为0的编号
+func (c *Client) doGenSequence() (seq uint64) {
+	for {
+		if seq = atomic.AddUint64(&c.seq, 1); seq != 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package mesh
+
+import "context"
+
+type Provider interface {
+	// Call 调用微服务方法
+	Call(ctx context.Context, service, method string, metadata map[string]string, args []byte) ([]byte, error)
+}
@@ -0,0 +1,72 @@
+package mesh
+
+import (
+	"context"
+	"time"
+
+	"github.com/devagame/due/v2/internal/transporter/internal/codes"
+	"github.com/devagame/due/v2/internal/transporter/internal/protocol"
+	"github.com/devagame/due/v2/internal/transporter/internal/route"
+	"github.com/devagame/due/v2/internal/transporter/internal/server"
+	"github.com/devagame/due/v2/log"
+)
+
+type Server struct {
+	*server.Server
+	provider Provider
+}
+
+type ServerOptions = server.Options
+
+func NewServer(provider Provider, opts *ServerOptions) (*Server, error) {
+	serv, err := server.NewServer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{Server: serv, provider: provider}
+	s.init()
+
+	return s, nil
+}
+
+func (s *Server) init() {
+	s.RegisterHandler(route.Call, s.call)
+}
+
+// 调用微服务，在独立协程中处理以避免阻塞连接读取
+func (s *Server) call(conn *server.Conn, data []byte) error {
+	seq, timeout, service, method, metadata, args, err := protocol.DecodeCallReq(data)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				log.Errorf("mesh service %s.%s panic: %v", service, method, e)
+				_ = conn.Send(protocol.EncodeCallRes(seq, codes.InternalError, nil))
+			}
+		}()
+
+		ctx, cancel := context.Background(), context.CancelFunc(nil)
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+			defer cancel()
+		}
+
+		reply, err := s.provider.Call(ctx, service, method, metadata, args)
+		if err == nil {
+			_ = conn.Send(protocol.EncodeCallRes(seq, codes.OK, reply))
+			return
+		}
+
+		if code := codes.ErrorToCode(err); code != codes.InternalError {
+			_ = conn.Send(protocol.EncodeCallRes(seq, code, nil))
+		} else {
+			_ = conn.Send(protocol.EncodeCallRes(seq, codes.ServiceError, []byte(err.Error())))
+		}
+	}()
+
+	return nil
+}
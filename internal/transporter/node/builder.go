@@ -1,6 +1,7 @@
 package node
 
 import (
+	"crypto/tls"
 	"sync"
 	"time"
 
@@ -14,8 +15,10 @@ import (
 const defaultFaultTimeout = 3 * time.Second // 默认故障超时时间
 
 type Options struct {
-	InsID   string       // 实例ID
-	InsKind cluster.Kind // 实例类型
+	InsID     string       // 实例ID
+	InsKind   cluster.Kind // 实例类型
+	TLSConfig *tls.Config  // TLS配置
+	Secret    string       // 握手鉴权秘钥
 }
 
 type Builder struct {
@@ -47,9 +50,11 @@ func (b *Builder) Build(addr string) (*Client, error) {
 		}
 
 		c := client.NewClient(&client.Options{
-			Addr:    addr,
-			InsID:   b.opts.InsID,
-			InsKind: b.opts.InsKind,
+			Addr:      addr,
+			InsID:     b.opts.InsID,
+			InsKind:   b.opts.InsKind,
+			TLSConfig: b.opts.TLSConfig,
+			Secret:    b.opts.Secret,
 			CloseHandler: func() {
 				b.faults.Store(addr, xtime.Now())
 				b.clients.Delete(addr)
@@ -72,3 +77,15 @@ func (b *Builder) Build(addr string) (*Client, error) {
 
 	return cli.(*Client), nil
 }
+
+// Stats 获取各连接地址的合并写入统计
+func (b *Builder) Stats() map[string]*cluster.BatchStat {
+	stats := make(map[string]*cluster.BatchStat)
+
+	b.clients.Range(func(addr, cli any) bool {
+		stats[addr.(string)] = cli.(*Client).cli.Stat()
+		return true
+	})
+
+	return stats
+}
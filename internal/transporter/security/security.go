@@ -0,0 +1,59 @@
+package security
+
+import (
+	"crypto/tls"
+	"sync"
+
+	xtls "github.com/devagame/due/v2/core/tls"
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultSecretKey         = "etc.cluster.link.secret"
+	defaultCertFileKey       = "etc.cluster.link.tls.certFile"
+	defaultKeyFileKey        = "etc.cluster.link.tls.keyFile"
+	defaultCAFileKey         = "etc.cluster.link.tls.caFile"
+	defaultServerNameKey     = "etc.cluster.link.tls.serverName"
+	defaultReloadIntervalKey = "etc.cluster.link.tls.reloadInterval"
+)
+
+// Options 集群内部通信链路的安全配置
+type Options struct {
+	Secret    string      // 握手鉴权秘钥
+	ServerTLS *tls.Config // 服务端TLS配置
+	ClientTLS *tls.Config // 客户端TLS配置
+}
+
+var (
+	once sync.Once
+	opts *Options
+	err  error
+)
+
+// Load 加载集群内部通信链路的安全配置
+// 配置仅在首次调用时加载，证书文件发生变化时自动重新加载，握手鉴权秘钥变更需重启后生效
+func Load() (*Options, error) {
+	once.Do(func() {
+		etc.RestartOnly(defaultSecretKey, defaultCertFileKey, defaultKeyFileKey, defaultCAFileKey, defaultServerNameKey)
+
+		o := &Options{Secret: etc.Get(defaultSecretKey).String()}
+
+		certFile := etc.Get(defaultCertFileKey).String()
+		keyFile := etc.Get(defaultKeyFileKey).String()
+
+		if certFile != "" && keyFile != "" {
+			reloader, e := xtls.NewReloader(certFile, keyFile, etc.Get(defaultCAFileKey).String(), etc.Get(defaultReloadIntervalKey).Duration())
+			if e != nil {
+				err = e
+				return
+			}
+
+			o.ServerTLS = reloader.ServerConfig()
+			o.ClientTLS = reloader.ClientConfig(etc.Get(defaultServerNameKey).String())
+		}
+
+		opts = o
+	})
+
+	return opts, err
+}
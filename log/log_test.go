@@ -3,6 +3,7 @@ package log_test
 import (
 	"testing"
 
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/log"
 )
 
@@ -14,3 +15,38 @@ func TestLog(t *testing.T) {
 	logger.Warn("welcome to due-framework")
 	logger.Error("welcome to due-framework")
 }
+
+type countSyncer struct {
+	count int
+}
+
+func (s *countSyncer) Name() string { return "count" }
+
+func (s *countSyncer) Write(entity *log.Entity) error {
+	s.count++
+	return nil
+}
+
+func (s *countSyncer) Close() error { return nil }
+
+func TestLogger_WithLevel(t *testing.T) {
+	syncer := &countSyncer{}
+
+	logger := log.NewLogger(
+		log.WithLevel(log.LevelError),
+		log.WithSyncers(syncer),
+		log.WithTerminals(log.Terminal(syncer.Name())),
+	)
+	defer logger.Close()
+
+	if err := etc.Set("etc.log.level", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	defer etc.Set("etc.log.level", "info")
+
+	logger.Info("welcome to due-framework")
+
+	if syncer.count != 0 {
+		t.Fatalf("explicit level is overridden by etc, count = %d", syncer.count)
+	}
+}
@@ -9,8 +9,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/devagame/due/v2/core/stack"
+	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/log/console"
 	"github.com/devagame/due/v2/log/file"
 	"github.com/devagame/due/v2/log/internal"
@@ -59,6 +62,8 @@ type terminal struct {
 type defaultLogger struct {
 	opts      *options
 	pool      *sync.Pool
+	level     atomic.Value
+	cancel    func()
 	terminals []*terminal
 }
 
@@ -71,6 +76,15 @@ func NewLogger(opts ...Option) *defaultLogger {
 	l := &defaultLogger{}
 	l.opts = o
 	l.pool = &sync.Pool{New: func() any { return &Entity{} }}
+	l.level.Store(o.level)
+
+	// 输出级别来自etc配置时可在运行时通过etc配置调整，其他配置需重启后生效
+	if o.watchLevel {
+		l.cancel = etc.OnChange(defaultLevelKey, func(val value.Value) {
+			l.level.Store(Level(val.String()))
+		}, defaultLevel)
+	}
+	etc.RestartOnly(defaultTerminalsKey, defaultStackLevelKey, defaultTimeFormatKey, defaultCallSkipKey, defaultCallFullPathKey)
 
 	syncers := make(map[string]Syncer, len(l.opts.syncers))
 	for _, syncer := range l.opts.syncers {
@@ -204,6 +218,10 @@ func (l *defaultLogger) Panicf(format string, a ...any) {
 
 // Close 关闭日志
 func (l *defaultLogger) Close() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
+
 	eg, _ := errgroup.WithContext(context.Background())
 
 	for i := range l.terminals {
@@ -223,7 +241,7 @@ func (l *defaultLogger) print(level Level, isOutStack bool, a ...any) {
 		return
 	}
 
-	if level.Priority() < l.opts.level.Priority() {
+	if level.Priority() < l.level.Load().(Level).Priority() {
 		return
 	}
 
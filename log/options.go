@@ -29,6 +29,7 @@ type Option func(o *options)
 
 type options struct {
 	level        Level    // 输出级别
+	watchLevel   bool     // 是否监听etc中输出级别的变化，显式设置输出级别时不监听
 	syncers      []Syncer // 日志同步器
 	terminals    any      // 输出终端
 	stackLevel   Level    // 输出栈的日志级别
@@ -40,6 +41,7 @@ type options struct {
 func defaultOptions() *options {
 	opts := &options{
 		level:        Level(etc.Get(defaultLevelKey, defaultLevel).String()),
+		watchLevel:   true,
 		terminals:    defaultTerminals,
 		stackLevel:   Level(etc.Get(defaultStackLevelKey, defaultStackLevel).String()),
 		timeFormat:   etc.Get(defaultTimeFormatKey, defaultTimeFormat).String(),
@@ -73,7 +75,7 @@ func defaultOptions() *options {
 
 // WithLevel 设置日志的输出级别
 func WithLevel(level Level) Option {
-	return func(o *options) { o.level = level }
+	return func(o *options) { o.level, o.watchLevel = level, false }
 }
 
 // WithSyncers 设置日志同步器
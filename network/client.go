@@ -19,3 +19,12 @@ type Client interface {
 	// OnDisconnect 监听连接断开
 	OnDisconnect(handler DisconnectHandler)
 }
+
+type ReconnectHandler func(conn Conn)
+
+// ReconnectClient 支持断线重连的客户端
+type ReconnectClient interface {
+	Client
+	// OnReconnect 监听断线重连成功
+	OnReconnect(handler ReconnectHandler)
+}
@@ -0,0 +1,119 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/packet"
+)
+
+const (
+	CloseNone        CloseCode = iota // 无，不下发关闭包
+	CloseNormal                       // 正常关闭
+	CloseKicked                       // 被其他登录顶替
+	CloseMaintenance                  // 服务器维护
+	CloseBanned                       // 账号封禁
+)
+
+// CloseCode 下发给客户端的关闭原因码，业务可在预定义原因码之外自行扩展
+type CloseCode uint16
+
+func (c CloseCode) String() string {
+	switch c {
+	case CloseNone:
+		return "none"
+	case CloseNormal:
+		return "normal"
+	case CloseKicked:
+		return "kicked"
+	case CloseMaintenance:
+		return "maintenance"
+	case CloseBanned:
+		return "banned"
+	}
+
+	return "unknown"
+}
+
+// CodeConn 可获取服务端下发的关闭原因的连接，可在客户端连接断开回调中获取
+type CodeConn interface {
+	// CloseCode 获取服务端下发的关闭原因码及说明信息，未收到关闭包时返回false
+	CloseCode() (CloseCode, string, bool)
+}
+
+// CloseWriteTimeout 强制关闭前写入关闭包的超时时间
+const CloseWriteTimeout = 500 * time.Millisecond
+
+// ForceCloser 可在强制关闭前直接写入消息的连接
+type ForceCloser interface {
+	// ForceClose 跳过写入队列中待发送的消息，在超时时间内尝试直接写入一次消息，无论写入成功与否随后强制关闭连接
+	ForceClose(msg []byte, timeout time.Duration) error
+}
+
+// CloseWithCode 向对端下发携带原因码及说明信息的关闭包后关闭连接
+// 优雅关闭时关闭包作为关键消息推送，排在已推送的消息之后且不会因写入队列溢出被丢弃，待发送的消息及关闭包发送完毕后才会关闭连接
+// 强制关闭时在CloseWriteTimeout内尝试直接写入一次关闭包后立即关闭连接，对端停止读取时关闭包可能无法送达
+// 原因码为CloseNone时不下发关闭包，等同于直接关闭连接
+func CloseWithCode(conn Conn, code CloseCode, message string, force ...bool) error {
+	if code == CloseNone {
+		return conn.Close(force...)
+	}
+
+	msg, err := packet.PackClose(uint16(code), message)
+	if err != nil {
+		return err
+	}
+
+	if len(force) > 0 && force[0] {
+		return ForceClose(conn, msg, CloseWriteTimeout)
+	}
+
+	if err = PushCritical(conn, msg); err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// ForceClose 在超时时间内尝试直接写入一次消息后强制关闭连接
+// 连接未实现ForceCloser时作为关键消息推送后强制关闭连接，消息可能随写入队列一同被丢弃
+func ForceClose(conn Conn, msg []byte, timeout time.Duration) error {
+	if c, ok := conn.(ForceCloser); ok {
+		return c.ForceClose(msg, timeout)
+	}
+
+	_ = PushCritical(conn, msg)
+
+	return conn.Close(true)
+}
+
+// CloseNotice 关闭通知，记录对端下发的关闭原因码及说明信息
+type CloseNotice struct {
+	notice atomic.Pointer[closeNotice]
+}
+
+type closeNotice struct {
+	code    CloseCode
+	message string
+}
+
+// Receive 接收数据包，为关闭包时记录其原因码及说明信息并返回true
+func (n *CloseNotice) Receive(data []byte) bool {
+	code, message, ok := packet.UnpackClose(data)
+	if !ok {
+		return false
+	}
+
+	n.notice.Store(&closeNotice{code: CloseCode(code), message: message})
+
+	return true
+}
+
+// Load 获取关闭原因码及说明信息，未收到关闭包时返回false
+func (n *CloseNotice) Load() (CloseCode, string, bool) {
+	if v := n.notice.Load(); v != nil {
+		return v.code, v.message, true
+	}
+
+	return CloseNone, "", false
+}
@@ -47,6 +47,22 @@ type (
 		RemoteIP() (string, error)
 		// RemoteAddr 获取远端地址
 		RemoteAddr() (net.Addr, error)
+		// Stats 获取连接流量统计
+		Stats() *ConnStat
+	}
+
+	// ProxyConn 经由代理（如启用了PROXY protocol的负载均衡器）接入的连接
+	// 启用代理解析后RemoteAddr返回真实的客户端地址，代理地址可通过ProxyAddr获取
+	ProxyConn interface {
+		// ProxyAddr 获取代理地址，未经代理时与RemoteAddr相同
+		ProxyAddr() (net.Addr, error)
+	}
+
+	// WrappedConn 包装了其他连接的连接（如网关为保证连接ID唯一而包装的连接）
+	// 可通过Unwrap获取被包装的连接，以访问其实现的扩展接口
+	WrappedConn interface {
+		// Unwrap 获取被包装的连接
+		Unwrap() Conn
 	}
 
 	Attr interface {
@@ -60,3 +76,15 @@ type (
 		Visit(fn func(key, value any) bool)
 	}
 )
+
+// Unwrap 逐层获取被包装的原始连接，连接未被包装时返回其自身
+func Unwrap(conn Conn) Conn {
+	for {
+		wc, ok := conn.(WrappedConn)
+		if !ok {
+			return conn
+		}
+
+		conn = wc.Unwrap()
+	}
+}
@@ -0,0 +1,330 @@
+package network
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/core/limiter"
+	"github.com/devagame/due/v2/errors"
+)
+
+const guardSweepInterval = time.Minute // 清理空闲IP记录的间隔时间
+
+// GuardOptions 连接准入配置
+type GuardOptions struct {
+	MaxConnPerIP int      // 单IP最大并发连接数，为0时不限制
+	AcceptRate   float64  // 单IP每秒允许建立的连接数，为0时不限制
+	AcceptBurst  int      // 单IP允许突发建立的连接数，为0时与AcceptRate相同
+	AllowList    []string // 白名单，支持CIDR及单个IP地址，不为空时仅允许白名单内的IP连接
+	DenyList     []string // 黑名单，支持CIDR及单个IP地址，优先级高于白名单
+}
+
+// Guard 连接准入控制器
+// 黑白名单由静态名单与动态名单组成，静态名单来自配置，动态名单可在运行时通过Allow、Deny等方法调整
+type Guard struct {
+	rate         float64            // 单IP每秒允许建立的连接数
+	burst        float64            // 单IP允许突发建立的连接数
+	maxConnPerIP atomic.Int64       // 单IP最大并发连接数
+	allowList    *ipList            // 白名单
+	denyList     *ipList            // 黑名单
+	mu           sync.Mutex         // 互斥锁
+	peers        map[string]*ipPeer // IP连接记录
+	lastSweep    time.Time          // 上次清理时间
+}
+
+type ipPeer struct {
+	conns    int              // 并发连接数
+	limiter  *limiter.Limiter // 建连限流器
+	lastSeen time.Time        // 最后活跃时间
+}
+
+// NewGuard 新建连接准入控制器，黑白名单需通过SetAllowList、SetDenyList设置
+func NewGuard(opts GuardOptions) *Guard {
+	g := &Guard{
+		rate:      opts.AcceptRate,
+		burst:     float64(opts.AcceptBurst),
+		allowList: &ipList{},
+		denyList:  &ipList{},
+		peers:     make(map[string]*ipPeer),
+		lastSweep: time.Now(),
+	}
+
+	if g.burst <= 0 {
+		g.burst = max(g.rate, 1)
+	}
+
+	g.maxConnPerIP.Store(int64(opts.MaxConnPerIP))
+
+	return g
+}
+
+// Admit 连接准入检测，通过后需在连接关闭时调用Release释放
+func (g *Guard) Admit(addr net.Addr) error {
+	ip := AddrIP(addr)
+
+	if g.denyList.contains(ip) {
+		return errors.ErrIPDenied
+	}
+
+	if !g.allowList.empty() && !g.allowList.contains(ip) {
+		return errors.ErrIPNotAllowed
+	}
+
+	if ip == nil {
+		return nil
+	}
+
+	key := ip.String()
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.sweep(now)
+
+	peer, ok := g.peers[key]
+	if !ok {
+		peer = &ipPeer{}
+		g.peers[key] = peer
+	}
+	peer.lastSeen = now
+
+	if n := g.maxConnPerIP.Load(); n > 0 && int64(peer.conns) >= n {
+		return errors.ErrTooManyConnPerIP
+	}
+
+	if g.rate > 0 {
+		if peer.limiter == nil {
+			peer.limiter = limiter.NewLimiter(g.burst, g.rate)
+		}
+
+		if !peer.limiter.Allow() {
+			return errors.ErrAcceptRateLimited
+		}
+	}
+
+	peer.conns++
+
+	return nil
+}
+
+// Release 释放连接
+func (g *Guard) Release(addr net.Addr) {
+	ip := AddrIP(addr)
+	if ip == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if peer, ok := g.peers[ip.String()]; ok && peer.conns > 0 {
+		peer.conns--
+		peer.lastSeen = time.Now()
+	}
+}
+
+// SetMaxConnPerIP 设置单IP最大并发连接数
+func (g *Guard) SetMaxConnPerIP(n int) {
+	g.maxConnPerIP.Store(int64(n))
+}
+
+// SetAllowList 设置静态白名单
+func (g *Guard) SetAllowList(cidrs []string) error {
+	return g.allowList.setStatic(cidrs)
+}
+
+// SetDenyList 设置静态黑名单
+func (g *Guard) SetDenyList(cidrs []string) error {
+	return g.denyList.setStatic(cidrs)
+}
+
+// Allow 添加动态白名单
+func (g *Guard) Allow(cidrs ...string) error {
+	return g.allowList.add(cidrs)
+}
+
+// Disallow 移除动态白名单
+func (g *Guard) Disallow(cidrs ...string) {
+	g.allowList.remove(cidrs)
+}
+
+// Deny 添加动态黑名单
+func (g *Guard) Deny(cidrs ...string) error {
+	return g.denyList.add(cidrs)
+}
+
+// Undeny 移除动态黑名单
+func (g *Guard) Undeny(cidrs ...string) {
+	g.denyList.remove(cidrs)
+}
+
+// 清理空闲的IP记录，空闲时间需足够令牌桶恢复满容量
+func (g *Guard) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < guardSweepInterval {
+		return
+	}
+
+	g.lastSweep = now
+
+	idle := guardSweepInterval
+	if g.rate > 0 {
+		idle = max(idle, time.Duration(g.burst/g.rate*float64(time.Second)))
+	}
+
+	for key, peer := range g.peers {
+		if peer.conns == 0 && now.Sub(peer.lastSeen) >= idle {
+			delete(g.peers, key)
+		}
+	}
+}
+
+type ipList struct {
+	rw      sync.RWMutex
+	static  []*net.IPNet
+	dynamic map[string]*net.IPNet
+}
+
+// 是否为空名单
+func (l *ipList) empty() bool {
+	l.rw.RLock()
+	defer l.rw.RUnlock()
+
+	return len(l.static) == 0 && len(l.dynamic) == 0
+}
+
+// 是否包含IP
+func (l *ipList) contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	l.rw.RLock()
+	defer l.rw.RUnlock()
+
+	for _, n := range l.static {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	for _, n := range l.dynamic {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 设置静态名单
+func (l *ipList) setStatic(cidrs []string) error {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		n, err := ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+
+		nets = append(nets, n)
+	}
+
+	l.rw.Lock()
+	l.static = nets
+	l.rw.Unlock()
+
+	return nil
+}
+
+// 添加动态名单
+func (l *ipList) add(cidrs []string) error {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		n, err := ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+
+		nets = append(nets, n)
+	}
+
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	if l.dynamic == nil {
+		l.dynamic = make(map[string]*net.IPNet, len(nets))
+	}
+
+	for _, n := range nets {
+		l.dynamic[n.String()] = n
+	}
+
+	return nil
+}
+
+// 移除动态名单
+func (l *ipList) remove(cidrs []string) {
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	for _, cidr := range cidrs {
+		if n, err := ParseCIDR(cidr); err == nil {
+			delete(l.dynamic, n.String())
+		}
+	}
+}
+
+// ParseCIDR 解析网段，支持CIDR及单个IP地址
+func ParseCIDR(cidr string) (*net.IPNet, error) {
+	cidr = strings.TrimSpace(cidr)
+
+	if !strings.Contains(cidr, "/") {
+		ip := net.ParseIP(cidr)
+		if ip == nil {
+			return nil, errors.ErrInvalidCIDR
+		}
+
+		if v4 := ip.To4(); v4 != nil {
+			return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, errors.ErrInvalidCIDR
+	}
+
+	return n, nil
+}
+
+// AddrIP 提取地址中的IP，无法提取时返回nil
+func AddrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case nil:
+		return nil
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+
+	return net.ParseIP(host)
+}
+
+// GuardServer 支持连接准入控制的服务器
+type GuardServer interface {
+	// Guard 获取连接准入控制器
+	Guard() *Guard
+}
@@ -0,0 +1,100 @@
+package network_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+)
+
+func TestGuard_Admit(t *testing.T) {
+	guard := network.NewGuard(network.GuardOptions{MaxConnPerIP: 2})
+
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1000}
+
+	for i := 0; i < 2; i++ {
+		if err := guard.Admit(addr); err != nil {
+			t.Fatalf("admit %d failed: %v", i, err)
+		}
+	}
+
+	if err := guard.Admit(addr); !errors.Is(err, errors.ErrTooManyConnPerIP) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrTooManyConnPerIP)
+	}
+
+	if err := guard.Admit(&net.TCPAddr{IP: net.ParseIP("10.0.0.2")}); err != nil {
+		t.Fatalf("admit other ip failed: %v", err)
+	}
+
+	guard.Release(addr)
+
+	if err := guard.Admit(addr); err != nil {
+		t.Fatalf("admit after release failed: %v", err)
+	}
+}
+
+func TestGuard_AcceptRate(t *testing.T) {
+	guard := network.NewGuard(network.GuardOptions{AcceptRate: 0.001, AcceptBurst: 3})
+
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}
+
+	for i := 0; i < 3; i++ {
+		if err := guard.Admit(addr); err != nil {
+			t.Fatalf("admit %d failed: %v", i, err)
+		}
+	}
+
+	if err := guard.Admit(addr); !errors.Is(err, errors.ErrAcceptRateLimited) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrAcceptRateLimited)
+	}
+}
+
+func TestGuard_List(t *testing.T) {
+	guard := network.NewGuard(network.GuardOptions{})
+
+	if err := guard.SetAllowList([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := guard.SetDenyList([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := guard.Deny("invalid"); !errors.Is(err, errors.ErrInvalidCIDR) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrInvalidCIDR)
+	}
+
+	cases := []struct {
+		ip  string
+		err error
+	}{
+		{"10.0.0.1", errors.ErrIPDenied},
+		{"10.0.0.2", nil},
+		{"192.168.0.1", errors.ErrIPNotAllowed},
+	}
+
+	for _, c := range cases {
+		if err := guard.Admit(&net.TCPAddr{IP: net.ParseIP(c.ip)}); !errors.Is(err, c.err) {
+			t.Fatalf("%s: err = %v, want %v", c.ip, err, c.err)
+		}
+	}
+
+	if err := guard.Deny("10.0.0.0/24"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := guard.Admit(&net.TCPAddr{IP: net.ParseIP("10.0.0.2")}); !errors.Is(err, errors.ErrIPDenied) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrIPDenied)
+	}
+
+	guard.Undeny("10.0.0.0/24")
+
+	if err := guard.Allow("192.168.0.0/16"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := guard.Admit(&net.TCPAddr{IP: net.ParseIP("192.168.0.1")}); err != nil {
+		t.Fatalf("admit allowed ip failed: %v", err)
+	}
+}
@@ -22,7 +22,13 @@ func NewClient(opts ...ClientOption) network.Client {
 		opt(o)
 	}
 
-	return &client{opts: o}
+	c := &client{opts: o}
+
+	if o.reconnect.Enable {
+		return network.NewReconnectClient(c, o.reconnect)
+	}
+
+	return c
 }
 
 // Dial 拨号连接
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/network"
 )
 
 const (
@@ -13,31 +14,37 @@ const (
 )
 
 const (
-	defaultClientDialAddrKey          = "etc.network.kcp.client.addr"
-	defaultClientDialTimeoutKey       = "etc.network.kcp.client.timeout"
-	defaultClientHeartbeatIntervalKey = "etc.network.kcp.client.heartbeatInterval"
-	defaultClientMtuKey               = "etc.network.kcp.client.mtu"
-	defaultClientNoDelayKey           = "etc.network.kcp.client.noDelay"
-	defaultClientAckNoDelayKey        = "etc.network.kcp.client.ackNoDelay"
-	defaultClientWriteDelayKey        = "etc.network.kcp.client.writeDelay"
-	defaultClientWindowSizeKey        = "etc.network.kcp.client.windowSize"
-	defaultClientReadBufferKey        = "etc.network.kcp.client.readBuffer"
-	defaultClientWriteBufferKey       = "etc.network.kcp.client.writeBuffer"
+	defaultClientDialAddrKey             = "etc.network.kcp.client.addr"
+	defaultClientDialTimeoutKey          = "etc.network.kcp.client.timeout"
+	defaultClientHeartbeatIntervalKey    = "etc.network.kcp.client.heartbeatInterval"
+	defaultClientMtuKey                  = "etc.network.kcp.client.mtu"
+	defaultClientNoDelayKey              = "etc.network.kcp.client.noDelay"
+	defaultClientAckNoDelayKey           = "etc.network.kcp.client.ackNoDelay"
+	defaultClientWriteDelayKey           = "etc.network.kcp.client.writeDelay"
+	defaultClientWindowSizeKey           = "etc.network.kcp.client.windowSize"
+	defaultClientReadBufferKey           = "etc.network.kcp.client.readBuffer"
+	defaultClientWriteBufferKey          = "etc.network.kcp.client.writeBuffer"
+	defaultClientReconnectEnableKey      = "etc.network.kcp.client.reconnect.enable"
+	defaultClientReconnectMaxAttemptsKey = "etc.network.kcp.client.reconnect.maxAttempts"
+	defaultClientReconnectMinIntervalKey = "etc.network.kcp.client.reconnect.minInterval"
+	defaultClientReconnectMaxIntervalKey = "etc.network.kcp.client.reconnect.maxInterval"
+	defaultClientReconnectJitterKey      = "etc.network.kcp.client.reconnect.jitter"
 )
 
 type ClientOption func(o *clientOptions)
 
 type clientOptions struct {
-	addr              string        // 地址
-	timeout           time.Duration // 拨号超时时间，默认5s
-	heartbeatInterval time.Duration // 心跳间隔时间，默认10s
-	mtu               int           // 最大传输单元，默认不设置
-	noDelay           []int         // 是否开启无延迟模式，默认不设置
-	ackNoDelay        bool          // 是否开启ACK延迟确认，默认不设置
-	writeDelay        bool          // 是否开启写延迟，默认不设置
-	windowSize        []int         // 窗口大小，默认不设置
-	readBuffer        int           // 读取缓冲区大小，默认不设置
-	writeBuffer       int           // 写入缓冲区大小，默认不设置
+	addr              string                   // 地址
+	timeout           time.Duration            // 拨号超时时间，默认5s
+	heartbeatInterval time.Duration            // 心跳间隔时间，默认10s
+	mtu               int                      // 最大传输单元，默认不设置
+	noDelay           []int                    // 是否开启无延迟模式，默认不设置
+	ackNoDelay        bool                     // 是否开启ACK延迟确认，默认不设置
+	writeDelay        bool                     // 是否开启写延迟，默认不设置
+	windowSize        []int                    // 窗口大小，默认不设置
+	readBuffer        int                      // 读取缓冲区大小，默认不设置
+	writeBuffer       int                      // 写入缓冲区大小，默认不设置
+	reconnect         network.ReconnectOptions // 断线重连配置，默认不启用
 }
 
 func defaultClientOptions() *clientOptions {
@@ -52,6 +59,13 @@ func defaultClientOptions() *clientOptions {
 		windowSize:        etc.Get(defaultClientWindowSizeKey).Ints(),
 		readBuffer:        int(etc.Get(defaultClientReadBufferKey).B()),
 		writeBuffer:       int(etc.Get(defaultClientWriteBufferKey).B()),
+		reconnect: network.ReconnectOptions{
+			Enable:      etc.Get(defaultClientReconnectEnableKey).Bool(),
+			MaxAttempts: etc.Get(defaultClientReconnectMaxAttemptsKey).Int(),
+			MinInterval: etc.Get(defaultClientReconnectMinIntervalKey).Duration(),
+			MaxInterval: etc.Get(defaultClientReconnectMaxIntervalKey).Duration(),
+			Jitter:      etc.Get(defaultClientReconnectJitterKey).Float64(),
+		},
 	}
 }
 
@@ -104,3 +118,8 @@ func WithClientReadBuffer(readBuffer int) ClientOption {
 func WithClientWriteBuffer(writeBuffer int) ClientOption {
 	return func(o *clientOptions) { o.writeBuffer = writeBuffer }
 }
+
+// WithClientReconnect 设置断线重连配置
+func WithClientReconnect(reconnect network.ReconnectOptions) ClientOption {
+	return func(o *clientOptions) { o.reconnect = reconnect }
+}
@@ -8,6 +8,7 @@ const (
 )
 
 type chWrite struct {
-	typ int
-	msg []byte
+	typ      int
+	msg      []byte
+	critical bool // 是否为关键消息，写入队列溢出时不会被丢弃
 }
@@ -0,0 +1,175 @@
+package kcp_test
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/network/kcp/v2"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+	kcpgo "github.com/xtaci/kcp-go/v5"
+)
+
+func TestServer_SlowConsumer(t *testing.T) {
+	addr := listenAddr(t)
+
+	server := kcp.NewServer(
+		kcp.WithServerListenAddr(addr),
+		kcp.WithServerHeartbeatInterval(0),
+		kcp.WithServerWriteQueue(network.QueueOptions{MaxMessages: 8, Policy: network.Disconnect}),
+	)
+
+	reasons := make(chan network.CloseReason, 1)
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			msg := make([]byte, 64*1024)
+
+			for i := 0; i < 1000; i++ {
+				if err := conn.Push(msg); err != nil {
+					if !errors.Is(err, errors.ErrSlowConsumer) && !errors.Is(err, errors.ErrConnectionClosed) {
+						t.Errorf("unexpected push error: %v", err)
+					}
+					return
+				}
+			}
+		}()
+	})
+
+	server.OnDisconnect(func(conn network.Conn) {
+		reasons <- conn.(network.ReasonConn).CloseReason()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// 客户端发送心跳建立连接后不读取任何数据
+	client := dial(t, addr)
+	defer client.Close()
+
+	select {
+	case reason := <-reasons:
+		if reason != network.ReasonSlowConsumer {
+			t.Fatalf("close reason = %v, want %v", reason, network.ReasonSlowConsumer)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow consumer not disconnected")
+	}
+
+	if stat := server.(network.QueueStater).QueueStat(); stat.Disconnected != 1 {
+		t.Fatalf("disconnected = %d, want 1", stat.Disconnected)
+	}
+}
+
+func TestServer_WriteQueue(t *testing.T) {
+	for _, policy := range []network.OverflowPolicy{network.DropOldest, network.DropNew} {
+		testWriteQueue(t, policy)
+	}
+}
+
+func testWriteQueue(t *testing.T, policy network.OverflowPolicy) {
+	addr := listenAddr(t)
+
+	server := kcp.NewServer(
+		kcp.WithServerListenAddr(addr),
+		kcp.WithServerHeartbeatInterval(0),
+		kcp.WithServerWriteQueue(network.QueueOptions{MaxMessages: 4, Policy: policy}),
+	)
+
+	marker := []byte("critical message")
+	pushed := make(chan error, 1)
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			msg := bytes.Repeat([]byte{'a'}, 64*1024)
+
+			// 客户端未读取数据，写满发送窗口及写入队列
+			for i := 0; i < 64; i++ {
+				if err := conn.Push(msg); err != nil && !errors.Is(err, errors.ErrWriteQueueFull) {
+					t.Errorf("%s: unexpected push error: %v", policy, err)
+				}
+			}
+
+			err := network.PushCritical(conn, marker)
+
+			for i := 0; i < 64; i++ {
+				_ = conn.Push(msg)
+			}
+
+			pushed <- err
+		}()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := dial(t, addr)
+	defer client.Close()
+
+	select {
+	case err := <-pushed:
+		if err != nil {
+			t.Fatalf("%s: push critical message error: %v", policy, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: push timeout", policy)
+	}
+
+	if stat := server.(network.QueueStater).QueueStat(); stat.Dropped == 0 || stat.Disconnected != 0 {
+		t.Fatalf("%s: dropped = %d, disconnected = %d, want messages dropped without disconnection", policy, stat.Dropped, stat.Disconnected)
+	}
+
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var (
+		data []byte
+		buf  = make([]byte, 64*1024)
+	)
+
+	for !bytes.Contains(data, marker) {
+		n, err := client.Read(buf)
+		if err != nil {
+			t.Fatalf("%s: critical message dropped: %v", policy, err)
+		}
+
+		// 仅保留尾部数据用于匹配跨读取边界的关键消息
+		data = append(data[max(len(data)-len(marker), 0):], buf[:n]...)
+	}
+}
+
+// 获取可用的监听地址
+func listenAddr(t *testing.T) string {
+	ln, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	return ln.LocalAddr().String()
+}
+
+// 建立连接并发送心跳，服务端收到首个数据包后才会接受连接
+func dial(t *testing.T, addr string) *kcpgo.UDPSession {
+	client, err := kcpgo.DialWithOptions(addr, nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	heartbeat, err := packet.PackHeartbeat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Write(heartbeat); err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
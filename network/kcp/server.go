@@ -8,6 +8,10 @@
 package kcp
 
 import (
+	"time"
+
+	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/network"
 	"github.com/xtaci/kcp-go/v5"
@@ -17,14 +21,24 @@ type server struct {
 	opts              *serverOptions
 	listener          *kcp.Listener
 	connMgr           *serverConnMgr
-	startHandler      network.StartHandler      // 服务器启动hook函数
-	stopHandler       network.CloseHandler      // 服务器关闭hook函数
-	connectHandler    network.ConnectHandler    // 连接打开hook函数
-	disconnectHandler network.DisconnectHandler // 连接关闭hook函数
-	receiveHandler    network.ReceiveHandler    // 接收消息hook函数
+	startHandler      network.StartHandler            // 服务器启动hook函数
+	stopHandler       network.CloseHandler            // 服务器关闭hook函数
+	connectHandler    network.ConnectHandler          // 连接打开hook函数
+	disconnectHandler network.DisconnectHandler       // 连接关闭hook函数
+	receiveHandler    network.ReceiveHandler          // 接收消息hook函数
+	maxConnNum        *network.Setting[int]           // 最大连接数，可在运行时调整
+	heartbeatInterval *network.Setting[time.Duration] // 心跳间隔时间，可在运行时调整
+	authorizeTimeout  *network.Setting[time.Duration] // 授权超时时间，可在运行时调整
+	queueMetrics      network.QueueMetrics            // 写入队列指标
+	guard             *network.Guard                  // 连接准入控制器
+	cancels           []func()                        // 取消配置监听
 }
 
-var _ network.Server = &server{}
+var (
+	_ network.Server      = &server{}
+	_ network.QueueStater = &server{}
+	_ network.GuardServer = &server{}
+)
 
 func NewServer(opts ...ServerOption) network.Server {
 	o := defaultServerOptions()
@@ -35,6 +49,10 @@ func NewServer(opts ...ServerOption) network.Server {
 	s := &server{}
 	s.opts = o
 	s.connMgr = newServerConnMgr(s)
+	s.maxConnNum = network.NewSetting(o.maxConnNum)
+	s.heartbeatInterval = network.NewSetting(o.heartbeatInterval)
+	s.authorizeTimeout = network.NewSetting(o.authorizeTimeout)
+	s.guard = network.NewGuard(o.guard)
 
 	return s
 }
@@ -50,6 +68,8 @@ func (s *server) Start() error {
 		return err
 	}
 
+	s.watch()
+
 	if s.startHandler != nil {
 		s.startHandler()
 	}
@@ -61,6 +81,10 @@ func (s *server) Start() error {
 
 // Stop 关闭服务器
 func (s *server) Stop() error {
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+
 	if err := s.listener.Close(); err != nil {
 		return err
 	}
@@ -70,6 +94,76 @@ func (s *server) Stop() error {
 	return nil
 }
 
+// 监听etc配置变化
+// 最大连接数、心跳间隔时间、授权超时时间、单IP最大连接数、黑白名单可在运行时生效，其他配置需重启后生效
+func (s *server) watch() {
+	s.cancels = s.cancels[:0]
+
+	s.onChange(defaultServerMaxConnNumKey, func(val value.Value) {
+		s.maxConnNum.Store(val.Int())
+	}, defaultServerMaxConnNum)
+
+	s.onChange(defaultServerHeartbeatIntervalKey, func(val value.Value) {
+		s.heartbeatInterval.Store(val.Duration())
+	}, defaultServerHeartbeatInterval)
+
+	s.onChange(defaultServerAuthorizeTimeoutKey, func(val value.Value) {
+		s.authorizeTimeout.Store(val.Duration())
+	}, defaultServerAuthorizeTimeout)
+
+	s.onChange(defaultServerMaxConnPerIPKey, func(val value.Value) {
+		s.guard.SetMaxConnPerIP(val.Int())
+	})
+
+	s.onChange(defaultServerAllowListKey, func(val value.Value) {
+		if err := s.guard.SetAllowList(val.Strings()); err != nil {
+			log.Warnf("%s server allow list update failed: %v", protocol, err)
+		}
+	})
+
+	s.onChange(defaultServerDenyListKey, func(val value.Value) {
+		if err := s.guard.SetDenyList(val.Strings()); err != nil {
+			log.Warnf("%s server deny list update failed: %v", protocol, err)
+		}
+	})
+
+	etc.RestartOnly(
+		defaultServerAddrKey,
+		defaultServerHeartbeatMechanismKey,
+		defaultServerMtuKey,
+		defaultServerNoDelayKey,
+		defaultServerAckNoDelayKey,
+		defaultServerWriteDelayKey,
+		defaultServerWindowSizeKey,
+		defaultServerReadBufferKey,
+		defaultServerWriteBufferKey,
+		defaultServerQueueMaxMessagesKey,
+		defaultServerQueueMaxBytesKey,
+		defaultServerQueuePolicyKey,
+		defaultServerAcceptRateKey,
+		defaultServerAcceptBurstKey,
+	)
+}
+
+// 监听etc配置变化，显式设置的配置项不监听
+func (s *server) onChange(key string, fn func(val value.Value), def ...any) {
+	if _, ok := s.opts.fixed[key]; ok {
+		return
+	}
+
+	s.cancels = append(s.cancels, etc.OnChange(key, fn, def...))
+}
+
+// QueueStat 获取写入队列统计
+func (s *server) QueueStat() *network.QueueStat {
+	return s.queueMetrics.Stat()
+}
+
+// Guard 获取连接准入控制器
+func (s *server) Guard() *network.Guard {
+	return s.guard
+}
+
 // Protocol 协议
 func (s *server) Protocol() string {
 	return protocol
@@ -102,6 +196,14 @@ func (s *server) OnReceive(handler network.ReceiveHandler) {
 
 // 初始化服务器
 func (s *server) init() error {
+	if err := s.guard.SetAllowList(s.opts.guard.AllowList); err != nil {
+		return err
+	}
+
+	if err := s.guard.SetDenyList(s.opts.guard.DenyList); err != nil {
+		return err
+	}
+
 	//key := pbkdf2.Key([]byte("demo pass"), []byte("demo salt"), 1024, 32, sha1.New)
 	//block, _ := kcp.NewAESBlockCrypt(key)
 
@@ -124,7 +226,16 @@ func (s *server) serve() {
 			return
 		}
 
-		if err = s.connMgr.allocate(conn); err != nil {
+		addr := conn.RemoteAddr()
+
+		if err = s.guard.Admit(addr); err != nil {
+			log.Warnf("kcp connection rejected, addr: %v, reason: %v", addr, err)
+			_ = conn.Close()
+			continue
+		}
+
+		if err = s.connMgr.allocate(conn, addr); err != nil {
+			s.guard.Release(addr)
 			_ = conn.Close()
 		}
 	}
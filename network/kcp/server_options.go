@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/network"
 )
 
 const (
@@ -19,6 +20,7 @@ const (
 	defaultServerHeartbeatInterval  = "10s"
 	defaultServerHeartbeatMechanism = "resp"
 	defaultServerAuthorizeTimeout   = "0s"
+	defaultServerQueuePolicy        = "disconnect"
 )
 
 const (
@@ -34,6 +36,14 @@ const (
 	defaultServerWindowSizeKey         = "etc.network.kcp.server.windowSize"
 	defaultServerReadBufferKey         = "etc.network.kcp.server.readBuffer"
 	defaultServerWriteBufferKey        = "etc.network.kcp.server.writeBuffer"
+	defaultServerQueueMaxMessagesKey   = "etc.network.kcp.server.writeQueue.maxMessages"
+	defaultServerQueueMaxBytesKey      = "etc.network.kcp.server.writeQueue.maxBytes"
+	defaultServerQueuePolicyKey        = "etc.network.kcp.server.writeQueue.policy"
+	defaultServerMaxConnPerIPKey       = "etc.network.kcp.server.guard.maxConnPerIP"
+	defaultServerAcceptRateKey         = "etc.network.kcp.server.guard.acceptRate"
+	defaultServerAcceptBurstKey        = "etc.network.kcp.server.guard.acceptBurst"
+	defaultServerAllowListKey          = "etc.network.kcp.server.guard.allowList"
+	defaultServerDenyListKey           = "etc.network.kcp.server.guard.denyList"
 )
 
 const (
@@ -46,18 +56,21 @@ type HeartbeatMechanism string
 type ServerOption func(o *serverOptions)
 
 type serverOptions struct {
-	addr               string             // 监听地址
-	maxConnNum         int                // 最大连接数
-	heartbeatInterval  time.Duration      // 心跳检测间隔时间，默认10s
-	heartbeatMechanism HeartbeatMechanism // 心跳机制，默认resp
-	authorizeTimeout   time.Duration      // 授权超时时间，默认0s，不检测
-	mtu                int                // 最大传输单元，默认不设置
-	noDelay            []int              // 是否开启无延迟模式，默认不设置
-	ackNoDelay         bool               // 是否开启ACK延迟确认，默认不设置
-	writeDelay         bool               // 是否开启写延迟，默认不设置
-	windowSize         []int              // 窗口大小，默认不设置
-	readBuffer         int                // 读取缓冲区大小，默认不设置
-	writeBuffer        int                // 写入缓冲区大小，默认不设置
+	addr               string               // 监听地址
+	maxConnNum         int                  // 最大连接数
+	heartbeatInterval  time.Duration        // 心跳检测间隔时间，默认10s
+	heartbeatMechanism HeartbeatMechanism   // 心跳机制，默认resp
+	authorizeTimeout   time.Duration        // 授权超时时间，默认0s，不检测
+	mtu                int                  // 最大传输单元，默认不设置
+	noDelay            []int                // 是否开启无延迟模式，默认不设置
+	ackNoDelay         bool                 // 是否开启ACK延迟确认，默认不设置
+	writeDelay         bool                 // 是否开启写延迟，默认不设置
+	windowSize         []int                // 窗口大小，默认不设置
+	readBuffer         int                  // 读取缓冲区大小，默认不设置
+	writeBuffer        int                  // 写入缓冲区大小，默认不设置
+	writeQueue         network.QueueOptions // 连接写入队列配置
+	guard              network.GuardOptions // 连接准入配置
+	fixed              map[string]struct{}  // 显式设置的配置项，不监听etc中对应配置的变化
 }
 
 func defaultServerOptions() *serverOptions {
@@ -74,6 +87,18 @@ func defaultServerOptions() *serverOptions {
 		windowSize:         etc.Get(defaultServerWindowSizeKey).Ints(),
 		readBuffer:         int(etc.Get(defaultServerReadBufferKey).B()),
 		writeBuffer:        int(etc.Get(defaultServerWriteBufferKey).B()),
+		writeQueue: network.QueueOptions{
+			MaxMessages: etc.Get(defaultServerQueueMaxMessagesKey).Int(),
+			MaxBytes:    int(etc.Get(defaultServerQueueMaxBytesKey).B()),
+			Policy:      network.OverflowPolicy(etc.Get(defaultServerQueuePolicyKey, defaultServerQueuePolicy).String()),
+		},
+		guard: network.GuardOptions{
+			MaxConnPerIP: etc.Get(defaultServerMaxConnPerIPKey).Int(),
+			AcceptRate:   etc.Get(defaultServerAcceptRateKey).Float64(),
+			AcceptBurst:  etc.Get(defaultServerAcceptBurstKey).Int(),
+			AllowList:    etc.Get(defaultServerAllowListKey).Strings(),
+			DenyList:     etc.Get(defaultServerDenyListKey).Strings(),
+		},
 	}
 }
 
@@ -84,12 +109,18 @@ func WithServerListenAddr(addr string) ServerOption {
 
 // WithServerMaxConnNum 设置连接的最大连接数
 func WithServerMaxConnNum(maxConnNum int) ServerOption {
-	return func(o *serverOptions) { o.maxConnNum = maxConnNum }
+	return func(o *serverOptions) {
+		o.maxConnNum = maxConnNum
+		o.fix(defaultServerMaxConnNumKey)
+	}
 }
 
 // WithServerHeartbeatInterval 设置心跳检测间隔时间
 func WithServerHeartbeatInterval(heartbeatInterval time.Duration) ServerOption {
-	return func(o *serverOptions) { o.heartbeatInterval = heartbeatInterval }
+	return func(o *serverOptions) {
+		o.heartbeatInterval = heartbeatInterval
+		o.fix(defaultServerHeartbeatIntervalKey)
+	}
 }
 
 // WithServerHeartbeatMechanism 设置心跳机制
@@ -99,7 +130,10 @@ func WithServerHeartbeatMechanism(heartbeatMechanism HeartbeatMechanism) ServerO
 
 // WithServerAuthorizeTimeout 设置授权超时时间
 func WithServerAuthorizeTimeout(authorizeTimeout time.Duration) ServerOption {
-	return func(o *serverOptions) { o.authorizeTimeout = authorizeTimeout }
+	return func(o *serverOptions) {
+		o.authorizeTimeout = authorizeTimeout
+		o.fix(defaultServerAuthorizeTimeoutKey)
+	}
 }
 
 // WithServerMtu 设置最大传输单元
@@ -136,3 +170,46 @@ func WithServerReadBuffer(readBuffer int) ServerOption {
 func WithServerWriteBuffer(writeBuffer int) ServerOption {
 	return func(o *serverOptions) { o.writeBuffer = writeBuffer }
 }
+
+// WithServerWriteQueue 设置连接写入队列配置
+func WithServerWriteQueue(writeQueue network.QueueOptions) ServerOption {
+	return func(o *serverOptions) { o.writeQueue = writeQueue }
+}
+
+// WithServerMaxConnPerIP 设置单IP最大并发连接数
+func WithServerMaxConnPerIP(maxConnPerIP int) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.MaxConnPerIP = maxConnPerIP
+		o.fix(defaultServerMaxConnPerIPKey)
+	}
+}
+
+// WithServerAcceptRate 设置单IP每秒允许建立的连接数及突发连接数
+func WithServerAcceptRate(rate float64, burst int) ServerOption {
+	return func(o *serverOptions) { o.guard.AcceptRate, o.guard.AcceptBurst = rate, burst }
+}
+
+// WithServerAllowList 设置白名单，支持CIDR及单个IP地址
+func WithServerAllowList(allowList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.AllowList = allowList
+		o.fix(defaultServerAllowListKey)
+	}
+}
+
+// WithServerDenyList 设置黑名单，支持CIDR及单个IP地址
+func WithServerDenyList(denyList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.DenyList = denyList
+		o.fix(defaultServerDenyListKey)
+	}
+}
+
+// 标记显式设置的配置项
+func (o *serverOptions) fix(key string) {
+	if o.fixed == nil {
+		o.fixed = make(map[string]struct{})
+	}
+
+	o.fixed[key] = struct{}{}
+}
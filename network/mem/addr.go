@@ -0,0 +1,92 @@
+package mem
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/errors"
+)
+
+const (
+	defaultHost      = "127.0.0.1" // 缺省主机
+	ephemeralPortMin = 49152       // 临时端口起始值
+)
+
+var (
+	listeners sync.Map     // 监听中的服务器
+	ephemeral atomic.Int64 // 临时端口分配
+)
+
+type addr struct {
+	host string
+	port int
+}
+
+var _ net.Addr = &addr{}
+
+// 解析地址，主机为空时使用127.0.0.1
+func resolveAddr(address string) (*addr, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = defaultHost
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, err
+	}
+
+	return &addr{host: host, port: p}, nil
+}
+
+// 分配临时地址
+func ephemeralAddr() *addr {
+	port := ephemeralPortMin + int(ephemeral.Add(1)-1)%(65536-ephemeralPortMin)
+
+	return &addr{host: defaultHost, port: port}
+}
+
+// Network 网络类型
+func (a *addr) Network() string {
+	return protocol
+}
+
+// String 地址字符串
+func (a *addr) String() string {
+	return net.JoinHostPort(a.host, strconv.Itoa(a.port))
+}
+
+// 注册监听
+func listen(s *server) error {
+	if _, loaded := listeners.LoadOrStore(s.addr.String(), s); loaded {
+		return errors.ErrAddressInUse
+	}
+
+	return nil
+}
+
+// 注销监听
+func unlisten(s *server) {
+	listeners.CompareAndDelete(s.addr.String(), s)
+}
+
+// 查找监听中的服务器
+func lookup(address string) (*server, error) {
+	a, err := resolveAddr(address)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := listeners.Load(a.String())
+	if !ok {
+		return nil, errors.ErrConnectionRefused
+	}
+
+	return v.(*server), nil
+}
@@ -0,0 +1,97 @@
+package mem
+
+import (
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/network"
+)
+
+type client struct {
+	opts              *clientOptions            // 配置
+	id                atomic.Int64              // 连接ID
+	connectHandler    network.ConnectHandler    // 连接打开hook函数
+	disconnectHandler network.DisconnectHandler // 连接关闭hook函数
+	receiveHandler    network.ReceiveHandler    // 接收消息hook函数
+}
+
+var _ network.Client = &client{}
+
+func NewClient(opts ...ClientOption) network.Client {
+	o := defaultClientOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &client{opts: o}
+}
+
+// Dial 拨号连接
+func (c *client) Dial(addr ...string) (network.Conn, error) {
+	var address string
+
+	if len(addr) > 0 && addr[0] != "" {
+		address = addr[0]
+	} else {
+		address = c.opts.addr
+	}
+
+	s, err := lookup(address)
+	if err != nil {
+		return nil, err
+	}
+
+	local := ephemeralAddr()
+
+	if c.opts.localAddr != "" {
+		if local, err = resolveAddr(c.opts.localAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	cc := newConn(c, c.id.Add(1), local, s.addr, c.opts.link)
+
+	if _, err = s.accept(cc); err != nil {
+		return nil, err
+	}
+
+	if c.connectHandler != nil {
+		c.connectHandler(cc)
+	}
+
+	return cc, nil
+}
+
+// Protocol 协议
+func (c *client) Protocol() string {
+	return protocol
+}
+
+// OnConnect 监听连接打开
+func (c *client) OnConnect(handler network.ConnectHandler) {
+	c.connectHandler = handler
+}
+
+// OnDisconnect 监听连接关闭
+func (c *client) OnDisconnect(handler network.DisconnectHandler) {
+	c.disconnectHandler = handler
+}
+
+// OnReceive 监听接收到消息
+func (c *client) OnReceive(handler network.ReceiveHandler) {
+	c.receiveHandler = handler
+}
+
+// 处理接收到的消息
+func (c *client) handleReceive(conn *conn, buf buffer.Buffer) {
+	if c.receiveHandler != nil {
+		c.receiveHandler(conn, buf)
+	}
+}
+
+// 处理连接关闭
+func (c *client) handleDisconnect(conn *conn) {
+	if c.disconnectHandler != nil {
+		c.disconnectHandler(conn)
+	}
+}
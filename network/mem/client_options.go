@@ -0,0 +1,54 @@
+package mem
+
+import (
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultClientAddr = "127.0.0.1:3553"
+)
+
+const (
+	defaultClientAddrKey      = "etc.network.mem.client.addr"
+	defaultClientLatencyKey   = "etc.network.mem.client.link.latency"
+	defaultClientJitterKey    = "etc.network.mem.client.link.jitter"
+	defaultClientLossKey      = "etc.network.mem.client.link.loss"
+	defaultClientBandwidthKey = "etc.network.mem.client.link.bandwidth"
+	defaultClientSeedKey      = "etc.network.mem.client.link.seed"
+)
+
+type ClientOption func(o *clientOptions)
+
+type clientOptions struct {
+	addr      string // 拨号地址
+	localAddr string // 本地地址，默认自动分配127.0.0.1上的临时端口
+	link      Link   // 上行链路条件，默认无延迟、无丢包、不限带宽
+}
+
+func defaultClientOptions() *clientOptions {
+	return &clientOptions{
+		addr: etc.Get(defaultClientAddrKey, defaultClientAddr).String(),
+		link: Link{
+			Latency:   etc.Get(defaultClientLatencyKey).Duration(),
+			Jitter:    etc.Get(defaultClientJitterKey).Duration(),
+			Loss:      etc.Get(defaultClientLossKey).Float64(),
+			Bandwidth: etc.Get(defaultClientBandwidthKey).Int64(),
+			Seed:      etc.Get(defaultClientSeedKey).Int64(),
+		},
+	}
+}
+
+// WithClientAddr 设置拨号地址
+func WithClientAddr(addr string) ClientOption {
+	return func(o *clientOptions) { o.addr = addr }
+}
+
+// WithClientLocalAddr 设置本地地址，可用于模拟来自指定IP的连接
+func WithClientLocalAddr(addr string) ClientOption {
+	return func(o *clientOptions) { o.localAddr = addr }
+}
+
+// WithClientLink 设置上行链路条件，作用于客户端发往服务器的消息
+func WithClientLink(link Link) ClientOption {
+	return func(o *clientOptions) { o.link = link }
+}
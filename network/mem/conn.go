@@ -0,0 +1,256 @@
+package mem
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+	"github.com/devagame/due/v2/utils/xnet"
+)
+
+type endpoint interface {
+	// 处理接收到的消息
+	handleReceive(c *conn, buf buffer.Buffer)
+	// 处理连接关闭
+	handleDisconnect(c *conn)
+}
+
+type conn struct {
+	id       int64               // 连接ID
+	uid      atomic.Int64        // 用户ID
+	attr     *attr               // 连接属性
+	state    atomic.Int32        // 连接状态
+	reason   atomic.Int32        // 关闭原因
+	local    *addr               // 本地地址
+	remote   *addr               // 远端地址
+	peer     *conn               // 对端连接
+	out      *link               // 发送链路
+	endpoint endpoint            // 所属端点
+	meter    network.ConnMeter   // 流量计量
+	notice   network.CloseNotice // 关闭通知
+}
+
+var (
+	_ network.Conn       = &conn{}
+	_ network.ReasonConn = &conn{}
+	_ network.CodeConn   = &conn{}
+)
+
+func newConn(endpoint endpoint, id int64, local, remote *addr, cond Link) *conn {
+	c := &conn{
+		id:       id,
+		attr:     &attr{},
+		local:    local,
+		remote:   remote,
+		out:      newLink(cond, id),
+		endpoint: endpoint,
+	}
+
+	c.state.Store(int32(network.ConnOpened))
+	c.meter.Reset()
+
+	return c
+}
+
+// 连接两端并启动链路投递
+func pair(a, b *conn) {
+	a.peer, b.peer = b, a
+
+	go a.out.run(b.receive, func() { _ = a.close(network.ReasonNone) })
+
+	go b.out.run(a.receive, func() { _ = b.close(network.ReasonNone) })
+}
+
+// ID 获取连接ID
+func (c *conn) ID() int64 {
+	return c.id
+}
+
+// UID 获取用户ID
+func (c *conn) UID() int64 {
+	return c.uid.Load()
+}
+
+// Attr 获取属性接口
+func (c *conn) Attr() network.Attr {
+	return c.attr
+}
+
+// Bind 绑定用户ID
+func (c *conn) Bind(uid int64) {
+	c.uid.Store(uid)
+}
+
+// Unbind 解绑用户ID
+func (c *conn) Unbind() {
+	c.uid.Store(0)
+}
+
+// Send 发送消息（同步）
+func (c *conn) Send(msg []byte) error {
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	if err := c.out.send(msg, false); err != nil {
+		return err
+	}
+
+	c.meter.Send(len(msg), false)
+
+	return nil
+}
+
+// Push 发送消息（异步）
+func (c *conn) Push(msg []byte) error {
+	return c.Send(msg)
+}
+
+// CloseCode 获取对端下发的关闭原因码及说明信息，未收到关闭包时返回false
+func (c *conn) CloseCode() (network.CloseCode, string, bool) {
+	return c.notice.Load()
+}
+
+// Stats 获取连接流量统计
+func (c *conn) Stats() *network.ConnStat {
+	return c.meter.Stat()
+}
+
+// State 获取连接状态
+func (c *conn) State() network.ConnState {
+	return network.ConnState(c.state.Load())
+}
+
+// CloseReason 获取连接关闭原因
+func (c *conn) CloseReason() network.CloseReason {
+	return network.CloseReason(c.reason.Load())
+}
+
+// Close 关闭连接
+// 优雅关闭时已发送的消息投递完毕后两端才会关闭，强制关闭时两端立即关闭并丢弃未投递的消息
+func (c *conn) Close(force ...bool) error {
+	if len(force) > 0 && force[0] {
+		return c.close(network.ReasonNone)
+	} else {
+		return c.graceClose()
+	}
+}
+
+// LocalIP 获取本地IP
+func (c *conn) LocalIP() (string, error) {
+	addr, err := c.LocalAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// LocalAddr 获取本地地址
+func (c *conn) LocalAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	return c.local, nil
+}
+
+// RemoteIP 获取远端IP
+func (c *conn) RemoteIP() (string, error) {
+	addr, err := c.RemoteAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// RemoteAddr 获取远端地址
+func (c *conn) RemoteAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	return c.remote, nil
+}
+
+// 检测连接状态
+func (c *conn) checkState() error {
+	switch c.State() {
+	case network.ConnHanged:
+		return errors.ErrConnectionHanged
+	case network.ConnClosed:
+		return errors.ErrConnectionClosed
+	default:
+		return nil
+	}
+}
+
+// 优雅关闭，经由链路发送关闭帧，关闭帧投递后关闭两端
+func (c *conn) graceClose() error {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnHanged)) {
+		return errors.ErrConnectionNotOpened
+	}
+
+	if err := c.out.send(nil, true); err != nil {
+		return c.close(network.ReasonNone)
+	}
+
+	return nil
+}
+
+// 关闭连接，同时关闭对端连接
+func (c *conn) close(reason network.CloseReason) error {
+	if network.ConnState(c.state.Swap(int32(network.ConnClosed))) == network.ConnClosed {
+		return errors.ErrConnectionClosed
+	}
+
+	c.reason.Store(int32(reason))
+	c.out.close()
+
+	if c.peer != nil {
+		_ = c.peer.close(network.ReasonClientClosed)
+	}
+
+	c.endpoint.handleDisconnect(c)
+
+	return nil
+}
+
+// 接收对端投递的消息
+func (c *conn) receive(msg []byte) {
+	switch c.State() {
+	case network.ConnHanged:
+		return
+	case network.ConnClosed:
+		return
+	default:
+		// ignore
+	}
+
+	// ignore empty packet
+	if len(msg) == 0 {
+		return
+	}
+
+	isHeartbeat, err := packet.CheckHeartbeat(msg)
+	if err != nil {
+		log.Errorf("check heartbeat message error: %v", err)
+		return
+	}
+
+	c.meter.Receive(len(msg), isHeartbeat)
+
+	// ignore heartbeat packet
+	if isHeartbeat {
+		// 记录对端下发的关闭包，连接随后由对端关闭
+		c.notice.Receive(msg)
+		return
+	}
+
+	c.endpoint.handleReceive(c, buffer.NewBytes(msg))
+}
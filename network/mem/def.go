@@ -0,0 +1,3 @@
+package mem
+
+const protocol = "mem"
@@ -0,0 +1,124 @@
+package mem
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/devagame/due/v2/errors"
+)
+
+// Link 链路条件，作用于单个传输方向
+type Link struct {
+	Latency   time.Duration // 固定延迟
+	Jitter    time.Duration // 延迟抖动，实际延迟在[Latency-Jitter, Latency+Jitter]区间内均匀分布
+	Loss      float64       // 丢包率，取值范围[0, 1)
+	Bandwidth int64         // 带宽，单位为字节每秒，为0时不限制
+	Seed      int64         // 随机数种子，种子相同时抖动与丢包序列相同
+}
+
+type frame struct {
+	msg []byte    // 消息
+	fin bool      // 是否为关闭帧
+	at  time.Time // 投递时间
+}
+
+type link struct {
+	cond   Link          // 链路条件
+	mu     sync.Mutex    // 互斥锁
+	rand   *rand.Rand    // 随机数生成器
+	busy   time.Time     // 链路占用截止时间，用于模拟带宽
+	last   time.Time     // 上一帧的投递时间，用于保证按序投递
+	fin    bool          // 是否已发送关闭帧
+	frames chan frame    // 待投递帧
+	done   chan struct{} // 关闭信号
+	once   sync.Once
+}
+
+func newLink(cond Link, seed int64) *link {
+	return &link{
+		cond:   cond,
+		rand:   rand.New(rand.NewSource(cond.Seed + seed)),
+		frames: make(chan frame, 4096),
+		done:   make(chan struct{}),
+	}
+}
+
+// 发送帧，关闭帧不会丢失且必定在已发送的数据之后投递
+func (l *link) send(msg []byte, fin bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fin {
+		return errors.ErrConnectionClosed
+	}
+
+	if !fin && l.cond.Loss > 0 && l.rand.Float64() < l.cond.Loss {
+		return nil
+	}
+
+	now := time.Now()
+	delay := l.cond.Latency
+
+	if l.cond.Jitter > 0 {
+		delay += time.Duration(l.rand.Int63n(int64(2*l.cond.Jitter)+1)) - l.cond.Jitter
+	}
+
+	if l.cond.Bandwidth > 0 {
+		if l.busy.Before(now) {
+			l.busy = now
+		}
+		l.busy = l.busy.Add(time.Duration(int64(len(msg)) * int64(time.Second) / l.cond.Bandwidth))
+		delay += l.busy.Sub(now)
+	}
+
+	at := now.Add(max(delay, 0))
+	if at.Before(l.last) {
+		at = l.last
+	}
+	l.last = at
+	l.fin = fin
+
+	select {
+	case l.frames <- frame{msg: msg, fin: fin, at: at}:
+		return nil
+	case <-l.done:
+		return errors.ErrConnectionClosed
+	}
+}
+
+// 按投递时间依次投递帧，收到关闭帧或链路关闭时退出
+func (l *link) run(deliver func(msg []byte), finish func()) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case f := <-l.frames:
+			if d := time.Until(f.at); d > 0 {
+				timer.Reset(d)
+
+				select {
+				case <-l.done:
+					return
+				case <-timer.C:
+				}
+			}
+
+			if f.fin {
+				finish()
+				return
+			}
+
+			deliver(f.msg)
+		}
+	}
+}
+
+// 关闭链路，丢弃所有未投递的帧
+func (l *link) close() {
+	l.once.Do(func() { close(l.done) })
+}
@@ -0,0 +1,292 @@
+package mem_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/network/mem"
+	"github.com/devagame/due/v2/packet"
+)
+
+func pack(t *testing.T, seq int32) []byte {
+	msg, err := packet.PackMessage(&packet.Message{Seq: seq, Route: 1, Buffer: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return msg
+}
+
+func TestConn_Order(t *testing.T) {
+	const total = 100
+
+	server := mem.NewServer(mem.WithServerListenAddr(":10001"))
+
+	received := make(chan int32, total)
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		message, err := packet.UnpackMessage(buf.Bytes())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		received <- message.Seq
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	link := mem.Link{Latency: 20 * time.Millisecond, Jitter: 10 * time.Millisecond, Seed: 1}
+	client := mem.NewClient(mem.WithClientAddr("127.0.0.1:10001"), mem.WithClientLink(link))
+
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(true)
+
+	start := time.Now()
+
+	for i := int32(1); i <= total; i++ {
+		if err = conn.Push(pack(t, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := int32(1); i <= total; i++ {
+		select {
+		case seq := <-received:
+			if seq != i {
+				t.Fatalf("seq = %d, want %d", seq, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("receive message timeout")
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < link.Latency-link.Jitter {
+		t.Fatalf("elapsed = %v, want >= %v", elapsed, link.Latency-link.Jitter)
+	}
+
+	if stat := conn.Stats(); stat.MessagesOut != total {
+		t.Fatalf("messages out = %d, want %d", stat.MessagesOut, total)
+	}
+}
+
+func TestConn_Loss(t *testing.T) {
+	server := mem.NewServer(mem.WithServerListenAddr(":10002"))
+
+	var (
+		mu       sync.Mutex
+		received int
+	)
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := mem.NewClient(mem.WithClientLink(mem.Link{Loss: 0.5, Seed: 1}))
+
+	conn, err := client.Dial("127.0.0.1:10002")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int32(1); i <= 1000; i++ {
+		if err = conn.Push(pack(t, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if received < 400 || received > 600 {
+		t.Fatalf("received = %d, want about 500", received)
+	}
+}
+
+func TestConn_GraceClose(t *testing.T) {
+	server := mem.NewServer(mem.WithServerListenAddr(":10003"))
+
+	var (
+		mu       sync.Mutex
+		received int
+	)
+
+	disconnected := make(chan network.CloseReason, 1)
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+	})
+
+	server.OnDisconnect(func(conn network.Conn) {
+		disconnected <- conn.(network.ReasonConn).CloseReason()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := mem.NewClient(mem.WithClientLink(mem.Link{Latency: 10 * time.Millisecond}))
+
+	conn, err := client.Dial("127.0.0.1:10003")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int32(1); i <= 10; i++ {
+		if err = conn.Push(pack(t, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn.Push(pack(t, 11)); !errors.Is(err, errors.ErrConnectionHanged) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrConnectionHanged)
+	}
+
+	select {
+	case reason := <-disconnected:
+		if reason != network.ReasonClientClosed {
+			t.Fatalf("reason = %v, want %v", reason, network.ReasonClientClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("disconnect timeout")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if received != 10 {
+		t.Fatalf("received = %d, want 10", received)
+	}
+
+	if conn.State() != network.ConnClosed {
+		t.Fatalf("state = %v, want %v", conn.State(), network.ConnClosed)
+	}
+}
+
+func TestClient_Dial(t *testing.T) {
+	client := mem.NewClient()
+
+	if _, err := client.Dial("127.0.0.1:10004"); !errors.Is(err, errors.ErrConnectionRefused) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrConnectionRefused)
+	}
+
+	server := mem.NewServer(mem.WithServerListenAddr(":10004"), mem.WithServerMaxConnNum(1))
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mem.NewServer(mem.WithServerListenAddr(":10004")).Start(); !errors.Is(err, errors.ErrAddressInUse) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrAddressInUse)
+	}
+
+	conn, err := mem.NewClient(mem.WithClientLocalAddr("10.0.0.1:2000")).Dial("127.0.0.1:10004")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ip, _ := conn.LocalIP(); ip != "10.0.0.1" {
+		t.Fatalf("local ip = %s, want 10.0.0.1", ip)
+	}
+
+	if _, err = client.Dial("127.0.0.1:10004"); !errors.Is(err, errors.ErrTooManyConnection) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrTooManyConnection)
+	}
+
+	if err = server.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if conn.State() != network.ConnClosed {
+		t.Fatalf("state = %v, want %v", conn.State(), network.ConnClosed)
+	}
+}
+
+func TestConn_CloseWithCode(t *testing.T) {
+	server := mem.NewServer(mem.WithServerListenAddr(":10005"))
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			for i := int32(1); i <= 10; i++ {
+				if err := conn.Push(pack(t, i)); err != nil {
+					t.Error(err)
+				}
+			}
+
+			if err := network.CloseWithCode(conn, network.CloseKicked, "login elsewhere"); err != nil {
+				t.Error(err)
+			}
+		}()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := mem.NewClient(mem.WithClientLink(mem.Link{Latency: 10 * time.Millisecond}))
+
+	var (
+		mu       sync.Mutex
+		received int
+	)
+
+	disconnected := make(chan network.Conn, 1)
+
+	client.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+	})
+
+	client.OnDisconnect(func(conn network.Conn) {
+		disconnected <- conn
+	})
+
+	if _, err := client.Dial("127.0.0.1:10005"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case conn := <-disconnected:
+		code, message, ok := conn.(network.CodeConn).CloseCode()
+		if !ok || code != network.CloseKicked || message != "login elsewhere" {
+			t.Fatalf("code = %v, message = %q, ok = %v", code, message, ok)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("disconnect timeout")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if received != 10 {
+		t.Fatalf("received = %d, want 10", received)
+	}
+}
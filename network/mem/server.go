@@ -0,0 +1,157 @@
+package mem
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+)
+
+type server struct {
+	opts              *serverOptions            // 配置
+	addr              *addr                     // 监听地址
+	id                atomic.Int64              // 连接ID
+	conns             sync.Map                  // 连接
+	total             atomic.Int64              // 连接总数
+	closed            atomic.Bool               // 是否已关闭
+	startHandler      network.StartHandler      // 服务器启动hook函数
+	stopHandler       network.CloseHandler      // 服务器关闭hook函数
+	connectHandler    network.ConnectHandler    // 连接打开hook函数
+	disconnectHandler network.DisconnectHandler // 连接关闭hook函数
+	receiveHandler    network.ReceiveHandler    // 接收消息hook函数
+}
+
+var _ network.Server = &server{}
+
+// NewServer 新建内存服务器，服务器与客户端位于同一进程内，通过内存链路传输消息
+// 内存连接无需心跳，收到的心跳包将被忽略
+func NewServer(opts ...ServerOption) network.Server {
+	o := defaultServerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &server{opts: o}
+}
+
+// Addr 监听地址
+func (s *server) Addr() string {
+	return s.opts.addr
+}
+
+// Start 启动服务器
+func (s *server) Start() error {
+	addr, err := resolveAddr(s.opts.addr)
+	if err != nil {
+		return err
+	}
+
+	s.addr = addr
+	s.closed.Store(false)
+
+	if err = listen(s); err != nil {
+		return err
+	}
+
+	if s.startHandler != nil {
+		s.startHandler()
+	}
+
+	return nil
+}
+
+// Stop 关闭服务器
+func (s *server) Stop() error {
+	if !s.closed.CompareAndSwap(false, true) {
+		return errors.ErrServerClosed
+	}
+
+	unlisten(s)
+
+	s.conns.Range(func(_, value any) bool {
+		_ = value.(*conn).Close(true)
+		return true
+	})
+
+	if s.stopHandler != nil {
+		s.stopHandler()
+	}
+
+	return nil
+}
+
+// Protocol 协议
+func (s *server) Protocol() string {
+	return protocol
+}
+
+// OnStart 监听服务器启动
+func (s *server) OnStart(handler network.StartHandler) {
+	s.startHandler = handler
+}
+
+// OnStop 监听服务器关闭
+func (s *server) OnStop(handler network.CloseHandler) {
+	s.stopHandler = handler
+}
+
+// OnConnect 监听连接打开
+func (s *server) OnConnect(handler network.ConnectHandler) {
+	s.connectHandler = handler
+}
+
+// OnDisconnect 监听连接关闭
+func (s *server) OnDisconnect(handler network.DisconnectHandler) {
+	s.disconnectHandler = handler
+}
+
+// OnReceive 监听接收到消息
+func (s *server) OnReceive(handler network.ReceiveHandler) {
+	s.receiveHandler = handler
+}
+
+// 接受客户端连接
+func (s *server) accept(cc *conn) (*conn, error) {
+	if s.closed.Load() {
+		return nil, errors.ErrConnectionRefused
+	}
+
+	if s.total.Add(1) > int64(s.opts.maxConnNum) {
+		s.total.Add(-1)
+		return nil, errors.ErrTooManyConnection
+	}
+
+	sc := newConn(s, s.id.Add(1), s.addr, cc.local, s.opts.link)
+
+	s.conns.Store(sc.id, sc)
+
+	pair(sc, cc)
+
+	if s.connectHandler != nil {
+		s.connectHandler(sc)
+	}
+
+	return sc, nil
+}
+
+// 处理接收到的消息
+func (s *server) handleReceive(c *conn, buf buffer.Buffer) {
+	if s.receiveHandler != nil {
+		s.receiveHandler(c, buf)
+	}
+}
+
+// 处理连接关闭
+func (s *server) handleDisconnect(c *conn) {
+	if _, ok := s.conns.LoadAndDelete(c.id); !ok {
+		return
+	}
+
+	s.total.Add(-1)
+
+	if s.disconnectHandler != nil {
+		s.disconnectHandler(c)
+	}
+}
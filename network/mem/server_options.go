@@ -0,0 +1,57 @@
+package mem
+
+import (
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultServerAddr       = ":3553"
+	defaultServerMaxConnNum = 5000
+)
+
+const (
+	defaultServerAddrKey       = "etc.network.mem.server.addr"
+	defaultServerMaxConnNumKey = "etc.network.mem.server.maxConnNum"
+	defaultServerLatencyKey    = "etc.network.mem.server.link.latency"
+	defaultServerJitterKey     = "etc.network.mem.server.link.jitter"
+	defaultServerLossKey       = "etc.network.mem.server.link.loss"
+	defaultServerBandwidthKey  = "etc.network.mem.server.link.bandwidth"
+	defaultServerSeedKey       = "etc.network.mem.server.link.seed"
+)
+
+type ServerOption func(o *serverOptions)
+
+type serverOptions struct {
+	addr       string // 监听地址，默认127.0.0.1:3553
+	maxConnNum int    // 最大连接数，默认5000
+	link       Link   // 下行链路条件，默认无延迟、无丢包、不限带宽
+}
+
+func defaultServerOptions() *serverOptions {
+	return &serverOptions{
+		addr:       etc.Get(defaultServerAddrKey, defaultServerAddr).String(),
+		maxConnNum: etc.Get(defaultServerMaxConnNumKey, defaultServerMaxConnNum).Int(),
+		link: Link{
+			Latency:   etc.Get(defaultServerLatencyKey).Duration(),
+			Jitter:    etc.Get(defaultServerJitterKey).Duration(),
+			Loss:      etc.Get(defaultServerLossKey).Float64(),
+			Bandwidth: etc.Get(defaultServerBandwidthKey).Int64(),
+			Seed:      etc.Get(defaultServerSeedKey).Int64(),
+		},
+	}
+}
+
+// WithServerListenAddr 设置监听地址
+func WithServerListenAddr(addr string) ServerOption {
+	return func(o *serverOptions) { o.addr = addr }
+}
+
+// WithServerMaxConnNum 设置连接的最大连接数
+func WithServerMaxConnNum(maxConnNum int) ServerOption {
+	return func(o *serverOptions) { o.maxConnNum = maxConnNum }
+}
+
+// WithServerLink 设置下行链路条件，作用于服务器发往客户端的消息
+func WithServerLink(link Link) ServerOption {
+	return func(o *serverOptions) { o.link = link }
+}
@@ -0,0 +1,132 @@
+package network
+
+import "sync/atomic"
+
+const defaultQueueMessages = 4096 // 默认写入队列容量
+
+const (
+	DropOldest OverflowPolicy = "dropOldest" // 丢弃最早入队的非关键消息
+	DropNew    OverflowPolicy = "dropNew"    // 丢弃新的非关键消息，新消息为关键消息时丢弃最早入队的非关键消息
+	Disconnect OverflowPolicy = "disconnect" // 作为慢消费者断开连接
+)
+
+// OverflowPolicy 写入队列溢出策略
+type OverflowPolicy string
+
+// QueueOptions 连接写入队列配置
+type QueueOptions struct {
+	MaxMessages int            // 最大消息数，为0时使用默认队列容量
+	MaxBytes    int            // 最大字节数，为0时不限制
+	Policy      OverflowPolicy // 溢出策略，默认为disconnect
+}
+
+// Enabled 是否启用了写入队列限制，未启用时队列写满后将阻塞发送
+func (o *QueueOptions) Enabled() bool {
+	return o.MaxMessages > 0 || o.MaxBytes > 0
+}
+
+// Capacity 写入队列容量，额外预留关闭信号的位置
+func (o *QueueOptions) Capacity() int {
+	if o.MaxMessages > 0 {
+		return o.MaxMessages + 1
+	}
+
+	return defaultQueueMessages
+}
+
+// Quota 连接写入队列配额，统计队列中的消息数及字节数
+type Quota struct {
+	messages atomic.Int64
+	bytes    atomic.Int64
+}
+
+// Acquire 申请配额，超出队列限制时返回false
+func (q *Quota) Acquire(opts *QueueOptions, size int) bool {
+	if !opts.Enabled() {
+		return true
+	}
+
+	maxMessages := int64(opts.Capacity() - 1)
+
+	if n := q.messages.Add(1); n > maxMessages {
+		q.messages.Add(-1)
+		return false
+	}
+
+	// 队列为空时总是允许写入，避免超过字节限制的单条消息永远无法发送
+	if n := q.bytes.Add(int64(size)); opts.MaxBytes > 0 && n > int64(opts.MaxBytes) && n != int64(size) {
+		q.messages.Add(-1)
+		q.bytes.Add(-int64(size))
+		return false
+	}
+
+	return true
+}
+
+// Release 释放配额
+func (q *Quota) Release(opts *QueueOptions, size int) {
+	if !opts.Enabled() {
+		return
+	}
+
+	q.messages.Add(-1)
+	q.bytes.Add(-int64(size))
+}
+
+// Reset 重置配额
+func (q *Quota) Reset() {
+	q.messages.Store(0)
+	q.bytes.Store(0)
+}
+
+// CriticalConn 可推送关键消息的连接
+// 写入队列溢出时关键消息不会被丢弃，队列中不存在可丢弃的非关键消息时作为慢消费者断开连接
+type CriticalConn interface {
+	// PushCritical 推送关键消息（异步）
+	PushCritical(msg []byte) error
+}
+
+// PushCritical 推送关键消息，连接不支持关键消息时按普通消息推送
+func PushCritical(conn Conn, msg []byte) error {
+	if cc, ok := conn.(CriticalConn); ok {
+		return cc.PushCritical(msg)
+	}
+
+	return conn.Push(msg)
+}
+
+// QueueStat 写入队列统计
+type QueueStat struct {
+	Dropped      int64 // 因队列溢出丢弃的消息数
+	Disconnected int64 // 因慢消费断开的连接数
+}
+
+// QueueMetrics 写入队列指标
+type QueueMetrics struct {
+	dropped      atomic.Int64
+	disconnected atomic.Int64
+}
+
+// Drop 记录丢弃的消息
+func (m *QueueMetrics) Drop() {
+	m.dropped.Add(1)
+}
+
+// Disconnect 记录因慢消费断开的连接
+func (m *QueueMetrics) Disconnect() {
+	m.disconnected.Add(1)
+}
+
+// Stat 获取统计信息
+func (m *QueueMetrics) Stat() *QueueStat {
+	return &QueueStat{
+		Dropped:      m.dropped.Load(),
+		Disconnected: m.disconnected.Load(),
+	}
+}
+
+// QueueStater 提供写入队列统计的服务器
+type QueueStater interface {
+	// QueueStat 获取写入队列统计
+	QueueStat() *QueueStat
+}
@@ -0,0 +1,35 @@
+package network
+
+const (
+	ReasonNone             CloseReason = iota // 无，连接未关闭或由服务端主动关闭
+	ReasonClientClosed                        // 客户端断开连接或读取失败
+	ReasonHeartbeatTimeout                    // 心跳超时
+	ReasonAuthorizeTimeout                    // 授权超时
+	ReasonSlowConsumer                        // 慢消费者，写入队列溢出
+)
+
+// CloseReason 连接关闭原因
+type CloseReason int32
+
+func (r CloseReason) String() string {
+	switch r {
+	case ReasonNone:
+		return "none"
+	case ReasonClientClosed:
+		return "client closed"
+	case ReasonHeartbeatTimeout:
+		return "heartbeat timeout"
+	case ReasonAuthorizeTimeout:
+		return "authorize timeout"
+	case ReasonSlowConsumer:
+		return "slow consumer"
+	}
+
+	return "unknown"
+}
+
+// ReasonConn 可获取关闭原因的连接，可在连接关闭回调中获取连接的关闭原因
+type ReasonConn interface {
+	// CloseReason 获取连接关闭原因
+	CloseReason() CloseReason
+}
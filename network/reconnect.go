@@ -0,0 +1,437 @@
+package network
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/utils/xcall"
+	"github.com/devagame/due/v2/utils/xrand"
+)
+
+const (
+	defaultReconnectMinInterval = time.Second      // 默认首次重连等待时间
+	defaultReconnectMaxInterval = 30 * time.Second // 默认最大重连等待时间
+)
+
+// ReconnectOptions 断线重连配置
+type ReconnectOptions struct {
+	Enable      bool          // 是否启用断线重连，默认false
+	MaxAttempts int           // 单次断线的最大重连次数，为0时不限制
+	MinInterval time.Duration // 首次重连的等待时间，此后每次翻倍，为0时使用默认值1s
+	MaxInterval time.Duration // 最大重连等待时间，为0时使用默认值30s
+	Jitter      float64       // 随机抖动比例，取值范围为0~1，避免大量客户端同时重连
+}
+
+// Backoff 获取第attempt次重连前的等待时间
+func (o *ReconnectOptions) Backoff(attempt int) time.Duration {
+	minInterval, maxInterval := o.MinInterval, o.MaxInterval
+
+	if minInterval <= 0 {
+		minInterval = defaultReconnectMinInterval
+	}
+
+	if maxInterval <= 0 {
+		maxInterval = defaultReconnectMaxInterval
+	}
+
+	maxInterval = max(minInterval, maxInterval)
+
+	interval := minInterval
+
+	for i := 1; i < attempt && interval < maxInterval; i++ {
+		interval *= 2
+	}
+
+	interval = min(interval, maxInterval)
+
+	if jitter := min(max(o.Jitter, 0), 1); jitter > 0 {
+		interval += time.Duration(float64(interval) * xrand.Float64(-jitter, jitter))
+	}
+
+	return interval
+}
+
+type reconnectClient struct {
+	client            Client            // 底层客户端
+	opts              ReconnectOptions  // 断线重连配置
+	mu                sync.Mutex        // 拨号锁，保证底层连接的回调晚于连接映射的建立
+	conns             sync.Map          // 底层连接 -> 重连连接
+	connectHandler    ConnectHandler    // 连接打开hook函数
+	disconnectHandler DisconnectHandler // 连接关闭hook函数
+	receiveHandler    ReceiveHandler    // 接收消息hook函数
+	reconnectHandler  ReconnectHandler  // 断线重连成功hook函数
+}
+
+var _ ReconnectClient = &reconnectClient{}
+
+// NewReconnectClient 为客户端附加断线重连能力
+// 连接意外断开后按指数退避重新拨号，重连期间连接ID、用户ID及属性保持不变，推送消息将返回ErrConnectionReconnecting
+// 主动关闭连接、服务端下发关闭原因或重连次数耗尽时才触发连接关闭回调，重连成功时触发断线重连回调
+func NewReconnectClient(client Client, opts ReconnectOptions) ReconnectClient {
+	c := &reconnectClient{client: client, opts: opts}
+	client.OnReceive(c.handleReceive)
+	client.OnDisconnect(c.handleDisconnect)
+
+	return c
+}
+
+// Dial 拨号连接
+func (c *reconnectClient) Dial(addr ...string) (Conn, error) {
+	rc := &reconnectConn{client: c, attr: &attr{}, close: make(chan struct{})}
+
+	if len(addr) > 0 {
+		rc.addr = addr[0]
+	}
+
+	if _, err := c.dial(rc); err != nil {
+		return nil, err
+	}
+
+	if c.connectHandler != nil {
+		c.connectHandler(rc)
+	}
+
+	return rc, nil
+}
+
+// Protocol 协议
+func (c *reconnectClient) Protocol() string {
+	return c.client.Protocol()
+}
+
+// OnConnect 监听连接打开
+func (c *reconnectClient) OnConnect(handler ConnectHandler) {
+	c.connectHandler = handler
+}
+
+// OnReceive 监听接收消息
+func (c *reconnectClient) OnReceive(handler ReceiveHandler) {
+	c.receiveHandler = handler
+}
+
+// OnDisconnect 监听连接断开
+func (c *reconnectClient) OnDisconnect(handler DisconnectHandler) {
+	c.disconnectHandler = handler
+}
+
+// OnReconnect 监听断线重连成功
+func (c *reconnectClient) OnReconnect(handler ReconnectHandler) {
+	c.reconnectHandler = handler
+}
+
+// 拨号并替换重连连接的底层连接
+func (c *reconnectClient) dial(rc *reconnectConn) (Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, err := c.client.Dial(rc.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if uid := rc.uid.Load(); uid != 0 {
+		conn.Bind(uid)
+	}
+
+	if rc.id == 0 {
+		rc.id = conn.ID()
+	}
+
+	rc.rw.Lock()
+	rc.conn = conn
+	rc.rw.Unlock()
+
+	rc.state.Store(int32(ConnOpened))
+
+	c.conns.Store(conn, rc)
+
+	return conn, nil
+}
+
+// 查找底层连接对应的重连连接
+func (c *reconnectClient) load(conn Conn) (*reconnectConn, bool) {
+	if val, ok := c.conns.Load(conn); ok {
+		return val.(*reconnectConn), true
+	}
+
+	// 底层连接可能在拨号返回前就已收到消息或断开，等待拨号完成后再次查找
+	c.mu.Lock()
+	c.mu.Unlock()
+
+	if val, ok := c.conns.Load(conn); ok {
+		return val.(*reconnectConn), true
+	}
+
+	return nil, false
+}
+
+// 处理接收到的消息
+func (c *reconnectClient) handleReceive(conn Conn, buf buffer.Buffer) {
+	rc, ok := c.load(conn)
+	if !ok {
+		buf.Release()
+		return
+	}
+
+	if c.receiveHandler != nil {
+		c.receiveHandler(rc, buf)
+	} else {
+		buf.Release()
+	}
+}
+
+// 处理底层连接断开
+func (c *reconnectClient) handleDisconnect(conn Conn) {
+	rc, ok := c.load(conn)
+	if !ok {
+		return
+	}
+
+	c.conns.Delete(conn)
+
+	if rc.closing.Load() || !c.opts.Enable {
+		rc.finish()
+		return
+	}
+
+	// 服务端下发了关闭原因（如被顶号、封禁）时不再重连
+	if cc, ok := conn.(CodeConn); ok {
+		if code, _, ok := cc.CloseCode(); ok {
+			log.Infof("connection is closed by server, cid: %d, code: %v", rc.id, code)
+			rc.finish()
+			return
+		}
+	}
+
+	if rc.state.CompareAndSwap(int32(ConnOpened), int32(ConnHanged)) {
+		log.Warnf("connection is disconnected, start reconnecting, cid: %d", rc.id)
+
+		xcall.Go(rc.reconnect)
+	}
+}
+
+type reconnectConn struct {
+	id      int64            // 连接ID，沿用首次拨号的底层连接ID
+	uid     atomic.Int64     // 用户ID
+	attr    *attr            // 连接属性，重连后保留
+	addr    string           // 拨号地址
+	state   atomic.Int32     // 连接状态，重连期间为挂起状态
+	client  *reconnectClient // 客户端
+	rw      sync.RWMutex     // 读写锁
+	conn    Conn             // 底层连接，重连期间为已断开的底层连接
+	closing atomic.Bool      // 是否已主动关闭
+	close   chan struct{}    // 关闭信号
+	once    sync.Once        // 保证连接关闭回调仅执行一次
+}
+
+var (
+	_ Conn     = &reconnectConn{}
+	_ CodeConn = &reconnectConn{}
+)
+
+// ID 获取连接ID
+func (c *reconnectConn) ID() int64 {
+	return c.id
+}
+
+// UID 获取用户ID
+func (c *reconnectConn) UID() int64 {
+	return c.uid.Load()
+}
+
+// Attr 获取属性接口
+func (c *reconnectConn) Attr() Attr {
+	return c.attr
+}
+
+// Bind 绑定用户ID，重连后自动绑定到新的底层连接
+func (c *reconnectConn) Bind(uid int64) {
+	c.uid.Store(uid)
+	c.current().Bind(uid)
+}
+
+// Unbind 解绑用户ID
+func (c *reconnectConn) Unbind() {
+	c.uid.Store(0)
+	c.current().Unbind()
+}
+
+// Send 发送消息（同步）
+func (c *reconnectConn) Send(msg []byte) error {
+	conn, err := c.opened()
+	if err != nil {
+		return err
+	}
+
+	return c.wrap(conn.Send(msg))
+}
+
+// Push 发送消息（异步）
+func (c *reconnectConn) Push(msg []byte) error {
+	conn, err := c.opened()
+	if err != nil {
+		return err
+	}
+
+	return c.wrap(conn.Push(msg))
+}
+
+// State 获取连接状态
+func (c *reconnectConn) State() ConnState {
+	return ConnState(c.state.Load())
+}
+
+// Close 关闭连接，重连期间关闭时将终止重连
+func (c *reconnectConn) Close(force ...bool) error {
+	if !c.closing.CompareAndSwap(false, true) {
+		return errors.ErrConnectionClosed
+	}
+
+	close(c.close)
+
+	if c.State() != ConnOpened {
+		return nil
+	}
+
+	return c.current().Close(force...)
+}
+
+// LocalIP 获取本地IP
+func (c *reconnectConn) LocalIP() (string, error) {
+	conn, err := c.opened()
+	if err != nil {
+		return "", err
+	}
+
+	return conn.LocalIP()
+}
+
+// LocalAddr 获取本地地址
+func (c *reconnectConn) LocalAddr() (net.Addr, error) {
+	conn, err := c.opened()
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.LocalAddr()
+}
+
+// RemoteIP 获取远端IP
+func (c *reconnectConn) RemoteIP() (string, error) {
+	conn, err := c.opened()
+	if err != nil {
+		return "", err
+	}
+
+	return conn.RemoteIP()
+}
+
+// RemoteAddr 获取远端地址
+func (c *reconnectConn) RemoteAddr() (net.Addr, error) {
+	conn, err := c.opened()
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.RemoteAddr()
+}
+
+// Stats 获取当前底层连接的流量统计，重连后重新计量
+func (c *reconnectConn) Stats() *ConnStat {
+	return c.current().Stats()
+}
+
+// CloseCode 获取服务端下发的关闭原因码及说明信息，底层连接不支持或未收到关闭包时返回false
+func (c *reconnectConn) CloseCode() (CloseCode, string, bool) {
+	if cc, ok := c.current().(CodeConn); ok {
+		return cc.CloseCode()
+	}
+
+	return CloseNone, "", false
+}
+
+// 获取底层连接
+func (c *reconnectConn) current() Conn {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return c.conn
+}
+
+// 获取处于打开状态的底层连接
+func (c *reconnectConn) opened() (Conn, error) {
+	switch c.State() {
+	case ConnHanged:
+		return nil, errors.ErrConnectionReconnecting
+	case ConnClosed:
+		return nil, errors.ErrConnectionClosed
+	default:
+		return c.current(), nil
+	}
+}
+
+// 底层连接已断开但尚未触发断开回调时，同样视为重连中
+func (c *reconnectConn) wrap(err error) error {
+	if errors.Is(err, errors.ErrConnectionClosed) && c.client.opts.Enable && !c.closing.Load() {
+		return errors.ErrConnectionReconnecting
+	}
+
+	return err
+}
+
+// 按指数退避重新拨号
+func (c *reconnectConn) reconnect() {
+	opts := &c.client.opts
+
+	for attempt := 1; opts.MaxAttempts <= 0 || attempt <= opts.MaxAttempts; attempt++ {
+		timer := time.NewTimer(opts.Backoff(attempt))
+
+		select {
+		case <-c.close:
+			timer.Stop()
+			c.finish()
+			return
+		case <-timer.C:
+		}
+
+		conn, err := c.client.dial(c)
+		if err != nil {
+			log.Warnf("connection reconnect failed, cid: %d, attempt: %d, err: %v", c.id, attempt, err)
+			continue
+		}
+
+		// 重连期间主动关闭了连接，关闭新的底层连接后由断开回调完成关闭
+		if c.closing.Load() {
+			_ = conn.Close(true)
+			return
+		}
+
+		log.Infof("connection is reconnected, cid: %d, attempt: %d", c.id, attempt)
+
+		if c.client.reconnectHandler != nil {
+			c.client.reconnectHandler(c)
+		}
+
+		return
+	}
+
+	log.Warnf("connection reconnect attempts exhausted, cid: %d", c.id)
+
+	c.finish()
+}
+
+// 结束连接并触发连接关闭回调
+func (c *reconnectConn) finish() {
+	c.once.Do(func() {
+		c.state.Store(int32(ConnClosed))
+
+		if c.client.disconnectHandler != nil {
+			c.client.disconnectHandler(c)
+		}
+	})
+}
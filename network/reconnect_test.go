@@ -0,0 +1,220 @@
+package network_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/network/mem"
+	"github.com/devagame/due/v2/packet"
+)
+
+func TestReconnectOptions_Backoff(t *testing.T) {
+	opts := network.ReconnectOptions{MinInterval: 100 * time.Millisecond, MaxInterval: time.Second}
+
+	for attempt, want := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		50: time.Second,
+	} {
+		if got := opts.Backoff(attempt); got != want {
+			t.Fatalf("attempt %d: backoff = %v, want %v", attempt, got, want)
+		}
+	}
+
+	opts.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		if got := opts.Backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff with jitter = %v, out of range", got)
+		}
+	}
+}
+
+func TestReconnectClient_Reconnect(t *testing.T) {
+	server := mem.NewServer(mem.WithServerListenAddr(":10101"))
+
+	accepted := make(chan network.Conn, 16)
+	received := make(chan int64, 16)
+
+	server.OnConnect(func(conn network.Conn) {
+		accepted <- conn
+	})
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		received <- conn.ID()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := network.NewReconnectClient(mem.NewClient(mem.WithClientAddr("127.0.0.1:10101")), network.ReconnectOptions{
+		Enable:      true,
+		MinInterval: 10 * time.Millisecond,
+	})
+
+	reconnected := make(chan network.Conn, 16)
+	disconnected := make(chan network.Conn, 16)
+
+	client.OnReconnect(func(conn network.Conn) { reconnected <- conn })
+	client.OnDisconnect(func(conn network.Conn) { disconnected <- conn })
+
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Bind(7)
+	conn.Attr().Set("key", "value")
+
+	first := <-accepted
+
+	if err = first.Close(true); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case c := <-reconnected:
+		if c != conn {
+			t.Fatal("reconnected connection mismatch")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconnect timeout")
+	}
+
+	second := <-accepted
+
+	if conn.UID() != 7 {
+		t.Fatalf("uid = %d, want 7", conn.UID())
+	}
+
+	if val, ok := conn.Attr().Get("key"); !ok || val != "value" {
+		t.Fatal("attr is lost after reconnect")
+	}
+
+	msg, err := packet.PackMessage(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn.Push(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case id := <-received:
+		if id != second.ID() {
+			t.Fatalf("message received by connection %d, want %d", id, second.ID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive message timeout")
+	}
+
+	if err = conn.Close(true); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnect timeout")
+	}
+
+	select {
+	case <-reconnected:
+		t.Fatal("reconnected after active close")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReconnectClient_CloseCode(t *testing.T) {
+	server := mem.NewServer(mem.WithServerListenAddr(":10103"))
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() { _ = network.CloseWithCode(conn, network.CloseBanned, "banned") }()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := network.NewReconnectClient(mem.NewClient(mem.WithClientAddr("127.0.0.1:10103")), network.ReconnectOptions{
+		Enable:      true,
+		MinInterval: 10 * time.Millisecond,
+	})
+
+	reconnected := make(chan network.Conn, 16)
+	disconnected := make(chan network.Conn, 1)
+
+	client.OnReconnect(func(conn network.Conn) { reconnected <- conn })
+	client.OnDisconnect(func(conn network.Conn) { disconnected <- conn })
+
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnect timeout")
+	}
+
+	if code, message, ok := conn.(network.CodeConn).CloseCode(); !ok || code != network.CloseBanned || message != "banned" {
+		t.Fatalf("code = %v, message = %q, ok = %v", code, message, ok)
+	}
+
+	select {
+	case <-reconnected:
+		t.Fatal("reconnected after close code received")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReconnectClient_Exhausted(t *testing.T) {
+	server := mem.NewServer(mem.WithServerListenAddr(":10102"))
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	client := network.NewReconnectClient(mem.NewClient(mem.WithClientAddr("127.0.0.1:10102")), network.ReconnectOptions{
+		Enable:      true,
+		MaxAttempts: 3,
+		MinInterval: 10 * time.Millisecond,
+	})
+
+	disconnected := make(chan network.Conn, 1)
+
+	client.OnDisconnect(func(conn network.Conn) { disconnected <- conn })
+
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = server.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconnect attempts are not exhausted")
+	}
+
+	if conn.State() != network.ConnClosed {
+		t.Fatalf("state = %v, want %v", conn.State(), network.ConnClosed)
+	}
+
+	if err = conn.Push([]byte("hello")); !errors.Is(err, errors.ErrConnectionClosed) {
+		t.Fatalf("push error = %v, want %v", err, errors.ErrConnectionClosed)
+	}
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Setting 可在运行时调整的配置值
+type Setting[T comparable] struct {
+	value   atomic.Pointer[T]
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+func NewSetting[T comparable](v T) *Setting[T] {
+	s := &Setting[T]{changed: make(chan struct{})}
+	s.value.Store(&v)
+
+	return s
+}
+
+// Load 获取配置值
+func (s *Setting[T]) Load() T {
+	return *s.value.Load()
+}
+
+// Store 设置配置值，配置值发生变化时通知所有等待者
+func (s *Setting[T]) Store(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if *s.value.Load() == v {
+		return
+	}
+
+	s.value.Store(&v)
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Changed 获取配置变化通知通道，配置值发生变化时通道会被关闭
+// 每次收到通知后需重新获取通道
+func (s *Setting[T]) Changed() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.changed
+}
+
+// NewTicker 新建定时器，间隔时间小于等于0时定时器处于停止状态，可通过ResetTicker重新启动
+func NewTicker(d time.Duration) *time.Ticker {
+	if d > 0 {
+		return time.NewTicker(d)
+	}
+
+	t := time.NewTicker(time.Hour)
+	t.Stop()
+
+	return t
+}
+
+// ResetTicker 重置定时器，间隔时间小于等于0时停止定时器
+func ResetTicker(t *time.Ticker, d time.Duration) {
+	if d > 0 {
+		t.Reset(d)
+	} else {
+		t.Stop()
+	}
+}
@@ -0,0 +1,119 @@
+package sse
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+)
+
+type client struct {
+	opts              *clientOptions            // 配置
+	id                int64                     // 连接ID
+	httpClient        *http.Client              // HTTP客户端
+	connectHandler    network.ConnectHandler    // 连接打开hook函数
+	disconnectHandler network.DisconnectHandler // 连接关闭hook函数
+	receiveHandler    network.ReceiveHandler    // 接收消息hook函数
+}
+
+var _ network.Client = &client{}
+
+func NewClient(opts ...ClientOption) network.Client {
+	o := defaultClientOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &client{opts: o, httpClient: &http.Client{}}
+}
+
+// Dial 拨号连接，addr为服务端路由前缀
+func (c *client) Dial(addr ...string) (network.Conn, error) {
+	var url string
+
+	if len(addr) > 0 && addr[0] != "" {
+		url = addr[0]
+	} else {
+		url = c.opts.url
+	}
+
+	url = strings.TrimSuffix(url, "/") + "/"
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.timeout)
+	defer cancel()
+
+	req, err := c.newRequest(ctx, http.MethodPost, url+routeConnect, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewError(errors.ErrConnectionRefused, resp.Status)
+	}
+
+	token := resp.Header.Get(tokenHeader)
+	if token == "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return nil, err
+		}
+
+		token = string(body)
+	}
+
+	if token == "" {
+		return nil, errors.ErrIllegalRequest
+	}
+
+	return newClientConn(atomic.AddInt64(&c.id, 1), url, token, c), nil
+}
+
+// Protocol 协议
+func (c *client) Protocol() string {
+	return protocol
+}
+
+// OnConnect 监听连接打开
+func (c *client) OnConnect(handler network.ConnectHandler) {
+	c.connectHandler = handler
+}
+
+// OnDisconnect 监听连接关闭
+func (c *client) OnDisconnect(handler network.DisconnectHandler) {
+	c.disconnectHandler = handler
+}
+
+// OnReceive 监听接收到消息
+func (c *client) OnReceive(handler network.ReceiveHandler) {
+	c.receiveHandler = handler
+}
+
+// 构建携带会话令牌的请求
+func (c *client) newRequest(ctx context.Context, method, url, token string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range c.opts.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if token != "" {
+		req.Header.Set(tokenHeader, token)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,658 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+	"github.com/devagame/due/v2/utils/xcall"
+	"github.com/devagame/due/v2/utils/xnet"
+	"github.com/devagame/due/v2/utils/xtime"
+)
+
+type clientConn struct {
+	rw                sync.RWMutex        // 锁
+	id                int64               // 连接ID
+	uid               atomic.Int64        // 用户ID
+	attr              *attr               // 连接属性
+	url               string              // 服务端路由前缀
+	token             string              // 会话令牌
+	state             atomic.Int32        // 连接状态
+	client            *client             // 客户端
+	opened            bool                // 写入队列是否可用
+	chWrite           chan chWrite        // 写入队列
+	ctx               context.Context     // 下行请求上下文
+	cancel            context.CancelFunc  // 取消挂起的下行请求
+	remoteClosed      atomic.Bool         // 会话是否已被服务端关闭
+	localAddr         atomic.Value        // 本地地址
+	remoteAddr        atomic.Value        // 远端地址
+	lastHeartbeatTime atomic.Int64        // 上次心跳时间
+	done              chan struct{}       // 写入完成信号
+	close             chan struct{}       // 关闭信号
+	meter             network.ConnMeter   // 流量计量
+	notice            network.CloseNotice // 关闭通知
+}
+
+var (
+	_ network.Conn     = &clientConn{}
+	_ network.CodeConn = &clientConn{}
+)
+
+func newClientConn(id int64, url, token string, client *client) network.Conn {
+	c := &clientConn{
+		id:      id,
+		attr:    &attr{},
+		url:     url,
+		token:   token,
+		client:  client,
+		opened:  true,
+		chWrite: make(chan chWrite, 4096),
+		done:    make(chan struct{}, 1),
+		close:   make(chan struct{}),
+	}
+
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.state.Store(int32(network.ConnOpened))
+	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+	c.meter.Reset()
+
+	xcall.Go(c.read)
+
+	xcall.Go(c.write)
+
+	if c.client.connectHandler != nil {
+		c.client.connectHandler(c)
+	}
+
+	return c
+}
+
+// ID 获取连接ID
+func (c *clientConn) ID() int64 {
+	return c.id
+}
+
+// UID 获取用户ID
+func (c *clientConn) UID() int64 {
+	return c.uid.Load()
+}
+
+// Attr 获取属性接口
+func (c *clientConn) Attr() network.Attr {
+	return c.attr
+}
+
+// Bind 绑定用户ID
+func (c *clientConn) Bind(uid int64) {
+	c.uid.Store(uid)
+}
+
+// Unbind 解绑用户ID
+func (c *clientConn) Unbind() {
+	c.uid.Store(0)
+}
+
+// Send 发送消息（同步）
+// 上行消息由写入协程合并后以POST请求发送，故而与Push一致，写入队列后即返回
+func (c *clientConn) Send(msg []byte) error {
+	return c.Push(msg)
+}
+
+// Push 发送消息（异步）
+func (c *clientConn) Push(msg []byte) error {
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	if !c.opened {
+		return errors.ErrConnectionClosed
+	}
+
+	c.chWrite <- chWrite{typ: dataPacket, msg: msg}
+
+	return nil
+}
+
+// CloseCode 获取服务端下发的关闭原因码及说明信息，未收到关闭包时返回false
+func (c *clientConn) CloseCode() (network.CloseCode, string, bool) {
+	return c.notice.Load()
+}
+
+// Stats 获取连接流量统计
+func (c *clientConn) Stats() *network.ConnStat {
+	return c.meter.Stat()
+}
+
+// State 获取连接状态
+func (c *clientConn) State() network.ConnState {
+	return network.ConnState(c.state.Load())
+}
+
+// Close 关闭连接（主动关闭）
+func (c *clientConn) Close(force ...bool) error {
+	if len(force) > 0 && force[0] {
+		return c.forceClose()
+	} else {
+		return c.graceClose()
+	}
+}
+
+// LocalIP 获取本地IP
+func (c *clientConn) LocalIP() (string, error) {
+	addr, err := c.LocalAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// LocalAddr 获取本地地址，即最近一次请求使用的本地地址
+func (c *clientConn) LocalAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	addr, ok := c.localAddr.Load().(net.Addr)
+	if !ok {
+		return nil, errors.ErrNotFoundIPAddress
+	}
+
+	return addr, nil
+}
+
+// RemoteIP 获取远端IP
+func (c *clientConn) RemoteIP() (string, error) {
+	addr, err := c.RemoteAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// RemoteAddr 获取远端地址，即最近一次请求使用的远端地址
+func (c *clientConn) RemoteAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	addr, ok := c.remoteAddr.Load().(net.Addr)
+	if !ok {
+		return nil, errors.ErrNotFoundIPAddress
+	}
+
+	return addr, nil
+}
+
+// 检测连接状态
+func (c *clientConn) checkState() error {
+	switch c.State() {
+	case network.ConnHanged:
+		return errors.ErrConnectionHanged
+	case network.ConnClosed:
+		return errors.ErrConnectionClosed
+	default:
+		return nil
+	}
+}
+
+// 优雅关闭，等待已入队的上行消息发送完毕
+func (c *clientConn) graceClose() error {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnHanged)) {
+		return errors.ErrConnectionNotOpened
+	}
+
+	c.rw.RLock()
+	if !c.opened {
+		c.rw.RUnlock()
+		return errors.ErrConnectionClosed
+	}
+	c.chWrite <- chWrite{typ: closeSig}
+	c.rw.RUnlock()
+
+	<-c.done
+
+	if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+		return errors.ErrConnectionNotHanged
+	}
+
+	return c.doClose()
+}
+
+// 强制关闭
+func (c *clientConn) forceClose() error {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnClosed)) {
+		if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+			return errors.ErrConnectionClosed
+		}
+	}
+
+	return c.doClose()
+}
+
+// 会话已被服务端关闭
+func (c *clientConn) remoteClose() {
+	c.remoteClosed.Store(true)
+
+	_ = c.forceClose()
+}
+
+// 执行关闭操作
+func (c *clientConn) doClose() error {
+	c.rw.Lock()
+
+	if !c.opened {
+		c.rw.Unlock()
+		return errors.ErrConnectionClosed
+	}
+
+	c.opened = false
+	close(c.chWrite)
+	close(c.close)
+	close(c.done)
+	c.cancel()
+	c.rw.Unlock()
+
+	var err error
+
+	if !c.remoteClosed.Load() {
+		err = c.closeSession()
+	}
+
+	if c.client.disconnectHandler != nil {
+		c.client.disconnectHandler(c)
+	}
+
+	return err
+}
+
+// 通知服务端关闭会话
+func (c *clientConn) closeSession() error {
+	resp, err := c.request(context.Background(), http.MethodPost, routeClose, nil)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	return nil
+}
+
+// 发起携带会话令牌的请求，POST请求受超时时间限制，GET请求需由调用方关闭响应体
+func (c *clientConn) request(ctx context.Context, method, route string, body io.Reader) (*http.Response, error) {
+	if method != http.MethodPost {
+		return c.doRequest(ctx, method, route, body)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.client.opts.timeout)
+	defer cancel()
+
+	resp, err := c.doRequest(ctx, method, route, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 上行请求的响应体为空，提前读取完毕以便释放上下文
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp, nil
+}
+
+// 执行请求并记录连接地址
+func (c *clientConn) doRequest(ctx context.Context, method, route string, body io.Reader) (*http.Response, error) {
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.localAddr.Store(info.Conn.LocalAddr())
+			c.remoteAddr.Store(info.Conn.RemoteAddr())
+		},
+	})
+
+	req, err := c.client.newRequest(ctx, method, c.url+route, c.token, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	return c.client.httpClient.Do(req)
+}
+
+// 读取消息
+func (c *clientConn) read() {
+	for {
+		select {
+		case <-c.close:
+			return
+		default:
+			var (
+				closed bool
+				err    error
+			)
+
+			if c.client.opts.mode == PollMode {
+				closed, err = c.poll()
+			} else {
+				closed, err = c.stream()
+			}
+
+			if c.isClosed() {
+				return
+			}
+
+			if err != nil {
+				log.Warnf("read message failed: %v", err)
+				_ = c.forceClose()
+				return
+			}
+
+			if closed {
+				c.remoteClose()
+				return
+			}
+		}
+	}
+}
+
+// 以SSE接收下行消息，返回会话是否已被服务端关闭
+func (c *clientConn) stream() (bool, error) {
+	resp, err := c.request(c.ctx, http.MethodGet, routeEvents, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return true, nil
+	default:
+		return false, errors.NewError(errors.ErrIllegalRequest, resp.Status)
+	}
+
+	var (
+		event   string
+		data    []byte
+		scanner = bufio.NewScanner(resp.Body)
+	)
+
+	scanner.Buffer(make([]byte, 0, 4096), base64.StdEncoding.EncodedLen(maxBodyBytes)+64)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		switch {
+		case len(line) == 0:
+			if event == closeEvent {
+				return true, nil
+			}
+
+			if len(data) > 0 {
+				msg, err := base64.StdEncoding.DecodeString(string(data))
+				if err != nil {
+					log.Errorf("decode data message error: %v", err)
+				} else {
+					c.dispatch(msg)
+				}
+			}
+
+			event, data = "", data[:0]
+		case line[0] == ':':
+			// 保活注释
+			c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+		case bytes.HasPrefix(line, []byte("event:")):
+			event = string(bytes.TrimSpace(line[len("event:"):]))
+		case bytes.HasPrefix(line, []byte("data:")):
+			data = append(data, bytes.TrimSpace(line[len("data:"):])...)
+		}
+	}
+
+	// 中间代理断开了SSE请求，重新发起即可
+	if err = scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// 以长轮询接收下行消息，返回会话是否已被服务端关闭
+func (c *clientConn) poll() (bool, error) {
+	resp, err := c.request(c.ctx, http.MethodGet, routePoll, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+		return false, nil
+	case http.StatusOK:
+	case http.StatusGone:
+		return true, nil
+	default:
+		return false, errors.NewError(errors.ErrIllegalRequest, resp.Status)
+	}
+
+	for {
+		msg, err := packet.ReadMessage(resp.Body)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		c.dispatch(msg)
+	}
+}
+
+// 分发下行消息
+func (c *clientConn) dispatch(msg []byte) {
+	if c.client.opts.heartbeatInterval > 0 {
+		c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+	}
+
+	switch c.State() {
+	case network.ConnHanged:
+		return
+	case network.ConnClosed:
+		return
+	default:
+		// ignore
+	}
+
+	// ignore empty packet
+	if len(msg) == 0 {
+		return
+	}
+
+	// check heartbeat packet
+	isHeartbeat, err := packet.CheckHeartbeat(msg)
+	if err != nil {
+		log.Errorf("check heartbeat message error: %v", err)
+		return
+	}
+
+	c.meter.Receive(len(msg), isHeartbeat)
+
+	// ignore heartbeat packet
+	if isHeartbeat {
+		// 记录对端下发的关闭包，连接随后由对端关闭
+		if c.notice.Receive(msg) {
+			return
+		}
+
+		// 回显服务端下发的探测心跳，供服务端测量往返时延
+		if _, ok := packet.UnpackPing(msg); ok {
+			c.rw.RLock()
+			if c.opened {
+				c.chWrite <- chWrite{typ: heartbeatPacket, msg: msg}
+			}
+			c.rw.RUnlock()
+		}
+		return
+	}
+
+	if c.client.receiveHandler != nil {
+		c.client.receiveHandler(c, buffer.NewBytes(msg))
+	}
+}
+
+// 写入消息，将队列中的消息合并为一个上行请求发送
+func (c *clientConn) write() {
+	var ticker *time.Ticker
+
+	if c.client.opts.heartbeatInterval > 0 {
+		ticker = time.NewTicker(c.client.opts.heartbeatInterval)
+		defer ticker.Stop()
+	} else {
+		ticker = &time.Ticker{C: make(chan time.Time, 1)}
+	}
+
+	batch := make([]chWrite, 0, 64)
+
+	for {
+		select {
+		case r, ok := <-c.chWrite:
+			if !ok {
+				return
+			}
+
+			var closing bool
+
+			batch, closing = c.batch(batch[:0], r)
+
+			if len(batch) > 0 {
+				c.doWrite(batch)
+			}
+
+			if closing {
+				c.rw.RLock()
+				if c.opened {
+					select {
+					case c.done <- struct{}{}:
+					default:
+					}
+				}
+				c.rw.RUnlock()
+				return
+			}
+		case t := <-ticker.C:
+			if !c.doHandleHeartbeat(t) {
+				return
+			}
+		}
+	}
+}
+
+// 合并队列中已有的消息，返回合并的消息及是否收到关闭信号
+func (c *clientConn) batch(batch []chWrite, r chWrite) ([]chWrite, bool) {
+	size := 0
+
+	for {
+		if r.typ == closeSig {
+			return batch, true
+		}
+
+		batch = append(batch, r)
+		size += len(r.msg)
+
+		if size >= maxPollBytes {
+			return batch, false
+		}
+
+		select {
+		case next, ok := <-c.chWrite:
+			if !ok {
+				return batch, false
+			}
+			r = next
+		default:
+			return batch, false
+		}
+	}
+}
+
+// 执行写入操作
+func (c *clientConn) doWrite(batch []chWrite) {
+	if c.isClosed() {
+		return
+	}
+
+	var body []byte
+
+	if len(batch) == 1 {
+		body = batch[0].msg
+	} else {
+		for _, r := range batch {
+			body = append(body, r.msg...)
+		}
+	}
+
+	resp, err := c.request(c.ctx, http.MethodPost, routeSend, bytes.NewReader(body))
+	if err != nil {
+		if !c.isClosed() && !errors.Is(err, context.Canceled) {
+			log.Errorf("write message error: %v", err)
+		}
+		return
+	}
+	_ = resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		for _, r := range batch {
+			c.meter.Send(len(r.msg), r.typ == heartbeatPacket)
+		}
+	case http.StatusGone:
+		xcall.Go(c.remoteClose)
+	default:
+		log.Errorf("write message error: %s", resp.Status)
+	}
+}
+
+// 处理心跳
+func (c *clientConn) doHandleHeartbeat(t time.Time) bool {
+	deadline := t.Add(-2 * c.client.opts.heartbeatInterval).UnixNano()
+
+	if c.lastHeartbeatTime.Load() < deadline {
+		log.Debugf("connection heartbeat timeout, cid: %d", c.id)
+		_ = c.forceClose()
+		return false
+	}
+
+	if c.isClosed() {
+		return false
+	}
+
+	if heartbeat, err := packet.PackHeartbeat(); err != nil {
+		log.Errorf("pack heartbeat message error: %v", err)
+	} else {
+		c.doWrite([]chWrite{{typ: heartbeatPacket, msg: heartbeat}})
+	}
+
+	return true
+}
+
+// 是否已关闭
+func (c *clientConn) isClosed() bool {
+	return c.State() == network.ConnClosed
+}
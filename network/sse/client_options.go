@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/devagame/due/v2/etc"
+)
+
+const (
+	defaultClientUrl               = "http://127.0.0.1:3553/"
+	defaultClientMode              = "sse"
+	defaultClientTimeout           = "10s"
+	defaultClientHeartbeatInterval = "10s"
+)
+
+const (
+	defaultClientUrlKey               = "etc.network.sse.client.url"
+	defaultClientModeKey              = "etc.network.sse.client.mode"
+	defaultClientTimeoutKey           = "etc.network.sse.client.timeout"
+	defaultClientHeartbeatIntervalKey = "etc.network.sse.client.heartbeatInterval"
+)
+
+type ClientOption func(o *clientOptions)
+
+type clientOptions struct {
+	url               string        // 服务器地址，即服务端路由前缀
+	mode              Mode          // 下行消息的接收方式，默认sse
+	timeout           time.Duration // 建立会话及发送上行消息的请求超时时间，默认10s
+	heartbeatInterval time.Duration // 心跳间隔时间，默认10s
+	header            http.Header   // 请求头
+}
+
+func defaultClientOptions() *clientOptions {
+	return &clientOptions{
+		url:               etc.Get(defaultClientUrlKey, defaultClientUrl).String(),
+		mode:              Mode(etc.Get(defaultClientModeKey, defaultClientMode).String()),
+		timeout:           etc.Get(defaultClientTimeoutKey, defaultClientTimeout).Duration(),
+		heartbeatInterval: etc.Get(defaultClientHeartbeatIntervalKey, defaultClientHeartbeatInterval).Duration(),
+	}
+}
+
+// WithClientUrl 设置服务器地址
+func WithClientUrl(url string) ClientOption {
+	return func(o *clientOptions) { o.url = url }
+}
+
+// WithClientMode 设置下行消息的接收方式
+func WithClientMode(mode Mode) ClientOption {
+	return func(o *clientOptions) { o.mode = mode }
+}
+
+// WithClientTimeout 设置建立会话及发送上行消息的请求超时时间
+func WithClientTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) { o.timeout = timeout }
+}
+
+// WithClientHeartbeatInterval 设置心跳间隔时间
+func WithClientHeartbeatInterval(heartbeatInterval time.Duration) ClientOption {
+	return func(o *clientOptions) { o.heartbeatInterval = heartbeatInterval }
+}
+
+// WithClientHeader 设置请求头，可用于携带鉴权令牌
+func WithClientHeader(header http.Header) ClientOption {
+	return func(o *clientOptions) { o.header = header }
+}
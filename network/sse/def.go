@@ -0,0 +1,38 @@
+package sse
+
+const protocol = "sse"
+
+const (
+	closeSig        int = iota // 关闭信号
+	dataPacket                 // 数据包
+	heartbeatPacket            // 心跳包，不占用写入队列配额
+)
+
+type chWrite struct {
+	typ      int
+	msg      []byte
+	critical bool // 是否为关键消息，写入队列溢出时不会被丢弃
+}
+
+const (
+	routeConnect = "connect" // 建立会话
+	routeEvents  = "events"  // 以SSE接收下行消息
+	routePoll    = "poll"    // 以长轮询接收下行消息
+	routeSend    = "send"    // 发送上行消息
+	routeClose   = "close"   // 关闭会话
+)
+
+const (
+	tokenHeader  = "X-Session-Token" // 会话令牌请求头
+	tokenQuery   = "token"           // 会话令牌查询参数
+	closeEvent   = "close"           // SSE关闭事件
+	maxBodyBytes = 4 << 20           // 上行请求的最大字节数
+	maxPollBytes = 64 << 10          // 单次长轮询响应的最大字节数，首条消息不受限制
+)
+
+type Mode string
+
+const (
+	SSEMode  Mode = "sse"  // 以SSE接收下行消息
+	PollMode Mode = "poll" // 以长轮询接收下行消息
+)
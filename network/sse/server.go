@@ -0,0 +1,445 @@
+package sse
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/core/proxyproto"
+	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/utils/xcall"
+)
+
+type server struct {
+	opts              *serverOptions                  // 配置
+	listener          net.Listener                    // 监听器
+	httpServer        *http.Server                    // HTTP服务器
+	trusted           proxyproto.Trusted              // 可信代理网段
+	id                atomic.Int64                    // 连接ID
+	total             atomic.Int64                    // 总连接数
+	sessions          sync.Map                        // 会话，令牌 -> 连接
+	closed            atomic.Bool                     // 是否已关闭
+	startHandler      network.StartHandler            // 服务器启动hook函数
+	stopHandler       network.CloseHandler            // 服务器关闭hook函数
+	connectHandler    network.ConnectHandler          // 连接打开hook函数
+	disconnectHandler network.DisconnectHandler       // 连接关闭hook函数
+	receiveHandler    network.ReceiveHandler          // 接收消息hook函数
+	maxConnNum        *network.Setting[int]           // 最大连接数，可在运行时调整
+	heartbeatInterval *network.Setting[time.Duration] // 心跳检测间隔时间，可在运行时调整
+	authorizeTimeout  *network.Setting[time.Duration] // 授权超时时间，可在运行时调整
+	queueMetrics      network.QueueMetrics            // 写入队列指标
+	guard             *network.Guard                  // 连接准入控制器
+	cancels           []func()                        // 取消配置监听
+}
+
+var (
+	_ network.Server      = &server{}
+	_ network.QueueStater = &server{}
+	_ network.GuardServer = &server{}
+)
+
+// NewServer 新建HTTP长连接回退服务器，适用于禁用了WebSocket的网络环境
+// 客户端先以POST请求建立会话并获得会话令牌，随后携带令牌以POST请求发送上行消息，以SSE或长轮询接收下行消息
+// 上下行消息均沿用packet的打包格式，SSE下行消息以base64编码
+func NewServer(opts ...ServerOption) network.Server {
+	o := defaultServerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	s := &server{}
+	s.opts = o
+	s.maxConnNum = network.NewSetting(o.maxConnNum)
+	s.heartbeatInterval = network.NewSetting(o.heartbeatInterval)
+	s.authorizeTimeout = network.NewSetting(o.authorizeTimeout)
+	s.guard = network.NewGuard(o.guard)
+
+	return s
+}
+
+// Addr 监听地址
+func (s *server) Addr() string {
+	return s.opts.addr
+}
+
+// Protocol 协议
+func (s *server) Protocol() string {
+	return protocol
+}
+
+// Start 启动服务器
+func (s *server) Start() error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	s.watch()
+
+	if s.startHandler != nil {
+		s.startHandler()
+	}
+
+	xcall.Go(s.serve)
+
+	return nil
+}
+
+// Stop 关闭服务器
+// 已建立的会话将在下行消息被取走或等待一个长轮询周期后关闭
+func (s *server) Stop() error {
+	if !s.closed.CompareAndSwap(false, true) {
+		return errors.ErrServerClosed
+	}
+
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+
+	var wg sync.WaitGroup
+
+	s.sessions.Range(func(_, value any) bool {
+		conn := value.(*serverConn)
+
+		wg.Add(1)
+
+		xcall.Go(func() {
+			_ = conn.Close()
+			wg.Done()
+		})
+
+		return true
+	})
+
+	wg.Wait()
+
+	if err := s.httpServer.Close(); err != nil {
+		return err
+	}
+
+	if s.stopHandler != nil {
+		s.stopHandler()
+	}
+
+	return nil
+}
+
+// QueueStat 获取写入队列统计
+func (s *server) QueueStat() *network.QueueStat {
+	return s.queueMetrics.Stat()
+}
+
+// Guard 获取连接准入控制器
+func (s *server) Guard() *network.Guard {
+	return s.guard
+}
+
+// OnStart 监听服务器启动
+func (s *server) OnStart(handler network.StartHandler) {
+	s.startHandler = handler
+}
+
+// OnStop 监听服务器关闭
+func (s *server) OnStop(handler network.CloseHandler) {
+	s.stopHandler = handler
+}
+
+// OnConnect 监听连接打开
+func (s *server) OnConnect(handler network.ConnectHandler) {
+	s.connectHandler = handler
+}
+
+// OnReceive 监听接收到消息
+func (s *server) OnReceive(handler network.ReceiveHandler) {
+	s.receiveHandler = handler
+}
+
+// OnDisconnect 监听连接断开
+func (s *server) OnDisconnect(handler network.DisconnectHandler) {
+	s.disconnectHandler = handler
+}
+
+// 初始化服务器
+func (s *server) init() error {
+	if err := s.guard.SetAllowList(s.opts.guard.AllowList); err != nil {
+		return err
+	}
+
+	if err := s.guard.SetDenyList(s.opts.guard.DenyList); err != nil {
+		return err
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", s.opts.addr)
+	if err != nil {
+		return err
+	}
+
+	if s.trusted, err = proxyproto.ParseTrusted(s.opts.trustedProxies); err != nil {
+		return err
+	}
+
+	if len(s.trusted) == 0 && (s.opts.forwardedHeaders) {
+		log.Warnf("%s server no trusted proxies are configured, proxy headers will be ignored", protocol)
+	}
+
+	ln, err := net.ListenTCP(addr.Network(), addr)
+	if err != nil {
+		return err
+	}
+
+	prefix := strings.TrimSuffix(s.opts.path, "/") + "/"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(prefix+routeConnect, s.cors(http.MethodPost, s.handleConnect))
+	mux.HandleFunc(prefix+routeEvents, s.cors(http.MethodGet, s.session(s.handleEvents)))
+	mux.HandleFunc(prefix+routePoll, s.cors(http.MethodGet, s.session(s.handlePoll)))
+	mux.HandleFunc(prefix+routeSend, s.cors(http.MethodPost, s.session(s.handleSend)))
+	mux.HandleFunc(prefix+routeClose, s.cors(http.MethodPost, s.session(s.handleClose)))
+
+	s.listener = ln
+	s.httpServer = &http.Server{Handler: mux}
+
+	return nil
+}
+
+// 监听etc配置变化
+// 最大连接数、心跳检测间隔时间、授权超时时间、单IP最大连接数、黑白名单可在运行时生效，其他配置需重启后生效
+func (s *server) watch() {
+	s.cancels = s.cancels[:0]
+
+	s.onChange(defaultServerMaxConnNumKey, func(val value.Value) {
+		s.maxConnNum.Store(val.Int())
+	}, defaultServerMaxConnNum)
+
+	s.onChange(defaultServerHeartbeatIntervalKey, func(val value.Value) {
+		s.heartbeatInterval.Store(val.Duration())
+	}, defaultServerHeartbeatInterval)
+
+	s.onChange(defaultServerAuthorizeTimeoutKey, func(val value.Value) {
+		s.authorizeTimeout.Store(val.Duration())
+	}, defaultServerAuthorizeTimeout)
+
+	s.onChange(defaultServerMaxConnPerIPKey, func(val value.Value) {
+		s.guard.SetMaxConnPerIP(val.Int())
+	})
+
+	s.onChange(defaultServerAllowListKey, func(val value.Value) {
+		if err := s.guard.SetAllowList(val.Strings()); err != nil {
+			log.Warnf("%s server allow list update failed: %v", protocol, err)
+		}
+	})
+
+	s.onChange(defaultServerDenyListKey, func(val value.Value) {
+		if err := s.guard.SetDenyList(val.Strings()); err != nil {
+			log.Warnf("%s server deny list update failed: %v", protocol, err)
+		}
+	})
+
+	etc.RestartOnly(
+		defaultServerAddrKey,
+		defaultServerPathKey,
+		defaultServerCheckOriginsKey,
+		defaultServerKeyFileKey,
+		defaultServerCertFileKey,
+		defaultServerHeartbeatMechanismKey,
+		defaultServerPollTimeoutKey,
+		defaultServerForwardedHeadersKey,
+		defaultServerTrustedProxiesKey,
+		defaultServerQueueMaxMessagesKey,
+		defaultServerQueueMaxBytesKey,
+		defaultServerQueuePolicyKey,
+		defaultServerAcceptRateKey,
+		defaultServerAcceptBurstKey,
+	)
+}
+
+// 监听etc配置变化，显式设置的配置项不监听
+func (s *server) onChange(key string, fn func(val value.Value), def ...any) {
+	if _, ok := s.opts.fixed[key]; ok {
+		return
+	}
+
+	s.cancels = append(s.cancels, etc.OnChange(key, fn, def...))
+}
+
+// 启动服务器
+func (s *server) serve() {
+	var err error
+	if s.opts.certFile != "" && s.opts.keyFile != "" {
+		err = s.httpServer.ServeTLS(s.listener, s.opts.certFile, s.opts.keyFile)
+	} else {
+		err = s.httpServer.Serve(s.listener)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Errorf("sse server shutdown, err: %v", err)
+	}
+}
+
+// 跨域处理及请求方法校验
+func (s *server) cors(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if s.opts.checkOrigin == nil || !s.opts.checkOrigin(r) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Headers", tokenHeader+", Content-Type")
+			w.Header().Set("Access-Control-Expose-Headers", tokenHeader)
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", method)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if r.Method != method {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// 根据会话令牌查找连接，会话不存在或已关闭时返回410
+func (s *server) session(next func(w http.ResponseWriter, r *http.Request, conn *serverConn)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(tokenHeader)
+		if token == "" {
+			token = r.URL.Query().Get(tokenQuery)
+		}
+
+		v, ok := s.sessions.Load(token)
+		if !ok || token == "" {
+			http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+			return
+		}
+
+		next(w, r, v.(*serverConn))
+	}
+}
+
+// 建立会话，响应体为会话令牌
+func (s *server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	if s.closed.Load() {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	addr := s.requestAddr(r)
+
+	if err := s.guard.Admit(addr); err != nil {
+		log.Warnf("sse connection rejected, addr: %v, reason: %v", addr, err)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	conn, err := s.allocate(addr)
+	if err != nil {
+		s.guard.Release(addr)
+		log.Errorf("connection allocate error: %v", err)
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set(tokenHeader, conn.token)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write([]byte(conn.token))
+}
+
+// 以SSE下发消息
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request, conn *serverConn) {
+	conn.stream(w, r)
+}
+
+// 以长轮询下发消息
+func (s *server) handlePoll(w http.ResponseWriter, r *http.Request, conn *serverConn) {
+	conn.poll(w, r)
+}
+
+// 接收上行消息
+func (s *server) handleSend(w http.ResponseWriter, r *http.Request, conn *serverConn) {
+	if err := conn.receive(http.MaxBytesReader(w, r.Body, maxBodyBytes)); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// 客户端主动关闭会话
+func (s *server) handleClose(w http.ResponseWriter, r *http.Request, conn *serverConn) {
+	_ = conn.closeWithReason(network.ReasonClientClosed)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// 分配连接，addr为准入地址
+func (s *server) allocate(addr net.Addr) (*serverConn, error) {
+	if s.total.Load() >= int64(s.maxConnNum.Load()) {
+		return nil, errors.ErrTooManyConnection
+	}
+
+	token, err := makeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := newServerConn(s, s.id.Add(1), token, addr)
+
+	s.sessions.Store(token, conn)
+	s.total.Add(1)
+
+	conn.init()
+
+	return conn, nil
+}
+
+// 回收连接
+func (s *server) recycle(conn *serverConn) {
+	if _, ok := s.sessions.LoadAndDelete(conn.token); ok {
+		s.guard.Release(conn.addr)
+		s.total.Add(-1)
+	}
+}
+
+// 获取请求的客户端地址，启用请求头解析时优先使用请求头中的客户端地址
+func (s *server) requestAddr(r *http.Request) net.Addr {
+	var addr net.Addr
+
+	if ap, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		addr = net.TCPAddrFromAddrPort(ap)
+	}
+
+	if s.opts.forwardedHeaders && addr != nil {
+		addr = proxyproto.ForwardedAddr(r.Header, addr, s.trusted)
+	}
+
+	return addr
+}
+
+// 生成会话令牌
+func makeToken() (string, error) {
+	buf := make([]byte, 16)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,696 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+	"github.com/devagame/due/v2/utils/xcall"
+	"github.com/devagame/due/v2/utils/xnet"
+	"github.com/devagame/due/v2/utils/xtime"
+)
+
+type serverConn struct {
+	id                int64             // 连接ID
+	uid               atomic.Int64      // 用户ID
+	attr              *attr             // 连接属性
+	state             atomic.Int32      // 连接状态
+	token             string            // 会话令牌
+	server            *server           // 服务器
+	rw                sync.RWMutex      // 读写锁
+	opened            bool              // 写入队列是否可用
+	chWrite           chan chWrite      // 写入队列
+	quota             network.Quota     // 写入队列配额
+	reason            atomic.Int32      // 关闭原因
+	addr              net.Addr          // 准入地址，同时作为远端地址
+	meter             network.ConnMeter // 流量计量
+	reading           chan struct{}     // 下行读取令牌，同一时刻仅允许一个下行请求取走消息
+	attached          atomic.Int32      // 挂起中的下行请求数，存在挂起的下行请求时视为存活
+	done              chan struct{}     // 写入完成信号
+	close             chan struct{}     // 关闭信号
+	lastHeartbeatTime atomic.Int64      // 上次心跳时间
+	authorizeTimer    atomic.Value      // 授权定时器
+}
+
+var (
+	_ network.Conn         = &serverConn{}
+	_ network.ReasonConn   = &serverConn{}
+	_ network.CriticalConn = &serverConn{}
+)
+
+func newServerConn(s *server, id int64, token string, addr net.Addr) *serverConn {
+	c := &serverConn{
+		id:      id,
+		attr:    &attr{},
+		token:   token,
+		server:  s,
+		opened:  true,
+		chWrite: make(chan chWrite, s.opts.writeQueue.Capacity()),
+		addr:    addr,
+		reading: make(chan struct{}, 1),
+		done:    make(chan struct{}, 1),
+		close:   make(chan struct{}),
+	}
+
+	c.state.Store(int32(network.ConnOpened))
+	c.reason.Store(int32(network.ReasonNone))
+	c.meter.Reset()
+	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+	c.authorizeTimer.Store((*time.Timer)(nil))
+
+	return c
+}
+
+// ID 获取连接ID
+func (c *serverConn) ID() int64 {
+	return c.id
+}
+
+// UID 获取用户ID
+func (c *serverConn) UID() int64 {
+	return c.uid.Load()
+}
+
+// Attr 获取属性接口
+func (c *serverConn) Attr() network.Attr {
+	return c.attr
+}
+
+// Bind 绑定用户ID
+func (c *serverConn) Bind(uid int64) {
+	c.uid.Store(uid)
+
+	c.uncheckAuthorize()
+}
+
+// Unbind 解绑用户ID
+func (c *serverConn) Unbind() {
+	c.uid.Store(0)
+
+	c.checkAuthorize()
+}
+
+// Send 发送消息（同步）
+// 下行消息需等待客户端通过SSE或长轮询取走，故而与Push一致，写入队列后即返回
+func (c *serverConn) Send(msg []byte) error {
+	return c.Push(msg)
+}
+
+// Push 发送消息（异步）
+func (c *serverConn) Push(msg []byte) error {
+	return c.push(msg, false)
+}
+
+// PushCritical 发送关键消息（异步），写入队列溢出时不会被丢弃
+func (c *serverConn) PushCritical(msg []byte) error {
+	return c.push(msg, true)
+}
+
+// 推送消息
+func (c *serverConn) push(msg []byte, critical bool) error {
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	c.rw.RLock()
+	err := c.enqueue(msg, critical)
+	c.rw.RUnlock()
+
+	if errors.Is(err, errors.ErrSlowConsumer) {
+		c.slowClose()
+	}
+
+	return err
+}
+
+// CloseReason 获取连接关闭原因
+func (c *serverConn) CloseReason() network.CloseReason {
+	return network.CloseReason(c.reason.Load())
+}
+
+// Stats 获取连接流量统计
+func (c *serverConn) Stats() *network.ConnStat {
+	return c.meter.Stat()
+}
+
+// State 获取连接状态
+func (c *serverConn) State() network.ConnState {
+	return network.ConnState(c.state.Load())
+}
+
+// Close 关闭连接
+// 优雅关闭时等待客户端取走已入队的下行消息，客户端未在一个长轮询周期内取走时强制关闭
+func (c *serverConn) Close(force ...bool) error {
+	if len(force) > 0 && force[0] {
+		return c.forceClose()
+	} else {
+		return c.graceClose()
+	}
+}
+
+// LocalIP 获取本地IP
+func (c *serverConn) LocalIP() (string, error) {
+	addr, err := c.LocalAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// LocalAddr 获取本地地址
+func (c *serverConn) LocalAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	return c.server.listener.Addr(), nil
+}
+
+// RemoteIP 获取远端IP
+func (c *serverConn) RemoteIP() (string, error) {
+	addr, err := c.RemoteAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// RemoteAddr 获取远端地址，即建立会话时的客户端地址
+func (c *serverConn) RemoteAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	if c.addr == nil {
+		return nil, errors.ErrNotFoundIPAddress
+	}
+
+	return c.addr, nil
+}
+
+// 检测连接状态
+func (c *serverConn) checkState() error {
+	switch c.State() {
+	case network.ConnHanged:
+		return errors.ErrConnectionHanged
+	case network.ConnClosed:
+		return errors.ErrConnectionClosed
+	default:
+		return nil
+	}
+}
+
+// 授权检查
+func (c *serverConn) checkAuthorize() {
+	if authorizeTimeout := c.server.authorizeTimeout.Load(); authorizeTimeout > 0 {
+		timer := c.authorizeTimer.Swap(time.AfterFunc(authorizeTimeout, func() {
+			if c.UID() != 0 {
+				return
+			}
+
+			c.closeWithReason(network.ReasonAuthorizeTimeout)
+		}))
+		if t, ok := timer.(*time.Timer); ok && t != nil {
+			t.Stop()
+		}
+	}
+}
+
+// 取消授权检查
+func (c *serverConn) uncheckAuthorize() {
+	timer := c.authorizeTimer.Swap((*time.Timer)(nil))
+
+	if t, ok := timer.(*time.Timer); ok && t != nil {
+		t.Stop()
+	}
+}
+
+// 初始化连接
+func (c *serverConn) init() {
+	xcall.Go(c.keepalive)
+
+	c.checkAuthorize()
+
+	if c.server.connectHandler != nil {
+		c.server.connectHandler(c)
+	}
+}
+
+// 优雅关闭
+func (c *serverConn) graceClose() error {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnHanged)) {
+		return errors.ErrConnectionNotOpened
+	}
+
+	c.uncheckAuthorize()
+
+	c.rw.RLock()
+	if !c.opened {
+		c.rw.RUnlock()
+		return errors.ErrConnectionClosed
+	}
+	// 队列被心跳包占满时无法等待客户端取走消息，直接关闭
+	select {
+	case c.chWrite <- chWrite{typ: closeSig}:
+		c.rw.RUnlock()
+
+		timer := time.NewTimer(max(c.server.opts.pollTimeout, time.Second))
+		defer timer.Stop()
+
+		select {
+		case <-c.done:
+		case <-timer.C:
+		}
+	default:
+		c.rw.RUnlock()
+	}
+
+	if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+		return errors.ErrConnectionNotHanged
+	}
+
+	return c.doClose()
+}
+
+// 强制关闭
+func (c *serverConn) forceClose() error {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnClosed)) {
+		if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+			return errors.ErrConnectionClosed
+		}
+	}
+
+	c.uncheckAuthorize()
+
+	return c.doClose()
+}
+
+// 携带关闭原因强制关闭
+func (c *serverConn) closeWithReason(reason network.CloseReason) error {
+	c.reason.CompareAndSwap(int32(network.ReasonNone), int32(reason))
+
+	return c.forceClose()
+}
+
+// 作为慢消费者关闭连接
+// 调用方可能持有会话锁（如广播消息），故而异步执行关闭操作，避免在关闭回调中重入会话锁
+func (c *serverConn) slowClose() {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnClosed)) {
+		if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+			return
+		}
+	}
+
+	c.reason.Store(int32(network.ReasonSlowConsumer))
+	c.server.queueMetrics.Disconnect()
+	c.uncheckAuthorize()
+
+	log.Warnf("connection write queue overflow, close slow consumer, cid: %d", c.id)
+
+	xcall.Go(func() { _ = c.doClose() })
+}
+
+// 写入队列，需持有读锁
+// 下行消息无法阻塞等待客户端取走，队列写满且未配置溢出策略时按disconnect处理
+func (c *serverConn) enqueue(msg []byte, critical bool) error {
+	if !c.opened {
+		return errors.ErrConnectionClosed
+	}
+
+	opts := &c.server.opts.writeQueue
+
+	for !c.quota.Acquire(opts, len(msg)) {
+		switch {
+		case opts.Policy == network.DropNew && !critical:
+			c.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		case opts.Policy == network.DropNew || opts.Policy == network.DropOldest:
+			if c.dropOldest(opts) {
+				continue
+			}
+
+			// 队列中不存在可丢弃的非关键消息，关键消息无法入队时作为慢消费者处理
+			if critical {
+				return errors.ErrSlowConsumer
+			}
+
+			c.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		default:
+			return errors.ErrSlowConsumer
+		}
+	}
+
+	select {
+	case c.chWrite <- chWrite{typ: dataPacket, msg: msg, critical: critical}:
+		return nil
+	default:
+		c.quota.Release(opts, len(msg))
+		return errors.ErrSlowConsumer
+	}
+}
+
+// 丢弃最早入队的非关键消息，需持有读锁
+// 依次取出队列中的消息，除被丢弃的消息外按原顺序重新入队，队列中不存在非关键消息时返回false
+func (c *serverConn) dropOldest(opts *network.QueueOptions) bool {
+	dropped := false
+
+	for n := len(c.chWrite); n > 0; n-- {
+		select {
+		case r := <-c.chWrite:
+			if !dropped && r.typ == dataPacket && !r.critical {
+				c.quota.Release(opts, len(r.msg))
+				c.server.queueMetrics.Drop()
+				dropped = true
+				continue
+			}
+
+			c.chWrite <- r
+		default:
+			// 下行请求已取出消息但尚未释放配额，重试
+			return true
+		}
+	}
+
+	return dropped
+}
+
+// 执行关闭操作
+func (c *serverConn) doClose() error {
+	c.rw.Lock()
+
+	if !c.opened {
+		c.rw.Unlock()
+		return errors.ErrConnectionClosed
+	}
+
+	c.opened = false
+	close(c.close)
+	close(c.done)
+	c.rw.Unlock()
+
+	c.server.recycle(c)
+
+	if c.server.disconnectHandler != nil {
+		c.server.disconnectHandler(c)
+	}
+
+	return nil
+}
+
+// 心跳检测
+func (c *serverConn) keepalive() {
+	var (
+		setting  = c.server.heartbeatInterval
+		changed  = setting.Changed()
+		interval = setting.Load()
+		ticker   = network.NewTicker(interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.close:
+			return
+		case <-changed:
+			changed, interval = setting.Changed(), setting.Load()
+			c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+			network.ResetTicker(ticker, interval)
+		case t := <-ticker.C:
+			// 存在挂起的下行请求时客户端必然在线
+			if c.attached.Load() > 0 {
+				c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+			}
+
+			deadline := t.Add(-2 * interval).UnixNano()
+
+			if c.lastHeartbeatTime.Load() < deadline {
+				log.Debugf("connection heartbeat timeout, cid: %d", c.id)
+				_ = c.closeWithReason(network.ReasonHeartbeatTimeout)
+				return
+			}
+
+			if c.server.opts.heartbeatMechanism == TickHeartbeat {
+				c.sendHeartbeat()
+			}
+		}
+	}
+}
+
+// 发送心跳包，写入队列已满时放弃本次心跳
+func (c *serverConn) sendHeartbeat() {
+	heartbeat, err := packet.PackPing()
+	if err != nil {
+		log.Errorf("pack heartbeat message error: %v", err)
+		return
+	}
+
+	if t, ok := packet.UnpackPing(heartbeat); ok {
+		c.meter.Ping(t)
+	}
+
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	if !c.opened {
+		return
+	}
+
+	select {
+	case c.chWrite <- chWrite{typ: heartbeatPacket, msg: heartbeat}:
+	default:
+	}
+}
+
+// 接收上行消息，请求体为一个或多个数据包
+func (c *serverConn) receive(body io.Reader) error {
+	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+
+	for {
+		buf, err := packet.ReadBuffer(body)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		switch c.State() {
+		case network.ConnHanged:
+			continue
+		case network.ConnClosed:
+			return nil
+		default:
+			// ignore
+		}
+
+		// ignore empty packet
+		if buf == nil || buf.Len() == 0 {
+			continue
+		}
+
+		isHeartbeat, err := packet.CheckHeartbeat(buf.Bytes())
+		if err != nil {
+			log.Errorf("check heartbeat message error: %v", err)
+			continue
+		}
+
+		c.meter.Receive(buf.Len(), isHeartbeat)
+
+		// ignore heartbeat packet
+		if isHeartbeat {
+			// 客户端回显的探测心跳仅用于测量往返时延，无需响应
+			if t, ok := packet.UnpackPing(buf.Bytes()); ok {
+				c.meter.Pong(t)
+				continue
+			}
+
+			// responsive heartbeat
+			if c.server.opts.heartbeatMechanism == RespHeartbeat {
+				c.sendHeartbeat()
+			}
+		} else {
+			if c.server.receiveHandler != nil {
+				c.server.receiveHandler(c, buf)
+			}
+		}
+	}
+}
+
+// 获取下行读取令牌，同一时刻仅允许一个下行请求取走消息
+func (c *serverConn) acquire(r *http.Request) bool {
+	select {
+	case c.reading <- struct{}{}:
+		c.attached.Add(1)
+		c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+		return true
+	case <-r.Context().Done():
+		return false
+	case <-c.close:
+		return false
+	}
+}
+
+// 释放下行读取令牌
+func (c *serverConn) release() {
+	c.attached.Add(-1)
+	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+	<-c.reading
+}
+
+// 取出一条待下发的消息，取到关闭信号时通知优雅关闭流程，返回false表示连接已关闭
+func (c *serverConn) take(r chWrite) ([]byte, bool) {
+	switch r.typ {
+	case closeSig:
+		c.rw.RLock()
+		if c.opened {
+			select {
+			case c.done <- struct{}{}:
+			default:
+			}
+		}
+		c.rw.RUnlock()
+		return nil, false
+	case dataPacket:
+		c.quota.Release(&c.server.opts.writeQueue, len(r.msg))
+	}
+
+	c.meter.Send(len(r.msg), r.typ == heartbeatPacket)
+
+	return r.msg, true
+}
+
+// 以SSE持续下发消息
+func (c *serverConn) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		return
+	}
+
+	if !c.acquire(r) {
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	}
+	defer c.release()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(max(c.server.opts.pollTimeout, time.Second))
+	defer ticker.Stop()
+
+	buf := make([]byte, 0, 512)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.close:
+			_, _ = io.WriteString(w, "event: "+closeEvent+"\ndata:\n\n")
+			flusher.Flush()
+			return
+		case <-ticker.C:
+			// 保活注释，避免中间代理因空闲断开连接
+			if _, err := io.WriteString(w, ":\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ch := <-c.chWrite:
+			msg, ok := c.take(ch)
+			if !ok {
+				_, _ = io.WriteString(w, "event: "+closeEvent+"\ndata:\n\n")
+				flusher.Flush()
+				return
+			}
+
+			buf = append(buf[:0], "data: "...)
+			buf = base64.StdEncoding.AppendEncode(buf, msg)
+			buf = append(buf, "\n\n"...)
+
+			if _, err := w.Write(buf); err != nil {
+				log.Errorf("write data message error: %v", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// 以长轮询下发消息，等待首条消息后取走当前所有可用消息，超时时响应204
+func (c *serverConn) poll(w http.ResponseWriter, r *http.Request) {
+	if !c.acquire(r) {
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	}
+	defer c.release()
+
+	timer := time.NewTimer(c.server.opts.pollTimeout)
+	defer timer.Stop()
+
+	var (
+		body   bytes.Buffer
+		closed bool
+	)
+
+	select {
+	case <-r.Context().Done():
+		return
+	case <-c.close:
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	case <-timer.C:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case ch := <-c.chWrite:
+		msg, ok := c.take(ch)
+		if !ok {
+			closed = true
+		} else {
+			body.Write(msg)
+		}
+	}
+
+	for !closed && body.Len() < maxPollBytes {
+		select {
+		case ch := <-c.chWrite:
+			msg, ok := c.take(ch)
+			if !ok {
+				closed = true
+			} else {
+				body.Write(msg)
+			}
+			continue
+		default:
+		}
+
+		break
+	}
+
+	if body.Len() == 0 {
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if _, err := w.Write(body.Bytes()); err != nil {
+		log.Errorf("write data message error: %v", err)
+	}
+}
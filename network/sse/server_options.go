@@ -0,0 +1,225 @@
+package sse
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/network"
+)
+
+const (
+	defaultServerAddr               = ":3553"
+	defaultServerPath               = "/"
+	defaultServerMaxConnNum         = 5000
+	defaultServerCheckOrigin        = "*"
+	defaultServerHeartbeatInterval  = "10s"
+	defaultServerHeartbeatMechanism = "resp"
+	defaultServerAuthorizeTimeout   = "0s"
+	defaultServerPollTimeout        = "25s"
+	defaultServerQueuePolicy        = "disconnect"
+)
+
+const (
+	defaultServerAddrKey               = "etc.network.sse.server.addr"
+	defaultServerPathKey               = "etc.network.sse.server.path"
+	defaultServerMaxConnNumKey         = "etc.network.sse.server.maxConnNum"
+	defaultServerCheckOriginsKey       = "etc.network.sse.server.origins"
+	defaultServerKeyFileKey            = "etc.network.sse.server.keyFile"
+	defaultServerCertFileKey           = "etc.network.sse.server.certFile"
+	defaultServerHeartbeatIntervalKey  = "etc.network.sse.server.heartbeatInterval"
+	defaultServerHeartbeatMechanismKey = "etc.network.sse.server.heartbeatMechanism"
+	defaultServerAuthorizeTimeoutKey   = "etc.network.sse.server.authorizeTimeout"
+	defaultServerPollTimeoutKey        = "etc.network.sse.server.pollTimeout"
+	defaultServerForwardedHeadersKey   = "etc.network.sse.server.forwardedHeaders"
+	defaultServerTrustedProxiesKey     = "etc.network.sse.server.trustedProxies"
+	defaultServerQueueMaxMessagesKey   = "etc.network.sse.server.writeQueue.maxMessages"
+	defaultServerQueueMaxBytesKey      = "etc.network.sse.server.writeQueue.maxBytes"
+	defaultServerQueuePolicyKey        = "etc.network.sse.server.writeQueue.policy"
+	defaultServerMaxConnPerIPKey       = "etc.network.sse.server.guard.maxConnPerIP"
+	defaultServerAcceptRateKey         = "etc.network.sse.server.guard.acceptRate"
+	defaultServerAcceptBurstKey        = "etc.network.sse.server.guard.acceptBurst"
+	defaultServerAllowListKey          = "etc.network.sse.server.guard.allowList"
+	defaultServerDenyListKey           = "etc.network.sse.server.guard.denyList"
+)
+
+const (
+	RespHeartbeat HeartbeatMechanism = "resp" // 响应式心跳
+	TickHeartbeat HeartbeatMechanism = "tick" // 主动定时心跳
+)
+
+type HeartbeatMechanism string
+
+type ServerOption func(o *serverOptions)
+
+type CheckOriginFunc func(r *http.Request) bool
+
+type serverOptions struct {
+	addr               string               // 监听地址
+	maxConnNum         int                  // 最大连接数
+	certFile           string               // 证书文件
+	keyFile            string               // 秘钥文件
+	path               string               // 路径，默认为"/"
+	checkOrigin        CheckOriginFunc      // 跨域检测
+	heartbeatInterval  time.Duration        // 心跳间隔时间，默认10s
+	heartbeatMechanism HeartbeatMechanism   // 心跳机制，默认resp
+	authorizeTimeout   time.Duration        // 授权超时时间，默认0s，不检测
+	pollTimeout        time.Duration        // 长轮询等待时间，同时作为SSE保活注释的发送间隔，默认25s
+	forwardedHeaders   bool                 // 是否解析X-Forwarded-For、X-Real-IP请求头，默认false
+	trustedProxies     []string             // 可信代理网段，仅解析来自可信代理的请求头，默认为空，不信任任何来源
+	writeQueue         network.QueueOptions // 连接写入队列配置
+	guard              network.GuardOptions // 连接准入配置
+	fixed              map[string]struct{}  // 显式设置的配置项，不监听etc中对应配置的变化
+}
+
+func defaultServerOptions() *serverOptions {
+	origins := etc.Get(defaultServerCheckOriginsKey, []string{defaultServerCheckOrigin}).Strings()
+	checkOrigin := func(r *http.Request) bool {
+		if len(origins) == 0 {
+			return false
+		}
+
+		origin := r.Header.Get("Origin")
+		for _, v := range origins {
+			if v == defaultServerCheckOrigin || origin == v {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return &serverOptions{
+		addr:               etc.Get(defaultServerAddrKey, defaultServerAddr).String(),
+		maxConnNum:         etc.Get(defaultServerMaxConnNumKey, defaultServerMaxConnNum).Int(),
+		path:               etc.Get(defaultServerPathKey, defaultServerPath).String(),
+		checkOrigin:        checkOrigin,
+		keyFile:            etc.Get(defaultServerKeyFileKey).String(),
+		certFile:           etc.Get(defaultServerCertFileKey).String(),
+		heartbeatInterval:  etc.Get(defaultServerHeartbeatIntervalKey, defaultServerHeartbeatInterval).Duration(),
+		heartbeatMechanism: HeartbeatMechanism(etc.Get(defaultServerHeartbeatMechanismKey, defaultServerHeartbeatMechanism).String()),
+		authorizeTimeout:   etc.Get(defaultServerAuthorizeTimeoutKey, defaultServerAuthorizeTimeout).Duration(),
+		pollTimeout:        etc.Get(defaultServerPollTimeoutKey, defaultServerPollTimeout).Duration(),
+		forwardedHeaders:   etc.Get(defaultServerForwardedHeadersKey).Bool(),
+		trustedProxies:     etc.Get(defaultServerTrustedProxiesKey).Strings(),
+		writeQueue: network.QueueOptions{
+			MaxMessages: etc.Get(defaultServerQueueMaxMessagesKey).Int(),
+			MaxBytes:    int(etc.Get(defaultServerQueueMaxBytesKey).B()),
+			Policy:      network.OverflowPolicy(etc.Get(defaultServerQueuePolicyKey, defaultServerQueuePolicy).String()),
+		},
+		guard: network.GuardOptions{
+			MaxConnPerIP: etc.Get(defaultServerMaxConnPerIPKey).Int(),
+			AcceptRate:   etc.Get(defaultServerAcceptRateKey).Float64(),
+			AcceptBurst:  etc.Get(defaultServerAcceptBurstKey).Int(),
+			AllowList:    etc.Get(defaultServerAllowListKey).Strings(),
+			DenyList:     etc.Get(defaultServerDenyListKey).Strings(),
+		},
+	}
+}
+
+// WithServerListenAddr 设置监听地址
+func WithServerListenAddr(addr string) ServerOption {
+	return func(o *serverOptions) { o.addr = addr }
+}
+
+// WithServerMaxConnNum 设置连接的最大连接数
+func WithServerMaxConnNum(maxConnNum int) ServerOption {
+	return func(o *serverOptions) {
+		o.maxConnNum = maxConnNum
+		o.fix(defaultServerMaxConnNumKey)
+	}
+}
+
+// WithServerPath 设置路径，各接口挂载于该路径之下
+func WithServerPath(path string) ServerOption {
+	return func(o *serverOptions) { o.path = path }
+}
+
+// WithServerCredentials 设置证书和秘钥
+func WithServerCredentials(certFile, keyFile string) ServerOption {
+	return func(o *serverOptions) { o.keyFile, o.certFile = keyFile, certFile }
+}
+
+// WithServerCheckOrigin 设置跨域检测函数
+func WithServerCheckOrigin(checkOrigin CheckOriginFunc) ServerOption {
+	return func(o *serverOptions) { o.checkOrigin = checkOrigin }
+}
+
+// WithServerHeartbeatInterval 设置心跳检测间隔时间
+func WithServerHeartbeatInterval(heartbeatInterval time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.heartbeatInterval = heartbeatInterval
+		o.fix(defaultServerHeartbeatIntervalKey)
+	}
+}
+
+// WithServerHeartbeatMechanism 设置心跳机制
+func WithServerHeartbeatMechanism(heartbeatMechanism HeartbeatMechanism) ServerOption {
+	return func(o *serverOptions) { o.heartbeatMechanism = heartbeatMechanism }
+}
+
+// WithServerAuthorizeTimeout 设置授权超时时间
+func WithServerAuthorizeTimeout(authorizeTimeout time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.authorizeTimeout = authorizeTimeout
+		o.fix(defaultServerAuthorizeTimeoutKey)
+	}
+}
+
+// WithServerPollTimeout 设置长轮询等待时间
+func WithServerPollTimeout(pollTimeout time.Duration) ServerOption {
+	return func(o *serverOptions) { o.pollTimeout = pollTimeout }
+}
+
+// WithServerForwardedHeaders 设置是否解析X-Forwarded-For、X-Real-IP请求头
+func WithServerForwardedHeaders(forwardedHeaders bool) ServerOption {
+	return func(o *serverOptions) { o.forwardedHeaders = forwardedHeaders }
+}
+
+// WithServerTrustedProxies 设置可信代理网段，支持CIDR及单个IP地址
+func WithServerTrustedProxies(trustedProxies ...string) ServerOption {
+	return func(o *serverOptions) { o.trustedProxies = trustedProxies }
+}
+
+// WithServerWriteQueue 设置连接写入队列配置
+func WithServerWriteQueue(writeQueue network.QueueOptions) ServerOption {
+	return func(o *serverOptions) { o.writeQueue = writeQueue }
+}
+
+// WithServerMaxConnPerIP 设置单IP最大并发连接数
+func WithServerMaxConnPerIP(maxConnPerIP int) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.MaxConnPerIP = maxConnPerIP
+		o.fix(defaultServerMaxConnPerIPKey)
+	}
+}
+
+// WithServerAcceptRate 设置单IP每秒允许建立的连接数及突发连接数
+func WithServerAcceptRate(rate float64, burst int) ServerOption {
+	return func(o *serverOptions) { o.guard.AcceptRate, o.guard.AcceptBurst = rate, burst }
+}
+
+// WithServerAllowList 设置白名单，支持CIDR及单个IP地址
+func WithServerAllowList(allowList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.AllowList = allowList
+		o.fix(defaultServerAllowListKey)
+	}
+}
+
+// WithServerDenyList 设置黑名单，支持CIDR及单个IP地址
+func WithServerDenyList(denyList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.DenyList = denyList
+		o.fix(defaultServerDenyListKey)
+	}
+}
+
+// 标记显式设置的配置项
+func (o *serverOptions) fix(key string) {
+	if o.fixed == nil {
+		o.fixed = make(map[string]struct{})
+	}
+
+	o.fixed[key] = struct{}{}
+}
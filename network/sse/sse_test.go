@@ -0,0 +1,162 @@
+package sse_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/network/sse/v2"
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+)
+
+func listenAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	return ln.Addr().String()
+}
+
+func TestSSEMode_Echo(t *testing.T) {
+	testEcho(t, sse.SSEMode)
+}
+
+func TestPollMode_Echo(t *testing.T) {
+	testEcho(t, sse.PollMode)
+}
+
+func testEcho(t *testing.T, mode sse.Mode) {
+	const total = 50
+
+	addr := listenAddr(t)
+
+	server := sse.NewServer(
+		sse.WithServerListenAddr(addr),
+		sse.WithServerPath("/gate"),
+		sse.WithServerPollTimeout(200*time.Millisecond),
+	)
+
+	reasons := make(chan network.CloseReason, 1)
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		if err := conn.Push(append([]byte(nil), buf.Bytes()...)); err != nil {
+			t.Errorf("push message failed: %v", err)
+		}
+	})
+
+	server.OnDisconnect(func(conn network.Conn) {
+		reasons <- conn.(network.ReasonConn).CloseReason()
+	})
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	received := make(chan int32, total)
+
+	client := sse.NewClient(sse.WithClientUrl("http://"+addr+"/gate"), sse.WithClientMode(mode))
+
+	client.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		message, err := packet.UnpackMessage(buf.Bytes())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		received <- message.Seq
+	})
+
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int32(1); i <= total; i++ {
+		msg, err := packet.PackMessage(&packet.Message{Seq: i, Route: 1, Buffer: []byte("hello world")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = conn.Push(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := int32(1); i <= total; i++ {
+		select {
+		case seq := <-received:
+			if seq != i {
+				t.Fatalf("seq = %d, want %d", seq, i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("receive message %d timeout", i)
+		}
+	}
+
+	if err = conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case reason := <-reasons:
+		if reason != network.ReasonClientClosed {
+			t.Fatalf("reason = %v, want %v", reason, network.ReasonClientClosed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnect timeout")
+	}
+}
+
+func TestServer_GraceClose(t *testing.T) {
+	addr := listenAddr(t)
+
+	server := sse.NewServer(sse.WithServerListenAddr(addr), sse.WithServerPollTimeout(time.Second))
+
+	msg, err := packet.PackMessage(&packet.Message{Seq: 1, Route: 1, Buffer: []byte("bye")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			_ = conn.Push(msg)
+			_ = conn.Close()
+		}()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	received := make(chan struct{}, 1)
+	disconnected := make(chan struct{}, 1)
+
+	client := sse.NewClient(sse.WithClientUrl("http://" + addr))
+
+	client.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		received <- struct{}{}
+	})
+
+	client.OnDisconnect(func(conn network.Conn) {
+		disconnected <- struct{}{}
+	})
+
+	if _, err = client.Dial(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 优雅关闭时已入队的消息须先送达客户端
+	for _, ch := range []chan struct{}{received, disconnected} {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatal("grace close timeout")
+		}
+	}
+}
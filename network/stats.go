@@ -0,0 +1,127 @@
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const pingSlots = 4 // 记录最近发出的心跳时间的数量
+
+// ConnStat 连接流量统计
+type ConnStat struct {
+	BytesIn     int64         // 接收字节数，含心跳
+	BytesOut    int64         // 发送字节数，含心跳
+	MessagesIn  int64         // 接收消息数，不含心跳
+	MessagesOut int64         // 发送消息数，不含心跳
+	LastActive  time.Time     // 最后活跃时间，即最后一次收到数据的时间
+	RTT         time.Duration // 平滑往返时延，未测得时为0
+}
+
+// ConnMeter 连接流量计量器
+// 往返时延通过客户端回显服务端下发的探测心跳测得，需开启packet.heartbeatTime
+type ConnMeter struct {
+	bytesIn     atomic.Int64
+	bytesOut    atomic.Int64
+	messagesIn  atomic.Int64
+	messagesOut atomic.Int64
+	lastActive  atomic.Int64
+	rtt         atomic.Int64
+	mu          sync.Mutex
+	pings       [pingSlots]int64 // 最近发出的心跳时间
+	next        int
+}
+
+// Reset 重置计量器
+func (m *ConnMeter) Reset() {
+	m.bytesIn.Store(0)
+	m.bytesOut.Store(0)
+	m.messagesIn.Store(0)
+	m.messagesOut.Store(0)
+	m.lastActive.Store(time.Now().UnixNano())
+	m.rtt.Store(0)
+
+	m.mu.Lock()
+	m.pings = [pingSlots]int64{}
+	m.next = 0
+	m.mu.Unlock()
+}
+
+// Receive 记录接收的数据
+func (m *ConnMeter) Receive(n int, isHeartbeat bool) {
+	m.bytesIn.Add(int64(n))
+	m.lastActive.Store(time.Now().UnixNano())
+
+	if !isHeartbeat {
+		m.messagesIn.Add(1)
+	}
+}
+
+// Send 记录发送的数据
+func (m *ConnMeter) Send(n int, isHeartbeat bool) {
+	m.bytesOut.Add(int64(n))
+
+	if !isHeartbeat {
+		m.messagesOut.Add(1)
+	}
+}
+
+// Ping 记录发出的心跳时间
+func (m *ConnMeter) Ping(t int64) {
+	m.mu.Lock()
+	m.pings[m.next] = t
+	m.next = (m.next + 1) % pingSlots
+	m.mu.Unlock()
+}
+
+// Pong 处理收到的心跳时间，为本端发出的心跳的回显时更新往返时延并返回true
+func (m *ConnMeter) Pong(t int64) bool {
+	if t == 0 {
+		return false
+	}
+
+	m.mu.Lock()
+	matched := false
+	for i := range m.pings {
+		if m.pings[i] == t {
+			m.pings[i] = 0
+			matched = true
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if !matched {
+		return false
+	}
+
+	sample := time.Now().UnixNano() - t
+	if sample < 0 {
+		return true
+	}
+
+	// 平滑往返时延，srtt = 7/8 * srtt + 1/8 * sample
+	for {
+		old := m.rtt.Load()
+		rtt := sample
+		if old > 0 {
+			rtt = old - old/8 + sample/8
+		}
+
+		if m.rtt.CompareAndSwap(old, rtt) {
+			return true
+		}
+	}
+}
+
+// Stat 获取流量统计
+func (m *ConnMeter) Stat() *ConnStat {
+	return &ConnStat{
+		BytesIn:     m.bytesIn.Load(),
+		BytesOut:    m.bytesOut.Load(),
+		MessagesIn:  m.messagesIn.Load(),
+		MessagesOut: m.messagesOut.Load(),
+		LastActive:  time.Unix(0, m.lastActive.Load()),
+		RTT:         time.Duration(m.rtt.Load()),
+	}
+}
@@ -17,19 +17,24 @@ import (
 
 type clientConn struct {
 	rw                sync.RWMutex
-	id                int64         // 连接ID
-	uid               atomic.Int64  // 用户ID
-	attr              *attr         // 连接属性
-	conn              net.Conn      // TCP源连接
-	state             atomic.Int32  // 连接状态
-	client            *client       // 客户端
-	chWrite           chan chWrite  // 写入队列
-	done              chan struct{} // 写入完成信号
-	close             chan struct{} // 关闭信号
-	lastHeartbeatTime atomic.Int64  // 上次心跳时间
+	id                int64               // 连接ID
+	uid               atomic.Int64        // 用户ID
+	attr              *attr               // 连接属性
+	conn              net.Conn            // TCP源连接
+	state             atomic.Int32        // 连接状态
+	client            *client             // 客户端
+	chWrite           chan chWrite        // 写入队列
+	done              chan struct{}       // 写入完成信号
+	close             chan struct{}       // 关闭信号
+	lastHeartbeatTime atomic.Int64        // 上次心跳时间
+	meter             network.ConnMeter   // 流量计量
+	notice            network.CloseNotice // 关闭通知
 }
 
-var _ network.Conn = &clientConn{}
+var (
+	_ network.Conn     = &clientConn{}
+	_ network.CodeConn = &clientConn{}
+)
 
 func newClientConn(client *client, id int64, conn net.Conn) network.Conn {
 	c := &clientConn{
@@ -44,10 +49,12 @@ func newClientConn(client *client, id int64, conn net.Conn) network.Conn {
 
 	c.state.Store(int32(network.ConnOpened))
 	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+	c.meter.Reset()
 
-	xcall.Go(c.read)
+	// 源连接以参数传入读写协程，避免与关闭时置空源连接产生竞争
+	xcall.Go(func() { c.read(conn) })
 
-	xcall.Go(c.write)
+	xcall.Go(func() { c.write(conn) })
 
 	if c.client.connectHandler != nil {
 		c.client.connectHandler(c)
@@ -95,7 +102,9 @@ func (c *clientConn) Send(msg []byte) error {
 		return errors.ErrConnectionClosed
 	}
 
-	_, err := conn.Write(msg)
+	n, err := conn.Write(msg)
+	c.meter.Send(n, false)
+
 	return err
 }
 
@@ -117,6 +126,16 @@ func (c *clientConn) Push(msg []byte) error {
 	return nil
 }
 
+// CloseCode 获取服务端下发的关闭原因码及说明信息，未收到关闭包时返回false
+func (c *clientConn) CloseCode() (network.CloseCode, string, bool) {
+	return c.notice.Load()
+}
+
+// Stats 获取连接流量统计
+func (c *clientConn) Stats() *network.ConnStat {
+	return c.meter.Stat()
+}
+
 // State 获取连接状态
 func (c *clientConn) State() network.ConnState {
 	return network.ConnState(c.state.Load())
@@ -257,9 +276,7 @@ func (c *clientConn) doClose() error {
 }
 
 // 读取消息
-func (c *clientConn) read() {
-	conn := c.conn
-
+func (c *clientConn) read(conn net.Conn) {
 	for {
 		select {
 		case <-c.close:
@@ -295,8 +312,16 @@ func (c *clientConn) read() {
 				continue
 			}
 
+			c.meter.Receive(buf.Len(), isHeartbeat)
+
 			// ignore heartbeat packet
 			if isHeartbeat {
+				// 记录对端下发的关闭包，连接随后由对端关闭
+				if c.notice.Receive(buf.Bytes()) {
+					continue
+				}
+
+				c.echoHeartbeat(conn, buf.Bytes())
 				continue
 			}
 
@@ -308,11 +333,8 @@ func (c *clientConn) read() {
 }
 
 // 写入消息
-func (c *clientConn) write() {
-	var (
-		conn   = c.conn
-		ticker *time.Ticker
-	)
+func (c *clientConn) write(conn net.Conn) {
+	var ticker *time.Ticker
 
 	if c.client.opts.heartbeatInterval > 0 {
 		ticker = time.NewTicker(c.client.opts.heartbeatInterval)
@@ -341,7 +363,10 @@ func (c *clientConn) write() {
 				return
 			}
 
-			if _, err := conn.Write(r.msg); err != nil {
+			n, err := conn.Write(r.msg)
+			c.meter.Send(n, false)
+
+			if err != nil {
 				log.Errorf("write data message error: %v", err)
 			}
 		case t, ok := <-ticker.C:
@@ -377,7 +402,10 @@ func (c *clientConn) doHandleHeartbeat(conn net.Conn, t time.Time) bool {
 		if heartbeat, err := packet.PackHeartbeat(); err != nil {
 			log.Errorf("pack heartbeat message error: %v", err)
 		} else {
-			if _, err = conn.Write(heartbeat); err != nil {
+			n, err := conn.Write(heartbeat)
+			c.meter.Send(n, true)
+
+			if err != nil {
 				log.Errorf("write heartbeat message error: %v", err)
 			}
 		}
@@ -385,3 +413,17 @@ func (c *clientConn) doHandleHeartbeat(conn net.Conn, t time.Time) bool {
 
 	return true
 }
+
+// 回显服务端下发的探测心跳，供服务端测量往返时延
+func (c *clientConn) echoHeartbeat(conn net.Conn, heartbeat []byte) {
+	if _, ok := packet.UnpackPing(heartbeat); !ok || c.isClosed() {
+		return
+	}
+
+	n, err := conn.Write(heartbeat)
+	c.meter.Send(n, true)
+
+	if err != nil {
+		log.Errorf("write heartbeat message error: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/network"
 )
 
 const (
@@ -13,21 +14,27 @@ const (
 )
 
 const (
-	defaultClientAddrKey              = "etc.network.tcp.client.addr"
-	defaultClientCAFileKey            = "etc.network.tcp.client.caFile"
-	defaultClientServerNameKey        = "etc.network.tcp.client.serverName"
-	defaultClientTimeoutKey           = "etc.network.tcp.client.timeout"
-	defaultClientHeartbeatIntervalKey = "etc.network.tcp.client.heartbeatInterval"
+	defaultClientAddrKey                 = "etc.network.tcp.client.addr"
+	defaultClientCAFileKey               = "etc.network.tcp.client.caFile"
+	defaultClientServerNameKey           = "etc.network.tcp.client.serverName"
+	defaultClientTimeoutKey              = "etc.network.tcp.client.timeout"
+	defaultClientHeartbeatIntervalKey    = "etc.network.tcp.client.heartbeatInterval"
+	defaultClientReconnectEnableKey      = "etc.network.tcp.client.reconnect.enable"
+	defaultClientReconnectMaxAttemptsKey = "etc.network.tcp.client.reconnect.maxAttempts"
+	defaultClientReconnectMinIntervalKey = "etc.network.tcp.client.reconnect.minInterval"
+	defaultClientReconnectMaxIntervalKey = "etc.network.tcp.client.reconnect.maxInterval"
+	defaultClientReconnectJitterKey      = "etc.network.tcp.client.reconnect.jitter"
 )
 
 type ClientOption func(o *clientOptions)
 
 type clientOptions struct {
-	addr              string        // 地址
-	caFile            string        // CA证书文件
-	serverName        string        // 服务器名称
-	timeout           time.Duration // 拨号超时时间，默认5s
-	heartbeatInterval time.Duration // 心跳间隔时间，默认10s
+	addr              string                   // 地址
+	caFile            string                   // CA证书文件
+	serverName        string                   // 服务器名称
+	timeout           time.Duration            // 拨号超时时间，默认5s
+	heartbeatInterval time.Duration            // 心跳间隔时间，默认10s
+	reconnect         network.ReconnectOptions // 断线重连配置，默认不启用
 }
 
 func defaultClientOptions() *clientOptions {
@@ -37,6 +44,13 @@ func defaultClientOptions() *clientOptions {
 		caFile:            etc.Get(defaultClientCAFileKey).String(),
 		serverName:        etc.Get(defaultClientServerNameKey).String(),
 		heartbeatInterval: etc.Get(defaultClientHeartbeatIntervalKey, defaultClientHeartbeatInterval).Duration(),
+		reconnect: network.ReconnectOptions{
+			Enable:      etc.Get(defaultClientReconnectEnableKey).Bool(),
+			MaxAttempts: etc.Get(defaultClientReconnectMaxAttemptsKey).Int(),
+			MinInterval: etc.Get(defaultClientReconnectMinIntervalKey).Duration(),
+			MaxInterval: etc.Get(defaultClientReconnectMaxIntervalKey).Duration(),
+			Jitter:      etc.Get(defaultClientReconnectJitterKey).Float64(),
+		},
 	}
 }
 
@@ -59,3 +73,8 @@ func WithClientCredentials(caFile string, serverName string) ClientOption {
 func WithClientHeartbeatInterval(heartbeatInterval time.Duration) ClientOption {
 	return func(o *clientOptions) { o.heartbeatInterval = heartbeatInterval }
 }
+
+// WithClientReconnect 设置断线重连配置
+func WithClientReconnect(reconnect network.ReconnectOptions) ClientOption {
+	return func(o *clientOptions) { o.reconnect = reconnect }
+}
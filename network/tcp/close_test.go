@@ -0,0 +1,136 @@
+package tcp_test
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/network/tcp/v2"
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+)
+
+func TestServer_CloseWithCode(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewServer(tcp.WithServerListenAddr(addr))
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			for i := 1; i <= 10; i++ {
+				msg, err := packet.PackMessage(&packet.Message{Seq: int32(i), Route: 1, Buffer: []byte("hello")})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if err = conn.Push(msg); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+
+			if err := network.CloseWithCode(conn, network.CloseMaintenance, "server maintenance"); err != nil {
+				t.Error(err)
+			}
+		}()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := tcp.NewClient(tcp.WithClientAddr(addr))
+
+	var received atomic.Int32
+
+	disconnected := make(chan network.Conn, 1)
+
+	client.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		received.Add(1)
+	})
+
+	client.OnDisconnect(func(conn network.Conn) {
+		disconnected <- conn
+	})
+
+	if _, err = client.Dial(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case conn := <-disconnected:
+		code, message, ok := conn.(network.CodeConn).CloseCode()
+		if !ok || code != network.CloseMaintenance || message != "server maintenance" {
+			t.Fatalf("code = %v, message = %q, ok = %v", code, message, ok)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnect timeout")
+	}
+
+	if n := received.Load(); n != 10 {
+		t.Fatalf("received = %d, want 10", n)
+	}
+}
+
+func TestServer_ForceCloseWithCode(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewServer(tcp.WithServerListenAddr(addr), tcp.WithServerHeartbeatInterval(0))
+
+	elapsed := make(chan time.Duration, 2)
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			// 对端未读取数据时写满发送缓冲区，使写入协程阻塞
+			msg := make([]byte, 64*1024)
+			for i := 0; i < 256; i++ {
+				if err := conn.Push(msg); err != nil {
+					break
+				}
+			}
+
+			start := time.Now()
+
+			if err := network.CloseWithCode(conn, network.CloseKicked, "login elsewhere", true); err != nil {
+				t.Error(err)
+			}
+
+			elapsed <- time.Since(start)
+		}()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// 客户端建立连接后不读取任何数据
+	client, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	select {
+	case d := <-elapsed:
+		if d > 2*network.CloseWriteTimeout {
+			t.Fatalf("force close took %v", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("force close blocked by a client that stops reading")
+	}
+}
@@ -3,11 +3,14 @@ package tcp
 const protocol = "tcp"
 
 const (
-	closeSig   int = iota // 关闭信号
-	dataPacket            // 数据包
+	closeSig        int = iota // 关闭信号
+	dataPacket                 // 数据包
+	syncPacket                 // 同步发送的数据包，不占用写入队列配额，仅反应器服务器使用
+	heartbeatPacket            // 心跳包，仅反应器服务器使用
 )
 
 type chWrite struct {
-	typ int
-	msg []byte
+	typ      int
+	msg      []byte
+	critical bool // 是否为关键消息，写入队列溢出时不会被丢弃
 }
@@ -0,0 +1,161 @@
+package tcp_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/network/tcp/v2"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/network"
+)
+
+func TestServer_SlowConsumer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerHeartbeatInterval(0),
+		tcp.WithServerWriteQueue(network.QueueOptions{MaxMessages: 8, Policy: network.Disconnect}),
+	)
+
+	reasons := make(chan network.CloseReason, 1)
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			msg := make([]byte, 1024*1024)
+
+			for i := 0; i < 1000; i++ {
+				if err := conn.Push(msg); err != nil {
+					if !errors.Is(err, errors.ErrSlowConsumer) && !errors.Is(err, errors.ErrConnectionClosed) {
+						t.Errorf("unexpected push error: %v", err)
+					}
+					return
+				}
+			}
+		}()
+	})
+
+	server.OnDisconnect(func(conn network.Conn) {
+		reasons <- conn.(network.ReasonConn).CloseReason()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// 客户端建立连接后不读取任何数据
+	client, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	select {
+	case reason := <-reasons:
+		if reason != network.ReasonSlowConsumer {
+			t.Fatalf("close reason = %v, want %v", reason, network.ReasonSlowConsumer)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow consumer not disconnected")
+	}
+
+	if stat := server.(network.QueueStater).QueueStat(); stat.Disconnected != 1 {
+		t.Fatalf("disconnected = %d, want 1", stat.Disconnected)
+	}
+}
+
+func TestServer_CriticalMessage(t *testing.T) {
+	newServers := map[string]func(opts ...tcp.ServerOption) network.Server{
+		"server":  tcp.NewServer,
+		"reactor": tcp.NewReactorServer,
+	}
+
+	for name, newServer := range newServers {
+		for _, policy := range []network.OverflowPolicy{network.DropOldest, network.DropNew} {
+			testCriticalMessage(t, name+"/"+string(policy), newServer, policy)
+		}
+	}
+}
+
+func testCriticalMessage(t *testing.T, name string, newServer func(opts ...tcp.ServerOption) network.Server, policy network.OverflowPolicy) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := newServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerHeartbeatInterval(0),
+		tcp.WithServerWriteQueue(network.QueueOptions{MaxMessages: 4, Policy: policy}),
+	)
+
+	marker := []byte("critical message")
+	pushed := make(chan error, 1)
+
+	server.OnConnect(func(conn network.Conn) {
+		go func() {
+			msg := bytes.Repeat([]byte{'a'}, 256*1024)
+
+			// 客户端未读取数据，写满套接字缓冲区及写入队列
+			for i := 0; i < 64; i++ {
+				_ = conn.Push(msg)
+			}
+
+			err := network.PushCritical(conn, marker)
+
+			for i := 0; i < 64; i++ {
+				_ = conn.Push(msg)
+			}
+
+			pushed <- err
+
+			_ = conn.Close()
+		}()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	select {
+	case err = <-pushed:
+		if err != nil {
+			t.Fatalf("%s: push critical message error: %v", name, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: push timeout", name)
+	}
+
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	data, err := io.ReadAll(client)
+	if err != nil {
+		t.Fatalf("%s: read error: %v", name, err)
+	}
+
+	if !bytes.Contains(data, marker) {
+		t.Fatalf("%s: critical message dropped", name)
+	}
+
+	if stat := server.(network.QueueStater).QueueStat(); stat.Dropped == 0 {
+		t.Fatalf("%s: dropped = 0, want messages dropped", name)
+	}
+}
@@ -0,0 +1,733 @@
+//go:build linux
+
+package tcp
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+	"github.com/devagame/due/v2/utils/xcall"
+	"github.com/devagame/due/v2/utils/xnet"
+	"github.com/devagame/due/v2/utils/xtime"
+)
+
+type reactorConn struct {
+	id                int64             // 连接ID
+	uid               atomic.Int64      // 用户ID
+	attr              *attr             // 连接属性
+	state             atomic.Int32      // 连接状态
+	reason            atomic.Int32      // 关闭原因
+	server            *reactorServer    // 服务器
+	poller            *poller           // 所属轮询器
+	conn              *net.TCPConn      // TCP源连接
+	raw               syscall.RawConn   // 原始连接，读写期间保证文件描述符有效
+	fd                int               // 文件描述符
+	addr              net.Addr          // 准入地址，连接回收时释放准入配额
+	meter             network.ConnMeter // 流量计量
+	lastHeartbeatTime atomic.Int64      // 上次心跳时间
+	authorizeTimer    atomic.Value      // 授权定时器
+	inbound           []byte            // 未读取完整的数据，仅由轮询协程访问
+	mu                sync.Mutex        // 写入锁
+	outbound          []chWrite         // 待写入的数据
+	offset            int               // 队首数据已写入的字节数
+	quota             network.Quota     // 写入队列配额
+	drained           chan struct{}     // 优雅关闭时的写入完成信号
+	pmu               sync.Mutex        // 待处理消息锁
+	pending           []buffer.Buffer   // 待处理消息
+	scheduled         bool              // 是否已提交至处理协程
+}
+
+var (
+	_ network.Conn         = &reactorConn{}
+	_ network.ProxyConn    = &reactorConn{}
+	_ network.ReasonConn   = &reactorConn{}
+	_ network.CriticalConn = &reactorConn{}
+	_ network.ForceCloser  = &reactorConn{}
+)
+
+func newReactorConn(s *reactorServer, p *poller, id int64, conn *net.TCPConn, raw syscall.RawConn, fd int, addr net.Addr) *reactorConn {
+	c := &reactorConn{
+		id:     id,
+		attr:   &attr{},
+		server: s,
+		poller: p,
+		conn:   conn,
+		raw:    raw,
+		fd:     fd,
+		addr:   addr,
+	}
+
+	c.state.Store(int32(network.ConnOpened))
+	c.meter.Reset()
+	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+	c.authorizeTimer.Store((*time.Timer)(nil))
+
+	return c
+}
+
+// ID 获取连接ID
+func (c *reactorConn) ID() int64 {
+	return c.id
+}
+
+// UID 获取用户ID
+func (c *reactorConn) UID() int64 {
+	return c.uid.Load()
+}
+
+// Attr 获取属性接口
+func (c *reactorConn) Attr() network.Attr {
+	return c.attr
+}
+
+// Bind 绑定用户ID
+func (c *reactorConn) Bind(uid int64) {
+	c.uid.Store(uid)
+
+	c.uncheckAuthorize()
+}
+
+// Unbind 解绑用户ID
+func (c *reactorConn) Unbind() {
+	c.uid.Store(0)
+
+	c.checkAuthorize()
+}
+
+// Send 发送消息（同步）
+// 套接字发送缓冲区已满时，未写入的数据将由轮询器在可写时继续写入
+func (c *reactorConn) Send(msg []byte) error {
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return errors.ErrConnectionClosed
+	}
+
+	return c.write(chWrite{typ: syncPacket, msg: msg})
+}
+
+// Push 发送消息（异步）
+func (c *reactorConn) Push(msg []byte) error {
+	return c.push(msg, false)
+}
+
+// PushCritical 发送关键消息（异步），写入队列溢出时不会被丢弃
+func (c *reactorConn) PushCritical(msg []byte) error {
+	return c.push(msg, true)
+}
+
+// 推送消息
+func (c *reactorConn) push(msg []byte, critical bool) error {
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	err := c.enqueue(msg, critical)
+	c.mu.Unlock()
+
+	if errors.Is(err, errors.ErrSlowConsumer) {
+		c.slowClose()
+	}
+
+	return err
+}
+
+// CloseReason 获取连接关闭原因
+func (c *reactorConn) CloseReason() network.CloseReason {
+	return network.CloseReason(c.reason.Load())
+}
+
+// Stats 获取连接流量统计
+func (c *reactorConn) Stats() *network.ConnStat {
+	return c.meter.Stat()
+}
+
+// State 获取连接状态
+func (c *reactorConn) State() network.ConnState {
+	return network.ConnState(c.state.Load())
+}
+
+// Close 关闭连接
+func (c *reactorConn) Close(force ...bool) error {
+	if len(force) > 0 && force[0] {
+		return c.forceClose()
+	} else {
+		return c.graceClose()
+	}
+}
+
+// ForceClose 跳过写入队列中待发送的消息，尝试直接写入一次消息后强制关闭连接
+// 套接字以非阻塞方式写入，发送缓冲区已满时消息无法送达，无需设置写入超时
+func (c *reactorConn) ForceClose(msg []byte, _ time.Duration) error {
+	c.mu.Lock()
+	if c.conn != nil && c.State() != network.ConnClosed {
+		// 仅保留已部分写入的队首数据，保证对端收到完整的数据包
+		if c.offset > 0 {
+			c.outbound = c.outbound[:1]
+		} else {
+			c.outbound = nil
+		}
+
+		c.outbound = append(c.outbound, chWrite{typ: syncPacket, msg: msg})
+
+		_ = c.flush()
+	}
+	c.mu.Unlock()
+
+	return c.forceClose()
+}
+
+// LocalIP 获取本地IP
+func (c *reactorConn) LocalIP() (string, error) {
+	addr, err := c.LocalAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// LocalAddr 获取本地地址
+func (c *reactorConn) LocalAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil, errors.ErrConnectionClosed
+	}
+
+	return conn.LocalAddr(), nil
+}
+
+// RemoteIP 获取远端IP
+func (c *reactorConn) RemoteIP() (string, error) {
+	addr, err := c.RemoteAddr()
+	if err != nil {
+		return "", err
+	}
+
+	return xnet.ExtractIP(addr)
+}
+
+// RemoteAddr 获取远端地址
+func (c *reactorConn) RemoteAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil, errors.ErrConnectionClosed
+	}
+
+	return conn.RemoteAddr(), nil
+}
+
+// ProxyAddr 获取代理地址，反应器服务器不解析代理头，总是与RemoteAddr相同
+func (c *reactorConn) ProxyAddr() (net.Addr, error) {
+	return c.RemoteAddr()
+}
+
+// 检测连接状态
+func (c *reactorConn) checkState() error {
+	switch c.State() {
+	case network.ConnHanged:
+		return errors.ErrConnectionHanged
+	case network.ConnClosed:
+		return errors.ErrConnectionClosed
+	default:
+		return nil
+	}
+}
+
+// 授权检查
+func (c *reactorConn) checkAuthorize() {
+	if authorizeTimeout := c.server.authorizeTimeout.Load(); authorizeTimeout > 0 {
+		timer := c.authorizeTimer.Swap(time.AfterFunc(authorizeTimeout, func() {
+			if c.UID() != 0 {
+				return
+			}
+
+			c.closeWithReason(network.ReasonAuthorizeTimeout)
+		}))
+		if t, ok := timer.(*time.Timer); ok && t != nil {
+			t.Stop()
+		}
+	}
+}
+
+// 取消授权检查
+func (c *reactorConn) uncheckAuthorize() {
+	timer := c.authorizeTimer.Swap((*time.Timer)(nil))
+
+	if t, ok := timer.(*time.Timer); ok && t != nil {
+		t.Stop()
+	}
+}
+
+// 优雅关闭，等待待写入的数据写入完毕后关闭
+func (c *reactorConn) graceClose() error {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnHanged)) {
+		return errors.ErrConnectionNotOpened
+	}
+
+	c.uncheckAuthorize()
+
+	c.mu.Lock()
+	if c.conn == nil {
+		c.mu.Unlock()
+		return errors.ErrConnectionClosed
+	}
+
+	var drained chan struct{}
+
+	if len(c.outbound) > 0 {
+		drained = make(chan struct{})
+		c.drained = drained
+	}
+	c.mu.Unlock()
+
+	if drained != nil {
+		<-drained
+	}
+
+	if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+		return errors.ErrConnectionNotHanged
+	}
+
+	return c.doClose()
+}
+
+// 强制关闭
+func (c *reactorConn) forceClose() error {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnClosed)) {
+		if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+			return errors.ErrConnectionClosed
+		}
+	}
+
+	c.uncheckAuthorize()
+
+	return c.doClose()
+}
+
+// 携带关闭原因强制关闭
+func (c *reactorConn) closeWithReason(reason network.CloseReason) error {
+	c.reason.CompareAndSwap(int32(network.ReasonNone), int32(reason))
+
+	return c.forceClose()
+}
+
+// 作为慢消费者关闭连接
+// 调用方可能持有会话锁（如广播消息），故而异步执行关闭操作，避免在关闭回调中重入会话锁
+func (c *reactorConn) slowClose() {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnClosed)) {
+		if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+			return
+		}
+	}
+
+	c.reason.Store(int32(network.ReasonSlowConsumer))
+	c.server.queueMetrics.Disconnect()
+	c.uncheckAuthorize()
+
+	log.Warnf("connection write queue overflow, close slow consumer, cid: %d", c.id)
+
+	xcall.Go(func() { _ = c.doClose() })
+}
+
+// 执行关闭操作
+func (c *reactorConn) doClose() error {
+	c.mu.Lock()
+
+	if c.conn == nil {
+		c.mu.Unlock()
+		return errors.ErrConnectionClosed
+	}
+
+	conn := c.conn
+	c.conn = nil
+	c.outbound = nil
+	c.offset = 0
+	c.quota.Reset()
+	c.signalDrained()
+	c.mu.Unlock()
+
+	// 须在关闭文件描述符前移除监听，避免误删复用了该文件描述符的新连接
+	c.poller.remove(c)
+
+	err := conn.Close()
+
+	if c.server.disconnectHandler != nil {
+		c.server.disconnectHandler(c)
+	}
+
+	c.server.recycle(c)
+
+	return err
+}
+
+// 写入队列，需持有写入锁
+func (c *reactorConn) enqueue(msg []byte, critical bool) error {
+	if c.conn == nil {
+		return errors.ErrConnectionClosed
+	}
+
+	opts := &c.server.opts.writeQueue
+
+	for !c.quota.Acquire(opts, len(msg)) {
+		switch {
+		case opts.Policy == network.DropNew && !critical:
+			c.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		case opts.Policy == network.DropNew || opts.Policy == network.DropOldest:
+			if c.dropOldest() {
+				c.server.queueMetrics.Drop()
+				continue
+			}
+
+			// 队列中不存在可丢弃的非关键消息，关键消息无法入队时作为慢消费者处理
+			if critical {
+				return errors.ErrSlowConsumer
+			}
+
+			c.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		default:
+			return errors.ErrSlowConsumer
+		}
+	}
+
+	_ = c.write(chWrite{typ: dataPacket, msg: msg, critical: critical})
+
+	return nil
+}
+
+// 丢弃最早入队且尚未开始写入的非关键数据包，需持有写入锁
+func (c *reactorConn) dropOldest() bool {
+	for i := range c.outbound {
+		if i == 0 && c.offset > 0 {
+			continue
+		}
+
+		if r := c.outbound[i]; r.typ == dataPacket && !r.critical {
+			c.quota.Release(&c.server.opts.writeQueue, len(r.msg))
+			c.outbound = append(c.outbound[:i], c.outbound[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// 写入数据，队列中已有待写入的数据时仅入队，需持有写入锁
+func (c *reactorConn) write(r chWrite) error {
+	c.outbound = append(c.outbound, r)
+
+	if len(c.outbound) > 1 {
+		return nil
+	}
+
+	return c.flush()
+}
+
+// 尽可能写入待写入的数据，套接字发送缓冲区已满时等待可写事件，需持有写入锁
+func (c *reactorConn) flush() error {
+	opts := &c.server.opts.writeQueue
+
+	for len(c.outbound) > 0 {
+		r := c.outbound[0]
+
+		n, err := c.writeFD(r.msg[c.offset:])
+		if n > 0 {
+			c.offset += n
+		}
+
+		if err != nil {
+			if err == syscall.EAGAIN {
+				return nil
+			}
+
+			if err == syscall.EINTR {
+				continue
+			}
+
+			log.Errorf("write data message error: %v", err)
+
+			c.outbound = nil
+			c.offset = 0
+			c.quota.Reset()
+			c.signalDrained()
+
+			return err
+		}
+
+		if c.offset < len(r.msg) {
+			continue
+		}
+
+		c.meter.Send(len(r.msg), r.typ == heartbeatPacket)
+
+		if r.typ == dataPacket {
+			c.quota.Release(opts, len(r.msg))
+		}
+
+		c.outbound[0] = chWrite{}
+		c.outbound = c.outbound[1:]
+		c.offset = 0
+	}
+
+	// 释放队列底层数组，降低空闲连接的内存占用
+	c.outbound = nil
+	c.signalDrained()
+
+	return nil
+}
+
+// 通知写入完成，需持有写入锁
+func (c *reactorConn) signalDrained() {
+	if c.drained != nil {
+		close(c.drained)
+		c.drained = nil
+	}
+}
+
+// 向文件描述符写入数据
+func (c *reactorConn) writeFD(data []byte) (n int, err error) {
+	if e := c.raw.Write(func(fd uintptr) bool {
+		n, err = syscall.Write(int(fd), data)
+		return true
+	}); e != nil {
+		return 0, e
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	return
+}
+
+// 从文件描述符读取数据
+func (c *reactorConn) readFD(buf []byte) (n int, err error) {
+	if e := c.raw.Read(func(fd uintptr) bool {
+		n, err = syscall.Read(int(fd), buf)
+		return true
+	}); e != nil {
+		return 0, e
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	return
+}
+
+// 处理可写事件
+func (c *reactorConn) handleWrite() {
+	c.mu.Lock()
+	if c.conn != nil {
+		_ = c.flush()
+	}
+	c.mu.Unlock()
+}
+
+// 处理可读事件，边缘触发模式下需读取至EAGAIN
+func (c *reactorConn) handleRead(buf []byte, reader *bytes.Reader) {
+	for {
+		n, err := c.readFD(buf)
+
+		if n > 0 {
+			c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+
+			if !c.receive(buf[:n], reader) {
+				return
+			}
+		}
+
+		switch {
+		case err == syscall.EAGAIN:
+			return
+		case err == syscall.EINTR:
+			continue
+		case err != nil || n == 0:
+			_ = c.closeWithReason(network.ReasonClientClosed)
+			return
+		}
+	}
+}
+
+// 切分并处理收到的数据，连接已关闭时返回false
+func (c *reactorConn) receive(data []byte, reader *bytes.Reader) bool {
+	if len(c.inbound) > 0 {
+		c.inbound = append(c.inbound, data...)
+		data = c.inbound
+	}
+
+	for len(data) > 0 {
+		n, err := packet.SplitFrame(data)
+		if err != nil {
+			log.Errorf("split packet error: %v", err)
+			_ = c.closeWithReason(network.ReasonClientClosed)
+			return false
+		}
+
+		if n == 0 {
+			break
+		}
+
+		if !c.handleFrame(data[:n], reader) {
+			return false
+		}
+
+		data = data[n:]
+	}
+
+	switch {
+	case len(data) == 0:
+		c.inbound = nil
+	case len(c.inbound) > 0:
+		c.inbound = c.inbound[:copy(c.inbound, data)]
+	default:
+		c.inbound = append([]byte(nil), data...)
+	}
+
+	return true
+}
+
+// 处理完整的数据包，连接已关闭时返回false
+func (c *reactorConn) handleFrame(frame []byte, reader *bytes.Reader) bool {
+	switch c.State() {
+	case network.ConnHanged:
+		return true
+	case network.ConnClosed:
+		return false
+	default:
+		// ignore
+	}
+
+	reader.Reset(frame)
+
+	buf, err := packet.ReadBuffer(reader)
+	if err != nil {
+		log.Errorf("read message error: %v", err)
+		return true
+	}
+
+	// ignore empty packet
+	if buf == nil || buf.Len() == 0 {
+		return true
+	}
+
+	isHeartbeat, err := packet.CheckHeartbeat(buf.Bytes())
+	if err != nil {
+		log.Errorf("check heartbeat message error: %v", err)
+		return true
+	}
+
+	c.meter.Receive(buf.Len(), isHeartbeat)
+
+	// ignore heartbeat packet
+	if isHeartbeat {
+		// 客户端回显的探测心跳仅用于测量往返时延，无需响应
+		if t, ok := packet.UnpackPing(buf.Bytes()); ok {
+			c.meter.Pong(t)
+			return true
+		}
+
+		// responsive heartbeat
+		if c.server.opts.heartbeatMechanism == RespHeartbeat {
+			c.sendHeartbeat()
+		}
+
+		return true
+	}
+
+	c.dispatch(buf)
+
+	return true
+}
+
+// 将消息提交至处理协程，同一连接的消息按接收顺序处理
+func (c *reactorConn) dispatch(buf buffer.Buffer) {
+	c.pmu.Lock()
+	c.pending = append(c.pending, buf)
+
+	if c.scheduled {
+		c.pmu.Unlock()
+		return
+	}
+
+	c.scheduled = true
+	c.pmu.Unlock()
+
+	c.server.tasks <- c
+}
+
+// 处理待处理消息
+func (c *reactorConn) process() {
+	for {
+		c.pmu.Lock()
+		pending := c.pending
+		c.pending = nil
+
+		if len(pending) == 0 {
+			c.scheduled = false
+			c.pmu.Unlock()
+			return
+		}
+		c.pmu.Unlock()
+
+		for _, buf := range pending {
+			if c.State() != network.ConnOpened || c.server.receiveHandler == nil {
+				buf.Release()
+				continue
+			}
+
+			c.server.receiveHandler(c, buf)
+		}
+	}
+}
+
+// 发送心跳包
+func (c *reactorConn) sendHeartbeat() {
+	heartbeat, err := packet.PackPing()
+	if err != nil {
+		log.Errorf("pack heartbeat message error: %v", err)
+		return
+	}
+
+	if t, ok := packet.UnpackPing(heartbeat); ok {
+		c.meter.Ping(t)
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		_ = c.write(chWrite{typ: heartbeatPacket, msg: heartbeat})
+	}
+	c.mu.Unlock()
+}
@@ -0,0 +1,327 @@
+//go:build linux
+
+package tcp
+
+import (
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/utils/xtime"
+)
+
+type reactorServer struct {
+	*server                    // 复用服务器配置、运行时设置及hook函数
+	listener *net.TCPListener  // 监听器
+	pollers  []*poller         // 轮询器
+	tasks    chan *reactorConn // 待处理消息的连接
+	id       atomic.Int64      // 连接ID
+	total    atomic.Int64      // 总连接数
+	done     chan struct{}     // 关闭信号
+	wg       sync.WaitGroup    // 等待轮询器及处理协程退出
+}
+
+var (
+	_ network.Server      = &reactorServer{}
+	_ network.QueueStater = &reactorServer{}
+	_ network.GuardServer = &reactorServer{}
+)
+
+// NewReactorServer 新建基于epoll边缘触发反应器的TCP服务器
+// 连接不再独占读写协程，读事件由少量轮询器处理，消息由固定数量的处理协程按连接顺序分发，适用于海量空闲连接的场景
+// 配置项与NewServer一致，可直接替换；暂不支持TLS及PROXY protocol，配置了二者时将回退为NewServer
+func NewReactorServer(opts ...ServerOption) network.Server {
+	o := defaultServerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.proxyProtocol || (o.certFile != "" && o.keyFile != "") {
+		log.Warnf("%s reactor server does not support tls or proxy protocol, fallback to the standard server", protocol)
+		return newServer(o)
+	}
+
+	return &reactorServer{server: newServer(o)}
+}
+
+// Start 启动服务器
+func (s *reactorServer) Start() error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	s.watch()
+
+	if s.startHandler != nil {
+		s.startHandler()
+	}
+
+	go s.serve()
+
+	go s.keepalive()
+
+	return nil
+}
+
+// Stop 关闭服务器
+func (s *reactorServer) Stop() error {
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+
+	if err := s.listener.Close(); err != nil {
+		return err
+	}
+
+	close(s.done)
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(s.pollers))
+
+	for i := range s.pollers {
+		p := s.pollers[i]
+
+		go func() {
+			p.visit(func(c *reactorConn) { _ = c.Close() })
+			wg.Done()
+		}()
+	}
+
+	wg.Wait()
+
+	for _, p := range s.pollers {
+		p.wakeup()
+	}
+
+	s.wg.Wait()
+
+	if s.stopHandler != nil {
+		s.stopHandler()
+	}
+
+	return nil
+}
+
+// 初始化反应器服务器
+func (s *reactorServer) init() error {
+	if err := s.guard.SetAllowList(s.opts.guard.AllowList); err != nil {
+		return err
+	}
+
+	if err := s.guard.SetDenyList(s.opts.guard.DenyList); err != nil {
+		return err
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", s.opts.addr)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.ListenTCP(addr.Network(), addr)
+	if err != nil {
+		return err
+	}
+
+	pollers := s.opts.reactorPollers
+	if pollers <= 0 {
+		pollers = runtime.NumCPU()
+	}
+
+	workers := s.opts.reactorWorkers
+	if workers <= 0 {
+		workers = 4 * runtime.NumCPU()
+	}
+
+	s.pollers = make([]*poller, 0, pollers)
+
+	for i := 0; i < pollers; i++ {
+		p, err := newPoller(s)
+		if err != nil {
+			for _, p := range s.pollers {
+				p.release()
+			}
+			_ = ln.Close()
+			return err
+		}
+
+		s.pollers = append(s.pollers, p)
+	}
+
+	s.listener = ln
+	s.tasks = make(chan *reactorConn, 1024*workers)
+	s.done = make(chan struct{})
+
+	var pollerWG sync.WaitGroup
+
+	pollerWG.Add(len(s.pollers))
+
+	for _, p := range s.pollers {
+		go func() {
+			p.run()
+			pollerWG.Done()
+		}()
+	}
+
+	s.wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+
+	// 轮询器全部退出后不再产生任务，此时关闭任务队列以结束处理协程
+	go func() {
+		pollerWG.Wait()
+		close(s.tasks)
+	}()
+
+	return nil
+}
+
+// 等待连接
+func (s *reactorServer) serve() {
+	var tempDelay time.Duration
+
+	for {
+		conn, err := s.listener.AcceptTCP()
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() {
+				if tempDelay == 0 {
+					tempDelay = 5 * time.Millisecond
+				} else {
+					tempDelay *= 2
+				}
+				if max := 1 * time.Second; tempDelay > max {
+					tempDelay = max
+				}
+
+				log.Warnf("tcp accept error: %v; retrying in %v", err, tempDelay)
+				time.Sleep(tempDelay)
+				continue
+			}
+
+			log.Warnf("tcp accept error: %v", err)
+			return
+		}
+
+		tempDelay = 0
+
+		s.handle(conn)
+	}
+}
+
+// 处理新连接
+func (s *reactorServer) handle(conn *net.TCPConn) {
+	addr := conn.RemoteAddr()
+
+	if err := s.guard.Admit(addr); err != nil {
+		log.Warnf("tcp connection rejected, addr: %v, reason: %v", addr, err)
+		_ = conn.Close()
+		return
+	}
+
+	if err := s.allocate(conn, addr); err != nil {
+		s.guard.Release(addr)
+		log.Errorf("connection allocate error: %v", err)
+		_ = conn.Close()
+	}
+}
+
+// 分配连接，addr为准入地址
+func (s *reactorServer) allocate(conn *net.TCPConn, addr net.Addr) error {
+	if s.total.Load() >= int64(s.maxConnNum.Load()) {
+		return errors.ErrTooManyConnection
+	}
+
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	fd := -1
+
+	if err = raw.Control(func(v uintptr) { fd = int(v) }); err != nil {
+		return err
+	}
+
+	p := s.pollers[fd%len(s.pollers)]
+	c := newReactorConn(s, p, s.id.Add(1), conn, raw, fd, addr)
+
+	p.store(c)
+	s.total.Add(1)
+
+	c.checkAuthorize()
+
+	if s.connectHandler != nil {
+		s.connectHandler(c)
+	}
+
+	// 连接打开回调执行完毕后才开始监听读事件，保证接收消息回调晚于连接打开回调
+	if err = p.watch(c); err != nil {
+		log.Errorf("connection watch error: %v", err)
+		_ = c.forceClose()
+	}
+
+	return nil
+}
+
+// 回收连接
+func (s *reactorServer) recycle(c *reactorConn) {
+	s.guard.Release(c.addr)
+	s.total.Add(-1)
+}
+
+// 处理连接收到的消息
+func (s *reactorServer) work() {
+	defer s.wg.Done()
+
+	for c := range s.tasks {
+		c.process()
+	}
+}
+
+// 心跳检测，所有连接共用一个定时器
+func (s *reactorServer) keepalive() {
+	var (
+		setting  = s.heartbeatInterval
+		changed  = setting.Changed()
+		interval = setting.Load()
+		ticker   = network.NewTicker(interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-changed:
+			changed, interval = setting.Changed(), setting.Load()
+			now := xtime.Now().UnixNano()
+			for _, p := range s.pollers {
+				p.visit(func(c *reactorConn) { c.lastHeartbeatTime.Store(now) })
+			}
+			network.ResetTicker(ticker, interval)
+		case t := <-ticker.C:
+			deadline := t.Add(-2 * interval).UnixNano()
+
+			for _, p := range s.pollers {
+				p.visit(func(c *reactorConn) {
+					if c.lastHeartbeatTime.Load() < deadline {
+						log.Debugf("connection heartbeat timeout, cid: %d", c.id)
+						_ = c.closeWithReason(network.ReasonHeartbeatTimeout)
+						return
+					}
+
+					if s.opts.heartbeatMechanism == TickHeartbeat {
+						c.sendHeartbeat()
+					}
+				})
+			}
+		}
+	}
+}
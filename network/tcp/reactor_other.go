@@ -0,0 +1,21 @@
+//go:build !linux
+
+package tcp
+
+import (
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/network"
+)
+
+// NewReactorServer 新建基于epoll边缘触发反应器的TCP服务器
+// 反应器仅支持Linux，其他平台回退为NewServer
+func NewReactorServer(opts ...ServerOption) network.Server {
+	o := defaultServerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	log.Warnf("%s reactor server is only supported on linux, fallback to the standard server", protocol)
+
+	return newServer(o)
+}
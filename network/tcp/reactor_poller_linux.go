@@ -0,0 +1,165 @@
+//go:build linux
+
+package tcp
+
+import (
+	"bytes"
+	"sync"
+	"syscall"
+
+	"github.com/devagame/due/v2/log"
+)
+
+const (
+	pollerEvents     = 128       // 单次轮询的最大事件数
+	pollerBufferSize = 64 * 1024 // 轮询器读缓冲区大小
+)
+
+const (
+	epollIn    = uint32(syscall.EPOLLIN)
+	epollOut   = uint32(syscall.EPOLLOUT)
+	epollErr   = uint32(syscall.EPOLLERR)
+	epollHup   = uint32(syscall.EPOLLHUP)
+	epollRdHup = uint32(syscall.EPOLLRDHUP)
+	epollET    = uint32(1) << 31
+)
+
+type poller struct {
+	server *reactorServer       // 服务器
+	epfd   int                  // epoll句柄
+	wake   [2]int               // 唤醒管道
+	rw     sync.RWMutex         // 读写锁
+	conns  map[int]*reactorConn // 连接
+	buf    []byte               // 读缓冲区，由轮询协程独占，所有连接共用
+	reader *bytes.Reader        // 数据包读取器，由轮询协程独占
+}
+
+func newPoller(s *reactorServer) (*poller, error) {
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &poller{
+		server: s,
+		epfd:   epfd,
+		wake:   [2]int{-1, -1},
+		conns:  make(map[int]*reactorConn),
+		buf:    make([]byte, pollerBufferSize),
+		reader: bytes.NewReader(nil),
+	}
+
+	if err = syscall.Pipe2(p.wake[:], syscall.O_NONBLOCK|syscall.O_CLOEXEC); err != nil {
+		p.release()
+		return nil, err
+	}
+
+	if err = syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, p.wake[0], &syscall.EpollEvent{
+		Events: epollIn,
+		Fd:     int32(p.wake[0]),
+	}); err != nil {
+		p.release()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// 存储连接
+func (p *poller) store(c *reactorConn) {
+	p.rw.Lock()
+	p.conns[c.fd] = c
+	p.rw.Unlock()
+}
+
+// 监听连接的读写事件
+func (p *poller) watch(c *reactorConn) error {
+	return syscall.EpollCtl(p.epfd, syscall.EPOLL_CTL_ADD, c.fd, &syscall.EpollEvent{
+		Events: epollIn | epollOut | epollRdHup | epollET,
+		Fd:     int32(c.fd),
+	})
+}
+
+// 移除连接，需在关闭连接前调用
+func (p *poller) remove(c *reactorConn) {
+	p.rw.Lock()
+	if p.conns[c.fd] == c {
+		delete(p.conns, c.fd)
+	}
+	p.rw.Unlock()
+
+	_ = syscall.EpollCtl(p.epfd, syscall.EPOLL_CTL_DEL, c.fd, nil)
+}
+
+// 访问所有连接，回调中可关闭连接
+func (p *poller) visit(fn func(c *reactorConn)) {
+	p.rw.RLock()
+	conns := make([]*reactorConn, 0, len(p.conns))
+	for _, c := range p.conns {
+		conns = append(conns, c)
+	}
+	p.rw.RUnlock()
+
+	for _, c := range conns {
+		fn(c)
+	}
+}
+
+// 唤醒并退出轮询
+func (p *poller) wakeup() {
+	_, _ = syscall.Write(p.wake[1], []byte{0})
+}
+
+// 释放轮询器资源
+func (p *poller) release() {
+	for _, fd := range p.wake {
+		if fd >= 0 {
+			_ = syscall.Close(fd)
+		}
+	}
+
+	_ = syscall.Close(p.epfd)
+}
+
+// 轮询事件
+func (p *poller) run() {
+	defer p.release()
+
+	events := make([]syscall.EpollEvent, pollerEvents)
+
+	for {
+		n, err := syscall.EpollWait(p.epfd, events, -1)
+		if err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+
+			log.Errorf("tcp reactor epoll wait error: %v", err)
+			return
+		}
+
+		for i := 0; i < n; i++ {
+			fd := int(events[i].Fd)
+
+			if fd == p.wake[0] {
+				return
+			}
+
+			p.rw.RLock()
+			c, ok := p.conns[fd]
+			p.rw.RUnlock()
+
+			if !ok {
+				continue
+			}
+
+			if events[i].Events&(epollIn|epollRdHup|epollHup|epollErr) != 0 {
+				c.handleRead(p.buf, p.reader)
+			}
+
+			if events[i].Events&(epollOut|epollHup|epollErr) != 0 {
+				c.handleWrite()
+			}
+		}
+	}
+}
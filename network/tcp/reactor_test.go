@@ -0,0 +1,225 @@
+//go:build linux
+
+package tcp_test
+
+import (
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/network/tcp/v2"
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+)
+
+func TestReactorServer_Echo(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewReactorServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerHeartbeatInterval(0),
+		tcp.WithServerReactorPollers(2),
+		tcp.WithServerReactorWorkers(2),
+	)
+
+	reasons := make(chan network.CloseReason, 1)
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		if err := conn.Push(buf.Bytes()); err != nil {
+			t.Errorf("push message failed: %v", err)
+		}
+	})
+
+	server.OnDisconnect(func(conn network.Conn) {
+		reasons <- conn.(network.ReasonConn).CloseReason()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const total = 100
+
+	var stream []byte
+
+	for i := 1; i <= total; i++ {
+		msg, err := packet.PackMessage(&packet.Message{Seq: int32(i), Route: 1, Buffer: []byte("hello world")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stream = append(stream, msg...)
+	}
+
+	// 以不对齐数据包边界的方式分段写入，验证反应器的拆包
+	go func() {
+		for i := 0; i < len(stream); i += 7 {
+			if _, err := conn.Write(stream[i:min(i+7, len(stream))]); err != nil {
+				return
+			}
+		}
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	for i := 1; i <= total; i++ {
+		data, err := packet.ReadMessage(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		message, err := packet.UnpackMessage(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if message.Seq != int32(i) {
+			t.Fatalf("seq = %d, want %d", message.Seq, i)
+		}
+	}
+
+	_ = conn.Close()
+
+	select {
+	case reason := <-reasons:
+		if reason != network.ReasonClientClosed {
+			t.Fatalf("reason = %v, want %v", reason, network.ReasonClientClosed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnect timeout")
+	}
+}
+
+func TestReactorServer_HeartbeatTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewReactorServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerHeartbeatInterval(50*time.Millisecond),
+	)
+
+	reasons := make(chan network.CloseReason, 1)
+
+	server.OnDisconnect(func(conn network.Conn) {
+		reasons <- conn.(network.ReasonConn).CloseReason()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	select {
+	case reason := <-reasons:
+		if reason != network.ReasonHeartbeatTimeout {
+			t.Fatalf("reason = %v, want %v", reason, network.ReasonHeartbeatTimeout)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat timeout not detected")
+	}
+}
+
+// 对比每个空闲连接的内存占用（含客户端连接的开销），可通过-benchtime=1x运行
+func BenchmarkServer_MemoryPerConn(b *testing.B) {
+	b.Run("Goroutine", func(b *testing.B) {
+		benchmarkMemoryPerConn(b, tcp.NewServer)
+	})
+
+	b.Run("Reactor", func(b *testing.B) {
+		benchmarkMemoryPerConn(b, tcp.NewReactorServer)
+	})
+}
+
+func benchmarkMemoryPerConn(b *testing.B, newServer func(opts ...tcp.ServerOption) network.Server) {
+	const total = 2000
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := newServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerHeartbeatInterval(0),
+		tcp.WithServerMaxConnNum(total),
+	)
+
+	connected := make(chan struct{}, total)
+	disconnected := make(chan struct{}, total)
+
+	server.OnConnect(func(conn network.Conn) { connected <- struct{}{} })
+	server.OnDisconnect(func(conn network.Conn) { disconnected <- struct{}{} })
+
+	if err = server.Start(); err != nil {
+		b.Fatal(err)
+	}
+	defer server.Stop()
+
+	var bytesPerConn, goroutinesPerConn float64
+
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		goroutines := runtime.NumGoroutine()
+
+		conns := make([]net.Conn, 0, total)
+
+		for j := 0; j < total; j++ {
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			conns = append(conns, conn)
+		}
+
+		for j := 0; j < total; j++ {
+			<-connected
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+
+		bytesPerConn += float64(after.HeapInuse+after.StackInuse-before.HeapInuse-before.StackInuse) / total
+		goroutinesPerConn += float64(runtime.NumGoroutine()-goroutines) / total
+
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+
+		for j := 0; j < total; j++ {
+			<-disconnected
+		}
+	}
+
+	b.ReportMetric(bytesPerConn/float64(b.N), "bytes/conn")
+	b.ReportMetric(goroutinesPerConn/float64(b.N), "goroutines/conn")
+}
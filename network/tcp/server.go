@@ -5,22 +5,36 @@ import (
 	"net"
 	"time"
 
+	"github.com/devagame/due/v2/core/proxyproto"
+	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/utils/xcall"
 )
 
 type server struct {
-	opts              *serverOptions            // 配置
-	listener          net.Listener              // 监听器
-	connMgr           *serverConnMgr            // 连接管理器
-	startHandler      network.StartHandler      // 服务器启动hook函数
-	stopHandler       network.CloseHandler      // 服务器关闭hook函数
-	connectHandler    network.ConnectHandler    // 连接打开hook函数
-	disconnectHandler network.DisconnectHandler // 连接关闭hook函数
-	receiveHandler    network.ReceiveHandler    // 接收消息hook函数
+	opts              *serverOptions                  // 配置
+	listener          net.Listener                    // 监听器
+	connMgr           *serverConnMgr                  // 连接管理器
+	startHandler      network.StartHandler            // 服务器启动hook函数
+	stopHandler       network.CloseHandler            // 服务器关闭hook函数
+	connectHandler    network.ConnectHandler          // 连接打开hook函数
+	disconnectHandler network.DisconnectHandler       // 连接关闭hook函数
+	receiveHandler    network.ReceiveHandler          // 接收消息hook函数
+	maxConnNum        *network.Setting[int]           // 最大连接数，可在运行时调整
+	heartbeatInterval *network.Setting[time.Duration] // 心跳检测间隔时间，可在运行时调整
+	authorizeTimeout  *network.Setting[time.Duration] // 授权超时时间，可在运行时调整
+	queueMetrics      network.QueueMetrics            // 写入队列指标
+	guard             *network.Guard                  // 连接准入控制器
+	cancels           []func()                        // 取消配置监听
 }
 
-var _ network.Server = &server{}
+var (
+	_ network.Server      = &server{}
+	_ network.QueueStater = &server{}
+	_ network.GuardServer = &server{}
+)
 
 func NewServer(opts ...ServerOption) network.Server {
 	o := defaultServerOptions()
@@ -28,9 +42,17 @@ func NewServer(opts ...ServerOption) network.Server {
 		opt(o)
 	}
 
+	return newServer(o)
+}
+
+func newServer(o *serverOptions) *server {
 	s := &server{}
 	s.opts = o
 	s.connMgr = newServerConnMgr(s)
+	s.maxConnNum = network.NewSetting(o.maxConnNum)
+	s.heartbeatInterval = network.NewSetting(o.heartbeatInterval)
+	s.authorizeTimeout = network.NewSetting(o.authorizeTimeout)
+	s.guard = network.NewGuard(o.guard)
 
 	return s
 }
@@ -46,6 +68,8 @@ func (s *server) Start() error {
 		return err
 	}
 
+	s.watch()
+
 	if s.startHandler != nil {
 		s.startHandler()
 	}
@@ -57,6 +81,10 @@ func (s *server) Start() error {
 
 // Stop 关闭服务器
 func (s *server) Stop() error {
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+
 	if err := s.listener.Close(); err != nil {
 		return err
 	}
@@ -75,6 +103,16 @@ func (s *server) Protocol() string {
 	return protocol
 }
 
+// QueueStat 获取写入队列统计
+func (s *server) QueueStat() *network.QueueStat {
+	return s.queueMetrics.Stat()
+}
+
+// Guard 获取连接准入控制器
+func (s *server) Guard() *network.Guard {
+	return s.guard
+}
+
 // OnStart 监听服务器启动
 func (s *server) OnStart(handler network.StartHandler) {
 	s.startHandler = handler
@@ -102,29 +140,112 @@ func (s *server) OnReceive(handler network.ReceiveHandler) {
 
 // 初始化TCP服务器
 func (s *server) init() error {
+	if err := s.guard.SetAllowList(s.opts.guard.AllowList); err != nil {
+		return err
+	}
+
+	if err := s.guard.SetDenyList(s.opts.guard.DenyList); err != nil {
+		return err
+	}
+
 	addr, err := net.ResolveTCPAddr("tcp", s.opts.addr)
 	if err != nil {
 		return err
 	}
 
+	ln, err := net.ListenTCP(addr.Network(), addr)
+	if err != nil {
+		return err
+	}
+
+	s.listener = ln
+
+	if s.opts.proxyProtocol {
+		trusted, err := proxyproto.ParseTrusted(s.opts.trustedProxies)
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+
+		if len(trusted) == 0 {
+			log.Warnf("%s server proxy protocol is enabled but no trusted proxies are configured, proxy headers will be ignored", protocol)
+		}
+
+		s.listener = proxyproto.NewListener(s.listener, trusted)
+	}
+
 	if s.opts.certFile != "" && s.opts.keyFile != "" {
 		cert, err := tls.LoadX509KeyPair(s.opts.certFile, s.opts.keyFile)
 		if err != nil {
+			_ = ln.Close()
 			return err
 		}
 
-		if s.listener, err = tls.Listen(addr.Network(), addr.String(), &tls.Config{
+		s.listener = tls.NewListener(s.listener, &tls.Config{
 			Certificates: []tls.Certificate{cert},
-		}); err != nil {
-			return err
+		})
+	}
+
+	return nil
+}
+
+// 监听etc配置变化
+// 最大连接数、心跳检测间隔时间、授权超时时间、单IP最大连接数、黑白名单可在运行时生效，其他配置需重启后生效
+func (s *server) watch() {
+	s.cancels = s.cancels[:0]
+
+	s.onChange(defaultServerMaxConnNumKey, func(val value.Value) {
+		s.maxConnNum.Store(val.Int())
+	}, defaultServerMaxConnNum)
+
+	s.onChange(defaultServerHeartbeatIntervalKey, func(val value.Value) {
+		s.heartbeatInterval.Store(val.Duration())
+	}, defaultServerHeartbeatInterval)
+
+	s.onChange(defaultServerAuthorizeTimeoutKey, func(val value.Value) {
+		s.authorizeTimeout.Store(val.Duration())
+	}, defaultServerAuthorizeTimeout)
+
+	s.onChange(defaultServerMaxConnPerIPKey, func(val value.Value) {
+		s.guard.SetMaxConnPerIP(val.Int())
+	})
+
+	s.onChange(defaultServerAllowListKey, func(val value.Value) {
+		if err := s.guard.SetAllowList(val.Strings()); err != nil {
+			log.Warnf("%s server allow list update failed: %v", protocol, err)
 		}
-	} else {
-		if s.listener, err = net.ListenTCP(addr.Network(), addr); err != nil {
-			return err
+	})
+
+	s.onChange(defaultServerDenyListKey, func(val value.Value) {
+		if err := s.guard.SetDenyList(val.Strings()); err != nil {
+			log.Warnf("%s server deny list update failed: %v", protocol, err)
 		}
+	})
+
+	etc.RestartOnly(
+		defaultServerAddrKey,
+		defaultServerCertFileKey,
+		defaultServerKeyFileKey,
+		defaultServerHeartbeatMechanismKey,
+		defaultServerProxyProtocolKey,
+		defaultServerTrustedProxiesKey,
+		defaultServerQueueMaxMessagesKey,
+		defaultServerQueueMaxBytesKey,
+		defaultServerQueuePolicyKey,
+		defaultServerAcceptRateKey,
+		defaultServerAcceptBurstKey,
+		defaultServerReactorPollersKey,
+		defaultServerReactorWorkersKey,
+	)
+}
+
+// 监听etc配置变化，显式设置的配置项不监听
+func (s *server) onChange(key string, fn func(val value.Value), def ...any) {
+	if _, ok := s.opts.fixed[key]; ok {
+		return
 	}
 
-	return nil
+	s.cancels = append(s.cancels, etc.OnChange(key, fn, def...))
 }
 
 // 等待连接
@@ -155,9 +276,28 @@ func (s *server) serve() {
 
 		tempDelay = 0
 
-		if err = s.connMgr.allocate(conn); err != nil {
-			log.Errorf("connection allocate error: %v", err)
-			_ = conn.Close()
+		if s.opts.proxyProtocol {
+			// 代理头在获取客户端地址时解析，需异步处理以免阻塞Accept
+			xcall.Go(func() { s.handle(conn) })
+		} else {
+			s.handle(conn)
 		}
 	}
 }
+
+// 处理新连接
+func (s *server) handle(conn net.Conn) {
+	addr := conn.RemoteAddr()
+
+	if err := s.guard.Admit(addr); err != nil {
+		log.Warnf("tcp connection rejected, addr: %v, reason: %v", addr, err)
+		_ = conn.Close()
+		return
+	}
+
+	if err := s.connMgr.allocate(conn, addr); err != nil {
+		s.guard.Release(addr)
+		log.Errorf("connection allocate error: %v", err)
+		_ = conn.Close()
+	}
+}
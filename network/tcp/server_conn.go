@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/devagame/due/v2/core/proxyproto"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/network"
@@ -16,21 +17,31 @@ import (
 )
 
 type serverConn struct {
-	id                int64          // 连接ID
-	uid               atomic.Int64   // 用户ID
-	attr              *attr          // 连接属性
-	state             atomic.Int32   // 连接状态
-	connMgr           *serverConnMgr // 连接管理
-	rw                sync.RWMutex   // 读写锁
-	conn              net.Conn       // TCP源连接
-	chWrite           chan chWrite   // 写入队列
-	done              chan struct{}  // 写入完成信号
-	close             chan struct{}  // 关闭信号
-	lastHeartbeatTime atomic.Int64   // 上次心跳时间
-	authorizeTimer    atomic.Value   // 授权定时器
-}
-
-var _ network.Conn = &serverConn{}
+	id                int64             // 连接ID
+	uid               atomic.Int64      // 用户ID
+	attr              *attr             // 连接属性
+	state             atomic.Int32      // 连接状态
+	connMgr           *serverConnMgr    // 连接管理
+	rw                sync.RWMutex      // 读写锁
+	conn              net.Conn          // TCP源连接
+	chWrite           chan chWrite      // 写入队列
+	quota             network.Quota     // 写入队列配额
+	reason            atomic.Int32      // 关闭原因
+	addr              net.Addr          // 准入地址，连接回收时释放准入配额
+	meter             network.ConnMeter // 流量计量
+	done              chan struct{}     // 写入完成信号
+	close             chan struct{}     // 关闭信号
+	lastHeartbeatTime atomic.Int64      // 上次心跳时间
+	authorizeTimer    atomic.Value      // 授权定时器
+}
+
+var (
+	_ network.Conn         = &serverConn{}
+	_ network.ProxyConn    = &serverConn{}
+	_ network.ReasonConn   = &serverConn{}
+	_ network.CriticalConn = &serverConn{}
+	_ network.ForceCloser  = &serverConn{}
+)
 
 // ID 获取连接ID
 func (c *serverConn) ID() int64 {
@@ -75,26 +86,47 @@ func (c *serverConn) Send(msg []byte) error {
 		return errors.ErrConnectionClosed
 	}
 
-	_, err := conn.Write(msg)
+	n, err := conn.Write(msg)
+	c.meter.Send(n, false)
+
 	return err
 }
 
 // Push 发送消息（异步）
 func (c *serverConn) Push(msg []byte) error {
+	return c.push(msg, false)
+}
+
+// PushCritical 发送关键消息（异步），写入队列溢出时不会被丢弃
+func (c *serverConn) PushCritical(msg []byte) error {
+	return c.push(msg, true)
+}
+
+// 推送消息
+func (c *serverConn) push(msg []byte, critical bool) error {
 	if err := c.checkState(); err != nil {
 		return err
 	}
 
 	c.rw.RLock()
-	defer c.rw.RUnlock()
+	err := c.enqueue(msg, critical)
+	c.rw.RUnlock()
 
-	if c.conn == nil {
-		return errors.ErrConnectionClosed
+	if errors.Is(err, errors.ErrSlowConsumer) {
+		c.slowClose()
 	}
 
-	c.chWrite <- chWrite{typ: dataPacket, msg: msg}
+	return err
+}
 
-	return nil
+// CloseReason 获取连接关闭原因
+func (c *serverConn) CloseReason() network.CloseReason {
+	return network.CloseReason(c.reason.Load())
+}
+
+// Stats 获取连接流量统计
+func (c *serverConn) Stats() *network.ConnStat {
+	return c.meter.Stat()
 }
 
 // State 获取连接状态
@@ -111,6 +143,23 @@ func (c *serverConn) Close(force ...bool) error {
 	}
 }
 
+// ForceClose 跳过写入队列中待发送的消息，在超时时间内尝试直接写入一次消息后强制关闭连接
+func (c *serverConn) ForceClose(msg []byte, timeout time.Duration) error {
+	c.rw.RLock()
+	conn := c.conn
+	c.rw.RUnlock()
+
+	if conn != nil && c.State() != network.ConnClosed {
+		// 写入超时同样作用于阻塞中的写入协程，避免对端停止读取时无限等待
+		_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+
+		n, _ := conn.Write(msg)
+		c.meter.Send(n, false)
+	}
+
+	return c.forceClose(true)
+}
+
 // LocalIP 获取本地IP
 func (c *serverConn) LocalIP() (string, error) {
 	addr, err := c.LocalAddr()
@@ -165,6 +214,23 @@ func (c *serverConn) RemoteAddr() (net.Addr, error) {
 	return conn.RemoteAddr(), nil
 }
 
+// ProxyAddr 获取代理地址，未经代理时与RemoteAddr相同
+func (c *serverConn) ProxyAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	c.rw.RLock()
+	conn := c.conn
+	c.rw.RUnlock()
+
+	if conn == nil {
+		return nil, errors.ErrConnectionClosed
+	}
+
+	return proxyproto.ProxyAddr(conn), nil
+}
+
 // 检测连接状态
 func (c *serverConn) checkState() error {
 	switch c.State() {
@@ -179,13 +245,13 @@ func (c *serverConn) checkState() error {
 
 // 授权检查
 func (c *serverConn) checkAuthorize() {
-	if c.connMgr.server.opts.authorizeTimeout > 0 {
-		timer := c.authorizeTimer.Swap(time.AfterFunc(c.connMgr.server.opts.authorizeTimeout, func() {
+	if authorizeTimeout := c.connMgr.server.authorizeTimeout.Load(); authorizeTimeout > 0 {
+		timer := c.authorizeTimer.Swap(time.AfterFunc(authorizeTimeout, func() {
 			if c.UID() != 0 {
 				return
 			}
 
-			c.forceClose(true)
+			c.closeWithReason(network.ReasonAuthorizeTimeout)
 		}))
 		if t, ok := timer.(*time.Timer); ok && t != nil {
 			t.Stop()
@@ -195,12 +261,10 @@ func (c *serverConn) checkAuthorize() {
 
 // 取消授权检查
 func (c *serverConn) uncheckAuthorize() {
-	if c.connMgr.server.opts.authorizeTimeout > 0 {
-		timer := c.authorizeTimer.Swap((*time.Timer)(nil))
+	timer := c.authorizeTimer.Swap((*time.Timer)(nil))
 
-		if t, ok := timer.(*time.Timer); ok && t != nil {
-			t.Stop()
-		}
+	if t, ok := timer.(*time.Timer); ok && t != nil {
+		t.Stop()
 	}
 }
 
@@ -212,15 +276,19 @@ func (c *serverConn) init(cm *serverConnMgr, id int64, conn net.Conn) {
 	c.state.Store(int32(network.ConnOpened))
 	c.conn = conn
 	c.connMgr = cm
-	c.chWrite = make(chan chWrite, 4096)
+	c.chWrite = make(chan chWrite, cm.server.opts.writeQueue.Capacity())
+	c.quota.Reset()
+	c.reason.Store(int32(network.ReasonNone))
+	c.meter.Reset()
 	c.done = make(chan struct{})
 	c.close = make(chan struct{})
 	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
 	c.authorizeTimer.Store((*time.Timer)(nil))
 
-	xcall.Go(c.read)
+	// 源连接以参数传入读写协程，避免与关闭时置空源连接产生竞争
+	xcall.Go(func() { c.read(conn) })
 
-	xcall.Go(c.write)
+	xcall.Go(func() { c.write(conn) })
 
 	c.checkAuthorize()
 
@@ -232,6 +300,7 @@ func (c *serverConn) init(cm *serverConnMgr, id int64, conn net.Conn) {
 // 重置连接
 func (c *serverConn) reset() {
 	c.attr = nil
+	c.addr = nil
 }
 
 // 优雅关闭
@@ -272,6 +341,91 @@ func (c *serverConn) forceClose(isNeedRecycle bool) error {
 	return c.doClose(isNeedRecycle)
 }
 
+// 携带关闭原因强制关闭
+func (c *serverConn) closeWithReason(reason network.CloseReason) error {
+	c.reason.CompareAndSwap(int32(network.ReasonNone), int32(reason))
+
+	return c.forceClose(true)
+}
+
+// 作为慢消费者关闭连接
+// 调用方可能持有会话锁（如广播消息），故而异步执行关闭操作，避免在关闭回调中重入会话锁
+func (c *serverConn) slowClose() {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnClosed)) {
+		if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+			return
+		}
+	}
+
+	c.reason.Store(int32(network.ReasonSlowConsumer))
+	c.connMgr.server.queueMetrics.Disconnect()
+	c.uncheckAuthorize()
+
+	log.Warnf("connection write queue overflow, close slow consumer, cid: %d", c.id)
+
+	xcall.Go(func() { _ = c.doClose(true) })
+}
+
+// 写入队列，需持有读锁
+func (c *serverConn) enqueue(msg []byte, critical bool) error {
+	if c.conn == nil {
+		return errors.ErrConnectionClosed
+	}
+
+	opts := &c.connMgr.server.opts.writeQueue
+
+	for !c.quota.Acquire(opts, len(msg)) {
+		switch {
+		case opts.Policy == network.DropNew && !critical:
+			c.connMgr.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		case opts.Policy == network.DropNew || opts.Policy == network.DropOldest:
+			if c.dropOldest(opts) {
+				continue
+			}
+
+			// 队列中不存在可丢弃的非关键消息，关键消息无法入队时作为慢消费者处理
+			if critical {
+				return errors.ErrSlowConsumer
+			}
+
+			c.connMgr.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		default:
+			return errors.ErrSlowConsumer
+		}
+	}
+
+	c.chWrite <- chWrite{typ: dataPacket, msg: msg, critical: critical}
+
+	return nil
+}
+
+// 丢弃最早入队的非关键消息，需持有读锁
+// 依次取出队列中的消息，除被丢弃的消息外按原顺序重新入队，队列中不存在非关键消息时返回false
+func (c *serverConn) dropOldest(opts *network.QueueOptions) bool {
+	dropped := false
+
+	for n := len(c.chWrite); n > 0; n-- {
+		select {
+		case r := <-c.chWrite:
+			if !dropped && r.typ == dataPacket && !r.critical {
+				c.quota.Release(opts, len(r.msg))
+				c.connMgr.server.queueMetrics.Drop()
+				dropped = true
+				continue
+			}
+
+			c.chWrite <- r
+		default:
+			// 写入协程已取出消息但尚未释放配额，重试
+			return true
+		}
+	}
+
+	return dropped
+}
+
 // 执行关闭操作
 func (c *serverConn) doClose(isNeedRecycle bool) error {
 	c.rw.Lock()
@@ -302,9 +456,7 @@ func (c *serverConn) doClose(isNeedRecycle bool) error {
 }
 
 // 读取消息
-func (c *serverConn) read() {
-	conn := c.conn
-
+func (c *serverConn) read(conn net.Conn) {
 	for {
 		select {
 		case <-c.close:
@@ -312,11 +464,11 @@ func (c *serverConn) read() {
 		default:
 			buf, err := packet.ReadBuffer(conn)
 			if err != nil {
-				_ = c.forceClose(true)
+				_ = c.closeWithReason(network.ReasonClientClosed)
 				return
 			}
 
-			if c.connMgr.server.opts.heartbeatInterval > 0 {
+			if c.connMgr.server.heartbeatInterval.Load() > 0 {
 				c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
 			}
 
@@ -340,8 +492,16 @@ func (c *serverConn) read() {
 				continue
 			}
 
+			c.meter.Receive(buf.Len(), isHeartbeat)
+
 			// ignore heartbeat packet
 			if isHeartbeat {
+				// 客户端回显的探测心跳仅用于测量往返时延，无需响应
+				if t, ok := packet.UnpackPing(buf.Bytes()); ok {
+					c.meter.Pong(t)
+					continue
+				}
+
 				// responsive heartbeat
 				if c.connMgr.server.opts.heartbeatMechanism == RespHeartbeat {
 					c.sendHeartbeat(conn)
@@ -356,18 +516,14 @@ func (c *serverConn) read() {
 }
 
 // 写入消息
-func (c *serverConn) write() {
+func (c *serverConn) write(conn net.Conn) {
 	var (
-		conn   = c.conn
-		ticker *time.Ticker
+		setting  = c.connMgr.server.heartbeatInterval
+		changed  = setting.Changed()
+		interval = setting.Load()
+		ticker   = network.NewTicker(interval)
 	)
-
-	if c.connMgr.server.opts.heartbeatInterval > 0 {
-		ticker = time.NewTicker(c.connMgr.server.opts.heartbeatInterval)
-		defer ticker.Stop()
-	} else {
-		ticker = &time.Ticker{C: make(chan time.Time, 1)}
-	}
+	defer ticker.Stop()
 
 	for {
 		select {
@@ -385,23 +541,32 @@ func (c *serverConn) write() {
 				return
 			}
 
+			c.quota.Release(&c.connMgr.server.opts.writeQueue, len(r.msg))
+
 			if c.isClosed() {
 				return
 			}
 
-			if _, err := conn.Write(r.msg); err != nil {
+			n, err := conn.Write(r.msg)
+			c.meter.Send(n, false)
+
+			if err != nil {
 				log.Errorf("write data message error: %v", err)
 			}
+		case <-changed:
+			changed, interval = setting.Changed(), setting.Load()
+			c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+			network.ResetTicker(ticker, interval)
 		case t, ok := <-ticker.C:
 			if !ok {
 				return
 			}
 
-			deadline := t.Add(-2 * c.connMgr.server.opts.heartbeatInterval).UnixNano()
+			deadline := t.Add(-2 * interval).UnixNano()
 
 			if c.lastHeartbeatTime.Load() < deadline {
 				log.Debugf("connection heartbeat timeout, cid: %d", c.id)
-				_ = c.forceClose(true)
+				_ = c.closeWithReason(network.ReasonHeartbeatTimeout)
 				return
 			} else {
 				if c.isClosed() {
@@ -423,10 +588,17 @@ func (c *serverConn) isClosed() bool {
 
 // 发送心跳包
 func (c *serverConn) sendHeartbeat(conn net.Conn) {
-	if heartbeat, err := packet.PackHeartbeat(); err != nil {
+	if heartbeat, err := packet.PackPing(); err != nil {
 		log.Errorf("pack heartbeat message error: %v", err)
 	} else {
-		if _, err = conn.Write(heartbeat); err != nil {
+		if t, ok := packet.UnpackPing(heartbeat); ok {
+			c.meter.Ping(t)
+		}
+
+		n, err := conn.Write(heartbeat)
+		c.meter.Send(n, true)
+
+		if err != nil {
 			log.Errorf("write heartbeat message error: %v", err)
 		}
 	}
@@ -49,14 +49,15 @@ func (cm *serverConnMgr) close() {
 	wg.Wait()
 }
 
-// 分配连接
-func (cm *serverConnMgr) allocate(c net.Conn) error {
-	if cm.total.Load() >= int64(cm.server.opts.maxConnNum) {
+// 分配连接，addr为准入地址
+func (cm *serverConnMgr) allocate(c net.Conn, addr net.Addr) error {
+	if cm.total.Load() >= int64(cm.server.maxConnNum.Load()) {
 		return errors.ErrTooManyConnection
 	}
 
 	id := cm.id.Add(1)
 	conn := cm.pool.Get().(*serverConn)
+	conn.addr = addr
 	conn.init(cm, id, c)
 	index := int(reflect.ValueOf(c).Pointer()) % len(cm.partitions)
 	cm.partitions[index].store(c, conn)
@@ -69,6 +70,7 @@ func (cm *serverConnMgr) allocate(c net.Conn) error {
 func (cm *serverConnMgr) recycle(c net.Conn) {
 	index := int(reflect.ValueOf(c).Pointer()) % len(cm.partitions)
 	if conn, ok := cm.partitions[index].delete(c); ok {
+		cm.server.guard.Release(conn.addr)
 		conn.reset()
 		cm.pool.Put(conn)
 		cm.total.Add(-1)
@@ -100,8 +102,16 @@ func (p *partition) delete(c net.Conn) (*serverConn, bool) {
 }
 
 // 关闭该分片内的所有连接
+// 关闭连接时会回收连接并从分片中删除，故而先复制连接列表再逐一关闭
 func (p *partition) close() {
+	p.rw.RLock()
+	connections := make([]*serverConn, 0, len(p.connections))
 	for _, conn := range p.connections {
+		connections = append(connections, conn)
+	}
+	p.rw.RUnlock()
+
+	for _, conn := range connections {
 		_ = conn.Close()
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/network"
 )
 
 const (
@@ -12,6 +13,7 @@ const (
 	defaultServerHeartbeatInterval  = "10s"
 	defaultServerHeartbeatMechanism = "resp"
 	defaultServerAuthorizeTimeout   = "0s"
+	defaultServerQueuePolicy        = "disconnect"
 )
 
 const (
@@ -22,6 +24,18 @@ const (
 	defaultServerHeartbeatIntervalKey  = "etc.network.tcp.server.heartbeatInterval"
 	defaultServerHeartbeatMechanismKey = "etc.network.tcp.server.heartbeatMechanism"
 	defaultServerAuthorizeTimeoutKey   = "etc.network.tcp.server.authorizeTimeout"
+	defaultServerProxyProtocolKey      = "etc.network.tcp.server.proxyProtocol"
+	defaultServerTrustedProxiesKey     = "etc.network.tcp.server.trustedProxies"
+	defaultServerQueueMaxMessagesKey   = "etc.network.tcp.server.writeQueue.maxMessages"
+	defaultServerQueueMaxBytesKey      = "etc.network.tcp.server.writeQueue.maxBytes"
+	defaultServerQueuePolicyKey        = "etc.network.tcp.server.writeQueue.policy"
+	defaultServerMaxConnPerIPKey       = "etc.network.tcp.server.guard.maxConnPerIP"
+	defaultServerAcceptRateKey         = "etc.network.tcp.server.guard.acceptRate"
+	defaultServerAcceptBurstKey        = "etc.network.tcp.server.guard.acceptBurst"
+	defaultServerAllowListKey          = "etc.network.tcp.server.guard.allowList"
+	defaultServerDenyListKey           = "etc.network.tcp.server.guard.denyList"
+	defaultServerReactorPollersKey     = "etc.network.tcp.server.reactor.pollers"
+	defaultServerReactorWorkersKey     = "etc.network.tcp.server.reactor.workers"
 )
 
 const (
@@ -34,13 +48,20 @@ type HeartbeatMechanism string
 type ServerOption func(o *serverOptions)
 
 type serverOptions struct {
-	addr               string             // 监听地址，默认0.0.0.0:3553
-	certFile           string             // 证书文件
-	keyFile            string             // 秘钥文件
-	maxConnNum         int                // 最大连接数，默认5000
-	heartbeatInterval  time.Duration      // 心跳检测间隔时间，默认10s
-	heartbeatMechanism HeartbeatMechanism // 心跳机制，默认resp
-	authorizeTimeout   time.Duration      // 授权超时时间，默认0s，不检测
+	addr               string               // 监听地址，默认0.0.0.0:3553
+	certFile           string               // 证书文件
+	keyFile            string               // 秘钥文件
+	maxConnNum         int                  // 最大连接数，默认5000
+	heartbeatInterval  time.Duration        // 心跳检测间隔时间，默认10s
+	heartbeatMechanism HeartbeatMechanism   // 心跳机制，默认resp
+	authorizeTimeout   time.Duration        // 授权超时时间，默认0s，不检测
+	proxyProtocol      bool                 // 是否解析PROXY protocol代理头，默认false
+	trustedProxies     []string             // 可信代理网段，仅解析来自可信代理的代理头，默认为空，不信任任何来源
+	writeQueue         network.QueueOptions // 连接写入队列配置
+	guard              network.GuardOptions // 连接准入配置
+	fixed              map[string]struct{}  // 显式设置的配置项，不监听etc中对应配置的变化
+	reactorPollers     int                  // 反应器轮询器数量，仅反应器服务器生效，默认为CPU核数
+	reactorWorkers     int                  // 反应器消息处理协程数量，仅反应器服务器生效，默认为CPU核数的4倍
 }
 
 func defaultServerOptions() *serverOptions {
@@ -52,6 +73,22 @@ func defaultServerOptions() *serverOptions {
 		heartbeatInterval:  etc.Get(defaultServerHeartbeatIntervalKey, defaultServerHeartbeatInterval).Duration(),
 		heartbeatMechanism: HeartbeatMechanism(etc.Get(defaultServerHeartbeatMechanismKey, defaultServerHeartbeatMechanism).String()),
 		authorizeTimeout:   etc.Get(defaultServerAuthorizeTimeoutKey, defaultServerAuthorizeTimeout).Duration(),
+		proxyProtocol:      etc.Get(defaultServerProxyProtocolKey).Bool(),
+		trustedProxies:     etc.Get(defaultServerTrustedProxiesKey).Strings(),
+		writeQueue: network.QueueOptions{
+			MaxMessages: etc.Get(defaultServerQueueMaxMessagesKey).Int(),
+			MaxBytes:    int(etc.Get(defaultServerQueueMaxBytesKey).B()),
+			Policy:      network.OverflowPolicy(etc.Get(defaultServerQueuePolicyKey, defaultServerQueuePolicy).String()),
+		},
+		guard: network.GuardOptions{
+			MaxConnPerIP: etc.Get(defaultServerMaxConnPerIPKey).Int(),
+			AcceptRate:   etc.Get(defaultServerAcceptRateKey).Float64(),
+			AcceptBurst:  etc.Get(defaultServerAcceptBurstKey).Int(),
+			AllowList:    etc.Get(defaultServerAllowListKey).Strings(),
+			DenyList:     etc.Get(defaultServerDenyListKey).Strings(),
+		},
+		reactorPollers: etc.Get(defaultServerReactorPollersKey).Int(),
+		reactorWorkers: etc.Get(defaultServerReactorWorkersKey).Int(),
 	}
 }
 
@@ -67,12 +104,18 @@ func WithServerCredentials(certFile, keyFile string) ServerOption {
 
 // WithServerMaxConnNum 设置连接的最大连接数
 func WithServerMaxConnNum(maxConnNum int) ServerOption {
-	return func(o *serverOptions) { o.maxConnNum = maxConnNum }
+	return func(o *serverOptions) {
+		o.maxConnNum = maxConnNum
+		o.fix(defaultServerMaxConnNumKey)
+	}
 }
 
 // WithServerHeartbeatInterval 设置心跳检测间隔时间
 func WithServerHeartbeatInterval(heartbeatInterval time.Duration) ServerOption {
-	return func(o *serverOptions) { o.heartbeatInterval = heartbeatInterval }
+	return func(o *serverOptions) {
+		o.heartbeatInterval = heartbeatInterval
+		o.fix(defaultServerHeartbeatIntervalKey)
+	}
 }
 
 // WithServerHeartbeatMechanism 设置心跳机制
@@ -82,5 +125,71 @@ func WithServerHeartbeatMechanism(heartbeatMechanism HeartbeatMechanism) ServerO
 
 // WithServerAuthorizeTimeout 设置授权超时时间
 func WithServerAuthorizeTimeout(authorizeTimeout time.Duration) ServerOption {
-	return func(o *serverOptions) { o.authorizeTimeout = authorizeTimeout }
+	return func(o *serverOptions) {
+		o.authorizeTimeout = authorizeTimeout
+		o.fix(defaultServerAuthorizeTimeoutKey)
+	}
+}
+
+// WithServerProxyProtocol 设置是否解析PROXY protocol代理头
+func WithServerProxyProtocol(proxyProtocol bool) ServerOption {
+	return func(o *serverOptions) { o.proxyProtocol = proxyProtocol }
+}
+
+// WithServerTrustedProxies 设置可信代理网段，支持CIDR及单个IP地址
+func WithServerTrustedProxies(trustedProxies ...string) ServerOption {
+	return func(o *serverOptions) { o.trustedProxies = trustedProxies }
+}
+
+// WithServerWriteQueue 设置连接写入队列配置
+func WithServerWriteQueue(writeQueue network.QueueOptions) ServerOption {
+	return func(o *serverOptions) { o.writeQueue = writeQueue }
+}
+
+// WithServerMaxConnPerIP 设置单IP最大并发连接数
+func WithServerMaxConnPerIP(maxConnPerIP int) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.MaxConnPerIP = maxConnPerIP
+		o.fix(defaultServerMaxConnPerIPKey)
+	}
+}
+
+// WithServerAcceptRate 设置单IP每秒允许建立的连接数及突发连接数
+func WithServerAcceptRate(rate float64, burst int) ServerOption {
+	return func(o *serverOptions) { o.guard.AcceptRate, o.guard.AcceptBurst = rate, burst }
+}
+
+// WithServerAllowList 设置白名单，支持CIDR及单个IP地址
+func WithServerAllowList(allowList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.AllowList = allowList
+		o.fix(defaultServerAllowListKey)
+	}
+}
+
+// WithServerDenyList 设置黑名单，支持CIDR及单个IP地址
+func WithServerDenyList(denyList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.DenyList = denyList
+		o.fix(defaultServerDenyListKey)
+	}
+}
+
+// WithServerReactorPollers 设置反应器轮询器数量，仅反应器服务器生效
+func WithServerReactorPollers(pollers int) ServerOption {
+	return func(o *serverOptions) { o.reactorPollers = pollers }
+}
+
+// WithServerReactorWorkers 设置反应器消息处理协程数量，仅反应器服务器生效
+func WithServerReactorWorkers(workers int) ServerOption {
+	return func(o *serverOptions) { o.reactorWorkers = workers }
+}
+
+// 标记显式设置的配置项
+func (o *serverOptions) fix(key string) {
+	if o.fixed == nil {
+		o.fixed = make(map[string]struct{})
+	}
+
+	o.fixed[key] = struct{}{}
 }
@@ -1,12 +1,16 @@
 package tcp_test
 
 import (
+	"io"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"testing"
+	"time"
 
 	"github.com/devagame/due/network/tcp/v2"
 	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/packet"
@@ -111,3 +115,49 @@ func TestServer_Benchmark(t *testing.T) {
 
 	select {}
 }
+
+func TestServer_ExplicitOptions(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerMaxConnNum(1),
+		tcp.WithServerHeartbeatInterval(0),
+	)
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// 显式设置的最大连接数不被etc配置覆盖
+	if err = etc.Set("etc.network.tcp.server.maxConnNum", 10); err != nil {
+		t.Fatal(err)
+	}
+	defer etc.Set("etc.network.tcp.server.maxConnNum", 5000)
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	_ = second.SetReadDeadline(time.Now().Add(time.Second))
+
+	if _, err = second.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("the connection exceeding the explicit max conn num is not rejected, err = %v", err)
+	}
+}
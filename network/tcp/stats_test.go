@@ -0,0 +1,170 @@
+package tcp_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/network/tcp/v2"
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+)
+
+func TestServer_Stats(t *testing.T) {
+	packer := packet.GetPacker()
+	packet.SetPacker(packet.NewPacker(packet.WithHeartbeatTime(true)))
+	defer packet.SetPacker(packer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := tcp.NewServer(
+		tcp.WithServerListenAddr(addr),
+		tcp.WithServerHeartbeatInterval(50*time.Millisecond),
+		tcp.WithServerHeartbeatMechanism(tcp.TickHeartbeat),
+	)
+
+	conns := make(chan network.Conn, 1)
+	received := make(chan struct{}, 1)
+
+	server.OnConnect(func(conn network.Conn) {
+		conns <- conn
+	})
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		received <- struct{}{}
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := tcp.NewClient(
+		tcp.WithClientAddr(addr),
+		tcp.WithClientHeartbeatInterval(time.Second),
+	)
+
+	cc, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close(true)
+
+	msg, err := packet.PackMessage(&packet.Message{Route: 1, Buffer: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = cc.Push(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := <-conns
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	// 等待服务端下发的心跳被客户端回显
+	deadline := time.Now().Add(5 * time.Second)
+	for conn.Stats().RTT == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("rtt not measured")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stat := conn.Stats()
+
+	if stat.MessagesIn != 1 {
+		t.Fatalf("messages in = %d, want 1", stat.MessagesIn)
+	}
+
+	if stat.BytesIn < int64(len(msg)) {
+		t.Fatalf("bytes in = %d, want >= %d", stat.BytesIn, len(msg))
+	}
+
+	if stat.BytesOut == 0 || stat.MessagesOut != 0 {
+		t.Fatalf("bytes out = %d, messages out = %d", stat.BytesOut, stat.MessagesOut)
+	}
+
+	if cs := cc.Stats(); cs.MessagesOut != 1 {
+		t.Fatalf("client messages out = %d, want 1", cs.MessagesOut)
+	}
+}
+
+func TestClient_RespHeartbeat(t *testing.T) {
+	packer := packet.GetPacker()
+	packet.SetPacker(packet.NewPacker(packet.WithHeartbeatTime(true)))
+	defer packet.SetPacker(packer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	heartbeats := make(chan int, 1)
+
+	// 模拟响应式心跳的旧版服务端，收到任意心跳均响应携带心跳时间的普通心跳
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.SetDeadline(time.Now().Add(500 * time.Millisecond))
+
+		n := 0
+
+		for {
+			buf, err := packet.ReadBuffer(conn)
+			if err != nil {
+				heartbeats <- n
+				return
+			}
+
+			if isHeartbeat, _ := packet.CheckHeartbeat(buf.Bytes()); isHeartbeat {
+				n++
+
+				heartbeat, err := packet.PackHeartbeat()
+				if err != nil {
+					t.Error(err)
+				}
+
+				_, _ = conn.Write(heartbeat)
+			}
+
+			buf.Release()
+		}
+	}()
+
+	client := tcp.NewClient(
+		tcp.WithClientAddr(ln.Addr().String()),
+		tcp.WithClientHeartbeatInterval(100*time.Millisecond),
+	)
+
+	cc, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close(true)
+
+	select {
+	case n := <-heartbeats:
+		if n > 10 {
+			t.Fatalf("heartbeats = %d, heartbeat ping-pong with the responsive server", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server timeout")
+	}
+}
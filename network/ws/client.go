@@ -23,9 +23,17 @@ func NewClient(opts ...ClientOption) network.Client {
 		opt(o)
 	}
 
-	return &client{opts: o, dialer: &websocket.Dialer{
-		HandshakeTimeout: o.handshakeTimeout,
+	c := &client{opts: o, dialer: &websocket.Dialer{
+		HandshakeTimeout:  o.handshakeTimeout,
+		Subprotocols:      o.subprotocols,
+		EnableCompression: o.compression.Enable,
 	}}
+
+	if o.reconnect.Enable {
+		return network.NewReconnectClient(c, o.reconnect)
+	}
+
+	return c
 }
 
 // Dial 拨号连接
@@ -38,7 +46,7 @@ func (c *client) Dial(addr ...string) (network.Conn, error) {
 		url = c.opts.url
 	}
 
-	conn, _, err := c.dialer.Dial(url, nil)
+	conn, _, err := c.dialer.Dial(url, c.opts.header)
 	if err != nil {
 		return nil, err
 	}
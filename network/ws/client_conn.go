@@ -18,21 +18,26 @@ import (
 )
 
 type clientConn struct {
-	rw                sync.RWMutex    // 锁
-	id                int64           // 连接ID
-	uid               atomic.Int64    // 用户ID
-	attr              *attr           // 连接属性
-	conn              *websocket.Conn // TCP源连接
-	state             atomic.Int32    // 连接状态
-	client            *client         // 客户端
-	chLowWrite        chan chWrite    // 低级队列
-	chHighWrite       chan chWrite    // 优先队列
-	lastHeartbeatTime atomic.Int64    // 上次心跳时间
-	done              chan struct{}   // 写入完成信号
-	close             chan struct{}   // 关闭信号
+	rw                sync.RWMutex        // 锁
+	id                int64               // 连接ID
+	uid               atomic.Int64        // 用户ID
+	attr              *attr               // 连接属性
+	conn              *websocket.Conn     // TCP源连接
+	state             atomic.Int32        // 连接状态
+	client            *client             // 客户端
+	chLowWrite        chan chWrite        // 低级队列
+	chHighWrite       chan chWrite        // 优先队列
+	lastHeartbeatTime atomic.Int64        // 上次心跳时间
+	done              chan struct{}       // 写入完成信号
+	close             chan struct{}       // 关闭信号
+	meter             network.ConnMeter   // 流量计量
+	notice            network.CloseNotice // 关闭通知
 }
 
-var _ network.Conn = &clientConn{}
+var (
+	_ Conn             = &clientConn{}
+	_ network.CodeConn = &clientConn{}
+)
 
 func newClientConn(id int64, conn *websocket.Conn, client *client) network.Conn {
 	c := &clientConn{
@@ -46,8 +51,14 @@ func newClientConn(id int64, conn *websocket.Conn, client *client) network.Conn
 		close:       make(chan struct{}),
 	}
 
+	c.attr.Set(AttrKeySubprotocol, conn.Subprotocol())
 	c.state.Store(int32(network.ConnOpened))
 	c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+	c.meter.Reset()
+
+	if compression := client.opts.compression; compression.Enable {
+		_ = conn.SetCompressionLevel(compression.Level)
+	}
 
 	xcall.Go(c.read)
 
@@ -65,6 +76,19 @@ func (c *clientConn) ID() int64 {
 	return c.id
 }
 
+// Subprotocol 获取协商的子协议
+func (c *clientConn) Subprotocol() string {
+	c.rw.RLock()
+	conn := c.conn
+	c.rw.RUnlock()
+
+	if conn == nil {
+		return ""
+	}
+
+	return conn.Subprotocol()
+}
+
 // UID 获取用户ID
 func (c *clientConn) UID() int64 {
 	return c.uid.Load()
@@ -123,6 +147,16 @@ func (c *clientConn) Push(msg []byte) (err error) {
 	return nil
 }
 
+// CloseCode 获取服务端下发的关闭原因码及说明信息，未收到关闭包时返回false
+func (c *clientConn) CloseCode() (network.CloseCode, string, bool) {
+	return c.notice.Load()
+}
+
+// Stats 获取连接流量统计
+func (c *clientConn) Stats() *network.ConnStat {
+	return c.meter.Stat()
+}
+
 // State 获取连接状态
 func (c *clientConn) State() network.ConnState {
 	return network.ConnState(c.state.Load())
@@ -283,7 +317,12 @@ func (c *clientConn) read() {
 				return
 			}
 
-			if msgType != websocket.BinaryMessage {
+			if msgType != c.client.opts.messageType.frame() {
+				continue
+			}
+
+			if msgData, err = c.client.opts.messageType.decode(msgData); err != nil {
+				log.Errorf("decode message error: %v", err)
 				continue
 			}
 
@@ -312,8 +351,23 @@ func (c *clientConn) read() {
 				continue
 			}
 
+			c.meter.Receive(len(msgData), isHeartbeat)
+
 			// ignore heartbeat packet
 			if isHeartbeat {
+				// 记录对端下发的关闭包，连接随后由对端关闭
+				if c.notice.Receive(msgData) {
+					continue
+				}
+
+				// 回显服务端下发的探测心跳，供服务端测量往返时延
+				if _, ok := packet.UnpackPing(msgData); ok {
+					c.rw.RLock()
+					if c.conn != nil {
+						c.chHighWrite <- chWrite{typ: heartbeatPacket, msg: msgData}
+					}
+					c.rw.RUnlock()
+				}
 				continue
 			}
 
@@ -402,7 +456,7 @@ func (c *clientConn) doWrite(conn *websocket.Conn, r chWrite) bool {
 		return false
 	}
 
-	if r.typ == heartbeatPacket {
+	if r.typ == heartbeatPacket && r.msg == nil {
 		if msg, err := packet.PackHeartbeat(); err != nil {
 			log.Errorf("pack heartbeat message error: %v", err)
 			return true
@@ -411,17 +465,32 @@ func (c *clientConn) doWrite(conn *websocket.Conn, r chWrite) bool {
 		}
 	}
 
-	if err := conn.WriteMessage(websocket.BinaryMessage, r.msg); err != nil {
+	if err := c.writeMessage(conn, r.msg); err != nil {
 		if !errors.Is(err, net.ErrClosed) {
 			if _, ok := err.(*websocket.CloseError); !ok {
 				log.Errorf("write message error: %v", err)
 			}
 		}
+	} else {
+		c.meter.Send(len(r.msg), r.typ == heartbeatPacket)
 	}
 
 	return true
 }
 
+// 写入消息，文本帧以base64编码数据包，启用压缩时仅压缩达到阈值的消息
+func (c *clientConn) writeMessage(conn *websocket.Conn, msg []byte) error {
+	opts := c.client.opts
+
+	data := opts.messageType.encode(msg)
+
+	if opts.compression.Enable {
+		conn.EnableWriteCompression(len(data) >= opts.compression.Threshold)
+	}
+
+	return conn.WriteMessage(opts.messageType.frame(), data)
+}
+
 // 处理心跳
 func (c *clientConn) doHandleHeartbeat(conn *websocket.Conn, t time.Time) bool {
 	deadline := t.Add(-2 * c.client.opts.heartbeatInterval).UnixNano()
@@ -439,8 +508,10 @@ func (c *clientConn) doHandleHeartbeat(conn *websocket.Conn, t time.Time) bool {
 			log.Errorf("pack heartbeat message error: %v", err)
 		} else {
 			// send heartbeat packet
-			if err := conn.WriteMessage(websocket.BinaryMessage, heartbeat); err != nil {
+			if err := c.writeMessage(conn, heartbeat); err != nil {
 				log.Errorf("write heartbeat message error: %v", err)
+			} else {
+				c.meter.Send(len(heartbeat), true)
 			}
 		}
 	}
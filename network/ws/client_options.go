@@ -1,29 +1,49 @@
 package ws
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/network"
 )
 
 const (
 	defaultClientUrl               = "ws://127.0.0.1:3553"
 	defaultClientHandshakeTimeout  = "10s"
 	defaultClientHeartbeatInterval = "10s"
+	defaultClientMessageType       = "binary"
+	defaultClientCompressLevel     = 1
+	defaultClientCompressThreshold = "512B"
 )
 
 const (
-	defaultClientUrlKey               = "etc.network.ws.client.url"
-	defaultClientHandshakeTimeoutKey  = "etc.network.ws.client.handshakeTimeout"
-	defaultClientHeartbeatIntervalKey = "etc.network.ws.client.heartbeatInterval"
+	defaultClientUrlKey                  = "etc.network.ws.client.url"
+	defaultClientHandshakeTimeoutKey     = "etc.network.ws.client.handshakeTimeout"
+	defaultClientHeartbeatIntervalKey    = "etc.network.ws.client.heartbeatInterval"
+	defaultClientMessageTypeKey          = "etc.network.ws.client.messageType"
+	defaultClientSubprotocolsKey         = "etc.network.ws.client.subprotocols"
+	defaultClientCompressEnableKey       = "etc.network.ws.client.compression.enable"
+	defaultClientCompressLevelKey        = "etc.network.ws.client.compression.level"
+	defaultClientCompressThresholdKey    = "etc.network.ws.client.compression.threshold"
+	defaultClientReconnectEnableKey      = "etc.network.ws.client.reconnect.enable"
+	defaultClientReconnectMaxAttemptsKey = "etc.network.ws.client.reconnect.maxAttempts"
+	defaultClientReconnectMinIntervalKey = "etc.network.ws.client.reconnect.minInterval"
+	defaultClientReconnectMaxIntervalKey = "etc.network.ws.client.reconnect.maxInterval"
+	defaultClientReconnectJitterKey      = "etc.network.ws.client.reconnect.jitter"
 )
 
 type ClientOption func(o *clientOptions)
 
 type clientOptions struct {
-	url               string        // 拨号地址
-	handshakeTimeout  time.Duration // 握手超时时间
-	heartbeatInterval time.Duration // 心跳间隔时间，默认10s
+	url               string                   // 拨号地址
+	handshakeTimeout  time.Duration            // 握手超时时间
+	heartbeatInterval time.Duration            // 心跳间隔时间，默认10s
+	messageType       MessageType              // 消息帧类型，默认binary
+	subprotocols      []string                 // 请求的子协议，按优先级排列
+	compression       Compression              // 压缩配置
+	header            http.Header              // 握手请求头
+	reconnect         network.ReconnectOptions // 断线重连配置，默认不启用
 }
 
 func defaultClientOptions() *clientOptions {
@@ -31,6 +51,20 @@ func defaultClientOptions() *clientOptions {
 		url:               etc.Get(defaultClientUrlKey, defaultClientUrl).String(),
 		handshakeTimeout:  etc.Get(defaultClientHandshakeTimeoutKey, defaultClientHandshakeTimeout).Duration(),
 		heartbeatInterval: etc.Get(defaultClientHeartbeatIntervalKey, defaultClientHeartbeatInterval).Duration(),
+		messageType:       MessageType(etc.Get(defaultClientMessageTypeKey, defaultClientMessageType).String()),
+		subprotocols:      etc.Get(defaultClientSubprotocolsKey).Strings(),
+		compression: Compression{
+			Enable:    etc.Get(defaultClientCompressEnableKey).Bool(),
+			Level:     etc.Get(defaultClientCompressLevelKey, defaultClientCompressLevel).Int(),
+			Threshold: int(etc.Get(defaultClientCompressThresholdKey, defaultClientCompressThreshold).B()),
+		},
+		reconnect: network.ReconnectOptions{
+			Enable:      etc.Get(defaultClientReconnectEnableKey).Bool(),
+			MaxAttempts: etc.Get(defaultClientReconnectMaxAttemptsKey).Int(),
+			MinInterval: etc.Get(defaultClientReconnectMinIntervalKey).Duration(),
+			MaxInterval: etc.Get(defaultClientReconnectMaxIntervalKey).Duration(),
+			Jitter:      etc.Get(defaultClientReconnectJitterKey).Float64(),
+		},
 	}
 }
 
@@ -48,3 +82,28 @@ func WithClientHandshakeTimeout(handshakeTimeout time.Duration) ClientOption {
 func WithClientHeartbeatInterval(heartbeatInterval time.Duration) ClientOption {
 	return func(o *clientOptions) { o.heartbeatInterval = heartbeatInterval }
 }
+
+// WithClientMessageType 设置消息帧类型
+func WithClientMessageType(messageType MessageType) ClientOption {
+	return func(o *clientOptions) { o.messageType = messageType }
+}
+
+// WithClientSubprotocols 设置请求的子协议，按优先级排列
+func WithClientSubprotocols(subprotocols ...string) ClientOption {
+	return func(o *clientOptions) { o.subprotocols = subprotocols }
+}
+
+// WithClientCompression 设置permessage-deflate压缩配置
+func WithClientCompression(compression Compression) ClientOption {
+	return func(o *clientOptions) { o.compression = compression }
+}
+
+// WithClientHeader 设置握手请求头，可用于携带鉴权令牌
+func WithClientHeader(header http.Header) ClientOption {
+	return func(o *clientOptions) { o.header = header }
+}
+
+// WithClientReconnect 设置断线重连配置
+func WithClientReconnect(reconnect network.ReconnectOptions) ClientOption {
+	return func(o *clientOptions) { o.reconnect = reconnect }
+}
@@ -1,7 +1,75 @@
 package ws
 
+import (
+	"encoding/base64"
+
+	"github.com/devagame/due/v2/network"
+	"github.com/gorilla/websocket"
+)
+
 const protocol = "ws"
 
+const (
+	BinaryMessage MessageType = "binary" // 二进制帧
+	TextMessage   MessageType = "text"   // 文本帧，数据包以base64编码后发送
+)
+
+// MessageType 消息帧类型
+type MessageType string
+
+// 转换为websocket帧类型
+func (t MessageType) frame() int {
+	if t == TextMessage {
+		return websocket.TextMessage
+	}
+
+	return websocket.BinaryMessage
+}
+
+// 编码写入帧的数据，文本帧以base64编码数据包，保证帧数据为合法的UTF-8文本
+func (t MessageType) encode(msg []byte) []byte {
+	if t != TextMessage {
+		return msg
+	}
+
+	data := make([]byte, base64.StdEncoding.EncodedLen(len(msg)))
+	base64.StdEncoding.Encode(data, msg)
+
+	return data
+}
+
+// 解码读取帧的数据
+func (t MessageType) decode(data []byte) ([]byte, error) {
+	if t != TextMessage {
+		return data, nil
+	}
+
+	msg := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+
+	n, err := base64.StdEncoding.Decode(msg, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg[:n], nil
+}
+
+// 握手信息在连接属性（Conn.Attr）中的键名
+const (
+	AttrKeyQuery       attrKey = iota + 1 // 握手请求的查询参数，值类型为url.Values
+	AttrKeyHeader                         // 握手请求头，值类型为http.Header
+	AttrKeySubprotocol                    // 协商的子协议，值类型为string
+)
+
+type attrKey int
+
+// Conn websocket连接，连接被包装时可通过network.Unwrap获取
+type Conn interface {
+	network.Conn
+	// Subprotocol 获取协商的子协议
+	Subprotocol() string
+}
+
 const (
 	closeSig        int = iota // 关闭信号
 	dataPacket                 // 数据包
@@ -9,6 +77,7 @@ const (
 )
 
 type chWrite struct {
-	typ int
-	msg []byte
+	typ      int
+	msg      []byte
+	critical bool // 是否为关键消息，写入队列溢出时不会被丢弃
 }
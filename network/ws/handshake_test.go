@@ -0,0 +1,103 @@
+package ws_test
+
+import (
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/network/ws/v2"
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+	"github.com/gorilla/websocket"
+)
+
+func TestServer_Handshake(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := ws.NewServer(
+		ws.WithServerListenAddr(addr),
+		ws.WithServerPath("/handshake"),
+		ws.WithServerHeartbeatInterval(0),
+		ws.WithServerMessageType(ws.TextMessage),
+		ws.WithServerSubprotocols("json", "proto"),
+		ws.WithServerCompression(ws.Compression{Enable: true, Level: 1, Threshold: 16}),
+	)
+
+	conns := make(chan network.Conn, 1)
+
+	server.OnConnect(func(conn network.Conn) {
+		conns <- conn
+	})
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		defer buf.Release()
+
+		_ = conn.Push(buf.Bytes())
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	dialer := &websocket.Dialer{HandshakeTimeout: time.Second, Subprotocols: []string{"proto", "json"}, EnableCompression: true}
+	header := http.Header{"Authorization": {"Bearer token"}}
+
+	client, _, err := dialer.Dial("ws://"+addr+"/handshake?token=abc", header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if client.Subprotocol() != "json" {
+		t.Fatalf("subprotocol = %q, want %q", client.Subprotocol(), "json")
+	}
+
+	var conn network.Conn
+	select {
+	case conn = <-conns:
+	case <-time.After(time.Second):
+		t.Fatal("connect timeout")
+	}
+
+	if c, ok := conn.(ws.Conn); !ok || c.Subprotocol() != "json" {
+		t.Fatal("server conn subprotocol mismatch")
+	}
+
+	if query, ok := conn.Attr().Get(ws.AttrKeyQuery); !ok || query.(url.Values).Get("token") != "abc" {
+		t.Fatalf("query attr = %v", query)
+	}
+
+	if header, ok := conn.Attr().Get(ws.AttrKeyHeader); !ok || header.(http.Header).Get("Authorization") != "Bearer token" {
+		t.Fatalf("header attr = %v", header)
+	}
+
+	msg, err := packet.PackMessage(&packet.Message{Route: 1, Buffer: []byte(`{"data":"hello websocket compression"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.WriteMessage(websocket.TextMessage, []byte(base64.StdEncoding.EncodeToString(msg))); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+
+	typ, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typ != websocket.TextMessage || string(data) != base64.StdEncoding.EncodeToString(msg) {
+		t.Fatalf("echo = %d %q, want text %q", typ, data, msg)
+	}
+}
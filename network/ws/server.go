@@ -8,12 +8,17 @@
 package ws
 
 import (
+	"github.com/devagame/due/v2/core/proxyproto"
+	"github.com/devagame/due/v2/core/value"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/network"
 	"github.com/devagame/due/v2/utils/xcall"
 	"github.com/gorilla/websocket"
 	"net"
 	"net/http"
+	"net/netip"
+	"time"
 )
 
 type UpgradeHandler func(w http.ResponseWriter, r *http.Request) (allowed bool)
@@ -25,18 +30,29 @@ type Server interface {
 }
 
 type server struct {
-	opts              *serverOptions            // 配置
-	listener          net.Listener              // 监听器
-	connMgr           *serverConnMgr            // 连接管理器
-	startHandler      network.StartHandler      // 服务器启动hook函数
-	stopHandler       network.CloseHandler      // 服务器关闭hook函数
-	connectHandler    network.ConnectHandler    // 连接打开hook函数
-	disconnectHandler network.DisconnectHandler // 连接关闭hook函数
-	receiveHandler    network.ReceiveHandler    // 接收消息hook函数
-	upgradeHandler    UpgradeHandler            // HTTP协议升级成WS协议hook函数
+	opts              *serverOptions                  // 配置
+	listener          net.Listener                    // 监听器
+	trusted           proxyproto.Trusted              // 可信代理网段
+	connMgr           *serverConnMgr                  // 连接管理器
+	startHandler      network.StartHandler            // 服务器启动hook函数
+	stopHandler       network.CloseHandler            // 服务器关闭hook函数
+	connectHandler    network.ConnectHandler          // 连接打开hook函数
+	disconnectHandler network.DisconnectHandler       // 连接关闭hook函数
+	receiveHandler    network.ReceiveHandler          // 接收消息hook函数
+	upgradeHandler    UpgradeHandler                  // HTTP协议升级成WS协议hook函数
+	maxConnNum        *network.Setting[int]           // 最大连接数，可在运行时调整
+	heartbeatInterval *network.Setting[time.Duration] // 心跳间隔时间，可在运行时调整
+	authorizeTimeout  *network.Setting[time.Duration] // 授权超时时间，可在运行时调整
+	queueMetrics      network.QueueMetrics            // 写入队列指标
+	guard             *network.Guard                  // 连接准入控制器
+	cancels           []func()                        // 取消配置监听
 }
 
-var _ Server = &server{}
+var (
+	_ Server              = &server{}
+	_ network.QueueStater = &server{}
+	_ network.GuardServer = &server{}
+)
 
 func NewServer(opts ...ServerOption) Server {
 	o := defaultServerOptions()
@@ -47,6 +63,10 @@ func NewServer(opts ...ServerOption) Server {
 	s := &server{}
 	s.opts = o
 	s.connMgr = newConnMgr(s)
+	s.maxConnNum = network.NewSetting(o.maxConnNum)
+	s.heartbeatInterval = network.NewSetting(o.heartbeatInterval)
+	s.authorizeTimeout = network.NewSetting(o.authorizeTimeout)
+	s.guard = network.NewGuard(o.guard)
 
 	return s
 }
@@ -67,6 +87,8 @@ func (s *server) Start() error {
 		return err
 	}
 
+	s.watch()
+
 	if s.startHandler != nil {
 		s.startHandler()
 	}
@@ -78,6 +100,10 @@ func (s *server) Start() error {
 
 // Stop 关闭服务器
 func (s *server) Stop() error {
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+
 	if err := s.listener.Close(); err != nil {
 		return err
 	}
@@ -87,13 +113,95 @@ func (s *server) Stop() error {
 	return nil
 }
 
+// 监听etc配置变化
+// 最大连接数、心跳间隔时间、授权超时时间、单IP最大连接数、黑白名单可在运行时生效，其他配置需重启后生效
+func (s *server) watch() {
+	s.cancels = s.cancels[:0]
+
+	s.onChange(defaultServerMaxConnNumKey, func(val value.Value) {
+		s.maxConnNum.Store(val.Int())
+	}, defaultServerMaxConnNum)
+
+	s.onChange(defaultServerHeartbeatIntervalKey, func(val value.Value) {
+		s.heartbeatInterval.Store(val.Duration())
+	}, defaultServerHeartbeatInterval)
+
+	s.onChange(defaultServerAuthorizeTimeoutKey, func(val value.Value) {
+		s.authorizeTimeout.Store(val.Duration())
+	}, defaultServerAuthorizeTimeout)
+
+	s.onChange(defaultServerMaxConnPerIPKey, func(val value.Value) {
+		s.guard.SetMaxConnPerIP(val.Int())
+	})
+
+	s.onChange(defaultServerAllowListKey, func(val value.Value) {
+		if err := s.guard.SetAllowList(val.Strings()); err != nil {
+			log.Warnf("%s server allow list update failed: %v", protocol, err)
+		}
+	})
+
+	s.onChange(defaultServerDenyListKey, func(val value.Value) {
+		if err := s.guard.SetDenyList(val.Strings()); err != nil {
+			log.Warnf("%s server deny list update failed: %v", protocol, err)
+		}
+	})
+
+	etc.RestartOnly(
+		defaultServerAddrKey,
+		defaultServerPathKey,
+		defaultServerCheckOriginsKey,
+		defaultServerKeyFileKey,
+		defaultServerCertFileKey,
+		defaultServerHandshakeTimeoutKey,
+		defaultServerHeartbeatMechanismKey,
+		defaultServerProxyProtocolKey,
+		defaultServerForwardedHeadersKey,
+		defaultServerTrustedProxiesKey,
+		defaultServerMessageTypeKey,
+		defaultServerSubprotocolsKey,
+		defaultServerCompressEnableKey,
+		defaultServerCompressLevelKey,
+		defaultServerCompressThresholdKey,
+		defaultServerQueueMaxMessagesKey,
+		defaultServerQueueMaxBytesKey,
+		defaultServerQueuePolicyKey,
+		defaultServerAcceptRateKey,
+		defaultServerAcceptBurstKey,
+	)
+}
+
+// 监听etc配置变化，显式设置的配置项不监听
+func (s *server) onChange(key string, fn func(val value.Value), def ...any) {
+	if _, ok := s.opts.fixed[key]; ok {
+		return
+	}
+
+	s.cancels = append(s.cancels, etc.OnChange(key, fn, def...))
+}
+
 // 初始化服务器
 func (s *server) init() error {
+	if err := s.guard.SetAllowList(s.opts.guard.AllowList); err != nil {
+		return err
+	}
+
+	if err := s.guard.SetDenyList(s.opts.guard.DenyList); err != nil {
+		return err
+	}
+
 	addr, err := net.ResolveTCPAddr("tcp", s.opts.addr)
 	if err != nil {
 		return err
 	}
 
+	if s.trusted, err = proxyproto.ParseTrusted(s.opts.trustedProxies); err != nil {
+		return err
+	}
+
+	if len(s.trusted) == 0 && (s.opts.proxyProtocol || s.opts.forwardedHeaders) {
+		log.Warnf("%s server no trusted proxies are configured, proxy headers will be ignored", protocol)
+	}
+
 	ln, err := net.ListenTCP(addr.Network(), addr)
 	if err != nil {
 		return err
@@ -101,6 +209,10 @@ func (s *server) init() error {
 
 	s.listener = ln
 
+	if s.opts.proxyProtocol {
+		s.listener = proxyproto.NewListener(ln, s.trusted)
+	}
+
 	return nil
 }
 
@@ -109,8 +221,9 @@ func (s *server) serve() {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:    4096,
 		WriteBufferSize:   4096,
-		EnableCompression: false,
+		EnableCompression: s.opts.compression.Enable,
 		CheckOrigin:       s.opts.checkOrigin,
+		Subprotocols:      s.opts.subprotocols,
 	}
 
 	http.HandleFunc(s.opts.path, func(w http.ResponseWriter, r *http.Request) {
@@ -119,18 +232,30 @@ func (s *server) serve() {
 			return
 		}
 
+		// 在协议升级前完成准入检测，减少握手洪泛的开销
+		addr := s.requestAddr(r)
+
+		if err := s.guard.Admit(addr); err != nil {
+			log.Warnf("websocket connection rejected, addr: %v, reason: %v", addr, err)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
 		if s.upgradeHandler != nil && !s.upgradeHandler(w, r) {
+			s.guard.Release(addr)
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		}
 
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
+			s.guard.Release(addr)
 			log.Errorf("websocket upgrade error: %v", err)
 			return
 		}
 
-		if err = s.connMgr.allocate(conn); err != nil {
+		if err = s.connMgr.allocate(conn, r, addr); err != nil {
+			s.guard.Release(addr)
 			log.Errorf("connection allocate error: %v", err)
 			_ = conn.Close()
 		}
@@ -148,6 +273,31 @@ func (s *server) serve() {
 	}
 }
 
+// 获取握手请求的客户端地址，启用请求头解析时优先使用请求头中的客户端地址
+func (s *server) requestAddr(r *http.Request) net.Addr {
+	var addr net.Addr
+
+	if ap, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		addr = net.TCPAddrFromAddrPort(ap)
+	}
+
+	if s.opts.forwardedHeaders && addr != nil {
+		addr = proxyproto.ForwardedAddr(r.Header, addr, s.trusted)
+	}
+
+	return addr
+}
+
+// QueueStat 获取写入队列统计
+func (s *server) QueueStat() *network.QueueStat {
+	return s.queueMetrics.Stat()
+}
+
+// Guard 获取连接准入控制器
+func (s *server) Guard() *network.Guard {
+	return s.guard
+}
+
 // OnStart 监听服务器启动
 func (s *server) OnStart(handler network.StartHandler) {
 	s.startHandler = handler
@@ -9,11 +9,13 @@ package ws
 
 import (
 	"net"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/core/proxyproto"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/network"
@@ -25,22 +27,34 @@ import (
 )
 
 type serverConn struct {
-	id                int64           // 连接ID
-	uid               atomic.Int64    // 用户ID
-	attr              *attr           // 连接属性
-	state             atomic.Int32    // 连接状态
-	connMgr           *serverConnMgr  // 连接管理
-	rw                sync.RWMutex    // 锁
-	conn              *websocket.Conn // WS源连接
-	chLowWrite        chan chWrite    // 低级队列
-	chHighWrite       chan chWrite    // 优先队列
-	done              chan struct{}   // 写入完成信号
-	close             chan struct{}   // 关闭信号
-	lastHeartbeatTime atomic.Int64    // 上次心跳时间
-	authorizeTimer    atomic.Value    // 授权定时器
-}
-
-var _ network.Conn = &serverConn{}
+	id                int64             // 连接ID
+	uid               atomic.Int64      // 用户ID
+	attr              *attr             // 连接属性
+	state             atomic.Int32      // 连接状态
+	connMgr           *serverConnMgr    // 连接管理
+	rw                sync.RWMutex      // 锁
+	conn              *websocket.Conn   // WS源连接
+	wmu               sync.Mutex        // 写入锁，WS源连接不支持并发写入
+	remoteAddr        net.Addr          // 经由请求头解析出的客户端地址
+	chLowWrite        chan chWrite      // 低级队列
+	chHighWrite       chan chWrite      // 优先队列
+	quota             network.Quota     // 低级队列配额
+	reason            atomic.Int32      // 关闭原因
+	addr              net.Addr          // 准入地址，连接回收时释放准入配额
+	meter             network.ConnMeter // 流量计量
+	done              chan struct{}     // 写入完成信号
+	close             chan struct{}     // 关闭信号
+	lastHeartbeatTime atomic.Int64      // 上次心跳时间
+	authorizeTimer    atomic.Value      // 授权定时器
+}
+
+var (
+	_ Conn                 = &serverConn{}
+	_ network.ProxyConn    = &serverConn{}
+	_ network.ReasonConn   = &serverConn{}
+	_ network.CriticalConn = &serverConn{}
+	_ network.ForceCloser  = &serverConn{}
+)
 
 // ID 获取连接ID
 func (c *serverConn) ID() int64 {
@@ -57,6 +71,19 @@ func (c *serverConn) Attr() network.Attr {
 	return c.attr
 }
 
+// Subprotocol 获取协商的子协议
+func (c *serverConn) Subprotocol() string {
+	c.rw.RLock()
+	conn := c.conn
+	c.rw.RUnlock()
+
+	if conn == nil {
+		return ""
+	}
+
+	return conn.Subprotocol()
+}
+
 // Bind 绑定用户ID
 func (c *serverConn) Bind(uid int64) {
 	c.uid.Store(uid)
@@ -91,20 +118,39 @@ func (c *serverConn) Send(msg []byte) (err error) {
 
 // Push 发送消息（异步）
 func (c *serverConn) Push(msg []byte) error {
+	return c.push(msg, false)
+}
+
+// PushCritical 发送关键消息（异步），写入队列溢出时不会被丢弃
+func (c *serverConn) PushCritical(msg []byte) error {
+	return c.push(msg, true)
+}
+
+// 推送消息
+func (c *serverConn) push(msg []byte, critical bool) error {
 	if err := c.checkState(); err != nil {
 		return err
 	}
 
 	c.rw.RLock()
-	defer c.rw.RUnlock()
+	err := c.enqueue(msg, critical)
+	c.rw.RUnlock()
 
-	if c.conn == nil {
-		return errors.ErrConnectionClosed
+	if errors.Is(err, errors.ErrSlowConsumer) {
+		c.slowClose()
 	}
 
-	c.chLowWrite <- chWrite{typ: dataPacket, msg: msg}
+	return err
+}
+
+// CloseReason 获取连接关闭原因
+func (c *serverConn) CloseReason() network.CloseReason {
+	return network.CloseReason(c.reason.Load())
+}
 
-	return nil
+// Stats 获取连接流量统计
+func (c *serverConn) Stats() *network.ConnStat {
+	return c.meter.Stat()
 }
 
 // State 获取连接状态
@@ -121,6 +167,30 @@ func (c *serverConn) Close(force ...bool) error {
 	}
 }
 
+// ForceClose 跳过写入队列中待发送的消息，在超时时间内尝试直接写入一次消息后强制关闭连接
+func (c *serverConn) ForceClose(msg []byte, timeout time.Duration) error {
+	c.rw.RLock()
+	conn := c.conn
+	c.rw.RUnlock()
+
+	if conn != nil && c.State() != network.ConnClosed {
+		deadline := time.Now().Add(timeout)
+
+		// 先设置底层连接的写入超时，使阻塞中的写入协程及时释放写入锁
+		_ = conn.NetConn().SetWriteDeadline(deadline)
+
+		c.wmu.Lock()
+		_ = conn.SetWriteDeadline(deadline)
+		c.wmu.Unlock()
+
+		if err := c.writeMessage(conn, msg); err == nil {
+			c.meter.Send(len(msg), false)
+		}
+	}
+
+	return c.forceClose(true)
+}
+
 // LocalIP 获取本地IP
 func (c *serverConn) LocalIP() (string, error) {
 	addr, err := c.LocalAddr()
@@ -172,18 +242,55 @@ func (c *serverConn) RemoteAddr() (net.Addr, error) {
 		return nil, errors.ErrConnectionClosed
 	}
 
+	if c.remoteAddr != nil {
+		return c.remoteAddr, nil
+	}
+
 	return conn.RemoteAddr(), nil
 }
 
+// ProxyAddr 获取代理地址，未经代理时与RemoteAddr相同
+func (c *serverConn) ProxyAddr() (net.Addr, error) {
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	c.rw.RLock()
+	conn := c.conn
+	c.rw.RUnlock()
+
+	if conn == nil {
+		return nil, errors.ErrConnectionClosed
+	}
+
+	return proxyproto.ProxyAddr(conn.NetConn()), nil
+}
+
 // 初始化连接
-func (c *serverConn) init(cm *serverConnMgr, id int64, conn *websocket.Conn) {
+func (c *serverConn) init(cm *serverConnMgr, id int64, conn *websocket.Conn, r *http.Request) {
 	c.id = id
 	c.uid.Store(0)
 	c.attr = &attr{}
+	c.attr.Set(AttrKeyQuery, r.URL.Query())
+	c.attr.Set(AttrKeyHeader, r.Header)
+	c.attr.Set(AttrKeySubprotocol, conn.Subprotocol())
 	c.state.Store(int32(network.ConnOpened))
 	c.conn = conn
 	c.connMgr = cm
-	c.chLowWrite = make(chan chWrite, 4096)
+
+	if opts := cm.server.opts; opts.forwardedHeaders {
+		c.remoteAddr = proxyproto.ForwardedAddr(r.Header, conn.RemoteAddr(), cm.server.trusted)
+	} else {
+		c.remoteAddr = nil
+	}
+
+	if compression := cm.server.opts.compression; compression.Enable {
+		_ = conn.SetCompressionLevel(compression.Level)
+	}
+	c.chLowWrite = make(chan chWrite, cm.server.opts.writeQueue.Capacity())
+	c.quota.Reset()
+	c.reason.Store(int32(network.ReasonNone))
+	c.meter.Reset()
 	c.chHighWrite = make(chan chWrite, 1024)
 	c.done = make(chan struct{})
 	c.close = make(chan struct{})
@@ -204,6 +311,8 @@ func (c *serverConn) init(cm *serverConnMgr, id int64, conn *websocket.Conn) {
 // 重置连接
 func (c *serverConn) reset() {
 	c.attr = nil
+	c.addr = nil
+	c.remoteAddr = nil
 }
 
 // 检测连接状态
@@ -220,13 +329,13 @@ func (c *serverConn) checkState() error {
 
 // 授权检查
 func (c *serverConn) checkAuthorize() {
-	if c.connMgr.server.opts.authorizeTimeout > 0 {
-		timer := c.authorizeTimer.Swap(time.AfterFunc(c.connMgr.server.opts.authorizeTimeout, func() {
+	if authorizeTimeout := c.connMgr.server.authorizeTimeout.Load(); authorizeTimeout > 0 {
+		timer := c.authorizeTimer.Swap(time.AfterFunc(authorizeTimeout, func() {
 			if c.UID() != 0 {
 				return
 			}
 
-			c.forceClose(true)
+			c.closeWithReason(network.ReasonAuthorizeTimeout)
 		}))
 		if t, ok := timer.(*time.Timer); ok && t != nil {
 			t.Stop()
@@ -236,12 +345,10 @@ func (c *serverConn) checkAuthorize() {
 
 // 取消授权检查
 func (c *serverConn) uncheckAuthorize() {
-	if c.connMgr.server.opts.authorizeTimeout > 0 {
-		timer := c.authorizeTimer.Swap((*time.Timer)(nil))
+	timer := c.authorizeTimer.Swap((*time.Timer)(nil))
 
-		if t, ok := timer.(*time.Timer); ok && t != nil {
-			t.Stop()
-		}
+	if t, ok := timer.(*time.Timer); ok && t != nil {
+		t.Stop()
 	}
 }
 
@@ -283,6 +390,91 @@ func (c *serverConn) forceClose(isNeedRecycle bool) error {
 	return c.doClose(isNeedRecycle)
 }
 
+// 携带关闭原因强制关闭
+func (c *serverConn) closeWithReason(reason network.CloseReason) error {
+	c.reason.CompareAndSwap(int32(network.ReasonNone), int32(reason))
+
+	return c.forceClose(true)
+}
+
+// 作为慢消费者关闭连接
+// 调用方可能持有会话锁（如广播消息），故而异步执行关闭操作，避免在关闭回调中重入会话锁
+func (c *serverConn) slowClose() {
+	if !c.state.CompareAndSwap(int32(network.ConnOpened), int32(network.ConnClosed)) {
+		if !c.state.CompareAndSwap(int32(network.ConnHanged), int32(network.ConnClosed)) {
+			return
+		}
+	}
+
+	c.reason.Store(int32(network.ReasonSlowConsumer))
+	c.connMgr.server.queueMetrics.Disconnect()
+	c.uncheckAuthorize()
+
+	log.Warnf("connection write queue overflow, close slow consumer, cid: %d", c.id)
+
+	xcall.Go(func() { _ = c.doClose(true) })
+}
+
+// 写入队列，需持有读锁
+func (c *serverConn) enqueue(msg []byte, critical bool) error {
+	if c.conn == nil {
+		return errors.ErrConnectionClosed
+	}
+
+	opts := &c.connMgr.server.opts.writeQueue
+
+	for !c.quota.Acquire(opts, len(msg)) {
+		switch {
+		case opts.Policy == network.DropNew && !critical:
+			c.connMgr.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		case opts.Policy == network.DropNew || opts.Policy == network.DropOldest:
+			if c.dropOldest(opts) {
+				continue
+			}
+
+			// 队列中不存在可丢弃的非关键消息，关键消息无法入队时作为慢消费者处理
+			if critical {
+				return errors.ErrSlowConsumer
+			}
+
+			c.connMgr.server.queueMetrics.Drop()
+			return errors.ErrWriteQueueFull
+		default:
+			return errors.ErrSlowConsumer
+		}
+	}
+
+	c.chLowWrite <- chWrite{typ: dataPacket, msg: msg, critical: critical}
+
+	return nil
+}
+
+// 丢弃最早入队的非关键消息，需持有读锁
+// 依次取出队列中的消息，除被丢弃的消息外按原顺序重新入队，队列中不存在非关键消息时返回false
+func (c *serverConn) dropOldest(opts *network.QueueOptions) bool {
+	dropped := false
+
+	for n := len(c.chLowWrite); n > 0; n-- {
+		select {
+		case r := <-c.chLowWrite:
+			if !dropped && r.typ == dataPacket && !r.critical {
+				c.quota.Release(opts, len(r.msg))
+				c.connMgr.server.queueMetrics.Drop()
+				dropped = true
+				continue
+			}
+
+			c.chLowWrite <- r
+		default:
+			// 写入协程已取出消息但尚未释放配额，重试
+			return true
+		}
+	}
+
+	return dropped
+}
+
 // 执行关闭操作
 func (c *serverConn) doClose(isNeedRecycle bool) error {
 	c.rw.Lock()
@@ -330,15 +522,20 @@ func (c *serverConn) read() {
 						log.Warnf("read message failed: %d %v", c.id, err)
 					}
 				}
-				_ = c.forceClose(true)
+				_ = c.closeWithReason(network.ReasonClientClosed)
 				return
 			}
 
-			if msgType != websocket.BinaryMessage {
+			if msgType != c.connMgr.server.opts.messageType.frame() {
 				continue
 			}
 
-			if c.connMgr.server.opts.heartbeatInterval > 0 {
+			if msgData, err = c.connMgr.server.opts.messageType.decode(msgData); err != nil {
+				log.Errorf("decode message error: %v", err)
+				continue
+			}
+
+			if c.connMgr.server.heartbeatInterval.Load() > 0 {
 				c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
 			}
 
@@ -363,8 +560,16 @@ func (c *serverConn) read() {
 				continue
 			}
 
+			c.meter.Receive(len(msgData), isHeartbeat)
+
 			// ignore heartbeat packet
 			if isHeartbeat {
+				// 客户端回显的探测心跳仅用于测量往返时延，无需响应
+				if t, ok := packet.UnpackPing(msgData); ok {
+					c.meter.Pong(t)
+					continue
+				}
+
 				// responsive heartbeat
 				if c.connMgr.server.opts.heartbeatMechanism == RespHeartbeat {
 					c.rw.RLock()
@@ -386,16 +591,12 @@ func (c *serverConn) read() {
 // 由于gorilla/websocket库并发写入的限制，同时为了保证心跳能够优先下发到客户端，故而实现一个优先队列
 func (c *serverConn) write() {
 	var (
-		conn   = c.conn
-		ticker *time.Ticker
+		conn    = c.conn
+		setting = c.connMgr.server.heartbeatInterval
+		changed = setting.Changed()
+		ticker  = network.NewTicker(setting.Load())
 	)
-
-	if c.connMgr.server.opts.heartbeatInterval > 0 {
-		ticker = time.NewTicker(c.connMgr.server.opts.heartbeatInterval)
-		defer ticker.Stop()
-	} else {
-		ticker = &time.Ticker{C: make(chan time.Time, 1)}
-	}
+	defer ticker.Stop()
 
 	for {
 		select {
@@ -430,6 +631,10 @@ func (c *serverConn) write() {
 					return
 				}
 
+				if r.typ == dataPacket {
+					c.quota.Release(&c.connMgr.server.opts.writeQueue, len(r.msg))
+				}
+
 				if !c.doWrite(conn, r) {
 					return
 				}
@@ -441,6 +646,10 @@ func (c *serverConn) write() {
 				if !c.doHandleHeartbeat(conn, t) {
 					return
 				}
+			case <-changed:
+				changed = setting.Changed()
+				c.lastHeartbeatTime.Store(xtime.Now().UnixNano())
+				network.ResetTicker(ticker, setting.Load())
 			}
 		}
 	}
@@ -462,32 +671,54 @@ func (c *serverConn) doWrite(conn *websocket.Conn, r chWrite) bool {
 	}
 
 	if r.typ == heartbeatPacket {
-		if msg, err := packet.PackHeartbeat(); err != nil {
+		if msg, err := packet.PackPing(); err != nil {
 			log.Errorf("pack heartbeat message error: %v", err)
 			return true
 		} else {
 			r.msg = msg
 		}
+
+		if t, ok := packet.UnpackPing(r.msg); ok {
+			c.meter.Ping(t)
+		}
 	}
 
-	if err := conn.WriteMessage(websocket.BinaryMessage, r.msg); err != nil {
+	if err := c.writeMessage(conn, r.msg); err != nil {
 		if !errors.Is(err, net.ErrClosed) {
 			if _, ok := err.(*websocket.CloseError); !ok {
 				log.Errorf("write message error: %v", err)
 			}
 		}
+	} else {
+		c.meter.Send(len(r.msg), r.typ == heartbeatPacket)
 	}
 
 	return true
 }
 
+// 写入消息，文本帧以base64编码数据包，启用压缩时仅压缩达到阈值的消息
+func (c *serverConn) writeMessage(conn *websocket.Conn, msg []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	opts := c.connMgr.server.opts
+
+	data := opts.messageType.encode(msg)
+
+	if opts.compression.Enable {
+		conn.EnableWriteCompression(len(data) >= opts.compression.Threshold)
+	}
+
+	return conn.WriteMessage(opts.messageType.frame(), data)
+}
+
 // 处理心跳
 func (c *serverConn) doHandleHeartbeat(conn *websocket.Conn, t time.Time) bool {
-	deadline := t.Add(-2 * c.connMgr.server.opts.heartbeatInterval).UnixNano()
+	deadline := t.Add(-2 * c.connMgr.server.heartbeatInterval.Load()).UnixNano()
 
 	if c.lastHeartbeatTime.Load() < deadline {
 		log.Debugf("connection heartbeat timeout, cid: %d", c.id)
-		_ = c.forceClose(true)
+		_ = c.closeWithReason(network.ReasonHeartbeatTimeout)
 		return false
 	} else {
 		if c.connMgr.server.opts.heartbeatMechanism == TickHeartbeat {
@@ -495,12 +726,18 @@ func (c *serverConn) doHandleHeartbeat(conn *websocket.Conn, t time.Time) bool {
 				return false
 			}
 
-			if heartbeat, err := packet.PackHeartbeat(); err != nil {
+			if heartbeat, err := packet.PackPing(); err != nil {
 				log.Errorf("pack heartbeat message error: %v", err)
 			} else {
+				if t, ok := packet.UnpackPing(heartbeat); ok {
+					c.meter.Ping(t)
+				}
+
 				// send heartbeat packet
-				if err := conn.WriteMessage(websocket.BinaryMessage, heartbeat); err != nil {
+				if err := c.writeMessage(conn, heartbeat); err != nil {
 					log.Errorf("write heartbeat message error: %v", err)
+				} else {
+					c.meter.Send(len(heartbeat), true)
 				}
 			}
 		}
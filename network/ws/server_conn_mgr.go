@@ -8,6 +8,8 @@
 package ws
 
 import (
+	"net"
+	"net/http"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -56,15 +58,16 @@ func (cm *serverConnMgr) close() {
 	wg.Wait()
 }
 
-// 分配连接
-func (cm *serverConnMgr) allocate(c *websocket.Conn) error {
-	if cm.total.Load() >= int64(cm.server.opts.maxConnNum) {
+// 分配连接，r为握手请求，addr为准入地址
+func (cm *serverConnMgr) allocate(c *websocket.Conn, r *http.Request, addr net.Addr) error {
+	if cm.total.Load() >= int64(cm.server.maxConnNum.Load()) {
 		return errors.ErrTooManyConnection
 	}
 
 	id := cm.id.Add(1)
 	conn := cm.pool.Get().(*serverConn)
-	conn.init(cm, id, c)
+	conn.addr = addr
+	conn.init(cm, id, c, r)
 	index := int(reflect.ValueOf(c).Pointer()) % len(cm.partitions)
 	cm.partitions[index].store(c, conn)
 	cm.total.Add(1)
@@ -76,6 +79,7 @@ func (cm *serverConnMgr) allocate(c *websocket.Conn) error {
 func (cm *serverConnMgr) recycle(c *websocket.Conn) {
 	index := int(reflect.ValueOf(c).Pointer()) % len(cm.partitions)
 	if conn, ok := cm.partitions[index].delete(c); ok {
+		cm.server.guard.Release(conn.addr)
 		conn.reset()
 		cm.pool.Put(conn)
 		cm.total.Add(-1)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/network"
 )
 
 const (
@@ -16,6 +17,10 @@ const (
 	defaultServerHeartbeatInterval  = "10s"
 	defaultServerHeartbeatMechanism = "resp"
 	defaultServerAuthorizeTimeout   = "0s"
+	defaultServerMessageType        = "binary"
+	defaultServerCompressLevel      = 1
+	defaultServerCompressThreshold  = "512B"
+	defaultServerQueuePolicy        = "disconnect"
 )
 
 const (
@@ -29,6 +34,22 @@ const (
 	defaultServerHeartbeatIntervalKey  = "etc.network.ws.server.heartbeatInterval"
 	defaultServerHeartbeatMechanismKey = "etc.network.ws.server.heartbeatMechanism"
 	defaultServerAuthorizeTimeoutKey   = "etc.network.ws.server.authorizeTimeout"
+	defaultServerProxyProtocolKey      = "etc.network.ws.server.proxyProtocol"
+	defaultServerForwardedHeadersKey   = "etc.network.ws.server.forwardedHeaders"
+	defaultServerTrustedProxiesKey     = "etc.network.ws.server.trustedProxies"
+	defaultServerMessageTypeKey        = "etc.network.ws.server.messageType"
+	defaultServerSubprotocolsKey       = "etc.network.ws.server.subprotocols"
+	defaultServerCompressEnableKey     = "etc.network.ws.server.compression.enable"
+	defaultServerCompressLevelKey      = "etc.network.ws.server.compression.level"
+	defaultServerCompressThresholdKey  = "etc.network.ws.server.compression.threshold"
+	defaultServerQueueMaxMessagesKey   = "etc.network.ws.server.writeQueue.maxMessages"
+	defaultServerQueueMaxBytesKey      = "etc.network.ws.server.writeQueue.maxBytes"
+	defaultServerQueuePolicyKey        = "etc.network.ws.server.writeQueue.policy"
+	defaultServerMaxConnPerIPKey       = "etc.network.ws.server.guard.maxConnPerIP"
+	defaultServerAcceptRateKey         = "etc.network.ws.server.guard.acceptRate"
+	defaultServerAcceptBurstKey        = "etc.network.ws.server.guard.acceptBurst"
+	defaultServerAllowListKey          = "etc.network.ws.server.guard.allowList"
+	defaultServerDenyListKey           = "etc.network.ws.server.guard.denyList"
 )
 
 const (
@@ -43,16 +64,32 @@ type ServerOption func(o *serverOptions)
 type CheckOriginFunc func(r *http.Request) bool
 
 type serverOptions struct {
-	addr               string             // 监听地址
-	maxConnNum         int                // 最大连接数
-	certFile           string             // 证书文件
-	keyFile            string             // 秘钥文件
-	path               string             // 路径，默认为"/"
-	checkOrigin        CheckOriginFunc    // 跨域检测
-	handshakeTimeout   time.Duration      // 握手超时时间，默认10s
-	heartbeatInterval  time.Duration      // 心跳间隔时间，默认10s
-	heartbeatMechanism HeartbeatMechanism // 心跳机制，默认resp
-	authorizeTimeout   time.Duration      // 授权超时时间，默认0s，不检测
+	addr               string               // 监听地址
+	maxConnNum         int                  // 最大连接数
+	certFile           string               // 证书文件
+	keyFile            string               // 秘钥文件
+	path               string               // 路径，默认为"/"
+	checkOrigin        CheckOriginFunc      // 跨域检测
+	handshakeTimeout   time.Duration        // 握手超时时间，默认10s
+	heartbeatInterval  time.Duration        // 心跳间隔时间，默认10s
+	heartbeatMechanism HeartbeatMechanism   // 心跳机制，默认resp
+	authorizeTimeout   time.Duration        // 授权超时时间，默认0s，不检测
+	proxyProtocol      bool                 // 是否解析PROXY protocol代理头，默认false
+	forwardedHeaders   bool                 // 是否解析X-Forwarded-For、X-Real-IP请求头，默认false
+	trustedProxies     []string             // 可信代理网段，仅解析来自可信代理的代理头及请求头，默认为空，不信任任何来源
+	messageType        MessageType          // 消息帧类型，默认binary
+	subprotocols       []string             // 支持的子协议，按优先级排列
+	compression        Compression          // 压缩配置
+	writeQueue         network.QueueOptions // 连接写入队列配置，仅作用于异步推送的消息
+	guard              network.GuardOptions // 连接准入配置
+	fixed              map[string]struct{}  // 显式设置的配置项，不监听etc中对应配置的变化
+}
+
+// Compression permessage-deflate压缩配置
+type Compression struct {
+	Enable    bool // 是否启用压缩，需客户端同时支持
+	Level     int  // 压缩等级，范围为-2~9，默认为1
+	Threshold int  // 压缩阈值，消息长度达到阈值时才进行压缩，默认为512字节
 }
 
 func defaultServerOptions() *serverOptions {
@@ -83,6 +120,28 @@ func defaultServerOptions() *serverOptions {
 		heartbeatInterval:  etc.Get(defaultServerHeartbeatIntervalKey, defaultServerHeartbeatInterval).Duration(),
 		heartbeatMechanism: HeartbeatMechanism(etc.Get(defaultServerHeartbeatMechanismKey, defaultServerHeartbeatMechanism).String()),
 		authorizeTimeout:   etc.Get(defaultServerAuthorizeTimeoutKey, defaultServerAuthorizeTimeout).Duration(),
+		proxyProtocol:      etc.Get(defaultServerProxyProtocolKey).Bool(),
+		forwardedHeaders:   etc.Get(defaultServerForwardedHeadersKey).Bool(),
+		trustedProxies:     etc.Get(defaultServerTrustedProxiesKey).Strings(),
+		messageType:        MessageType(etc.Get(defaultServerMessageTypeKey, defaultServerMessageType).String()),
+		subprotocols:       etc.Get(defaultServerSubprotocolsKey).Strings(),
+		compression: Compression{
+			Enable:    etc.Get(defaultServerCompressEnableKey).Bool(),
+			Level:     etc.Get(defaultServerCompressLevelKey, defaultServerCompressLevel).Int(),
+			Threshold: int(etc.Get(defaultServerCompressThresholdKey, defaultServerCompressThreshold).B()),
+		},
+		writeQueue: network.QueueOptions{
+			MaxMessages: etc.Get(defaultServerQueueMaxMessagesKey).Int(),
+			MaxBytes:    int(etc.Get(defaultServerQueueMaxBytesKey).B()),
+			Policy:      network.OverflowPolicy(etc.Get(defaultServerQueuePolicyKey, defaultServerQueuePolicy).String()),
+		},
+		guard: network.GuardOptions{
+			MaxConnPerIP: etc.Get(defaultServerMaxConnPerIPKey).Int(),
+			AcceptRate:   etc.Get(defaultServerAcceptRateKey).Float64(),
+			AcceptBurst:  etc.Get(defaultServerAcceptBurstKey).Int(),
+			AllowList:    etc.Get(defaultServerAllowListKey).Strings(),
+			DenyList:     etc.Get(defaultServerDenyListKey).Strings(),
+		},
 	}
 }
 
@@ -93,7 +152,10 @@ func WithServerListenAddr(addr string) ServerOption {
 
 // WithServerMaxConnNum 设置连接的最大连接数
 func WithServerMaxConnNum(maxConnNum int) ServerOption {
-	return func(o *serverOptions) { o.maxConnNum = maxConnNum }
+	return func(o *serverOptions) {
+		o.maxConnNum = maxConnNum
+		o.fix(defaultServerMaxConnNumKey)
+	}
 }
 
 // WithServerPath 设置Websocket的连接路径
@@ -118,7 +180,10 @@ func WithServerHandshakeTimeout(handshakeTimeout time.Duration) ServerOption {
 
 // WithServerHeartbeatInterval 设置心跳检测间隔时间
 func WithServerHeartbeatInterval(heartbeatInterval time.Duration) ServerOption {
-	return func(o *serverOptions) { o.heartbeatInterval = heartbeatInterval }
+	return func(o *serverOptions) {
+		o.heartbeatInterval = heartbeatInterval
+		o.fix(defaultServerHeartbeatIntervalKey)
+	}
 }
 
 // WithServerHeartbeatMechanism 设置心跳机制
@@ -128,5 +193,81 @@ func WithServerHeartbeatMechanism(heartbeatMechanism HeartbeatMechanism) ServerO
 
 // WithServerAuthorizeTimeout 设置授权超时时间
 func WithServerAuthorizeTimeout(authorizeTimeout time.Duration) ServerOption {
-	return func(o *serverOptions) { o.authorizeTimeout = authorizeTimeout }
+	return func(o *serverOptions) {
+		o.authorizeTimeout = authorizeTimeout
+		o.fix(defaultServerAuthorizeTimeoutKey)
+	}
+}
+
+// WithServerProxyProtocol 设置是否解析PROXY protocol代理头
+func WithServerProxyProtocol(proxyProtocol bool) ServerOption {
+	return func(o *serverOptions) { o.proxyProtocol = proxyProtocol }
+}
+
+// WithServerForwardedHeaders 设置是否解析X-Forwarded-For、X-Real-IP请求头
+func WithServerForwardedHeaders(forwardedHeaders bool) ServerOption {
+	return func(o *serverOptions) { o.forwardedHeaders = forwardedHeaders }
+}
+
+// WithServerTrustedProxies 设置可信代理网段，支持CIDR及单个IP地址
+func WithServerTrustedProxies(trustedProxies ...string) ServerOption {
+	return func(o *serverOptions) { o.trustedProxies = trustedProxies }
+}
+
+// WithServerMessageType 设置消息帧类型
+func WithServerMessageType(messageType MessageType) ServerOption {
+	return func(o *serverOptions) { o.messageType = messageType }
+}
+
+// WithServerSubprotocols 设置支持的子协议，按优先级排列
+func WithServerSubprotocols(subprotocols ...string) ServerOption {
+	return func(o *serverOptions) { o.subprotocols = subprotocols }
+}
+
+// WithServerCompression 设置permessage-deflate压缩配置
+func WithServerCompression(compression Compression) ServerOption {
+	return func(o *serverOptions) { o.compression = compression }
+}
+
+// WithServerWriteQueue 设置连接写入队列配置
+func WithServerWriteQueue(writeQueue network.QueueOptions) ServerOption {
+	return func(o *serverOptions) { o.writeQueue = writeQueue }
+}
+
+// WithServerMaxConnPerIP 设置单IP最大并发连接数
+func WithServerMaxConnPerIP(maxConnPerIP int) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.MaxConnPerIP = maxConnPerIP
+		o.fix(defaultServerMaxConnPerIPKey)
+	}
+}
+
+// WithServerAcceptRate 设置单IP每秒允许建立的连接数及突发连接数
+func WithServerAcceptRate(rate float64, burst int) ServerOption {
+	return func(o *serverOptions) { o.guard.AcceptRate, o.guard.AcceptBurst = rate, burst }
+}
+
+// WithServerAllowList 设置白名单，支持CIDR及单个IP地址
+func WithServerAllowList(allowList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.AllowList = allowList
+		o.fix(defaultServerAllowListKey)
+	}
+}
+
+// WithServerDenyList 设置黑名单，支持CIDR及单个IP地址
+func WithServerDenyList(denyList ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.guard.DenyList = denyList
+		o.fix(defaultServerDenyListKey)
+	}
+}
+
+// 标记显式设置的配置项
+func (o *serverOptions) fix(key string) {
+	if o.fixed == nil {
+		o.fixed = make(map[string]struct{})
+	}
+
+	o.fixed[key] = struct{}{}
 }
@@ -0,0 +1,189 @@
+package ws_test
+
+import (
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/devagame/due/network/ws/v2"
+	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/network"
+	"github.com/devagame/due/v2/packet"
+	"github.com/gorilla/websocket"
+)
+
+func TestServer_TextMessage(t *testing.T) {
+	packer := packet.GetPacker()
+	packet.SetPacker(packet.NewPacker(packet.WithHeartbeatTime(true)))
+	defer packet.SetPacker(packer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	server := ws.NewServer(
+		ws.WithServerListenAddr(addr),
+		ws.WithServerPath("/text"),
+		ws.WithServerMessageType(ws.TextMessage),
+		ws.WithServerHeartbeatInterval(20*time.Millisecond),
+		ws.WithServerHeartbeatMechanism(ws.TickHeartbeat),
+	)
+
+	server.OnReceive(func(conn network.Conn, buf buffer.Buffer) {
+		defer buf.Release()
+
+		if err := conn.Push(buf.Bytes()); err != nil {
+			t.Error(err)
+		}
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_ = network.CloseWithCode(conn, network.CloseKicked, "login elsewhere")
+		}()
+	})
+
+	if err = server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/text", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	msg, err := packet.PackMessage(&packet.Message{Route: 1, Buffer: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.WriteMessage(websocket.TextMessage, []byte(base64.StdEncoding.EncodeToString(msg))); err != nil {
+		t.Fatal(err)
+	}
+
+	var echoed, heartbeats int
+
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	for {
+		typ, data, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("read message error before close packet: %v", err)
+		}
+
+		if typ != websocket.TextMessage || !utf8.Valid(data) {
+			t.Fatalf("frame = %d %q, want valid utf-8 text", typ, data)
+		}
+
+		packed, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code, message, ok := packet.UnpackClose(packed); ok {
+			if code != uint16(network.CloseKicked) || message != "login elsewhere" {
+				t.Fatalf("code = %d, message = %q", code, message)
+			}
+			break
+		}
+
+		if isHeartbeat, err := packet.CheckHeartbeat(packed); err != nil {
+			t.Fatal(err)
+		} else if isHeartbeat {
+			heartbeats++
+
+			// 回显心跳以维持连接
+			if err = client.WriteMessage(websocket.TextMessage, data); err != nil {
+				t.Fatal(err)
+			}
+		} else if string(packed) == string(msg) {
+			echoed++
+		}
+	}
+
+	if echoed != 1 || heartbeats == 0 {
+		t.Fatalf("echoed = %d, heartbeats = %d", echoed, heartbeats)
+	}
+}
+
+func TestClient_TextMessage(t *testing.T) {
+	frames := make(chan []byte, 64)
+
+	upgrader := websocket.Upgrader{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				close(frames)
+				return
+			}
+
+			if typ != websocket.TextMessage || !utf8.Valid(data) {
+				t.Errorf("frame = %d %q, want valid utf-8 text", typ, data)
+			}
+
+			frames <- data
+		}
+	}))
+	defer ts.Close()
+
+	client := ws.NewClient(
+		ws.WithClientUrl("ws"+strings.TrimPrefix(ts.URL, "http")),
+		ws.WithClientMessageType(ws.TextMessage),
+		ws.WithClientHeartbeatInterval(20*time.Millisecond),
+	)
+
+	conn, err := client.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := packet.PackMessage(&packet.Message{Route: 1, Buffer: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn.Push(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	var received, heartbeats int
+
+	for received == 0 || heartbeats == 0 {
+		select {
+		case data := <-frames:
+			packed, err := base64.StdEncoding.DecodeString(string(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if isHeartbeat, err := packet.CheckHeartbeat(packed); err != nil {
+				t.Fatal(err)
+			} else if isHeartbeat {
+				heartbeats++
+			} else if string(packed) == string(msg) {
+				received++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received = %d, heartbeats = %d", received, heartbeats)
+		}
+	}
+
+	_ = conn.Close(true)
+}
@@ -11,6 +11,11 @@ import (
 // | size(4 byte) = (1 byte + 8 byte) | header(1 byte) | heartbeat time(8 byte) |
 // ------------------------------------------------------------------------------
 
+// close packet
+// --------------------------------------------------------------------------------------------
+// | size(4 byte) = (1 byte + 2 byte + x byte) | header(1 byte) | code(2 byte) | message(x byte) |
+// --------------------------------------------------------------------------------------------
+
 // data packet
 // -----------------------------------------------------------------------------------------------------------------------
 // | size(4 byte) = (1 byte + n byte + m byte + x byte) | header(1 byte) | route(n byte) | seq(m byte) | message(x byte) |
@@ -29,6 +34,7 @@ const (
 	defaultBufferBytes        = 5000
 	defaultHeartbeatTime      = false
 	defaultHeartbeatTimeBytes = 8
+	defaultCloseCodeBytes     = 2
 )
 
 const (
@@ -56,6 +62,10 @@ type options struct {
 	// 默认为5000字节
 	bufferBytes int
 
+	// 是否监听etc中消息字节数的变化
+	// 显式设置消息字节数时不监听
+	watchBufferBytes bool
+
 	// 是否携带心跳时间
 	// 默认为false
 	heartbeatTime bool
@@ -65,11 +75,12 @@ type Option func(o *options)
 
 func defaultOptions() *options {
 	opts := &options{
-		byteOrder:     binary.BigEndian,
-		routeBytes:    etc.Get(defaultRouteBytesKey, defaultRouteBytes).Int(),
-		seqBytes:      etc.Get(defaultSeqBytesKey, defaultSeqBytes).Int(),
-		bufferBytes:   etc.Get(defaultBufferBytesKey, defaultBufferBytes).Int(),
-		heartbeatTime: etc.Get(defaultHeartbeatTimeKey, defaultHeartbeatTime).Bool(),
+		byteOrder:        binary.BigEndian,
+		routeBytes:       etc.Get(defaultRouteBytesKey, defaultRouteBytes).Int(),
+		seqBytes:         etc.Get(defaultSeqBytesKey, defaultSeqBytes).Int(),
+		bufferBytes:      etc.Get(defaultBufferBytesKey, defaultBufferBytes).Int(),
+		watchBufferBytes: true,
+		heartbeatTime:    etc.Get(defaultHeartbeatTimeKey, defaultHeartbeatTime).Bool(),
 	}
 
 	endian := etc.Get(defaultEndianKey, bigEndian).String()
@@ -100,7 +111,7 @@ func WithSeqBytes(seqBytes int) Option {
 
 // WithBufferBytes 设置消息字节数
 func WithBufferBytes(bufferBytes int) Option {
-	return func(o *options) { o.bufferBytes = bufferBytes }
+	return func(o *options) { o.bufferBytes, o.watchBufferBytes = bufferBytes, false }
 }
 
 // WithHeartbeatTime 是否携带心跳时间
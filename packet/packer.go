@@ -4,16 +4,25 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/core/value"
 	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/log"
 )
 
 const (
 	dataBit      = 0 << 7 // 数据标识
 	heartbeatBit = 1 << 7 // 心跳标识
+	extcodeMask  = 1<<7 - 1
+)
+
+const (
+	closeExtcode = 1 // 关闭扩展操作码
+	pingExtcode  = 2 // 探测扩展操作码
 )
 
 type NocopyReader interface {
@@ -48,8 +57,10 @@ type Packer interface {
 }
 
 type defaultPacker struct {
-	opts      *options
-	heartbeat []byte
+	opts        *options
+	heartbeat   []byte
+	bufferBytes atomic.Int64 // 消息字节数，可在运行时通过etc配置调整
+	cancel      func()       // 取消监听etc配置变化
 }
 
 func NewPacker(opts ...Option) *defaultPacker {
@@ -70,10 +81,31 @@ func NewPacker(opts ...Option) *defaultPacker {
 		log.Fatalf("the number of buffer bytes must be greater than or equal to 0, and give %d", o.bufferBytes)
 	}
 
-	return &defaultPacker{
+	p := &defaultPacker{
 		opts:      o,
 		heartbeat: makeHeartbeat(o.byteOrder),
 	}
+	p.bufferBytes.Store(int64(o.bufferBytes))
+
+	// 消息字节数仅用于限制消息大小，来自etc配置时可在运行时调整；其他配置影响协议格式，需重启后生效
+	if o.watchBufferBytes {
+		p.cancel = etc.OnChange(defaultBufferBytesKey, func(val value.Value) {
+			if bufferBytes := val.Int64(); bufferBytes >= 0 {
+				p.bufferBytes.Store(bufferBytes)
+			}
+		}, defaultBufferBytes)
+	}
+	etc.RestartOnly(defaultEndianKey, defaultRouteBytesKey, defaultSeqBytesKey, defaultHeartbeatTimeKey)
+
+	return p
+}
+
+// Close 取消监听etc配置变化，打包器不再使用时调用
+func (p *defaultPacker) Close() {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
 }
 
 // ReadBuffer 以buffer的形式读取消息
@@ -115,7 +147,7 @@ func (p *defaultPacker) PackBuffer(message *Message) (*buffer.NocopyBuffer, erro
 		}
 	}
 
-	if len(message.Buffer) > p.opts.bufferBytes {
+	if int64(len(message.Buffer)) > p.bufferBytes.Load() {
 		return nil, errors.ErrMessageTooLarge
 	}
 
@@ -219,7 +251,7 @@ func (p *defaultPacker) PackMessage(message *Message) ([]byte, error) {
 		}
 	}
 
-	if len(message.Buffer) > p.opts.bufferBytes {
+	if int64(len(message.Buffer)) > p.bufferBytes.Load() {
 		return nil, errors.ErrMessageTooLarge
 	}
 
@@ -361,29 +393,44 @@ func (p *defaultPacker) UnpackMessage(data []byte) (*Message, error) {
 // PackHeartbeat 打包心跳
 func (p *defaultPacker) PackHeartbeat() ([]byte, error) {
 	if p.opts.heartbeatTime {
-		var (
-			buf  = &bytes.Buffer{}
-			size = defaultHeaderBytes + defaultHeartbeatTimeBytes
-		)
+		return p.packHeartbeatTime(heartbeatBit)
+	} else {
+		return p.heartbeat, nil
+	}
+}
 
-		buf.Grow(defaultSizeBytes + size)
+// PackPing 打包探测心跳，对端收到后原样回显，用于测量往返时延
+// 未开启心跳时间时无法测量往返时延，返回普通心跳包
+func (p *defaultPacker) PackPing() ([]byte, error) {
+	if p.opts.heartbeatTime {
+		return p.packHeartbeatTime(heartbeatBit | pingExtcode)
+	} else {
+		return p.heartbeat, nil
+	}
+}
 
-		if err := binary.Write(buf, p.opts.byteOrder, uint32(size)); err != nil {
-			return nil, err
-		}
+// 打包携带心跳时间的心跳包
+func (p *defaultPacker) packHeartbeatTime(header uint8) ([]byte, error) {
+	var (
+		buf  = &bytes.Buffer{}
+		size = defaultHeaderBytes + defaultHeartbeatTimeBytes
+	)
 
-		if err := binary.Write(buf, p.opts.byteOrder, uint8(heartbeatBit)); err != nil {
-			return nil, err
-		}
+	buf.Grow(defaultSizeBytes + size)
 
-		if err := binary.Write(buf, p.opts.byteOrder, time.Now().UnixNano()); err != nil {
-			return nil, err
-		}
+	if err := binary.Write(buf, p.opts.byteOrder, uint32(size)); err != nil {
+		return nil, err
+	}
 
-		return buf.Bytes(), nil
-	} else {
-		return p.heartbeat, nil
+	if err := binary.Write(buf, p.opts.byteOrder, header); err != nil {
+		return nil, err
 	}
+
+	if err := binary.Write(buf, p.opts.byteOrder, time.Now().UnixNano()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
 }
 
 // CheckHeartbeat 检测心跳包
@@ -413,6 +460,96 @@ func (p *defaultPacker) CheckHeartbeat(data []byte) (bool, error) {
 	return header&heartbeatBit == heartbeatBit, nil
 }
 
+// UnpackHeartbeatTime 解析心跳包携带的心跳时间，未携带心跳时间时返回false
+func (p *defaultPacker) UnpackHeartbeatTime(data []byte) (int64, bool) {
+	if len(data) != defaultSizeBytes+defaultHeaderBytes+defaultHeartbeatTimeBytes {
+		return 0, false
+	}
+
+	if header := data[defaultSizeBytes]; header != heartbeatBit && header != heartbeatBit|pingExtcode {
+		return 0, false
+	}
+
+	return int64(p.opts.byteOrder.Uint64(data[defaultSizeBytes+defaultHeaderBytes:])), true
+}
+
+// UnpackPing 解析探测心跳携带的心跳时间，非探测心跳时返回false
+func (p *defaultPacker) UnpackPing(data []byte) (int64, bool) {
+	if len(data) != defaultSizeBytes+defaultHeaderBytes+defaultHeartbeatTimeBytes {
+		return 0, false
+	}
+
+	if data[defaultSizeBytes] != heartbeatBit|pingExtcode {
+		return 0, false
+	}
+
+	return int64(p.opts.byteOrder.Uint64(data[defaultSizeBytes+defaultHeaderBytes:])), true
+}
+
+// PackClose 打包关闭包
+func (p *defaultPacker) PackClose(code uint16, message string) ([]byte, error) {
+	var (
+		buf  = &bytes.Buffer{}
+		size = defaultHeaderBytes + defaultCloseCodeBytes + len(message)
+	)
+
+	if int64(len(message)) > p.bufferBytes.Load() {
+		return nil, errors.ErrMessageTooLarge
+	}
+
+	buf.Grow(defaultSizeBytes + size)
+
+	if err := binary.Write(buf, p.opts.byteOrder, uint32(size)); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, p.opts.byteOrder, uint8(heartbeatBit|closeExtcode)); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, p.opts.byteOrder, code); err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(message)
+
+	return buf.Bytes(), nil
+}
+
+// UnpackClose 解析关闭包携带的原因码及说明信息，非关闭包时返回false
+func (p *defaultPacker) UnpackClose(data []byte) (uint16, string, bool) {
+	if len(data) < defaultSizeBytes+defaultHeaderBytes+defaultCloseCodeBytes {
+		return 0, "", false
+	}
+
+	if data[defaultSizeBytes] != heartbeatBit|closeExtcode {
+		return 0, "", false
+	}
+
+	if uint64(len(data))-defaultSizeBytes != uint64(p.opts.byteOrder.Uint32(data)) {
+		return 0, "", false
+	}
+
+	code := p.opts.byteOrder.Uint16(data[defaultSizeBytes+defaultHeaderBytes:])
+
+	return code, string(data[defaultSizeBytes+defaultHeaderBytes+defaultCloseCodeBytes:]), true
+}
+
+// SplitFrame 获取字节流中首个完整数据包的长度，数据不完整时返回0
+func (p *defaultPacker) SplitFrame(data []byte) (int, error) {
+	if len(data) < defaultSizeBytes {
+		return 0, nil
+	}
+
+	n := defaultSizeBytes + int(p.opts.byteOrder.Uint32(data))
+
+	if len(data) < n {
+		return 0, nil
+	}
+
+	return n, nil
+}
+
 // 构建心跳包
 func makeHeartbeat(byteOrder binary.ByteOrder) []byte {
 	buf := bytes.NewBuffer(nil)
@@ -1,13 +1,44 @@
 package packet
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/devagame/due/v2/core/buffer"
+	"github.com/devagame/due/v2/errors"
 )
 
 var globalPacker Packer
 
+// HeartbeatTimeUnpacker 可解析心跳时间的打包器
+type HeartbeatTimeUnpacker interface {
+	// UnpackHeartbeatTime 解析心跳包携带的心跳时间，未携带心跳时间时返回false
+	UnpackHeartbeatTime(data []byte) (int64, bool)
+}
+
+// PingPacker 可打包及解析探测心跳的打包器
+// 探测心跳由服务端下发，客户端仅回显探测心跳，避免回显普通心跳时与响应式心跳的服务端无限往复
+type PingPacker interface {
+	// PackPing 打包探测心跳，对端收到后原样回显，用于测量往返时延
+	PackPing() ([]byte, error)
+	// UnpackPing 解析探测心跳携带的心跳时间，非探测心跳时返回false
+	UnpackPing(data []byte) (int64, bool)
+}
+
+// FrameSplitter 可从字节流中切分数据包的打包器
+type FrameSplitter interface {
+	// SplitFrame 获取字节流中首个完整数据包的长度，数据不完整时返回0
+	SplitFrame(data []byte) (int, error)
+}
+
+// ClosePacker 可打包及解析关闭包的打包器
+type ClosePacker interface {
+	// PackClose 打包关闭包
+	PackClose(code uint16, message string) ([]byte, error)
+	// UnpackClose 解析关闭包携带的原因码及说明信息，非关闭包时返回false
+	UnpackClose(data []byte) (uint16, string, bool)
+}
+
 func init() {
 	globalPacker = NewPacker()
 }
@@ -56,3 +87,68 @@ func PackHeartbeat() ([]byte, error) {
 func CheckHeartbeat(data []byte) (bool, error) {
 	return globalPacker.CheckHeartbeat(data)
 }
+
+// UnpackHeartbeatTime 解析心跳包携带的心跳时间，打包器不支持或未携带心跳时间时返回false
+func UnpackHeartbeatTime(data []byte) (int64, bool) {
+	if p, ok := globalPacker.(HeartbeatTimeUnpacker); ok {
+		return p.UnpackHeartbeatTime(data)
+	}
+
+	return 0, false
+}
+
+// PackPing 打包探测心跳，打包器不支持时返回普通心跳包
+func PackPing() ([]byte, error) {
+	if p, ok := globalPacker.(PingPacker); ok {
+		return p.PackPing()
+	}
+
+	return globalPacker.PackHeartbeat()
+}
+
+// UnpackPing 解析探测心跳携带的心跳时间，打包器不支持或非探测心跳时返回false
+func UnpackPing(data []byte) (int64, bool) {
+	if p, ok := globalPacker.(PingPacker); ok {
+		return p.UnpackPing(data)
+	}
+
+	return 0, false
+}
+
+// PackClose 打包关闭包，打包器不支持时返回ErrIllegalOperation
+func PackClose(code uint16, message string) ([]byte, error) {
+	if p, ok := globalPacker.(ClosePacker); ok {
+		return p.PackClose(code, message)
+	}
+
+	return nil, errors.ErrIllegalOperation
+}
+
+// UnpackClose 解析关闭包携带的原因码及说明信息，打包器不支持或非关闭包时返回false
+func UnpackClose(data []byte) (uint16, string, bool) {
+	if p, ok := globalPacker.(ClosePacker); ok {
+		return p.UnpackClose(data)
+	}
+
+	return 0, "", false
+}
+
+// SplitFrame 获取字节流中首个完整数据包的长度，数据不完整时返回0
+// 打包器未实现FrameSplitter时，尝试以ReadBuffer读取数据包以确定其长度
+func SplitFrame(data []byte) (int, error) {
+	if p, ok := globalPacker.(FrameSplitter); ok {
+		return p.SplitFrame(data)
+	}
+
+	reader := bytes.NewReader(data)
+
+	if _, err := globalPacker.ReadBuffer(reader); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return len(data) - reader.Len(), nil
+}
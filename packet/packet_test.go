@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/packet"
 	"github.com/devagame/due/v2/utils/xrand"
 )
@@ -96,6 +98,98 @@ func TestDefaultPacker_PackHeartbeat(t *testing.T) {
 	t.Log(isHeartbeat)
 }
 
+func TestDefaultPacker_SplitFrame(t *testing.T) {
+	data, err := packer.PackMessage(&packet.Message{
+		Seq:    1,
+		Route:  1,
+		Buffer: []byte("hello world"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := append(append([]byte{}, data...), data[:5]...)
+
+	n, err := packer.SplitFrame(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != len(data) {
+		t.Fatalf("n = %d, want %d", n, len(data))
+	}
+
+	if n, err = packer.SplitFrame(stream[n:]); err != nil || n != 0 {
+		t.Fatalf("n = %d, err = %v, want 0", n, err)
+	}
+}
+
+func TestDefaultPacker_PackClose(t *testing.T) {
+	data, err := packer.PackClose(2, "kicked")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isHeartbeat, err := packer.CheckHeartbeat(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isHeartbeat {
+		t.Fatal("close packet should be compatible with heartbeat packet")
+	}
+
+	if _, ok := packer.UnpackHeartbeatTime(data); ok {
+		t.Fatal("close packet should not carry heartbeat time")
+	}
+
+	code, message, ok := packer.UnpackClose(data)
+	if !ok || code != 2 || message != "kicked" {
+		t.Fatalf("code = %d, message = %q, ok = %v", code, message, ok)
+	}
+
+	heartbeat, err := packer.PackHeartbeat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok = packer.UnpackClose(heartbeat); ok {
+		t.Fatal("heartbeat packet should not be a close packet")
+	}
+}
+
+func TestDefaultPacker_PackPing(t *testing.T) {
+	data, err := packer.PackPing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if isHeartbeat, err := packer.CheckHeartbeat(data); err != nil || !isHeartbeat {
+		t.Fatal("ping packet should be compatible with heartbeat packet")
+	}
+
+	if _, ok := packer.UnpackPing(data); !ok {
+		t.Fatal("ping packet should carry heartbeat time")
+	}
+
+	if _, ok := packer.UnpackHeartbeatTime(data); !ok {
+		t.Fatal("heartbeat time of ping packet should be unpacked")
+	}
+
+	if _, _, ok := packer.UnpackClose(data); ok {
+		t.Fatal("ping packet should not be a close packet")
+	}
+
+	heartbeat, err := packer.PackHeartbeat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := packer.UnpackPing(heartbeat); ok {
+		t.Fatal("heartbeat packet should not be a ping packet")
+	}
+}
+
 func BenchmarkDefaultPacker_ReadBuffer(b *testing.B) {
 	data, err := packer.PackMessage(&packet.Message{
 		Seq:    1,
@@ -203,3 +297,39 @@ func BenchmarkDefaultPacker_UnpackMessage(b *testing.B) {
 		}
 	}
 }
+
+func TestNewPacker_BufferBytes(t *testing.T) {
+	message := &packet.Message{Route: 1, Buffer: []byte(xrand.Letters(100))}
+
+	explicit := packet.NewPacker(packet.WithBufferBytes(10))
+	defer explicit.Close()
+
+	watched := packet.NewPacker()
+
+	if err := etc.Set("etc.packet.bufferBytes", 50); err != nil {
+		t.Fatal(err)
+	}
+	defer etc.Set("etc.packet.bufferBytes", 5000)
+
+	if _, err := watched.PackMessage(message); !errors.Is(err, errors.ErrMessageTooLarge) {
+		t.Fatalf("err = %v, want %v", err, errors.ErrMessageTooLarge)
+	}
+
+	if err := etc.Set("etc.packet.bufferBytes", 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := explicit.PackMessage(message); !errors.Is(err, errors.ErrMessageTooLarge) {
+		t.Fatalf("explicit buffer bytes is overridden by etc, err = %v", err)
+	}
+
+	watched.Close()
+
+	if err := etc.Set("etc.packet.bufferBytes", 50); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := watched.PackMessage(message); err != nil {
+		t.Fatalf("closed packer still watches etc, err = %v", err)
+	}
+}
@@ -68,8 +68,10 @@ func (s *Session) RemConn(conn network.Conn) {
 		delete(s.users, uid)
 	}
 
-	conn.Attr().Visit(func(channel, _ any) bool {
-		s.doUnsubscribe(channel.(string), conn)
+	conn.Attr().Visit(func(key, _ any) bool {
+		if channel, ok := key.(string); ok {
+			s.doUnsubscribe(channel, conn)
+		}
 
 		return true
 	})
@@ -187,6 +189,19 @@ func (s *Session) RemoteAddr(kind Kind, target int64) (net.Addr, error) {
 	return conn.RemoteAddr()
 }
 
+// Stats 获取连接流量统计
+func (s *Session) Stats(kind Kind, target int64) (*network.ConnStat, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	conn, err := s.conn(kind, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.Stats(), nil
+}
+
 // Close 关闭会话
 func (s *Session) Close(kind Kind, target int64, force ...bool) error {
 	s.rw.RLock()
@@ -200,6 +215,20 @@ func (s *Session) Close(kind Kind, target int64, force ...bool) error {
 	return conn.Close(force...)
 }
 
+// CloseWithCode 向客户端下发关闭原因后关闭会话
+// 强制关闭时仅在较短的写入超时内尝试写入一次关闭包，随后立即关闭连接
+func (s *Session) CloseWithCode(kind Kind, target int64, code network.CloseCode, message string, force ...bool) error {
+	s.rw.RLock()
+	conn, err := s.conn(kind, target)
+	s.rw.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	return network.CloseWithCode(conn, code, message, force...)
+}
+
 // Send 发送消息（同步）
 func (s *Session) Send(kind Kind, target int64, message []byte) error {
 	s.rw.RLock()
@@ -11,6 +11,7 @@ readonly modules=(
     "./config/nacos"
     "./crypto/rsa"
     "./crypto/ecc"
+    "./crypto/aes"
     "./eventbus/kafka"
     "./eventbus/nats"
     "./eventbus/redis"
@@ -22,6 +23,7 @@ readonly modules=(
     "./network/kcp"
     "./network/tcp"
     "./network/ws"
+    "./network/sse"
     "./registry/consul"
     "./registry/etcd"
     "./registry/nacos"
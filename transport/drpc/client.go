@@ -0,0 +1,87 @@
+package drpc
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/devagame/due/v2/internal/transporter/mesh"
+	"github.com/devagame/due/v2/transport"
+)
+
+type Client struct {
+	target      *url.URL
+	opts        *options
+	builder     *mesh.Builder
+	resolver    *resolver
+	interceptor transport.ClientInterceptor
+}
+
+func newClient(target string, opts *options, builder *mesh.Builder, resolver *resolver) (*Client, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = resolver.resolve(u); err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		target:      u,
+		opts:        opts,
+		builder:     builder,
+		resolver:    resolver,
+		interceptor: transport.ChainClientInterceptors(opts.client.interceptors...),
+	}, nil
+}
+
+// Call 调用服务方法
+func (c *Client) Call(ctx context.Context, service, method string, args any, reply any, opts ...any) (err error) {
+	if c.interceptor == nil {
+		err = c.invoke(ctx, service, method, args, reply, opts...)
+	} else {
+		err = c.interceptor(ctx, service, method, args, reply, c.invoke, opts...)
+	}
+
+	if err != nil && c.opts.client.fallback != nil {
+		err = c.opts.client.fallback(ctx, service, method, args, reply, err)
+	}
+
+	return
+}
+
+// Client 获取内部客户端
+func (c *Client) Client() any {
+	return c
+}
+
+// 执行调用
+func (c *Client) invoke(ctx context.Context, service, method string, args any, reply any, _ ...any) error {
+	addr, err := c.resolver.resolve(c.target)
+	if err != nil {
+		return err
+	}
+
+	cli, err := c.builder.Build(addr)
+	if err != nil {
+		return err
+	}
+
+	data, err := c.opts.codec.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	md, _ := transport.FromOutgoingContext(ctx)
+
+	res, err := cli.Call(ctx, service, method, md, data)
+	if err != nil {
+		return err
+	}
+
+	if reply == nil {
+		return nil
+	}
+
+	return c.opts.codec.Unmarshal(res, reply)
+}
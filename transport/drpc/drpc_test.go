@@ -0,0 +1,169 @@
+package drpc_test
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/internal/transporter/mesh"
+	"github.com/devagame/due/v2/transport"
+	"github.com/devagame/due/v2/transport/drpc"
+)
+
+type HelloArgs struct {
+	Name string
+}
+
+type HelloReply struct {
+	Message string
+}
+
+type greeter struct{}
+
+func (greeter) Hello(ctx context.Context, args *HelloArgs, reply *HelloReply) error {
+	if args.Name == "" {
+		return errors.New("empty name")
+	}
+
+	md, _ := transport.FromIncomingContext(ctx)
+	reply.Message = "hello " + args.Name + " from " + md.Get("user")
+
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	// 启用集群内部通信链路的握手鉴权，服务端及客户端均需沿用该配置
+	if err := etc.Set("etc.cluster.link.secret", "due"); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestTransporter(t *testing.T) {
+	transporter := drpc.NewTransporter(drpc.WithServerAddr("127.0.0.1:3551"))
+
+	server, err := transporter.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = server.RegisterService("greeter", &greeter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Start()
+	defer server.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := transporter.NewClient("direct://127.0.0.1:3551")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := transport.AppendToOutgoingContext(context.Background(), "user", "due")
+	reply := &HelloReply{}
+
+	if err = client.Call(ctx, "greeter", "Hello", &HelloArgs{Name: "world"}, reply); err != nil {
+		t.Fatal(err)
+	}
+
+	if reply.Message != "hello world from due" {
+		t.Fatalf("unexpected reply: %v", reply.Message)
+	}
+
+	if err = client.Call(ctx, "greeter", "Hello", &HelloArgs{}, reply); err == nil || err.Error() != "empty name" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = client.Call(ctx, "greeter", "Bye", &HelloArgs{}, reply); !errors.Is(err, errors.ErrNotFoundMethod) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTransporter_Secret(t *testing.T) {
+	transporter := drpc.NewTransporter(drpc.WithServerAddr("127.0.0.1:3552"))
+
+	server, err := transporter.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = server.RegisterService("greeter", &greeter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Start()
+	defer server.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err = mesh.NewBuilder(&mesh.Options{InsID: "mesh", Secret: "invalid"}).Build("127.0.0.1:3552"); err == nil {
+		t.Fatal("build client with invalid secret should fail")
+	}
+
+	client, err := transporter.NewClient("direct://127.0.0.1:3552")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reply := &HelloReply{}
+
+	if err = client.Call(context.Background(), "greeter", "Hello", &HelloArgs{Name: "world"}, reply); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransporter_UseInterceptors(t *testing.T) {
+	var clientCalls, serverCalls atomic.Int32
+
+	clientInterceptor := func(ctx context.Context, service, method string, args any, reply any, invoker transport.Invoker, opts ...any) error {
+		clientCalls.Add(1)
+		return invoker(ctx, service, method, args, reply, opts...)
+	}
+
+	serverInterceptor := func(ctx context.Context, service, method string, args any, handler transport.Handler) (any, error) {
+		serverCalls.Add(1)
+		return handler(ctx, args)
+	}
+
+	transporter := drpc.NewTransporter(drpc.WithServerAddr("127.0.0.1:3553"))
+
+	// 重复调用时替换之前设置的拦截器，不会重复添加
+	for i := 0; i < 3; i++ {
+		transporter.UseClientInterceptors(clientInterceptor)
+		transporter.UseServerInterceptors(serverInterceptor)
+	}
+
+	server, err := transporter.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = server.RegisterService("greeter", &greeter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Start()
+	defer server.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := transporter.NewClient("direct://127.0.0.1:3553")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.Call(context.Background(), "greeter", "Hello", &HelloArgs{Name: "world"}, &HelloReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if clientCalls.Load() != 1 || serverCalls.Load() != 1 {
+		t.Fatalf("client interceptor calls = %d, server interceptor calls = %d, want 1 and 1", clientCalls.Load(), serverCalls.Load())
+	}
+}
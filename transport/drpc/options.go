@@ -0,0 +1,94 @@
+package drpc
+
+import (
+	"time"
+
+	"github.com/devagame/due/v2/encoding"
+	"github.com/devagame/due/v2/etc"
+	"github.com/devagame/due/v2/registry"
+	"github.com/devagame/due/v2/transport"
+)
+
+const (
+	defaultServerAddr    = ":0"            // 默认服务器地址
+	defaultCodec         = "json"          // 默认编解码器
+	defaultClientTimeout = 3 * time.Second // 默认客户端调用超时时间
+)
+
+const (
+	defaultCodecKey         = "etc.transport.drpc.codec"
+	defaultServerAddrKey    = "etc.transport.drpc.server.addr"
+	defaultServerExposeKey  = "etc.transport.drpc.server.expose"
+	defaultClientTimeoutKey = "etc.transport.drpc.client.timeout"
+)
+
+type Option func(o *options)
+
+type options struct {
+	codec  encoding.Codec // 编解码器
+	server serverOptions  // 服务器配置
+	client clientOptions  // 客户端配置
+}
+
+type serverOptions struct {
+	addr         string                        // 监听地址
+	expose       bool                          // 是否将内部通信地址暴露到公网
+	interceptors []transport.ServerInterceptor // 服务端拦截器
+}
+
+type clientOptions struct {
+	timeout      time.Duration                 // 调用超时时间
+	discovery    registry.Discovery            // 服务发现组件
+	fallback     transport.Fallback            // 降级处理函数
+	interceptors []transport.ClientInterceptor // 客户端拦截器
+}
+
+func defaultOptions() *options {
+	opts := &options{}
+	opts.codec = encoding.Invoke(etc.Get(defaultCodecKey, defaultCodec).String())
+	opts.server.addr = etc.Get(defaultServerAddrKey, defaultServerAddr).String()
+	opts.server.expose = etc.Get(defaultServerExposeKey).Bool()
+	opts.client.timeout = etc.Get(defaultClientTimeoutKey, defaultClientTimeout).Duration()
+
+	return opts
+}
+
+// WithCodec 设置编解码器，服务端与客户端需使用相同的编解码器
+func WithCodec(codec encoding.Codec) Option {
+	return func(o *options) { o.codec = codec }
+}
+
+// WithServerAddr 设置服务器监听地址
+func WithServerAddr(addr string) Option {
+	return func(o *options) { o.server.addr = addr }
+}
+
+// WithServerExpose 设置是否将内部通信地址暴露到公网
+func WithServerExpose(expose bool) Option {
+	return func(o *options) { o.server.expose = expose }
+}
+
+// WithServerInterceptors 设置服务端拦截器
+func WithServerInterceptors(interceptors ...transport.ServerInterceptor) Option {
+	return func(o *options) { o.server.interceptors = interceptors }
+}
+
+// WithClientTimeout 设置客户端调用超时时间
+func WithClientTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.client.timeout = timeout }
+}
+
+// WithClientDiscovery 设置客户端服务发现组件
+func WithClientDiscovery(discovery registry.Discovery) Option {
+	return func(o *options) { o.client.discovery = discovery }
+}
+
+// WithClientInterceptors 设置客户端拦截器
+func WithClientInterceptors(interceptors ...transport.ClientInterceptor) Option {
+	return func(o *options) { o.client.interceptors = interceptors }
+}
+
+// WithClientFallback 设置客户端降级处理函数
+func WithClientFallback(fallback transport.Fallback) Option {
+	return func(o *options) { o.client.fallback = fallback }
+}
@@ -0,0 +1,145 @@
+package drpc
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/core/endpoint"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/registry"
+)
+
+const (
+	directScheme    = "direct"
+	discoveryScheme = "discovery"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type resolver struct {
+	dis       registry.Discovery
+	ctx       context.Context
+	cancel    context.CancelFunc
+	watcher   registry.Watcher
+	rw        sync.RWMutex
+	instances map[string]string   // 实例ID -> 地址
+	services  map[string][]string // 服务名 -> 地址列表
+	counter   atomic.Uint64
+}
+
+func newResolver(dis registry.Discovery) *resolver {
+	r := &resolver{}
+	r.dis = dis
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	if err := r.init(); err != nil {
+		log.Fatalf("init client resolver failed: %v", err)
+	}
+
+	return r
+}
+
+func (r *resolver) init() error {
+	if r.dis == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, defaultTimeout)
+	instances, err := r.dis.Services(ctx, cluster.Mesh.String())
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel = context.WithTimeout(r.ctx, defaultTimeout)
+	watcher, err := r.dis.Watch(ctx, cluster.Mesh.String())
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	r.watcher = watcher
+	r.updateInstances(instances)
+
+	go r.watch()
+
+	return nil
+}
+
+func (r *resolver) watch() {
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		default:
+			// exec watch
+		}
+		instances, err := r.watcher.Next()
+		if err != nil {
+			continue
+		}
+
+		r.updateInstances(instances)
+	}
+}
+
+func (r *resolver) updateInstances(instances []*registry.ServiceInstance) {
+	addrs := make(map[string]string, len(instances))
+	services := make(map[string][]string)
+	for _, instance := range instances {
+		ep, err := endpoint.ParseEndpoint(instance.Endpoint)
+		if err != nil {
+			log.Errorf("parse discovery endpoint failed: %v", err)
+			continue
+		}
+
+		addrs[instance.ID] = ep.Address()
+
+		for _, service := range instance.Services {
+			services[service] = append(services[service], ep.Address())
+		}
+	}
+
+	r.rw.Lock()
+	r.instances = addrs
+	r.services = services
+	r.rw.Unlock()
+}
+
+// 解析目标地址，服务发现模式下按轮询方式选取端点
+func (r *resolver) resolve(target *url.URL) (string, error) {
+	switch target.Scheme {
+	case directScheme:
+		if _, _, err := net.SplitHostPort(target.Host); err == nil {
+			return target.Host, nil
+		}
+
+		r.rw.RLock()
+		addr, ok := r.instances[target.Host]
+		r.rw.RUnlock()
+
+		if !ok {
+			return "", errors.ErrNotFoundServiceAddress
+		}
+
+		return addr, nil
+	case discoveryScheme:
+		r.rw.RLock()
+		addrs := r.services[target.Host]
+		r.rw.RUnlock()
+
+		if len(addrs) == 0 {
+			return "", errors.ErrNotFoundServiceAddress
+		}
+
+		return addrs[int(r.counter.Add(1)%uint64(len(addrs)))], nil
+	default:
+		return "", errors.ErrMissingResolver
+	}
+}
@@ -0,0 +1,128 @@
+package drpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/devagame/due/v2/core/endpoint"
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/internal/transporter/mesh"
+	"github.com/devagame/due/v2/internal/transporter/security"
+	"github.com/devagame/due/v2/transport"
+)
+
+type Server struct {
+	opts        *options
+	server      *mesh.Server
+	rw          sync.RWMutex
+	services    map[string]*service
+	interceptor transport.ServerInterceptor
+}
+
+func newServer(opts *options) (*Server, error) {
+	s := &Server{}
+	s.opts = opts
+	s.services = make(map[string]*service)
+	s.interceptor = transport.ChainServerInterceptors(opts.server.interceptors...)
+
+	sec, err := security.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := mesh.NewServer(s, &mesh.ServerOptions{
+		Addr:      opts.server.addr,
+		Expose:    opts.server.expose,
+		TLSConfig: sec.ServerTLS,
+		Secret:    sec.Secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.server = server
+
+	return s, nil
+}
+
+// Start 启动服务器
+func (s *Server) Start() error {
+	return s.server.Start()
+}
+
+// Stop 停止服务器
+func (s *Server) Stop() error {
+	return s.server.Stop()
+}
+
+// Addr 监听地址
+func (s *Server) Addr() string {
+	return s.server.ListenAddr()
+}
+
+// Scheme 协议
+func (s *Server) Scheme() string {
+	return s.server.Scheme()
+}
+
+// Endpoint 服务端口
+func (s *Server) Endpoint() *endpoint.Endpoint {
+	return s.server.Endpoint()
+}
+
+// RegisterService 注册服务
+// desc为服务名时通过反射注册服务，服务方法需满足func(ctx context.Context, args *T, reply *R) error签名
+// desc为ServiceDesc时通过代码生成的描述注册服务
+func (s *Server) RegisterService(desc, srv any) error {
+	var (
+		svc *service
+		err error
+	)
+
+	switch sd := desc.(type) {
+	case string:
+		svc, err = newServiceFromReflect(sd, srv)
+	case ServiceDesc:
+		svc, err = newServiceFromDesc(&sd, srv)
+	case *ServiceDesc:
+		svc, err = newServiceFromDesc(sd, srv)
+	default:
+		err = errors.ErrInvalidServiceDesc
+	}
+	if err != nil {
+		return err
+	}
+
+	s.rw.Lock()
+	s.services[svc.name] = svc
+	s.rw.Unlock()
+
+	return nil
+}
+
+// Call 调用服务方法
+func (s *Server) Call(ctx context.Context, service, method string, metadata map[string]string, args []byte) ([]byte, error) {
+	s.rw.RLock()
+	svc, ok := s.services[service]
+	s.rw.RUnlock()
+
+	if !ok {
+		return nil, errors.ErrNotFoundService
+	}
+
+	handler, ok := svc.methods[method]
+	if !ok {
+		return nil, errors.ErrNotFoundMethod
+	}
+
+	ctx = transport.NewIncomingContext(ctx, metadata)
+
+	reply, err := handler(svc.srv, ctx, func(v any) error {
+		return s.opts.codec.Unmarshal(args, v)
+	}, s.interceptor)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.opts.codec.Marshal(reply)
+}
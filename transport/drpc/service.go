@@ -0,0 +1,124 @@
+package drpc
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/transport"
+)
+
+var (
+	typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// MethodHandler 服务方法处理器，由代码生成工具生成
+// dec用于解码请求参数，interceptor不为nil时需经由拦截器调用服务方法
+type MethodHandler func(srv any, ctx context.Context, dec func(args any) error, interceptor transport.ServerInterceptor) (any, error)
+
+// MethodDesc 服务方法描述
+type MethodDesc struct {
+	MethodName string        // 方法名
+	Handler    MethodHandler // 处理器
+}
+
+// ServiceDesc 服务描述，由代码生成工具生成，适用于不希望使用反射注册服务的场景
+type ServiceDesc struct {
+	ServiceName string       // 服务名
+	HandlerType any          // 服务接口类型，用于校验服务实现，如(*GreeterServer)(nil)
+	Methods     []MethodDesc // 方法列表
+}
+
+type service struct {
+	name    string
+	methods map[string]MethodHandler
+	srv     any
+}
+
+// 根据服务描述构建服务
+func newServiceFromDesc(desc *ServiceDesc, srv any) (*service, error) {
+	if desc.HandlerType != nil {
+		ht := reflect.TypeOf(desc.HandlerType).Elem()
+		if st := reflect.TypeOf(srv); !st.Implements(ht) {
+			return nil, errors.ErrInvalidServiceDesc
+		}
+	}
+
+	s := &service{name: desc.ServiceName, srv: srv, methods: make(map[string]MethodHandler, len(desc.Methods))}
+	for _, m := range desc.Methods {
+		s.methods[m.MethodName] = m.Handler
+	}
+
+	return s, nil
+}
+
+// 通过反射构建服务，服务方法需满足func(ctx context.Context, args *T, reply *R) error签名
+func newServiceFromReflect(name string, srv any) (*service, error) {
+	rv := reflect.ValueOf(srv)
+	rt := rv.Type()
+
+	s := &service{name: name, srv: srv, methods: make(map[string]MethodHandler)}
+
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+		if !m.IsExported() || !isSuitableMethod(m.Type) {
+			continue
+		}
+
+		s.methods[m.Name] = reflectHandler(name, m.Name, rv.Method(i))
+	}
+
+	if len(s.methods) == 0 {
+		return nil, errors.ErrInvalidServiceDesc
+	}
+
+	return s, nil
+}
+
+// 构建反射方法处理器
+func reflectHandler(service, method string, fn reflect.Value) MethodHandler {
+	argType, replyType := fn.Type().In(1), fn.Type().In(2).Elem()
+
+	return func(_ any, ctx context.Context, dec func(args any) error, interceptor transport.ServerInterceptor) (any, error) {
+		var argv reflect.Value
+		if argType.Kind() == reflect.Ptr {
+			argv = reflect.New(argType.Elem())
+		} else {
+			argv = reflect.New(argType)
+		}
+
+		if err := dec(argv.Interface()); err != nil {
+			return nil, err
+		}
+
+		if argType.Kind() != reflect.Ptr {
+			argv = argv.Elem()
+		}
+
+		handler := func(ctx context.Context, args any) (any, error) {
+			replyv := reflect.New(replyType)
+			out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(args), replyv})
+			if err, _ := out[0].Interface().(error); err != nil {
+				return nil, err
+			}
+
+			return replyv.Interface(), nil
+		}
+
+		if interceptor == nil {
+			return handler(ctx, argv.Interface())
+		}
+
+		return interceptor(ctx, service, method, argv.Interface(), handler)
+	}
+}
+
+// 检测方法是否符合func(ctx context.Context, args T, reply *R) error签名
+func isSuitableMethod(t reflect.Type) bool {
+	if t.NumIn() != 4 || t.NumOut() != 1 {
+		return false
+	}
+
+	return t.In(1).Implements(typeOfContext) && t.In(3).Kind() == reflect.Ptr && t.Out(0) == typeOfError
+}
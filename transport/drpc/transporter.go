@@ -0,0 +1,124 @@
+package drpc
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/internal/transporter/mesh"
+	"github.com/devagame/due/v2/internal/transporter/security"
+	"github.com/devagame/due/v2/registry"
+	"github.com/devagame/due/v2/transport"
+	"github.com/devagame/due/v2/utils/xuuid"
+)
+
+const name = "drpc"
+
+type Transporter struct {
+	opts     *options
+	once     sync.Once
+	builder  *mesh.Builder
+	resolver *resolver
+	err      error
+
+	rw                 sync.RWMutex
+	clientInterceptors []transport.ClientInterceptor // 通过UseClientInterceptors设置的客户端拦截器
+	serverInterceptors []transport.ServerInterceptor // 通过UseServerInterceptors设置的服务端拦截器
+}
+
+func NewTransporter(opts ...Option) *Transporter {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Transporter{opts: o}
+}
+
+// Name 获取传输器组件名
+func (t *Transporter) Name() string {
+	return name
+}
+
+// SetDefaultDiscovery 设置默认的服务发现组件
+func (t *Transporter) SetDefaultDiscovery(discovery registry.Discovery) {
+	if t.opts.client.discovery == nil {
+		t.opts.client.discovery = discovery
+	}
+}
+
+// UseClientInterceptors 设置客户端拦截器，在选项设置的拦截器之后执行，仅对之后新建的客户端生效
+// 重复调用时替换之前设置的拦截器，多次调用不会重复添加
+func (t *Transporter) UseClientInterceptors(interceptors ...transport.ClientInterceptor) {
+	t.rw.Lock()
+	t.clientInterceptors = slices.Clone(interceptors)
+	t.rw.Unlock()
+}
+
+// UseServerInterceptors 设置服务端拦截器，在选项设置的拦截器之后执行，仅对之后新建的服务器生效
+// 重复调用时替换之前设置的拦截器，多次调用不会重复添加
+func (t *Transporter) UseServerInterceptors(interceptors ...transport.ServerInterceptor) {
+	t.rw.Lock()
+	t.serverInterceptors = slices.Clone(interceptors)
+	t.rw.Unlock()
+}
+
+// NewServer 新建传输服务器
+func (t *Transporter) NewServer() (transport.Server, error) {
+	return newServer(t.options())
+}
+
+// NewClient 新建传输客户端
+// target参数可分为三种模式:
+// 服务直连模式: 	direct://127.0.0.1:8011
+// 服务直连模式: 	direct://711baf8d-8a06-11ef-b7df-f4f19e1f0070
+// 服务发现模式: 	discovery://service_name
+func (t *Transporter) NewClient(target string) (transport.Client, error) {
+	if err := t.init(); err != nil {
+		return nil, err
+	}
+
+	return newClient(target, t.options(), t.builder, t.resolver)
+}
+
+// Stats 获取与各服务连接的合并写入统计，键为服务地址
+func (t *Transporter) Stats() map[string]*cluster.BatchStat {
+	if err := t.init(); err != nil {
+		return make(map[string]*cluster.BatchStat)
+	}
+
+	return t.builder.Stats()
+}
+
+// 获取合并了通过Use方法设置的拦截器的配置
+func (t *Transporter) options() *options {
+	t.rw.RLock()
+	defer t.rw.RUnlock()
+
+	opts := *t.opts
+	opts.client.interceptors = slices.Concat(opts.client.interceptors, t.clientInterceptors)
+	opts.server.interceptors = slices.Concat(opts.server.interceptors, t.serverInterceptors)
+
+	return &opts
+}
+
+// 初始化客户端构建器及解析器，沿用集群内部通信链路的TLS及握手鉴权配置
+func (t *Transporter) init() error {
+	t.once.Do(func() {
+		sec, err := security.Load()
+		if err != nil {
+			t.err = err
+			return
+		}
+
+		t.builder = mesh.NewBuilder(&mesh.Options{
+			InsID:     xuuid.UUID(),
+			Timeout:   t.opts.client.timeout,
+			TLSConfig: sec.ClientTLS,
+			Secret:    sec.Secret,
+		})
+		t.resolver = newResolver(t.opts.client.discovery)
+	})
+
+	return t.err
+}
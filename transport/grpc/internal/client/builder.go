@@ -3,29 +3,41 @@ package client
 import (
 	"sync"
 
+	"github.com/devagame/due/transport/grpc/v2/internal/picker"
 	"github.com/devagame/due/transport/grpc/v2/internal/resolver/direct"
 	"github.com/devagame/due/transport/grpc/v2/internal/resolver/discovery"
+	"github.com/devagame/due/v2/core/breaker"
 	"github.com/devagame/due/v2/registry"
+	"github.com/devagame/due/v2/transport"
 	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
 )
 
+const name = "grpc"
+
 type Builder struct {
 	err         error
 	opts        *Options
 	dialOpts    []grpc.DialOption
+	breaker     *breaker.Group
 	sfg         singleflight.Group
 	connections sync.Map
 }
 
 type Options struct {
-	CAFile     string
-	ServerName string
-	Discovery  registry.Discovery
-	DialOpts   []grpc.DialOption
+	CAFile       string
+	ServerName   string
+	Discovery    registry.Discovery
+	DialOpts     []grpc.DialOption
+	Interceptors []transport.ClientInterceptor
+	Fallback     transport.Fallback
+	Breaker      bool
+	BreakerOpts  []breaker.Option
 }
 
 func NewBuilder(opts *Options) *Builder {
@@ -40,21 +52,40 @@ func NewBuilder(opts *Options) *Builder {
 		cred = insecure.NewCredentials()
 	}
 
+	if opts.Breaker {
+		b.breaker = breaker.NewGroup(name, append([]breaker.Option{breaker.WithFailurePredicate(isFailure)}, opts.BreakerOpts...)...)
+	}
+
 	resolvers := make([]resolver.Builder, 0, 2)
 	resolvers = append(resolvers, direct.NewBuilder(opts.Discovery))
 	if opts.Discovery != nil {
-		resolvers = append(resolvers, discovery.NewBuilder(opts.Discovery))
+		resolvers = append(resolvers, discovery.NewBuilder(opts.Discovery, b.breaker))
+	}
+
+	policy := "round_robin"
+	if b.breaker != nil {
+		// 由熔断负载均衡器选择端点并记录请求结果
+		for i, rb := range resolvers {
+			resolvers[i] = picker.WrapResolver(rb, b.breaker)
+		}
+
+		policy = picker.Name
 	}
 
 	b.dialOpts = make([]grpc.DialOption, 0, len(opts.DialOpts)+2)
 	b.dialOpts = append(b.dialOpts, opts.DialOpts...)
 	b.dialOpts = append(b.dialOpts, grpc.WithTransportCredentials(cred))
 	b.dialOpts = append(b.dialOpts, grpc.WithResolvers(resolvers...))
-	b.dialOpts = append(b.dialOpts, grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin":{}}]}`))
+	b.dialOpts = append(b.dialOpts, grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"`+policy+`":{}}]}`))
 
 	return b
 }
 
+// Breaker 获取熔断器组，未启用熔断时返回nil
+func (b *Builder) Breaker() *breaker.Group {
+	return b.breaker
+}
+
 // Build 构建连接
 func (b *Builder) Build(target string) (*grpc.ClientConn, error) {
 	if c, ok := b.connections.Load(target); ok {
@@ -77,3 +108,13 @@ func (b *Builder) Build(target string) (*grpc.ClientConn, error) {
 
 	return c.(*grpc.ClientConn), nil
 }
+
+// 判定调用是否失败，业务错误不计入熔断统计
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
@@ -3,19 +3,42 @@ package client
 import (
 	"context"
 
+	"github.com/devagame/due/v2/transport"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 type Client struct {
-	cc *grpc.ClientConn
+	cc          *grpc.ClientConn
+	fallback    transport.Fallback
+	interceptor transport.ClientInterceptor
 }
 
-func NewClient(cc *grpc.ClientConn) *Client {
-	return &Client{cc: cc}
+func NewClient(cc *grpc.ClientConn, opts *Options) *Client {
+	return &Client{
+		cc:          cc,
+		fallback:    opts.Fallback,
+		interceptor: transport.ChainClientInterceptors(opts.Interceptors...),
+	}
 }
 
 // Call 调用服务方法
-func (c *Client) Call(ctx context.Context, service, method string, args any, reply any, opts ...any) error {
+func (c *Client) Call(ctx context.Context, service, method string, args any, reply any, opts ...any) (err error) {
+	if c.interceptor == nil {
+		err = c.invoke(ctx, service, method, args, reply, opts...)
+	} else {
+		err = c.interceptor(ctx, service, method, args, reply, c.invoke, opts...)
+	}
+
+	if err != nil && c.fallback != nil {
+		err = c.fallback(ctx, service, method, args, reply, err)
+	}
+
+	return
+}
+
+// 执行调用
+func (c *Client) invoke(ctx context.Context, service, method string, args any, reply any, opts ...any) error {
 	path := ""
 
 	if service != "" {
@@ -26,7 +49,15 @@ func (c *Client) Call(ctx context.Context, service, method string, args any, rep
 		path += "/" + method
 	}
 
-	options := make([]grpc.CallOption, 0, len(opts))
+	if md, ok := transport.FromOutgoingContext(ctx); ok && len(md) > 0 {
+		kv := make([]string, 0, len(md)*2)
+		for k, v := range md {
+			kv = append(kv, k, v)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+	}
+
+	options := make([]grpc.CallOption, 0, len(opts)+1)
 	for _, opt := range opts {
 		if o, ok := opt.(grpc.CallOption); ok {
 			options = append(options, o)
@@ -0,0 +1,112 @@
+package picker
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/devagame/due/v2/core/breaker"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+// Name 熔断负载均衡器名称
+// 按轮询选择端点，跳过被熔断及半开状态下探测名额已满的端点，并在请求完成时记录请求结果
+const Name = "due_breaker"
+
+type breakerKey struct{}
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(Name, &builder{}, base.Config{HealthCheck: true}))
+}
+
+// WrapResolver 包装解析器构建器，为解析出的端点附加熔断器组
+func WrapResolver(rb resolver.Builder, group *breaker.Group) resolver.Builder {
+	return &resolverBuilder{Builder: rb, group: group}
+}
+
+type resolverBuilder struct {
+	resolver.Builder
+	group *breaker.Group
+}
+
+func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	return b.Builder.Build(target, &clientConn{ClientConn: cc, group: b.group}, opts)
+}
+
+type clientConn struct {
+	resolver.ClientConn
+	group *breaker.Group
+}
+
+// UpdateState 更新解析状态
+func (c *clientConn) UpdateState(state resolver.State) error {
+	addresses := make([]resolver.Address, 0, len(state.Addresses))
+	for _, addr := range state.Addresses {
+		addr.BalancerAttributes = addr.BalancerAttributes.WithValue(breakerKey{}, c.group)
+		addresses = append(addresses, addr)
+	}
+
+	state.Addresses = addresses
+
+	return c.ClientConn.UpdateState(state)
+}
+
+type builder struct{}
+
+// Build 构建选择器
+func (b *builder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	p := &picker{subConns: make([]subConn, 0, len(info.ReadySCs))}
+
+	for sc, sci := range info.ReadySCs {
+		if group, ok := sci.Address.BalancerAttributes.Value(breakerKey{}).(*breaker.Group); ok {
+			p.group = group
+		}
+
+		p.subConns = append(p.subConns, subConn{sc: sc, addr: sci.Address.Addr})
+	}
+
+	return p
+}
+
+type subConn struct {
+	sc   balancer.SubConn
+	addr string
+}
+
+type picker struct {
+	group    *breaker.Group
+	subConns []subConn
+	next     atomic.Uint32
+}
+
+// Pick 选择端点，所有端点均不允许请求时按轮询选择
+func (p *picker) Pick(_ balancer.PickInfo) (balancer.PickResult, error) {
+	n := uint32(len(p.subConns))
+	start := p.next.Add(1)
+
+	if p.group == nil {
+		return balancer.PickResult{SubConn: p.subConns[start%n].sc}, nil
+	}
+
+	for i := uint32(0); i < n; i++ {
+		if sc := p.subConns[(start+i)%n]; p.group.Allow(sc.addr) {
+			return p.result(sc), nil
+		}
+	}
+
+	return p.result(p.subConns[start%n]), nil
+}
+
+// 生成选择结果，请求完成时记录请求结果
+func (p *picker) result(sc subConn) balancer.PickResult {
+	begin := time.Now()
+
+	return balancer.PickResult{SubConn: sc.sc, Done: func(info balancer.DoneInfo) {
+		p.group.Done(sc.addr, info.Err, time.Since(begin))
+	}}
+}
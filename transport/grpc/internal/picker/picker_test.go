@@ -0,0 +1,71 @@
+package picker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devagame/due/v2/core/breaker"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+type testSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+func TestPicker_HalfOpenProbes(t *testing.T) {
+	group := breaker.NewGroup("test",
+		breaker.WithConsecutiveFailures(1),
+		breaker.WithOpenTimeout(20*time.Millisecond, time.Second),
+		breaker.WithHalfOpenRequests(1),
+	)
+	defer group.Close()
+
+	states := make(chan breaker.State, 10)
+	group.OnStateChange(func(addr string, state breaker.State) { states <- state })
+
+	info := base.PickerBuildInfo{ReadySCs: make(map[balancer.SubConn]base.SubConnInfo)}
+	for _, addr := range []string{"127.0.0.1:1", "127.0.0.1:2"} {
+		info.ReadySCs[&testSubConn{addr: addr}] = base.SubConnInfo{Address: resolver.Address{
+			Addr:               addr,
+			BalancerAttributes: attributes.New(breakerKey{}, group),
+		}}
+	}
+
+	p := (&builder{}).Build(info)
+
+	group.Done("127.0.0.1:1", errors.New("unavailable"), time.Millisecond)
+
+	<-states
+
+	if state := <-states; state != breaker.StateHalfOpen {
+		t.Fatalf("unexpected state: %v", state)
+	}
+
+	var probes []balancer.PickResult
+
+	for i := 0; i < 10; i++ {
+		result, err := p.Pick(balancer.PickInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.SubConn.(*testSubConn).addr == "127.0.0.1:1" {
+			probes = append(probes, result)
+		}
+	}
+
+	if len(probes) != 1 {
+		t.Fatalf("half-open endpoint picked %d times, want 1", len(probes))
+	}
+
+	probes[0].Done(balancer.DoneInfo{})
+
+	if state := <-states; state != breaker.StateClosed {
+		t.Fatalf("unexpected state: %v", state)
+	}
+}
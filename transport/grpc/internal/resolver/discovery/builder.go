@@ -3,6 +3,7 @@ package discovery
 import (
 	"context"
 	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/core/breaker"
 	"github.com/devagame/due/v2/core/endpoint"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/log"
@@ -18,6 +19,7 @@ const defaultTimeout = 10 * time.Second
 
 type Builder struct {
 	dis       registry.Discovery
+	breaker   *breaker.Group
 	ctx       context.Context
 	cancel    context.CancelFunc
 	watcher   registry.Watcher
@@ -28,11 +30,16 @@ type Builder struct {
 
 var _ resolver.Builder = &Builder{}
 
-func NewBuilder(dis registry.Discovery) *Builder {
+func NewBuilder(dis registry.Discovery, breaker *breaker.Group) *Builder {
 	b := &Builder{}
 	b.dis = dis
+	b.breaker = breaker
 	b.ctx, b.cancel = context.WithCancel(context.Background())
 
+	if b.breaker != nil {
+		b.breaker.OnStateChange(b.onBreakerStateChange)
+	}
+
 	if err := b.init(); err != nil {
 		log.Fatalf("init client builder failed: %v", err)
 	}
@@ -49,7 +56,7 @@ func (b *Builder) Build(target resolver.Target, cc resolver.ClientConn, opts res
 		return nil, errors.ErrNotFoundServiceAddress
 	}
 
-	if err := cc.UpdateState(*state); err != nil {
+	if err := cc.UpdateState(b.eject(*state)); err != nil {
 		return nil, err
 	}
 
@@ -110,6 +117,7 @@ func (b *Builder) watch() {
 
 func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 	states := make(map[string]*resolver.State, len(instances))
+	addrs := make([]string, 0, len(instances))
 	for _, instance := range instances {
 		ep, err := endpoint.ParseEndpoint(instance.Endpoint)
 		if err != nil {
@@ -117,6 +125,8 @@ func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 			continue
 		}
 
+		addrs = append(addrs, ep.Address())
+
 		for _, service := range instance.Services {
 			if state, ok := states[service]; ok {
 				state.Addresses = append(state.Addresses, resolver.Address{Addr: ep.Address(), ServerName: service})
@@ -126,15 +136,28 @@ func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 		}
 	}
 
+	if b.breaker != nil {
+		b.breaker.Retain(addrs)
+	}
+
 	b.rw.Lock()
 	b.states = states
 	b.rw.Unlock()
 
+	b.refreshResolvers()
+}
+
+// 刷新所有解析器状态
+func (b *Builder) refreshResolvers() {
+	b.rw.RLock()
+	states := b.states
+	b.rw.RUnlock()
+
 	b.resolvers.Range(func(_, value any) bool {
 		r := value.(*Resolver)
 
 		if state, ok := states[r.target.URL.Host]; ok {
-			r.updateState(*state)
+			r.updateState(b.eject(*state))
 		} else {
 			b.removeResolver(r)
 		}
@@ -143,13 +166,53 @@ func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 	})
 }
 
+// 熔断器状态变化时刷新解析器状态，摘除或恢复端点
+func (b *Builder) onBreakerStateChange(addr string, state breaker.State) {
+	log.Warnf("mesh endpoint %s circuit breaker is %s", addr, state)
+
+	b.refreshResolvers()
+}
+
+// 摘除被熔断的端点
+func (b *Builder) eject(state resolver.State) resolver.State {
+	if b.breaker == nil {
+		return state
+	}
+
+	addrs := make([]string, 0, len(state.Addresses))
+	for _, addr := range state.Addresses {
+		addrs = append(addrs, addr.Addr)
+	}
+
+	available := b.breaker.Available(addrs)
+	if len(available) == len(addrs) {
+		return state
+	}
+
+	keeps := make(map[string]struct{}, len(available))
+	for _, addr := range available {
+		keeps[addr] = struct{}{}
+	}
+
+	addresses := make([]resolver.Address, 0, len(available))
+	for _, addr := range state.Addresses {
+		if _, ok := keeps[addr.Addr]; ok {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	state.Addresses = addresses
+
+	return state
+}
+
 func (b *Builder) updateResolver(r *Resolver) {
 	b.rw.RLock()
 	states := b.states
 	b.rw.RUnlock()
 
 	if state, ok := states[r.target.URL.Host]; ok {
-		r.updateState(*state)
+		r.updateState(b.eject(*state))
 	} else {
 		b.resolvers.Delete(r.target.URL.Host)
 	}
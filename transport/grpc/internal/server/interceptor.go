@@ -3,8 +3,11 @@ package server
 import (
 	"context"
 	"github.com/devagame/due/v2/log"
+	"github.com/devagame/due/v2/transport"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"runtime"
+	"strings"
 )
 
 func recoverInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
@@ -21,3 +24,40 @@ func recoverInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo
 
 	return handler(ctx, req)
 }
+
+// 将GRPC元数据转换为传输元数据
+func metadataInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		incoming := make(transport.Metadata, len(md))
+		for k, vs := range md {
+			if len(vs) > 0 {
+				incoming[k] = vs[0]
+			}
+		}
+		ctx = transport.NewIncomingContext(ctx, incoming)
+	}
+
+	return handler(ctx, req)
+}
+
+// 将传输服务端拦截器适配为GRPC一元拦截器
+func unaryInterceptor(interceptor transport.ServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		service, method := splitFullMethod(info.FullMethod)
+
+		return interceptor(ctx, service, method, req, func(ctx context.Context, args any) (any, error) {
+			return handler(ctx, args)
+		})
+	}
+}
+
+// 拆分完整方法名，格式为/service/method
+func splitFullMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+
+	return "", fullMethod
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/devagame/due/v2/core/endpoint"
 	xnet "github.com/devagame/due/v2/core/net"
 	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/transport"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -20,11 +21,12 @@ type Server struct {
 }
 
 type Options struct {
-	Addr       string
-	Expose     bool
-	KeyFile    string
-	CertFile   string
-	ServerOpts []grpc.ServerOption
+	Addr         string
+	Expose       bool
+	KeyFile      string
+	CertFile     string
+	ServerOpts   []grpc.ServerOption
+	Interceptors []transport.ServerInterceptor
 }
 
 func NewServer(opts *Options) (*Server, error) {
@@ -34,9 +36,15 @@ func NewServer(opts *Options) (*Server, error) {
 	}
 
 	isSecure := false
-	serverOpts := make([]grpc.ServerOption, 0, len(opts.ServerOpts)+2)
+	interceptors := []grpc.UnaryServerInterceptor{metadataInterceptor}
+	if interceptor := transport.ChainServerInterceptors(opts.Interceptors...); interceptor != nil {
+		interceptors = append(interceptors, unaryInterceptor(interceptor))
+	}
+
+	serverOpts := make([]grpc.ServerOption, 0, len(opts.ServerOpts)+3)
 	serverOpts = append(serverOpts, opts.ServerOpts...)
 	serverOpts = append(serverOpts, grpc.UnaryInterceptor(recoverInterceptor))
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(interceptors...))
 	if opts.CertFile != "" && opts.KeyFile != "" {
 		cred, err := credentials.NewServerTLSFromFile(opts.CertFile, opts.KeyFile)
 		if err != nil {
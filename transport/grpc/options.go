@@ -3,8 +3,10 @@ package grpc
 import (
 	"github.com/devagame/due/transport/grpc/v2/internal/client"
 	"github.com/devagame/due/transport/grpc/v2/internal/server"
+	"github.com/devagame/due/v2/core/breaker"
 	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/registry"
+	"github.com/devagame/due/v2/transport"
 	"google.golang.org/grpc"
 )
 
@@ -19,6 +21,7 @@ const (
 	defaultServerCertFileKey   = "etc.transport.grpc.server.certFile"
 	defaultClientCAFileKey     = "etc.transport.grpc.client.caFile"
 	defaultClientServerNameKey = "etc.transport.grpc.client.serverName"
+	defaultClientBreakerKey    = "etc.transport.breaker.enable"
 )
 
 type Option func(o *options)
@@ -36,6 +39,7 @@ func defaultOptions() *options {
 	opts.server.CertFile = etc.Get(defaultServerCertFileKey).String()
 	opts.client.CAFile = etc.Get(defaultClientCAFileKey).String()
 	opts.client.ServerName = etc.Get(defaultClientServerNameKey).String()
+	opts.client.Breaker = etc.Get(defaultClientBreakerKey).Bool()
 
 	return opts
 }
@@ -60,6 +64,11 @@ func WithServerOptions(opts ...grpc.ServerOption) Option {
 	return func(o *options) { o.server.ServerOpts = opts }
 }
 
+// WithServerInterceptors 设置服务端拦截器
+func WithServerInterceptors(interceptors ...transport.ServerInterceptor) Option {
+	return func(o *options) { o.server.Interceptors = interceptors }
+}
+
 // WithClientCredentials 设置客户端证书和校验域名
 func WithClientCredentials(caFile string, serverName string) Option {
 	return func(o *options) { o.client.CAFile, o.client.ServerName = caFile, serverName }
@@ -74,3 +83,18 @@ func WithClientDiscovery(discovery registry.Discovery) Option {
 func WithClientDialOptions(opts ...grpc.DialOption) Option {
 	return func(o *options) { o.client.DialOpts = opts }
 }
+
+// WithClientInterceptors 设置客户端拦截器
+func WithClientInterceptors(interceptors ...transport.ClientInterceptor) Option {
+	return func(o *options) { o.client.Interceptors = interceptors }
+}
+
+// WithClientBreaker 设置客户端是否启用熔断及离群摘除，以及熔断器选项
+func WithClientBreaker(enable bool, opts ...breaker.Option) Option {
+	return func(o *options) { o.client.Breaker, o.client.BreakerOpts = enable, opts }
+}
+
+// WithClientFallback 设置客户端降级处理函数
+func WithClientFallback(fallback transport.Fallback) Option {
+	return func(o *options) { o.client.Fallback = fallback }
+}
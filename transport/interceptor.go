@@ -0,0 +1,71 @@
+package transport
+
+import "context"
+
+// Invoker 客户端调用器
+type Invoker func(ctx context.Context, service, method string, args any, reply any, opts ...any) error
+
+// ClientInterceptor 客户端一元拦截器
+// 拦截器可在调用前后执行日志、监控、重试、附加元数据等逻辑，需调用invoker完成实际调用
+type ClientInterceptor func(ctx context.Context, service, method string, args any, reply any, invoker Invoker, opts ...any) error
+
+// Handler 服务端处理器
+type Handler func(ctx context.Context, args any) (reply any, err error)
+
+// ServerInterceptor 服务端一元拦截器
+// 拦截器可在处理前后执行日志、监控、鉴权等逻辑，需调用handler完成实际处理
+type ServerInterceptor func(ctx context.Context, service, method string, args any, handler Handler) (reply any, err error)
+
+// ChainClientInterceptors 串联多个客户端拦截器，按设置顺序由外向内执行，没有拦截器时返回nil
+func ChainClientInterceptors(interceptors ...ClientInterceptor) ClientInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+
+	return func(ctx context.Context, service, method string, args any, reply any, invoker Invoker, opts ...any) error {
+		return interceptors[0](ctx, service, method, args, reply, chainInvoker(interceptors, 0, invoker), opts...)
+	}
+}
+
+// 构建第i个拦截器之后的调用器
+func chainInvoker(interceptors []ClientInterceptor, i int, invoker Invoker) Invoker {
+	if i == len(interceptors)-1 {
+		return invoker
+	}
+
+	return func(ctx context.Context, service, method string, args any, reply any, opts ...any) error {
+		return interceptors[i+1](ctx, service, method, args, reply, chainInvoker(interceptors, i+1, invoker), opts...)
+	}
+}
+
+// ChainServerInterceptors 串联多个服务端拦截器，按设置顺序由外向内执行，没有拦截器时返回nil
+func ChainServerInterceptors(interceptors ...ServerInterceptor) ServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+
+	return func(ctx context.Context, service, method string, args any, handler Handler) (any, error) {
+		return interceptors[0](ctx, service, method, args, chainHandler(interceptors, 0, service, method, handler))
+	}
+}
+
+// 构建第i个拦截器之后的处理器
+func chainHandler(interceptors []ServerInterceptor, i int, service, method string, handler Handler) Handler {
+	if i == len(interceptors)-1 {
+		return handler
+	}
+
+	return func(ctx context.Context, args any) (any, error) {
+		return interceptors[i+1](ctx, service, method, args, chainHandler(interceptors, i+1, service, method, handler))
+	}
+}
+
+// Fallback 降级处理函数，调用失败（包括端点被熔断摘除）时回调
+// 返回nil表示已完成降级处理，reply即为最终应答
+type Fallback func(ctx context.Context, service, method string, args any, reply any, err error) error
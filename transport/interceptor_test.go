@@ -0,0 +1,59 @@
+package transport_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devagame/due/v2/transport"
+)
+
+func TestChainClientInterceptors(t *testing.T) {
+	orders := make([]string, 0, 3)
+
+	interceptor := transport.ChainClientInterceptors(
+		func(ctx context.Context, service, method string, args any, reply any, invoker transport.Invoker, opts ...any) error {
+			orders = append(orders, "first")
+			return invoker(transport.AppendToOutgoingContext(ctx, "User", "1"), service, method, args, reply, opts...)
+		},
+		func(ctx context.Context, service, method string, args any, reply any, invoker transport.Invoker, opts ...any) error {
+			orders = append(orders, "second")
+			return invoker(ctx, service, method, args, reply, opts...)
+		},
+	)
+
+	err := interceptor(context.Background(), "greeter", "Hello", nil, nil, func(ctx context.Context, service, method string, args any, reply any, opts ...any) error {
+		md, _ := transport.FromOutgoingContext(ctx)
+		orders = append(orders, service+"/"+method+"/"+md.Get("user"))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orders) != 3 || orders[0] != "first" || orders[1] != "second" || orders[2] != "greeter/Hello/1" {
+		t.Fatalf("unexpected orders: %v", orders)
+	}
+}
+
+func TestChainServerInterceptors(t *testing.T) {
+	interceptor := transport.ChainServerInterceptors(
+		func(ctx context.Context, service, method string, args any, handler transport.Handler) (any, error) {
+			reply, err := handler(ctx, args)
+			return reply.(int) * 2, err
+		},
+		func(ctx context.Context, service, method string, args any, handler transport.Handler) (any, error) {
+			return handler(ctx, args.(int)+1)
+		},
+	)
+
+	reply, err := interceptor(context.Background(), "greeter", "Hello", 1, func(ctx context.Context, args any) (any, error) {
+		return args, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reply != 4 {
+		t.Fatalf("unexpected reply: %v", reply)
+	}
+}
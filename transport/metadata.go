@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"context"
+	"strings"
+)
+
+type (
+	outgoingCtxKey struct{}
+	incomingCtxKey struct{}
+)
+
+// Metadata 调用元数据，键名统一为小写
+// 客户端通过上下文附加的元数据会随调用传递到服务端，常用于传递鉴权信息、链路信息等
+type Metadata map[string]string
+
+// Get 获取元数据
+func (md Metadata) Get(key string) string {
+	return md[strings.ToLower(key)]
+}
+
+// Set 设置元数据
+func (md Metadata) Set(key, value string) {
+	md[strings.ToLower(key)] = value
+}
+
+// Clone 克隆元数据
+func (md Metadata) Clone() Metadata {
+	dst := make(Metadata, len(md))
+	for k, v := range md {
+		dst[k] = v
+	}
+
+	return dst
+}
+
+// NewOutgoingContext 设置客户端调用时发送的元数据
+func NewOutgoingContext(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, outgoingCtxKey{}, md)
+}
+
+// AppendToOutgoingContext 追加客户端调用时发送的元数据，kv为键值对
+func AppendToOutgoingContext(ctx context.Context, kv ...string) context.Context {
+	md, _ := FromOutgoingContext(ctx)
+	md = md.Clone()
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		md.Set(kv[i], kv[i+1])
+	}
+
+	return NewOutgoingContext(ctx, md)
+}
+
+// FromOutgoingContext 获取客户端调用时发送的元数据
+func FromOutgoingContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(outgoingCtxKey{}).(Metadata)
+	return md, ok
+}
+
+// NewIncomingContext 设置服务端接收到的元数据，由传输器在处理调用前设置
+func NewIncomingContext(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, incomingCtxKey{}, md)
+}
+
+// FromIncomingContext 获取服务端接收到的元数据
+func FromIncomingContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(incomingCtxKey{}).(Metadata)
+	return md, ok
+}
@@ -1,37 +1,48 @@
 package client
 
 import (
+	"context"
 	"net/url"
 	"sync"
 
 	"github.com/devagame/due/transport/rpcx/v2/internal/resolver"
 	"github.com/devagame/due/transport/rpcx/v2/internal/resolver/direct"
 	"github.com/devagame/due/transport/rpcx/v2/internal/resolver/discovery"
+	"github.com/devagame/due/v2/core/breaker"
 	"github.com/devagame/due/v2/core/tls"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/registry"
+	"github.com/devagame/due/v2/transport"
 	cli "github.com/smallnest/rpcx/client"
 	proto "github.com/smallnest/rpcx/protocol"
 	"golang.org/x/sync/singleflight"
 )
 
-const defaultPoolSize = 10
+const (
+	name            = "rpcx"
+	defaultPoolSize = 10
+)
 
 type Builder struct {
 	err      error
 	opts     *Options
 	dialOpts cli.Option
 	builders map[string]resolver.Builder
+	breaker  *breaker.Group
 	sfg      singleflight.Group
 	pools    sync.Map
 }
 
 type Options struct {
-	PoolSize   int
-	CAFile     string
-	ServerName string
-	Discovery  registry.Discovery
-	FailMode   cli.FailMode
+	PoolSize     int
+	CAFile       string
+	ServerName   string
+	Discovery    registry.Discovery
+	FailMode     cli.FailMode
+	Interceptors []transport.ClientInterceptor
+	Fallback     transport.Fallback
+	Breaker      bool
+	BreakerOpts  []breaker.Option
 }
 
 func NewBuilder(opts *Options) *Builder {
@@ -40,9 +51,13 @@ func NewBuilder(opts *Options) *Builder {
 	b.builders = make(map[string]resolver.Builder)
 	b.dialOpts = cli.DefaultOption
 	b.dialOpts.CompressType = proto.Gzip
+	if opts.Breaker {
+		b.breaker = breaker.NewGroup(name, append([]breaker.Option{breaker.WithFailurePredicate(isFailure)}, opts.BreakerOpts...)...)
+	}
+
 	b.RegisterBuilder(direct.NewBuilder(opts.Discovery))
 	if opts.Discovery != nil {
-		b.RegisterBuilder(discovery.NewBuilder(opts.Discovery))
+		b.RegisterBuilder(discovery.NewBuilder(opts.Discovery, b.breaker))
 	}
 
 	if opts.CAFile != "" {
@@ -57,6 +72,11 @@ func (b *Builder) RegisterBuilder(builder resolver.Builder) {
 	b.builders[builder.Scheme()] = builder
 }
 
+// Breaker 获取熔断器组，未启用熔断时返回nil
+func (b *Builder) Breaker() *breaker.Group {
+	return b.breaker
+}
+
 // Build 建立Discovery
 func (b *Builder) Build(target string) (*cli.OneClient, error) {
 	if b.err != nil {
@@ -91,6 +111,15 @@ func (b *Builder) Build(target string) (*cli.OneClient, error) {
 
 		pool := cli.NewOneClientPool(size, cli.Failtry, cli.RoundRobin, dis, b.dialOpts)
 
+		if b.breaker != nil {
+			plugins := cli.NewPluginContainer()
+			plugins.Add(selectPlugin{breaker: b.breaker})
+
+			for i := 0; i < size; i++ {
+				pool.Get().SetPlugins(plugins)
+			}
+		}
+
 		b.pools.Store(target, pool)
 
 		return pool, nil
@@ -101,3 +130,14 @@ func (b *Builder) Build(target string) (*cli.OneClient, error) {
 
 	return val.(*cli.OneClientPool).Get(), nil
 }
+
+// 判定调用是否失败，业务错误不计入熔断统计
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	_, ok := err.(cli.ServiceError)
+
+	return !ok
+}
@@ -2,24 +2,137 @@ package client
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/devagame/due/v2/core/breaker"
+	"github.com/devagame/due/v2/transport"
 	cli "github.com/smallnest/rpcx/client"
+	"github.com/smallnest/rpcx/share"
 )
 
+type selectedCtxKey struct{}
+
 type Client struct {
-	cli *cli.OneClient
+	cli         *cli.OneClient
+	breaker     *breaker.Group
+	fallback    transport.Fallback
+	interceptor transport.ClientInterceptor
 }
 
-func NewClient(cli *cli.OneClient) *Client {
-	return &Client{cli: cli}
+func NewClient(cli *cli.OneClient, opts *Options, breaker *breaker.Group) *Client {
+	return &Client{
+		cli:         cli,
+		breaker:     breaker,
+		fallback:    opts.Fallback,
+		interceptor: transport.ChainClientInterceptors(opts.Interceptors...),
+	}
 }
 
 // Call 调用服务方法
-func (c *Client) Call(ctx context.Context, service, method string, args any, reply any, opts ...any) error {
-	return c.cli.Call(ctx, service, method, args, reply)
+func (c *Client) Call(ctx context.Context, service, method string, args any, reply any, opts ...any) (err error) {
+	if c.interceptor == nil {
+		err = c.invoke(ctx, service, method, args, reply, opts...)
+	} else {
+		err = c.interceptor(ctx, service, method, args, reply, c.invoke, opts...)
+	}
+
+	if err != nil && c.fallback != nil {
+		err = c.fallback(ctx, service, method, args, reply, err)
+	}
+
+	return
+}
+
+// 执行调用，将传输元数据及调用截止时间附加到请求元数据中
+func (c *Client) invoke(ctx context.Context, service, method string, args any, reply any, opts ...any) error {
+	md, _ := transport.FromOutgoingContext(ctx)
+	deadline, hasDeadline := ctx.Deadline()
+
+	if len(md) > 0 || hasDeadline {
+		meta := make(map[string]string, len(md)+1)
+		if m, ok := ctx.Value(share.ReqMetaDataKey).(map[string]string); ok {
+			for k, v := range m {
+				meta[k] = v
+			}
+		}
+
+		for k, v := range md {
+			meta[k] = v
+		}
+
+		if hasDeadline {
+			if timeout := time.Until(deadline).Milliseconds(); timeout > 0 {
+				meta[share.ServerTimeout] = strconv.FormatInt(timeout, 10)
+			}
+		}
+
+		ctx = context.WithValue(ctx, share.ReqMetaDataKey, meta)
+	}
+
+	if c.breaker == nil {
+		return c.cli.Call(ctx, service, method, args, reply)
+	}
+
+	selected := new(string)
+	ctx = context.WithValue(ctx, selectedCtxKey{}, selected)
+	start := time.Now()
+
+	err := c.cli.Call(ctx, service, method, args, reply)
+
+	if *selected != "" {
+		c.breaker.Done(*selected, err, time.Since(start))
+	}
+
+	return err
 }
 
 // Client 获取客户端
 func (c *Client) Client() any {
 	return c.cli
 }
+
+// 记录选中端点的插件
+// 选中的端点处于半开状态且探测名额已满时重新选择，所有端点均不可用时使用首次选中的端点
+type selectPlugin struct {
+	breaker *breaker.Group
+}
+
+// WrapSelect 包装端点选择函数
+func (p selectPlugin) WrapSelect(fn cli.SelectFunc) cli.SelectFunc {
+	return func(ctx context.Context, servicePath, serviceMethod string, args any) string {
+		selected, ok := ctx.Value(selectedCtxKey{}).(*string)
+		if !ok || *selected != "" {
+			// 重试时不再重复占用探测名额
+			return fn(ctx, servicePath, serviceMethod, args)
+		}
+
+		var (
+			k     = fn(ctx, servicePath, serviceMethod, args)
+			first = k
+			tried = make(map[string]struct{})
+		)
+
+		for k != "" && !p.breaker.Allow(strings.TrimPrefix(k, "tcp@")) {
+			tried[k] = struct{}{}
+
+			if k = fn(ctx, servicePath, serviceMethod, args); k == "" {
+				break
+			}
+
+			if _, ok := tried[k]; ok {
+				k = first
+				break
+			}
+		}
+
+		if k == "" {
+			k = first
+		}
+
+		*selected = strings.TrimPrefix(k, "tcp@")
+
+		return k
+	}
+}
@@ -3,12 +3,14 @@ package discovery
 import (
 	"context"
 	"github.com/devagame/due/v2/cluster"
+	"github.com/devagame/due/v2/core/breaker"
 	"github.com/devagame/due/v2/core/endpoint"
 	"github.com/devagame/due/v2/errors"
 	"github.com/devagame/due/v2/log"
 	"github.com/devagame/due/v2/registry"
 	cli "github.com/smallnest/rpcx/client"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 )
@@ -19,6 +21,7 @@ const defaultTimeout = 10 * time.Second
 
 type Builder struct {
 	dis       registry.Discovery
+	breaker   *breaker.Group
 	ctx       context.Context
 	cancel    context.CancelFunc
 	watcher   registry.Watcher
@@ -27,11 +30,16 @@ type Builder struct {
 	resolvers sync.Map
 }
 
-func NewBuilder(dis registry.Discovery) *Builder {
+func NewBuilder(dis registry.Discovery, breaker *breaker.Group) *Builder {
 	b := &Builder{}
 	b.dis = dis
+	b.breaker = breaker
 	b.ctx, b.cancel = context.WithCancel(context.Background())
 
+	if b.breaker != nil {
+		b.breaker.OnStateChange(b.onBreakerStateChange)
+	}
+
 	if err := b.init(); err != nil {
 		log.Fatalf("init client builder failed: %v", err)
 	}
@@ -108,6 +116,7 @@ func (b *Builder) watch() {
 
 func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 	pairs := make(map[string][]*cli.KVPair, len(instances))
+	addrs := make([]string, 0, len(instances))
 	for _, instance := range instances {
 		ep, err := endpoint.ParseEndpoint(instance.Endpoint)
 		if err != nil {
@@ -115,15 +124,30 @@ func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 			continue
 		}
 
+		addrs = append(addrs, ep.Address())
+
 		for _, service := range instance.Services {
 			pairs[service] = append(pairs[service], &cli.KVPair{Key: "tcp@" + ep.Address()})
 		}
 	}
 
+	if b.breaker != nil {
+		b.breaker.Retain(addrs)
+	}
+
 	b.rw.Lock()
 	b.pairs = pairs
 	b.rw.Unlock()
 
+	b.refreshResolvers()
+}
+
+// 刷新所有解析器状态
+func (b *Builder) refreshResolvers() {
+	b.rw.RLock()
+	pairs := b.pairs
+	b.rw.RUnlock()
+
 	b.resolvers.Range(func(_, value any) bool {
 		r := value.(*Resolver)
 		r.updateState(pairs[r.name])
@@ -132,6 +156,44 @@ func (b *Builder) updateInstances(instances []*registry.ServiceInstance) {
 	})
 }
 
+// 熔断器状态变化时刷新解析器状态，摘除或恢复端点
+func (b *Builder) onBreakerStateChange(addr string, state breaker.State) {
+	log.Warnf("mesh endpoint %s circuit breaker is %s", addr, state)
+
+	b.refreshResolvers()
+}
+
+// 摘除被熔断的端点
+func (b *Builder) eject(list []*cli.KVPair) []*cli.KVPair {
+	if b.breaker == nil {
+		return list
+	}
+
+	addrs := make([]string, 0, len(list))
+	for _, pair := range list {
+		addrs = append(addrs, strings.TrimPrefix(pair.Key, "tcp@"))
+	}
+
+	available := b.breaker.Available(addrs)
+	if len(available) == len(addrs) {
+		return list
+	}
+
+	keeps := make(map[string]struct{}, len(available))
+	for _, addr := range available {
+		keeps[addr] = struct{}{}
+	}
+
+	pairs := make([]*cli.KVPair, 0, len(available))
+	for _, pair := range list {
+		if _, ok := keeps[strings.TrimPrefix(pair.Key, "tcp@")]; ok {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
 func (b *Builder) removeResolver(r *Resolver) {
 	b.resolvers.Delete(r.name)
 }
@@ -84,6 +84,8 @@ func (r *Resolver) Close() {
 func (r *Resolver) updateState(list []*cli.KVPair) {
 	var pairs []*cli.KVPair
 
+	list = r.builder.eject(list)
+
 	if r.filter != nil {
 		pairs = make([]*cli.KVPair, 0, len(list))
 		for _, pair := range list {
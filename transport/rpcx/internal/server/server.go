@@ -6,24 +6,27 @@ import (
 	"github.com/devagame/due/v2/core/endpoint"
 	"github.com/devagame/due/v2/core/net"
 	"github.com/devagame/due/v2/errors"
+	"github.com/devagame/due/v2/transport"
 	"github.com/smallnest/rpcx/server"
 )
 
 const scheme = "rpcx"
 
 type Server struct {
-	listenAddr string
-	exposeAddr string
-	server     *server.Server
-	endpoint   *endpoint.Endpoint
+	listenAddr  string
+	exposeAddr  string
+	server      *server.Server
+	endpoint    *endpoint.Endpoint
+	interceptor transport.ServerInterceptor
 }
 
 type Options struct {
-	Addr       string
-	Expose     bool
-	KeyFile    string
-	CertFile   string
-	ServerOpts []server.OptionFn
+	Addr         string
+	Expose       bool
+	KeyFile      string
+	CertFile     string
+	ServerOpts   []server.OptionFn
+	Interceptors []transport.ServerInterceptor
 }
 
 func NewServer(opts *Options) (*Server, error) {
@@ -50,6 +53,8 @@ func NewServer(opts *Options) (*Server, error) {
 	s.exposeAddr = exposeAddr
 	s.server = server.NewServer(serverOpts...)
 	s.endpoint = endpoint.NewEndpoint(scheme, exposeAddr, isSecure)
+	s.interceptor = transport.ChainServerInterceptors(opts.Interceptors...)
+	s.server.Plugins.Add(metadataPlugin{})
 
 	return s, nil
 }
@@ -86,5 +91,19 @@ func (s *Server) RegisterService(desc, ss any) error {
 		return errors.ErrInvalidServiceDesc
 	}
 
-	return s.server.RegisterName(name, ss, "")
+	// 未设置拦截器时无需逐个包装方法
+	if s.interceptor == nil {
+		return s.server.RegisterName(name, ss, "")
+	}
+
+	n, err := s.registerFunctions(name, ss)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return s.server.RegisterName(name, ss, "")
+	}
+
+	return nil
 }
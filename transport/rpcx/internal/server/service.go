@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/devagame/due/v2/transport"
+	"github.com/smallnest/rpcx/protocol"
+	"github.com/smallnest/rpcx/share"
+)
+
+var (
+	typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// 以函数形式注册服务的所有方法，使服务端拦截器对每个方法生效
+func (s *Server) registerFunctions(name string, ss any) (int, error) {
+	rv := reflect.ValueOf(ss)
+	rt := rv.Type()
+	n := 0
+
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+		if !m.IsExported() || !isSuitableMethod(m.Type) {
+			continue
+		}
+
+		if err := s.server.RegisterFunctionName(name, m.Name, s.wrapMethod(name, m.Name, rv.Method(i)), ""); err != nil {
+			return n, err
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// 包装服务方法
+func (s *Server) wrapMethod(service, method string, fn reflect.Value) reflect.Value {
+	return reflect.MakeFunc(fn.Type(), func(in []reflect.Value) []reflect.Value {
+		ctx := in[0].Interface().(context.Context)
+		reply := in[2]
+
+		r, err := s.interceptor(ctx, service, method, in[1].Interface(), func(ctx context.Context, args any) (any, error) {
+			out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(args), reply})
+			err, _ := out[0].Interface().(error)
+			return reply.Interface(), err
+		})
+
+		// 拦截器未调用处理器而直接返回应答时，将应答写回
+		if rv := reflect.ValueOf(r); err == nil && rv.IsValid() && rv.Type() == reply.Type() && rv.Pointer() != reply.Pointer() {
+			reply.Elem().Set(rv.Elem())
+		}
+
+		return []reflect.Value{reflect.ValueOf(&err).Elem()}
+	})
+}
+
+// 元数据插件，处理请求前将RPCX请求元数据转换为传输元数据
+type metadataPlugin struct{}
+
+// PreHandleRequest 处理请求前回调
+func (metadataPlugin) PreHandleRequest(ctx context.Context, _ *protocol.Message) error {
+	sc, ok := ctx.(*share.Context)
+	if !ok {
+		return nil
+	}
+
+	md := make(transport.Metadata)
+	if meta, ok := sc.Value(share.ReqMetaDataKey).(map[string]string); ok {
+		for k, v := range meta {
+			md.Set(k, v)
+		}
+	}
+
+	// 原地替换内层上下文，保持上下文类型不变
+	sc.Context = transport.NewIncomingContext(sc.Context, md)
+
+	return nil
+}
+
+// 检测方法是否符合func(ctx context.Context, args T, reply *R) error签名
+func isSuitableMethod(t reflect.Type) bool {
+	if t.NumIn() != 4 || t.NumOut() != 1 {
+		return false
+	}
+
+	return t.In(1).Implements(typeOfContext) && t.In(3).Kind() == reflect.Ptr && t.Out(0) == typeOfError
+}
@@ -3,8 +3,10 @@ package rpcx
 import (
 	"github.com/devagame/due/transport/rpcx/v2/internal/client"
 	"github.com/devagame/due/transport/rpcx/v2/internal/server"
+	"github.com/devagame/due/v2/core/breaker"
 	"github.com/devagame/due/v2/etc"
 	"github.com/devagame/due/v2/registry"
+	"github.com/devagame/due/v2/transport"
 )
 
 const (
@@ -20,6 +22,7 @@ const (
 	defaultClientPoolSizeKey   = "etc.transport.rpcx.client.poolSize"
 	defaultClientCAFileKey     = "etc.transport.rpcx.client.caFile"
 	defaultClientServerNameKey = "etc.transport.rpcx.client.serverName"
+	defaultClientBreakerKey    = "etc.transport.breaker.enable"
 )
 
 type Option func(o *options)
@@ -38,6 +41,7 @@ func defaultOptions() *options {
 	opts.client.PoolSize = etc.Get(defaultClientPoolSizeKey, defaultClientPoolSize).Int()
 	opts.client.CAFile = etc.Get(defaultClientCAFileKey).String()
 	opts.client.ServerName = etc.Get(defaultClientServerNameKey).String()
+	opts.client.Breaker = etc.Get(defaultClientBreakerKey).Bool()
 
 	return opts
 }
@@ -57,6 +61,11 @@ func WithServerCredentials(certFile, keyFile string) Option {
 	return func(o *options) { o.server.CertFile, o.server.KeyFile = certFile, keyFile }
 }
 
+// WithServerInterceptors 设置服务端拦截器
+func WithServerInterceptors(interceptors ...transport.ServerInterceptor) Option {
+	return func(o *options) { o.server.Interceptors = interceptors }
+}
+
 // WithClientPoolSize 设置客户端连接池大小
 func WithClientPoolSize(size int) Option {
 	return func(o *options) { o.client.PoolSize = size }
@@ -71,3 +80,18 @@ func WithClientCredentials(caFile string, serverName string) Option {
 func WithClientDiscovery(discovery registry.Discovery) Option {
 	return func(o *options) { o.client.Discovery = discovery }
 }
+
+// WithClientInterceptors 设置客户端拦截器
+func WithClientInterceptors(interceptors ...transport.ClientInterceptor) Option {
+	return func(o *options) { o.client.Interceptors = interceptors }
+}
+
+// WithClientBreaker 设置客户端是否启用熔断及离群摘除，以及熔断器选项
+func WithClientBreaker(enable bool, opts ...breaker.Option) Option {
+	return func(o *options) { o.client.Breaker, o.client.BreakerOpts = enable, opts }
+}
+
+// WithClientFallback 设置客户端降级处理函数
+func WithClientFallback(fallback transport.Fallback) Option {
+	return func(o *options) { o.client.Fallback = fallback }
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/devagame/due/transport/rpcx/v2/internal/server"
 	"github.com/devagame/due/v2/registry"
 	"github.com/devagame/due/v2/transport"
+	"slices"
 	"sync"
 )
 
@@ -15,6 +16,10 @@ type Transporter struct {
 	opts    *options
 	once    sync.Once
 	builder *client.Builder
+
+	rw                 sync.RWMutex
+	clientInterceptors []transport.ClientInterceptor // 通过UseClientInterceptors设置的客户端拦截器
+	serverInterceptors []transport.ServerInterceptor // 通过UseServerInterceptors设置的服务端拦截器
 }
 
 func NewTransporter(opts ...Option) *Transporter {
@@ -40,9 +45,30 @@ func (t *Transporter) SetDefaultDiscovery(discovery registry.Discovery) {
 	}
 }
 
+// UseClientInterceptors 设置客户端拦截器，在选项设置的拦截器之后执行，仅对之后新建的客户端生效
+// 重复调用时替换之前设置的拦截器，多次调用不会重复添加
+func (t *Transporter) UseClientInterceptors(interceptors ...transport.ClientInterceptor) {
+	t.rw.Lock()
+	t.clientInterceptors = slices.Clone(interceptors)
+	t.rw.Unlock()
+}
+
+// UseServerInterceptors 设置服务端拦截器，在选项设置的拦截器之后执行，仅对之后新建的服务器生效
+// 重复调用时替换之前设置的拦截器，多次调用不会重复添加
+func (t *Transporter) UseServerInterceptors(interceptors ...transport.ServerInterceptor) {
+	t.rw.Lock()
+	t.serverInterceptors = slices.Clone(interceptors)
+	t.rw.Unlock()
+}
+
 // NewServer 新建传输服务器
 func (t *Transporter) NewServer() (transport.Server, error) {
-	return server.NewServer(&t.opts.server)
+	t.rw.RLock()
+	opts := t.opts.server
+	opts.Interceptors = slices.Concat(opts.Interceptors, t.serverInterceptors)
+	t.rw.RUnlock()
+
+	return server.NewServer(&opts)
 }
 
 // NewClient 新建传输客户端
@@ -60,5 +86,10 @@ func (t *Transporter) NewClient(target string) (transport.Client, error) {
 		return nil, err
 	}
 
-	return client.NewClient(cli), nil
+	t.rw.RLock()
+	opts := t.opts.client
+	opts.Interceptors = slices.Concat(opts.Interceptors, t.clientInterceptors)
+	t.rw.RUnlock()
+
+	return client.NewClient(cli, &opts, t.builder.Breaker()), nil
 }
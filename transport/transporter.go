@@ -42,6 +42,10 @@ type Transporter interface {
 	NewClient(target string) (Client, error)
 	// SetDefaultDiscovery 设置默认的服务发现组件
 	SetDefaultDiscovery(discovery registry.Discovery)
+	// UseClientInterceptors 设置客户端拦截器，仅对之后新建的客户端生效，重复调用时替换之前设置的拦截器
+	UseClientInterceptors(interceptors ...ClientInterceptor)
+	// UseServerInterceptors 设置服务端拦截器，仅对之后新建的服务器生效，重复调用时替换之前设置的拦截器
+	UseServerInterceptors(interceptors ...ServerInterceptor)
 }
 
 type NewMeshClient func(target string) (Client, error)
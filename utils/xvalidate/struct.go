@@ -0,0 +1,201 @@
+package xvalidate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/devagame/due/v2/utils/xreflect"
+)
+
+const validateTag = "validate"
+
+// Struct 按照结构体字段的validate标签校验结构体
+// 多个规则以英文逗号分隔，支持以下规则：
+// required      : 字段值不能为零值
+// omitempty     : 字段值为零值时跳过后续规则
+// min=n | max=n : 数值的最小、最大值；字符串、切片、字典的最小、最大长度
+// len=n         : 字符串、切片、字典的固定长度
+// oneof=a b c   : 字段值必须为空格分隔的候选值之一
+// email、url、mobile、telephone、idcard、qq、number、digit : 对应的格式校验
+// 嵌套结构体、结构体指针以及结构体切片会被递归校验
+func Struct(v any) error {
+	kind, rv := xreflect.Value(v)
+	if kind != reflect.Struct {
+		return fmt.Errorf("validate: invalid struct type %T", v)
+	}
+
+	return validateStruct(rv, rv.Type().Name())
+}
+
+// 校验结构体
+func validateStruct(rv reflect.Value, path string) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		name := path + "." + field.Name
+
+		if tag := field.Tag.Get(validateTag); tag != "" && tag != "-" {
+			if err := validateField(fv, name, tag); err != nil {
+				return err
+			}
+		}
+
+		if err := validateNested(fv, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 递归校验嵌套结构
+func validateNested(fv reflect.Value, path string) error {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		return validateStruct(fv, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			if err := validateNested(fv.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// 校验字段
+func validateField(fv reflect.Value, name string, tag string) error {
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch key {
+		case "":
+			continue
+		case "omitempty":
+			if fv.IsZero() {
+				return nil
+			}
+			continue
+		case "required":
+			if fv.IsZero() {
+				return fmt.Errorf("validate: %s is required", name)
+			}
+			continue
+		}
+
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				return fmt.Errorf("validate: %s is nil", name)
+			}
+			fv = fv.Elem()
+		}
+
+		ok, err := checkRule(fv, key, param)
+		if err != nil {
+			return fmt.Errorf("validate: %s %v", name, err)
+		}
+
+		if !ok {
+			return fmt.Errorf("validate: %s does not satisfy the rule '%s'", name, rule)
+		}
+	}
+
+	return nil
+}
+
+// 检测规则
+func checkRule(fv reflect.Value, key, param string) (bool, error) {
+	switch key {
+	case "min", "max", "len":
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, fmt.Errorf("has an invalid rule param '%s'", param)
+		}
+
+		size, ok := measure(fv, key == "len")
+		if !ok {
+			return false, fmt.Errorf("does not support the rule '%s'", key)
+		}
+
+		switch key {
+		case "min":
+			return size >= n, nil
+		case "max":
+			return size <= n, nil
+		default:
+			return size == n, nil
+		}
+	case "oneof":
+		return In(fmt.Sprint(fv.Interface()), strings.Fields(param)), nil
+	}
+
+	if fv.Kind() != reflect.String {
+		return false, fmt.Errorf("does not support the rule '%s'", key)
+	}
+
+	s := fv.String()
+
+	switch key {
+	case "email":
+		return IsEmail(s), nil
+	case "url":
+		return IsUrl(s), nil
+	case "mobile":
+		return IsMobile(s), nil
+	case "telephone":
+		return IsTelephone(s), nil
+	case "idcard":
+		return IsIdCard(s), nil
+	case "qq":
+		return IsQQ(s), nil
+	case "number":
+		return IsNumber(s), nil
+	case "digit":
+		return IsDigit(s), nil
+	default:
+		return false, fmt.Errorf("has an unknown rule '%s'", key)
+	}
+}
+
+// 度量字段值，数值返回值本身，字符串、切片、字典返回长度
+func measure(fv reflect.Value, length bool) (float64, bool) {
+	switch fv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(fv.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(fv.Len()), true
+	}
+
+	if length {
+		return 0, false
+	}
+
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), true
+	default:
+		return 0, false
+	}
+}
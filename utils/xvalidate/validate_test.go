@@ -62,3 +62,29 @@ func TestIn(t *testing.T) {
 func TestIsIdCard(t *testing.T) {
 	t.Log(xvalidate.IsIdCard("512301195011260279"))
 }
+
+func TestStruct(t *testing.T) {
+	type item struct {
+		ID  int `validate:"min=1"`
+		Num int `validate:"min=1,max=99"`
+	}
+
+	type table struct {
+		Name  string `validate:"required,max=8"`
+		Email string `validate:"omitempty,email"`
+		Kind  string `validate:"oneof=normal boss"`
+		Items []item `validate:"min=1"`
+	}
+
+	valid := &table{Name: "dungeon", Kind: "boss", Items: []item{{ID: 1, Num: 2}}}
+	if err := xvalidate.Struct(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := &table{Name: "dungeon", Kind: "boss", Items: []item{{ID: 1, Num: 100}}}
+	if err := xvalidate.Struct(invalid); err == nil {
+		t.Fatal("expect a validation error")
+	} else {
+		t.Log(err)
+	}
+}